@@ -138,7 +138,7 @@ func main() {
 	}
 	logger.Info("Starting sidecar query server")
 
-	sidecarQueryServer, err := NewSideCarQueryServer(encCfg.Marshaler, config, logger)
+	sidecarQueryServer, err := NewSideCarQueryServer(ctx, encCfg.Marshaler, config, logger)
 	if err != nil {
 		panic(err)
 	}
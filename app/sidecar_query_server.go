@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/labstack/echo/v4"
@@ -12,6 +13,7 @@ import (
 	ingestrpcdelivry "github.com/osmosis-labs/sqs/ingest/delivery/grpc"
 	ingestusecase "github.com/osmosis-labs/sqs/ingest/usecase"
 
+	"github.com/osmosis-labs/sqs/chaininfo/client"
 	chaininforepo "github.com/osmosis-labs/sqs/chaininfo/repository"
 	chaininfousecase "github.com/osmosis-labs/sqs/chaininfo/usecase"
 	poolsHttpDelivery "github.com/osmosis-labs/sqs/pools/delivery/http"
@@ -85,7 +87,8 @@ func (sqs *sideCarQueryServer) Start(context.Context) error {
 }
 
 // NewSideCarQueryServer creates a new sidecar query server (SQS).
-func NewSideCarQueryServer(appCodec codec.Codec, config domain.Config, logger log.Logger) (SideCarQueryServer, error) {
+// The context bounds the lifetime of the background workers started by the server.
+func NewSideCarQueryServer(ctx context.Context, appCodec codec.Codec, config domain.Config, logger log.Logger) (SideCarQueryServer, error) {
 	// Setup echo server
 	e := echo.New()
 	middleware := middleware.InitMiddleware(config.CORS)
@@ -99,7 +102,10 @@ func NewSideCarQueryServer(appCodec codec.Codec, config domain.Config, logger lo
 	poolsUseCase := poolsUseCase.NewPoolsUsecase(config.Pools, config.ChainGRPCGatewayEndpoint, routerRepository)
 
 	// Initialize router repository, usecase
-	routerUsecase := routerUseCase.NewRouterUsecase(routerRepository, poolsUseCase, *config.Router, poolsUseCase.GetCosmWasmPoolConfig(), logger, cache.New(), cache.New())
+	routerUsecase, err := routerUseCase.NewRouterUsecase(routerRepository, poolsUseCase, *config.Router, poolsUseCase.GetCosmWasmPoolConfig(), logger, cache.New(), cache.New())
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize system handler
 	chainInfoRepository := chaininforepo.New()
@@ -115,11 +121,21 @@ func NewSideCarQueryServer(appCodec codec.Codec, config domain.Config, logger lo
 	tokensUseCase := tokensUseCase.NewTokensUsecase(tokenMetadataByChainDenom)
 
 	// Initialize chain pricing strategy
-	chainPricingSource, err := pricing.NewPricingStrategy(*config.Pricing, tokensUseCase, routerUsecase)
+	chainPricingSource, chainPricingSourceAdmin, err := pricing.NewPricingStrategy(ctx, *config.Pricing, tokensUseCase, routerUsecase, logger)
 	if err != nil {
 		return nil, err
 	}
 
+	// Detect the prices computed from the pools that are stale due to the ingest lag.
+	if config.Pricing.MaxPoolDataStalenessBlocks > 0 {
+		chainClient, err := client.NewClient(config.ChainID, config.ChainGRPCGatewayEndpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		chainPricingSourceAdmin.SetBlockHeightProvider(chaininfousecase.NewBlockHeightProvider(chainInfoRepository, chainClient))
+	}
+
 	// Register pricing strategy on the tokens use case.
 	tokensUseCase.RegisterPricingStrategy(domain.ChainPricingSourceType, chainPricingSource)
 
@@ -134,20 +150,20 @@ func NewSideCarQueryServer(appCodec codec.Codec, config domain.Config, logger lo
 	// Start grpc ingest server if enabled
 	grpcIngesterConfig := config.GRPCIngester
 	if grpcIngesterConfig.Enabeld {
-		// Get the default quote denom
-		defaultQuoteDenom, err := tokensUseCase.GetChainDenom(config.Pricing.DefaultQuoteHumanDenom)
-		if err != nil {
-			return nil, err
-		}
+		// Get the default quote denom.
+		// It is the synthetic composite quote denom if configured so that the worker refreshes
+		// the composite prices cached indefinitely rather than the prices in a component.
+		defaultQuoteDenom := chainPricingSourceAdmin.DefaultQuoteDenom()
 
-		quotePriceUpdateWorker := pricingWorker.New(tokensUseCase, defaultQuoteDenom, logger)
+		workerUpdateTimeout := time.Duration(config.Pricing.WorkerUpdateTimeoutMs) * time.Millisecond
+		quotePriceUpdateWorker := pricingWorker.New(ctx, tokensUseCase, chainPricingSourceAdmin, defaultQuoteDenom, workerUpdateTimeout, config.Pricing.WorkerFullRecomputeHeightInterval, logger)
 
 		// chain info use case acts as the healthcheck. It receives updates from the pricing worker.
 		// It then passes the healthcheck as long as updates are received at the appropriate intervals.
 		quotePriceUpdateWorker.RegisterListener(chainInfoUseCase)
 
 		// Initialize ingest handler and usecase
-		ingestUseCase, err := ingestusecase.NewIngestUsecase(poolsUseCase, routerUsecase, chainInfoUseCase, appCodec, quotePriceUpdateWorker, logger)
+		ingestUseCase, err := ingestusecase.NewIngestUsecase(poolsUseCase, routerUsecase, chainInfoUseCase, tokensUseCase, appCodec, quotePriceUpdateWorker, logger)
 		if err != nil {
 			return nil, err
 		}
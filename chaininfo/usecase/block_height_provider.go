@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/osmosis-labs/sqs/chaininfo/client"
+	chaininforepo "github.com/osmosis-labs/sqs/chaininfo/repository"
+	"github.com/osmosis-labs/sqs/domain"
+)
+
+// chainHeightRefreshInterval is the min interval between the chain height queries to the node.
+// It is well below the block time so that the ingest lag is detected within a block.
+const chainHeightRefreshInterval = time.Second
+
+// chainHeightRefreshTimeout bounds the chain height queries to the node.
+const chainHeightRefreshTimeout = 5 * time.Second
+
+type blockHeightProvider struct {
+	chainInfoRepository chaininforepo.ChainInfoRepository
+	chainClient         client.Client
+
+	// The chain height is refreshed in the background at most once per refresh interval
+	// so that the price computations never wait on the node.
+	chainHeightMx        sync.RWMutex
+	chainHeight          uint64
+	chainHeightUpdatedAt time.Time
+	isRefreshing         atomic.Bool
+}
+
+var _ domain.BlockHeightProvider = &blockHeightProvider{}
+
+// NewBlockHeightProvider returns a block height provider with the pool data height
+// from the latest ingested height and the chain height from the node.
+func NewBlockHeightProvider(chainInfoRepository chaininforepo.ChainInfoRepository, chainClient client.Client) domain.BlockHeightProvider {
+	return &blockHeightProvider{
+		chainInfoRepository: chainInfoRepository,
+		chainClient:         chainClient,
+	}
+}
+
+// GetPoolDataHeight implements domain.BlockHeightProvider.
+func (p *blockHeightProvider) GetPoolDataHeight() uint64 {
+	return p.chainInfoRepository.GetLatestHeight()
+}
+
+// GetChainHeight implements domain.BlockHeightProvider.
+// Returns the last chain height fetched from the node, triggering a background refresh if it is older
+// than the refresh interval. If the node is unreachable, the last fetched height keeps being returned.
+// Returns domain.ErrChainHeightUnavailable until the chain height is fetched for the first time.
+func (p *blockHeightProvider) GetChainHeight(ctx context.Context) (uint64, error) {
+	p.chainHeightMx.RLock()
+	chainHeight, chainHeightUpdatedAt := p.chainHeight, p.chainHeightUpdatedAt
+	p.chainHeightMx.RUnlock()
+
+	if time.Since(chainHeightUpdatedAt) >= chainHeightRefreshInterval && p.isRefreshing.CompareAndSwap(false, true) {
+		go p.refreshChainHeight()
+	}
+
+	if chainHeightUpdatedAt.IsZero() {
+		return 0, domain.ErrChainHeightUnavailable
+	}
+
+	return chainHeight, nil
+}
+
+// refreshChainHeight fetches the chain height from the node.
+// The previous chain height is kept on failure.
+func (p *blockHeightProvider) refreshChainHeight() {
+	defer p.isRefreshing.Store(false)
+
+	// Detached from the request that triggered the refresh so that its cancellation does not fail the refresh.
+	ctx, cancel := context.WithTimeout(context.Background(), chainHeightRefreshTimeout)
+	defer cancel()
+
+	chainHeight, err := p.chainClient.GetLatestHeight(ctx)
+	if err != nil {
+		return
+	}
+
+	p.chainHeightMx.Lock()
+	defer p.chainHeightMx.Unlock()
+
+	p.chainHeight = chainHeight
+	p.chainHeightUpdatedAt = time.Now()
+}
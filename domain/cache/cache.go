@@ -1,14 +1,41 @@
 package cache
 
 import (
+	"container/list"
 	"sync"
 	"time"
 )
 
 // Cache is a concurrent cache structure.
+// The keys are partitioned across independently locked shards by their hash so that
+// the concurrent operations on the keys of different shards do not contend on the same lock.
 type Cache struct {
+	shards []*cacheShard
+
+	// gracePeriod is the duration for which the expired items are retained
+	// so that they can still be retrieved via GetStale.
+	gracePeriod time.Duration
+
+	// onEvict is invoked with the key and the value of the evicted items.
+	// Nil if not registered.
+	onEvict   func(key string, value interface{})
+	onEvictMu sync.RWMutex
+}
+
+// cacheShard is a partition of the cache items guarded by its own lock.
+type cacheShard struct {
 	data  map[string]CacheItem
 	mutex sync.RWMutex
+
+	// maxEntries is the max number of items in the shard beyond which the least recently used items are evicted.
+	// Zero implies no limit.
+	maxEntries int
+	// recency orders the keys of the items with expiration from the most to the least recently used.
+	// The items without expiration are pinned so they are neither tracked nor evicted.
+	// Nil if the shard has no limit.
+	recency *list.List
+	// recencyElements maps the keys tracked by recency to their elements.
+	recencyElements map[string]*list.Element
 }
 
 // CacheItem represents an item in the cache.
@@ -19,58 +46,431 @@ type CacheItem struct {
 
 const NoExpirationTTL time.Duration = 0
 
-// New creates a new concurrent cache.
+// Status is the status of a cache lookup.
+type Status int
+
+const (
+	// StatusMissing implies that the key is not in the cache.
+	// That is, it has never been set or it has been removed.
+	StatusMissing Status = iota
+	// StatusExpired implies that the key is in the cache but has expired.
+	StatusExpired
+	// StatusHit implies that the key is in the cache and has not expired.
+	StatusHit
+)
+
+// New creates a new concurrent cache with a single shard.
 func New() *Cache {
+	return newCache(1, 0, 0)
+}
+
+// NewWithGracePeriod creates a new concurrent cache that retains the expired items
+// for the given grace period so that they can still be retrieved via GetStale.
+// The expired items are misses for Get regardless of the grace period.
+func NewWithGracePeriod(gracePeriod time.Duration) *Cache {
+	return newCache(1, gracePeriod, 0)
+}
+
+// NewSharded creates a new concurrent cache partitioning the keys across n shards by their hash
+// to reduce the lock contention under high concurrency. It behaves the same as the cache created by New
+// except that Range and Keys observe each shard at a different instant.
+// Non-positive n implies a single shard.
+func NewSharded(n int) *Cache {
+	return newCache(n, 0, 0)
+}
+
+// NewShardedWithGracePeriod creates a new concurrent cache partitioning the keys across n shards by their hash
+// that is bounded to the given max number of entries and retains the expired items for the given grace period.
+// The bound is split evenly across the shards (rounded up), each evicting its own least recently used items,
+// so the evicted items are the least recently used ones of their shard rather than of the whole cache.
+// See NewSharded, NewLRU and NewWithGracePeriod. Non-positive n implies a single shard
+// and non-positive maxEntries implies no bound.
+func NewShardedWithGracePeriod(n int, maxEntries int, gracePeriod time.Duration) *Cache {
+	if n < 1 {
+		n = 1
+	}
+
+	maxEntriesPerShard := 0
+	if maxEntries > 0 {
+		maxEntriesPerShard = (maxEntries + n - 1) / n
+	}
+
+	return newCache(n, gracePeriod, maxEntriesPerShard)
+}
+
+// NewLRU creates a new concurrent cache bounded to the given max number of entries.
+// Setting an item beyond the bound evicts the least recently set or retrieved items with expiration.
+// The items without expiration (NoExpirationTTL) are pinned. They count towards the bound but are never evicted
+// so the cache might exceed the bound if it is filled with the pinned items.
+// Non-positive maxEntries implies no bound.
+func NewLRU(maxEntries int) *Cache {
+	return newCache(1, 0, maxEntries)
+}
+
+// NewLRUWithGracePeriod creates a new concurrent cache bounded to the given max number of entries
+// that retains the expired items for the given grace period. See NewLRU and NewWithGracePeriod.
+func NewLRUWithGracePeriod(maxEntries int, gracePeriod time.Duration) *Cache {
+	return newCache(1, gracePeriod, maxEntries)
+}
+
+// newCache creates a new concurrent cache with the given number of shards, grace period
+// and max number of entries per shard.
+func newCache(numShards int, gracePeriod time.Duration, maxEntries int) *Cache {
+	if numShards < 1 {
+		numShards = 1
+	}
+
+	shards := make([]*cacheShard, numShards)
+	for i := range shards {
+		shards[i] = &cacheShard{
+			data: make(map[string]CacheItem),
+		}
+
+		if maxEntries > 0 {
+			shards[i].maxEntries = maxEntries
+			shards[i].recency = list.New()
+			shards[i].recencyElements = make(map[string]*list.Element)
+		}
+	}
+
 	return &Cache{
-		data: make(map[string]CacheItem),
+		shards:      shards,
+		gracePeriod: gracePeriod,
 	}
 }
 
+// getShard returns the shard of the given key.
+func (c *Cache) getShard(key string) *cacheShard {
+	if len(c.shards) == 1 {
+		return c.shards[0]
+	}
+
+	// FNV-1a hash of the key computed inline to avoid allocating.
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+
+	return c.shards[hash%uint32(len(c.shards))]
+}
+
+// getOnEvict returns the registered eviction callback. Nil if not registered.
+func (c *Cache) getOnEvict() func(key string, value interface{}) {
+	c.onEvictMu.RLock()
+	defer c.onEvictMu.RUnlock()
+
+	return c.onEvict
+}
+
+// OnEvict registers the callback invoked with the key and the value of the items
+// that are overwritten by Set, removed for expiring past the grace period by Get, GetWithStatus or PurgeExpired
+// or evicted by Set for exceeding the max number of entries, as well as the items removed by Delete.
+// The callback is invoked outside of the cache lock
+// so that it may access the cache. Replaces the previously registered callback, if any.
+func (c *Cache) OnEvict(onEvict func(key string, value interface{})) {
+	c.onEvictMu.Lock()
+	defer c.onEvictMu.Unlock()
+
+	c.onEvict = onEvict
+}
+
 // Set adds an item to the cache with a specified key, value, and expiration time.
 func (c *Cache) Set(key string, value interface{}, expiration time.Duration) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	shard := c.getShard(key)
+
+	shard.mutex.Lock()
 
 	expirationTime := time.Time{}
 	if expiration != NoExpirationTTL {
 		expirationTime = time.Now().Add(expiration)
 	}
-	c.data[key] = CacheItem{
+
+	evictedItem, isOverwrite := shard.data[key]
+	shard.data[key] = CacheItem{
 		Value:      value,
 		Expiration: expirationTime,
 	}
+
+	shard.track(key, expirationTime.IsZero())
+	lruEvictedItems := shard.evictLeastRecentlyUsed()
+
+	shard.mutex.Unlock()
+
+	onEvict := c.getOnEvict()
+	if onEvict == nil {
+		return
+	}
+
+	if isOverwrite {
+		onEvict(key, evictedItem.Value)
+	}
+
+	for evictedKey, item := range lruEvictedItems {
+		onEvict(evictedKey, item.Value)
+	}
+}
+
+// track marks the item of the given key as the most recently used unless it is pinned,
+// in which case it is no longer tracked. No-op if the shard has no limit.
+// The shard must be write locked.
+func (s *cacheShard) track(key string, isPinned bool) {
+	if s.recency == nil {
+		return
+	}
+
+	element, isTracked := s.recencyElements[key]
+	switch {
+	case isPinned && isTracked:
+		s.recency.Remove(element)
+		delete(s.recencyElements, key)
+	case isPinned:
+	case isTracked:
+		s.recency.MoveToFront(element)
+	default:
+		s.recencyElements[key] = s.recency.PushFront(key)
+	}
+}
+
+// markUsed marks the item of the given key as the most recently used if it is still tracked.
+// No-op if the shard has no limit. Takes the shard write lock unless the shard has no limit.
+func (s *cacheShard) markUsed(key string) {
+	if s.recency == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if element, isTracked := s.recencyElements[key]; isTracked {
+		s.recency.MoveToFront(element)
+	}
+}
+
+// untrack stops tracking the item of the given key. No-op if the shard has no limit or the item is not tracked.
+// The shard must be write locked.
+func (s *cacheShard) untrack(key string) {
+	if s.recency == nil {
+		return
+	}
+
+	if element, isTracked := s.recencyElements[key]; isTracked {
+		s.recency.Remove(element)
+		delete(s.recencyElements, key)
+	}
+}
+
+// evictLeastRecentlyUsed removes the least recently used items until the shard is within its max number of entries
+// or only the pinned items are left. Returns the evicted items by their keys.
+// The shard must be write locked.
+func (s *cacheShard) evictLeastRecentlyUsed() map[string]CacheItem {
+	if s.recency == nil {
+		return nil
+	}
+
+	var evictedItems map[string]CacheItem
+	for len(s.data) > s.maxEntries && s.recency.Len() > 0 {
+		key := s.recency.Remove(s.recency.Back()).(string)
+		delete(s.recencyElements, key)
+
+		if evictedItems == nil {
+			evictedItems = make(map[string]CacheItem)
+		}
+		evictedItems[key] = s.data[key]
+		delete(s.data, key)
+	}
+
+	return evictedItems
 }
 
 // Get retrieves the value associated with a key from the cache.
 func (c *Cache) Get(key string) (interface{}, bool) {
-	c.mutex.RLock()
+	value, status := c.GetWithStatus(key)
+	return value, status == StatusHit
+}
+
+// GetWithStatus retrieves the value associated with a key from the cache
+// alongside the status distinguishing the missing keys from the expired keys.
+// The value is nil unless the status is StatusHit.
+// The expired items are reported as StatusExpired within the grace period.
+// Past the grace period, they are removed on lookup and reported as StatusMissing
+// so that the caches that are never purged do not retain them.
+func (c *Cache) GetWithStatus(key string) (interface{}, Status) {
+	shard := c.getShard(key)
+
+	shard.mutex.RLock()
 
-	item, exists := c.data[key]
+	item, exists := shard.data[key]
 	if !exists {
-		c.mutex.RUnlock()
-		return nil, false
+		shard.mutex.RUnlock()
+		return nil, StatusMissing
 	}
 
-	if !item.Expiration.IsZero() && time.Now().After(item.Expiration) {
-		// Unlock before locking again
-		c.mutex.RUnlock()
+	now := time.Now()
+	if !item.Expiration.IsZero() && now.After(item.Expiration) {
+		shard.mutex.RUnlock()
 
-		// Acquire write mutex.
-		c.mutex.Lock()
-		delete(c.data, key)
-		c.mutex.Unlock()
-		return nil, false
+		if c.isPastGracePeriod(item, now) {
+			c.deleteExpired(key, now)
+			return nil, StatusMissing
+		}
+
+		return nil, StatusExpired
 	}
 
-	c.mutex.RUnlock()
+	// Only the tracked items need the write lock to be marked as used so that the hits
+	// on the unbounded caches and on the pinned items need the read lock alone.
+	_, isTracked := shard.recencyElements[key]
+	shard.mutex.RUnlock()
+
+	if isTracked {
+		shard.markUsed(key)
+	}
 
-	return item.Value, true
+	return item.Value, StatusHit
+}
+
+// GetStale retrieves the value associated with a key from the cache
+// even if it has expired as long as it is within the grace period.
+// Returns true if the value has expired.
+func (c *Cache) GetStale(key string) (value interface{}, isExpired bool, found bool) {
+	shard := c.getShard(key)
+
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+
+	item, exists := shard.data[key]
+	if !exists {
+		return nil, false, false
+	}
+
+	now := time.Now()
+	if c.isPastGracePeriod(item, now) {
+		return nil, false, false
+	}
+
+	isExpired = !item.Expiration.IsZero() && now.After(item.Expiration)
+	return item.Value, isExpired, true
+}
+
+// deleteExpired removes the item of the given key if it has expired for longer than the grace period
+// at the given time. Rechecked under the write lock since the item may have been overwritten
+// after the lookup. Invokes the eviction callback if the item is removed.
+func (c *Cache) deleteExpired(key string, now time.Time) {
+	shard := c.getShard(key)
+
+	shard.mutex.Lock()
+	item, exists := shard.data[key]
+	isDeleted := exists && c.isPastGracePeriod(item, now)
+	if isDeleted {
+		delete(shard.data, key)
+		shard.untrack(key)
+	}
+	shard.mutex.Unlock()
+
+	if onEvict := c.getOnEvict(); isDeleted && onEvict != nil {
+		onEvict(key, item.Value)
+	}
+}
+
+// isPastGracePeriod returns true if the given item has expired for longer than the grace period.
+func (c *Cache) isPastGracePeriod(item CacheItem, now time.Time) bool {
+	return !item.Expiration.IsZero() && now.After(item.Expiration.Add(c.gracePeriod))
+}
+
+// PurgeExpired removes all items expired for longer than the grace period from the cache.
+// Returns the number of removed items.
+func (c *Cache) PurgeExpired() int {
+	now := time.Now()
+
+	purgedItems := map[string]CacheItem{}
+	for _, shard := range c.shards {
+		shard.mutex.Lock()
+		for key, item := range shard.data {
+			if c.isPastGracePeriod(item, now) {
+				delete(shard.data, key)
+				shard.untrack(key)
+				purgedItems[key] = item
+			}
+		}
+		shard.mutex.Unlock()
+	}
+
+	if onEvict := c.getOnEvict(); onEvict != nil {
+		for key, item := range purgedItems {
+			onEvict(key, item.Value)
+		}
+	}
+
+	return len(purgedItems)
+}
+
+// Range calls f sequentially for each unexpired key, value and expiration time present in the cache.
+// Zero expiration time implies no expiration. If f returns false, range stops the iteration.
+// The cache must not be modified from within f.
+func (c *Cache) Range(f func(key string, value interface{}, expiry time.Time) bool) {
+	now := time.Now()
+
+	for _, shard := range c.shards {
+		if !shard.rangeUnexpired(now, f) {
+			return
+		}
+	}
+}
+
+// rangeUnexpired calls f sequentially for each item of the shard unexpired at the given time.
+// Returns false if f stopped the iteration.
+func (s *cacheShard) rangeUnexpired(now time.Time, f func(key string, value interface{}, expiry time.Time) bool) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for key, item := range s.data {
+		if !item.Expiration.IsZero() && now.After(item.Expiration) {
+			continue
+		}
+
+		if !f(key, item.Value, item.Expiration) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Len returns the number of unexpired items in the cache.
+func (c *Cache) Len() int {
+	count := 0
+	c.Range(func(key string, value interface{}, expiry time.Time) bool {
+		count++
+		return true
+	})
+
+	return count
+}
+
+// Keys returns the keys of the unexpired items in the cache in no particular order.
+func (c *Cache) Keys() []string {
+	keys := make([]string, 0)
+	c.Range(func(key string, value interface{}, expiry time.Time) bool {
+		keys = append(keys, key)
+		return true
+	})
+
+	return keys
 }
 
 // Delete removes an item from the cache.
+// Invokes the eviction callback if the item is found.
 func (c *Cache) Delete(key string) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	shard := c.getShard(key)
+
+	shard.mutex.Lock()
+	item, found := shard.data[key]
+	delete(shard.data, key)
+	shard.untrack(key)
+	shard.mutex.Unlock()
 
-	delete(c.data, key)
+	if onEvict := c.getOnEvict(); found && onEvict != nil {
+		onEvict(key, item.Value)
+	}
 }
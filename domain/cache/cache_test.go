@@ -3,6 +3,8 @@ package cache_test
 import (
 	"fmt"
 	"math/rand"
+	"reflect"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -179,3 +181,586 @@ func TestCache_SetExpiration(t *testing.T) {
 		})
 	}
 }
+
+// Validates that PurgeExpired removes only the expired items and returns their count.
+func TestCache_PurgeExpired(t *testing.T) {
+	cache := cache.New()
+
+	cache.Set("expired1", "value", time.Nanosecond)
+	cache.Set("expired2", "value", time.Nanosecond)
+	cache.Set("valid", "value", time.Minute)
+	cache.Set("noExpiration", "value", 0)
+
+	// Sleep to simulate expiration
+	time.Sleep(time.Millisecond * 10)
+
+	purgedCount := cache.PurgeExpired()
+	if purgedCount != 2 {
+		t.Errorf("Expected purged count: %d, Got: %d", 2, purgedCount)
+	}
+
+	for _, key := range []string{"valid", "noExpiration"} {
+		if _, exists := cache.Get(key); !exists {
+			t.Errorf("Expected key %s to exist", key)
+		}
+	}
+
+	// Purging again removes nothing.
+	if purgedCount := cache.PurgeExpired(); purgedCount != 0 {
+		t.Errorf("Expected purged count: %d, Got: %d", 0, purgedCount)
+	}
+}
+
+func TestCache_Range(t *testing.T) {
+	cache := cache.New()
+
+	cache.Set("expired", "value", time.Nanosecond)
+	cache.Set("valid", "value1", time.Minute)
+	cache.Set("noExpiration", "value2", 0)
+
+	// Sleep to simulate expiration
+	time.Sleep(time.Millisecond * 10)
+
+	ranged := map[string]interface{}{}
+	cache.Range(func(key string, value interface{}, expiry time.Time) bool {
+		if key == "noExpiration" && !expiry.IsZero() {
+			t.Errorf("Expected zero expiry for key %s, Got: %s", key, expiry)
+		}
+		if key == "valid" && expiry.IsZero() {
+			t.Errorf("Expected non-zero expiry for key %s", key)
+		}
+
+		ranged[key] = value
+		return true
+	})
+
+	expected := map[string]interface{}{
+		"valid":        "value1",
+		"noExpiration": "value2",
+	}
+	if !reflect.DeepEqual(ranged, expected) {
+		t.Errorf("Expected ranged items: %v, Got: %v", expected, ranged)
+	}
+
+	// Returning false stops the iteration.
+	rangedCount := 0
+	cache.Range(func(key string, value interface{}, expiry time.Time) bool {
+		rangedCount++
+		return false
+	})
+	if rangedCount != 1 {
+		t.Errorf("Expected ranged count: %d, Got: %d", 1, rangedCount)
+	}
+}
+
+// Validates that the expired items are retained for GetStale within the grace period
+// while remaining misses for Get.
+func TestCache_GetStale(t *testing.T) {
+	graceCache := cache.NewWithGracePeriod(time.Minute)
+
+	graceCache.Set("expired", "value1", time.Nanosecond)
+	graceCache.Set("valid", "value2", time.Minute)
+
+	// Sleep to simulate expiration
+	time.Sleep(time.Millisecond * 10)
+
+	// Expired items are misses for Get but are not removed within the grace period.
+	if _, exists := graceCache.Get("expired"); exists {
+		t.Errorf("Expected key %s to be a miss", "expired")
+	}
+
+	value, isExpired, found := graceCache.GetStale("expired")
+	if !found || !isExpired || value != "value1" {
+		t.Errorf("Expected stale value: %s, Got: %v, expired: %t, found: %t", "value1", value, isExpired, found)
+	}
+
+	value, isExpired, found = graceCache.GetStale("valid")
+	if !found || isExpired || value != "value2" {
+		t.Errorf("Expected fresh value: %s, Got: %v, expired: %t, found: %t", "value2", value, isExpired, found)
+	}
+
+	if _, _, found := graceCache.GetStale("missing"); found {
+		t.Errorf("Expected key %s to be a miss", "missing")
+	}
+
+	// Purging removes nothing within the grace period.
+	if purgedCount := graceCache.PurgeExpired(); purgedCount != 0 {
+		t.Errorf("Expected purged count: %d, Got: %d", 0, purgedCount)
+	}
+
+	// Items past the grace period are gone.
+	noGraceCache := cache.New()
+	noGraceCache.Set("expired", "value1", time.Nanosecond)
+	time.Sleep(time.Millisecond * 10)
+	if _, _, found := noGraceCache.GetStale("expired"); found {
+		t.Errorf("Expected key %s to be a miss past the grace period", "expired")
+	}
+}
+
+// Validates that Len and Keys account for the inserted items and exclude the expired items.
+func TestCache_LenAndKeys(t *testing.T) {
+	cache := cache.New()
+
+	if length := cache.Len(); length != 0 {
+		t.Errorf("Expected length: %d, Got: %d", 0, length)
+	}
+	if keys := cache.Keys(); len(keys) != 0 {
+		t.Errorf("Expected no keys, Got: %v", keys)
+	}
+
+	cache.Set("expired", "value", time.Nanosecond)
+	cache.Set("valid", "value1", time.Minute)
+	cache.Set("noExpiration", "value2", 0)
+
+	// Overwriting does not add an item.
+	cache.Set("valid", "value3", time.Minute)
+
+	// Sleep to simulate expiration
+	time.Sleep(time.Millisecond * 10)
+
+	if length := cache.Len(); length != 2 {
+		t.Errorf("Expected length: %d, Got: %d", 2, length)
+	}
+
+	keys := cache.Keys()
+	sort.Strings(keys)
+	expected := []string{"noExpiration", "valid"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys: %v, Got: %v", expected, keys)
+	}
+
+	cache.Delete("valid")
+	if length := cache.Len(); length != 1 {
+		t.Errorf("Expected length: %d, Got: %d", 1, length)
+	}
+}
+
+// Validates that Len and Keys are safe for concurrent use with the writes.
+func TestCache_LenAndKeys_Concurrent(t *testing.T) {
+	cache := cache.New()
+
+	const (
+		numWriters       = 10
+		numKeysPerWriter = 100
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWriters; i++ {
+		wg.Add(2)
+
+		go func(writer int) {
+			defer wg.Done()
+			for j := 0; j < numKeysPerWriter; j++ {
+				cache.Set(fmt.Sprintf("key%d_%d", writer, j), j, time.Minute)
+			}
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numKeysPerWriter; j++ {
+				_ = cache.Len()
+				_ = cache.Keys()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if length := cache.Len(); length != numWriters*numKeysPerWriter {
+		t.Errorf("Expected length: %d, Got: %d", numWriters*numKeysPerWriter, length)
+	}
+	if keys := cache.Keys(); len(keys) != numWriters*numKeysPerWriter {
+		t.Errorf("Expected keys count: %d, Got: %d", numWriters*numKeysPerWriter, len(keys))
+	}
+}
+
+// Validates that the eviction callback is invoked for the overwritten items,
+// for the expired items on purge and for the deleted items.
+func TestCache_OnEvict(t *testing.T) {
+	cache := cache.New()
+
+	evicted := map[string]interface{}{}
+	cache.OnEvict(func(key string, value interface{}) {
+		evicted[key] = value
+
+		// The callback is invoked outside of the lock so accessing the cache does not deadlock.
+		_ = cache.Len()
+	})
+
+	// Inserting a new key does not evict.
+	cache.Set("overwritten", "value1", time.Minute)
+	if len(evicted) != 0 {
+		t.Errorf("Expected no evictions, Got: %v", evicted)
+	}
+
+	// Overwriting evicts the previous value.
+	cache.Set("overwritten", "value2", time.Minute)
+	expected := map[string]interface{}{"overwritten": "value1"}
+	if !reflect.DeepEqual(evicted, expected) {
+		t.Errorf("Expected evictions: %v, Got: %v", expected, evicted)
+	}
+
+	// Purging evicts the expired items.
+	cache.Set("expired", "value3", time.Nanosecond)
+	time.Sleep(time.Millisecond * 10)
+	cache.PurgeExpired()
+	expected["expired"] = "value3"
+	if !reflect.DeepEqual(evicted, expected) {
+		t.Errorf("Expected evictions: %v, Got: %v", expected, evicted)
+	}
+
+	// Deleting evicts the deleted value.
+	cache.Delete("overwritten")
+	expected["overwritten"] = "value2"
+	if !reflect.DeepEqual(evicted, expected) {
+		t.Errorf("Expected evictions: %v, Got: %v", expected, evicted)
+	}
+
+	// Deleting the missing key does not evict.
+	delete(expected, "overwritten")
+	delete(evicted, "overwritten")
+	cache.Delete("overwritten")
+	if !reflect.DeepEqual(evicted, expected) {
+		t.Errorf("Expected evictions: %v, Got: %v", expected, evicted)
+	}
+}
+
+// Validates that GetWithStatus distinguishes the hits, the missing keys and the expired keys.
+func TestCache_GetWithStatus(t *testing.T) {
+	statusCache := cache.New()
+
+	statusCache.Set("expired", "value1", time.Nanosecond)
+	statusCache.Set("valid", "value2", time.Minute)
+
+	// Sleep to simulate expiration
+	time.Sleep(time.Millisecond * 10)
+
+	if value, status := statusCache.GetWithStatus("valid"); status != cache.StatusHit || value != "value2" {
+		t.Errorf("Expected hit with value: %s, Got: %v, status: %d", "value2", value, status)
+	}
+
+	// Without the grace period, the expired item is removed on lookup.
+	if value, status := statusCache.GetWithStatus("expired"); status != cache.StatusMissing || value != nil {
+		t.Errorf("Expected missing with nil value, Got: %v, status: %d", value, status)
+	}
+	if _, _, found := statusCache.GetStale("expired"); found {
+		t.Errorf("Expected expired item to be removed")
+	}
+
+	// Within the grace period, the expired item is reported as expired and retained.
+	graceCache := cache.NewWithGracePeriod(time.Minute)
+	graceCache.Set("expired", "value1", time.Nanosecond)
+	time.Sleep(time.Millisecond * 10)
+
+	for i := 0; i < 2; i++ {
+		if value, status := graceCache.GetWithStatus("expired"); status != cache.StatusExpired || value != nil {
+			t.Errorf("Expected expired with nil value, Got: %v, status: %d", value, status)
+		}
+	}
+
+	if _, status := statusCache.GetWithStatus("missing"); status != cache.StatusMissing {
+		t.Errorf("Expected missing status, Got: %d", status)
+	}
+}
+
+// Validates that the LRU cache evicts the least recently set or retrieved items
+// once over capacity and invokes the eviction callback for them.
+func TestLRUCache_EvictionOrder(t *testing.T) {
+	lruCache := cache.NewLRU(3)
+
+	evicted := []string{}
+	lruCache.OnEvict(func(key string, value interface{}) {
+		evicted = append(evicted, key)
+	})
+
+	lruCache.Set("a", "a", time.Minute)
+	lruCache.Set("b", "b", time.Minute)
+	lruCache.Set("c", "c", time.Minute)
+
+	// Retrieving "a" makes "b" the least recently used.
+	if _, found := lruCache.Get("a"); !found {
+		t.Errorf("Expected key a to be found")
+	}
+
+	lruCache.Set("d", "d", time.Minute)
+	if expected := []string{"b"}; !reflect.DeepEqual(evicted, expected) {
+		t.Errorf("Expected evictions: %v, Got: %v", expected, evicted)
+	}
+
+	// Overwriting "c" makes "a" the least recently used.
+	lruCache.Set("c", "c2", time.Minute)
+	lruCache.Set("e", "e", time.Minute)
+	if expected := []string{"b", "c", "a"}; !reflect.DeepEqual(evicted, expected) {
+		t.Errorf("Expected evictions: %v, Got: %v", expected, evicted)
+	}
+
+	keys := lruCache.Keys()
+	sort.Strings(keys)
+	if expected := []string{"c", "d", "e"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys: %v, Got: %v", expected, keys)
+	}
+
+	// Deleted items free up capacity so that only the deleted item is evicted.
+	lruCache.Delete("d")
+	lruCache.Set("f", "f", time.Minute)
+	if expected := []string{"b", "c", "a", "d"}; lruCache.Len() != 3 || !reflect.DeepEqual(evicted, expected) {
+		t.Errorf("Expected 3 items and evictions: %v, Got: %d items, %v evictions", expected, lruCache.Len(), evicted)
+	}
+}
+
+// Validates that the LRU cache never evicts the items without expiration,
+// even if they are the least recently used or fill the cache beyond capacity.
+func TestLRUCache_PinnedEntriesSurvive(t *testing.T) {
+	lruCache := cache.NewLRU(2)
+
+	lruCache.Set("pinned", "pinned", cache.NoExpiration)
+	lruCache.Set("a", "a", time.Minute)
+	lruCache.Set("b", "b", time.Minute)
+
+	// The pinned item is the least recently used but "a" is evicted instead.
+	if _, found := lruCache.Get("pinned"); !found {
+		t.Errorf("Expected pinned key to be found")
+	}
+	if _, found := lruCache.Get("a"); found {
+		t.Errorf("Expected key a to be evicted")
+	}
+
+	// Pinning a previously evictable item stops tracking it.
+	lruCache.Set("b", "b", cache.NoExpiration)
+	lruCache.Set("c", "c", time.Minute)
+	for _, key := range []string{"pinned", "b"} {
+		if _, found := lruCache.Get(key); !found {
+			t.Errorf("Expected pinned key %s to be found", key)
+		}
+	}
+	if _, found := lruCache.Get("c"); found {
+		t.Errorf("Expected key c to be evicted")
+	}
+
+	// The cache exceeds its capacity when filled with the pinned items.
+	lruCache.Set("d", "d", cache.NoExpiration)
+	if lruCache.Len() != 3 {
+		t.Errorf("Expected 3 pinned items, Got: %d", lruCache.Len())
+	}
+}
+
+// Validates that the non-positive capacity implies an unbounded cache.
+func TestLRUCache_Unbounded(t *testing.T) {
+	lruCache := cache.NewLRU(0)
+
+	for i := 0; i < 100; i++ {
+		lruCache.Set(fmt.Sprintf("key%d", i), i, time.Minute)
+	}
+
+	if lruCache.Len() != 100 {
+		t.Errorf("Expected 100 items, Got: %d", lruCache.Len())
+	}
+}
+
+// Validates that the sharded cache splits its bound across the shards, never evicts the items
+// without expiration and retains the expired items within the grace period.
+func TestShardedCache_LRUWithGracePeriod(t *testing.T) {
+	const (
+		numShards  = 4
+		maxEntries = 8
+	)
+
+	shardedCache := cache.NewShardedWithGracePeriod(numShards, maxEntries, time.Minute)
+
+	shardedCache.Set("pinned", "pinned", cache.NoExpiration)
+	for i := 0; i < 100; i++ {
+		shardedCache.Set(fmt.Sprintf("key%d", i), i, time.Minute)
+	}
+
+	// Each shard holds at most its share of the bound with the pinned item on top.
+	if length := shardedCache.Len(); length > maxEntries+1 {
+		t.Errorf("Expected at most %d items, Got: %d", maxEntries+1, length)
+	}
+	if _, found := shardedCache.Get("pinned"); !found {
+		t.Errorf("Expected pinned key to be found")
+	}
+
+	// The most recently set item is never evicted.
+	if value, found := shardedCache.Get("key99"); !found || value != 99 {
+		t.Errorf("Expected key %s with value: %d, Got: %v, found: %t", "key99", 99, value, found)
+	}
+
+	shardedCache.Set("expired", "value", time.Nanosecond)
+
+	// Sleep to simulate expiration
+	time.Sleep(time.Millisecond * 10)
+
+	if _, exists := shardedCache.Get("expired"); exists {
+		t.Errorf("Expected key %s to be a miss", "expired")
+	}
+	value, isExpired, found := shardedCache.GetStale("expired")
+	if !found || !isExpired || value != "value" {
+		t.Errorf("Expected stale value: %s, Got: %v, expired: %t, found: %t", "value", value, isExpired, found)
+	}
+
+	// The non-positive bound implies an unbounded cache.
+	unboundedCache := cache.NewShardedWithGracePeriod(numShards, 0, 0)
+	for i := 0; i < 100; i++ {
+		unboundedCache.Set(fmt.Sprintf("key%d", i), i, time.Minute)
+	}
+	if length := unboundedCache.Len(); length != 100 {
+		t.Errorf("Expected 100 items, Got: %d", length)
+	}
+}
+
+// Validates that the sharded cache behaves the same as the single shard cache
+// for the keys spread across the shards, including the non-positive number of shards.
+func TestShardedCache(t *testing.T) {
+	const numKeys = 100
+
+	for _, numShards := range []int{-1, 0, 1, 16} {
+		t.Run(fmt.Sprintf("shards=%d", numShards), func(t *testing.T) {
+			shardedCache := cache.NewSharded(numShards)
+
+			evicted := map[string]interface{}{}
+			shardedCache.OnEvict(func(key string, value interface{}) {
+				evicted[key] = value
+			})
+
+			for i := 0; i < numKeys; i++ {
+				shardedCache.Set(fmt.Sprintf("key%d", i), i, time.Minute)
+			}
+			shardedCache.Set("expired", "value", time.Nanosecond)
+
+			// Sleep to simulate expiration
+			time.Sleep(time.Millisecond * 10)
+
+			for i := 0; i < numKeys; i++ {
+				key := fmt.Sprintf("key%d", i)
+				if value, exists := shardedCache.Get(key); !exists || value != i {
+					t.Errorf("Expected key %s with value: %d, Got: %v, exists: %t", key, i, value, exists)
+				}
+			}
+
+			if length := shardedCache.Len(); length != numKeys {
+				t.Errorf("Expected length: %d, Got: %d", numKeys, length)
+			}
+			if keys := shardedCache.Keys(); len(keys) != numKeys {
+				t.Errorf("Expected keys count: %d, Got: %d", numKeys, len(keys))
+			}
+
+			rangeCount := 0
+			shardedCache.Range(func(key string, value interface{}, expiry time.Time) bool {
+				rangeCount++
+				return true
+			})
+			if rangeCount != numKeys {
+				t.Errorf("Expected range count: %d, Got: %d", numKeys, rangeCount)
+			}
+
+			// Range stops across the shards once f returns false.
+			rangeCount = 0
+			shardedCache.Range(func(key string, value interface{}, expiry time.Time) bool {
+				rangeCount++
+				return false
+			})
+			if rangeCount != 1 {
+				t.Errorf("Expected range count: %d, Got: %d", 1, rangeCount)
+			}
+
+			if purged := shardedCache.PurgeExpired(); purged != 1 {
+				t.Errorf("Expected purged: %d, Got: %d", 1, purged)
+			}
+
+			shardedCache.Set("key0", "overwritten", time.Minute)
+			expected := map[string]interface{}{"expired": "value", "key0": 0}
+			if !reflect.DeepEqual(evicted, expected) {
+				t.Errorf("Expected evictions: %v, Got: %v", expected, evicted)
+			}
+
+			shardedCache.Delete("key0")
+			if _, exists := shardedCache.Get("key0"); exists {
+				t.Errorf("Expected key %s to be deleted", "key0")
+			}
+			if length := shardedCache.Len(); length != numKeys-1 {
+				t.Errorf("Expected length: %d, Got: %d", numKeys-1, length)
+			}
+		})
+	}
+}
+
+// Validates that the sharded cache is safe for concurrent use.
+// Meant to be run with the race detector (go test -race).
+func TestShardedCache_Concurrent(t *testing.T) {
+	shardedCache := cache.NewSharded(8)
+
+	const (
+		numWriters       = 10
+		numKeysPerWriter = 100
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWriters; i++ {
+		wg.Add(2)
+
+		go func(writer int) {
+			defer wg.Done()
+			for j := 0; j < numKeysPerWriter; j++ {
+				key := fmt.Sprintf("key%d_%d", writer, j)
+				shardedCache.Set(key, j, time.Minute)
+
+				if value, exists := shardedCache.Get(key); !exists || value != j {
+					t.Errorf("Expected key %s with value: %d, Got: %v, exists: %t", key, j, value, exists)
+				}
+			}
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numKeysPerWriter; j++ {
+				_ = shardedCache.Len()
+				_ = shardedCache.PurgeExpired()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if length := shardedCache.Len(); length != numWriters*numKeysPerWriter {
+		t.Errorf("Expected length: %d, Got: %d", numWriters*numKeysPerWriter, length)
+	}
+}
+
+// Benchmarks the concurrent Get and Set of the single shard cache against the sharded cache.
+// Every tenth operation is a Set.
+func BenchmarkCache_ConcurrentGetSet(b *testing.B) {
+	const numKeys = 1024
+
+	keys := make([]string, numKeys)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	benchmarks := []struct {
+		name  string
+		cache *cache.Cache
+	}{
+		{"single shard", cache.New()},
+		{"16 shards", cache.NewSharded(16)},
+		{"64 shards", cache.NewSharded(64)},
+	}
+
+	for _, bm := range benchmarks {
+		for _, key := range keys {
+			bm.cache.Set(key, key, time.Minute)
+		}
+
+		b.Run(bm.name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					key := keys[i%numKeys]
+					if i%10 == 0 {
+						bm.cache.Set(key, key, time.Minute)
+					} else {
+						bm.cache.Get(key)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
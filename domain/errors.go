@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
 )
 
 var (
@@ -15,6 +17,21 @@ var (
 	ErrConflict = errors.New("your Item already exist")
 	// ErrBadParamInput will throw if the given request-body or params is not valid
 	ErrBadParamInput = errors.New("given Param is not valid")
+	// ErrEmptyRoutePools will throw if a route selected for pricing has no pools
+	ErrEmptyRoutePools = errors.New("route has no pools")
+	// ErrRateLimited will throw if the pricing recompute budget of the rate limit key is exhausted
+	ErrRateLimited = errors.New("pricing recompute rate limit exceeded")
+	// ErrStaleData will throw alongside a stale cached price served in place of a failed price computation
+	ErrStaleData = errors.New("stale price served after computation failure")
+	// ErrNilPoolInRoute will throw if a route contains a nil pool when preparing the result
+	ErrNilPoolInRoute = errors.New("route contains nil pool")
+	// ErrUnpriceable will throw if no route is found to price a denom
+	ErrUnpriceable = errors.New("no route found for pricing")
+	// ErrComputeBudgetExhausted will throw, possibly alongside a fallback price, if the price computation
+	// does not complete within the requested compute budget
+	ErrComputeBudgetExhausted = errors.New("price compute budget exhausted")
+	// ErrChainHeightUnavailable will throw if the chain height has not been fetched from the node yet
+	ErrChainHeightUnavailable = errors.New("chain height is not available yet")
 )
 
 // GetStatusCode returbs status code given error
@@ -166,3 +183,145 @@ type StaleHeightError struct {
 func (e StaleHeightError) Error() string {
 	return fmt.Sprintf("stored height (%d) is stale, time since last update (%d), max allowed seconds (%d)", e.StoredHeight, e.TimeSinceLastUpdate, e.MaxAllowedTimeDeltaSecs)
 }
+
+// PriceImpactTooHighError is returned when the price impact of the optimal quote
+// exceeds the max price impact configured via WithMaxQuotePriceImpact.
+type PriceImpactTooHighError struct {
+	MaxPriceImpact    osmomath.Dec
+	ActualPriceImpact osmomath.Dec
+}
+
+func (e PriceImpactTooHighError) Error() string {
+	return fmt.Sprintf("price impact (%s) exceeds max allowed price impact (%s)", e.ActualPriceImpact, e.MaxPriceImpact)
+}
+
+// PricingCircuitOpenError is returned when the pricing circuit breaker is open for a base denom
+// due to consecutive pricing failures.
+type PricingCircuitOpenError struct {
+	BaseDenom string
+	// LastErr is the last pricing error that caused the circuit to open.
+	LastErr error
+}
+
+func (e PricingCircuitOpenError) Error() string {
+	return fmt.Sprintf("pricing circuit is open for base denom (%s), last error: %s", e.BaseDenom, e.LastErr)
+}
+
+func (e PricingCircuitOpenError) Unwrap() error {
+	return e.LastErr
+}
+
+// PriceOutOfRangeError is returned when the computed price is invalid or outside
+// of the configured min and max price bounds. For example, this might happen for
+// the tokens with extreme scaling factors.
+type PriceOutOfRangeError struct {
+	BaseDenom  string
+	QuoteDenom string
+	Price      osmomath.BigDec
+	// MinPrice and MaxPrice are nil if unbounded.
+	MinPrice osmomath.BigDec
+	MaxPrice osmomath.BigDec
+}
+
+func (e PriceOutOfRangeError) Error() string {
+	return fmt.Sprintf("price (%s) of base denom (%s) in quote denom (%s) is out of range, min (%s), max (%s)", e.Price, e.BaseDenom, e.QuoteDenom, e.MinPrice, e.MaxPrice)
+}
+
+// RouteLiquidityTooLowError is returned when a pool in the route selected for pricing
+// has less OSMO liquidity than the min liquidity.
+type RouteLiquidityTooLowError struct {
+	BaseDenom    string
+	QuoteDenom   string
+	PoolID       uint64
+	Liquidity    osmomath.Int
+	MinLiquidity int
+}
+
+func (e RouteLiquidityTooLowError) Error() string {
+	return fmt.Sprintf("pool (%d) in route for base denom (%s) and quote denom (%s) has liquidity (%s) below min liquidity (%d)", e.PoolID, e.BaseDenom, e.QuoteDenom, e.Liquidity, e.MinLiquidity)
+}
+
+// InvalidDefaultQuoteDenomError is returned when the default quote denom override
+// of a pricing request is not a valid chain denom.
+type InvalidDefaultQuoteDenomError struct {
+	Denom string
+}
+
+func (e InvalidDefaultQuoteDenomError) Error() string {
+	return fmt.Sprintf("default quote denom override (%s) is not a valid chain denom", e.Denom)
+}
+
+// NoRouteThroughIntermediateDenomError is returned when none of the candidate routes
+// passes through the required intermediate denom.
+type NoRouteThroughIntermediateDenomError struct {
+	TokenInDenom      string
+	TokenOutDenom     string
+	IntermediateDenom string
+}
+
+func (e NoRouteThroughIntermediateDenomError) Error() string {
+	return fmt.Sprintf("no route from token in denom (%s) to token out denom (%s) passes through intermediate denom (%s)", e.TokenInDenom, e.TokenOutDenom, e.IntermediateDenom)
+}
+
+// InvalidBasketWeightsError is returned when the basket is empty, a basket component weight
+// is not positive or the weights do not sum to one within the tolerance.
+type InvalidBasketWeightsError struct {
+	WeightSum osmomath.Dec
+}
+
+func (e InvalidBasketWeightsError) Error() string {
+	return fmt.Sprintf("basket component weights must be positive and sum to one, got sum (%s)", e.WeightSum)
+}
+
+// NonPositiveTokenInError is returned when the token in amount of a quote is not positive.
+type NonPositiveTokenInError struct {
+	TokenInDenom  string
+	TokenInAmount osmomath.Int
+}
+
+func (e NonPositiveTokenInError) Error() string {
+	return fmt.Sprintf("token in (%s) amount must be positive, was (%s)", e.TokenInDenom, e.TokenInAmount)
+}
+
+// UnknownTokenOutDenomError is returned when the token out denom of a quote is empty
+// or is not in any of the routable pools.
+type UnknownTokenOutDenomError struct {
+	TokenOutDenom string
+}
+
+func (e UnknownTokenOutDenomError) Error() string {
+	if e.TokenOutDenom == "" {
+		return "token out denom must not be empty"
+	}
+	return fmt.Sprintf("token out denom (%s) is not in any of the routable pools", e.TokenOutDenom)
+}
+
+// UnknownHumanDenomError is returned when the human denom does not resolve to a chain denom.
+type UnknownHumanDenomError struct {
+	HumanDenom string
+}
+
+func (e UnknownHumanDenomError) Error() string {
+	return fmt.Sprintf("chain denom for human denom (%s) is not found", e.HumanDenom)
+}
+
+// StalePoolDataError is returned when the height of the pool data used for pricing
+// lags the chain height by more than the configured max staleness.
+type StalePoolDataError struct {
+	PoolDataHeight     uint64
+	ChainHeight        uint64
+	MaxStalenessBlocks uint64
+}
+
+func (e StalePoolDataError) Error() string {
+	return fmt.Sprintf("pool data height (%d) lags chain height (%d) by more than (%d) blocks", e.PoolDataHeight, e.ChainHeight, e.MaxStalenessBlocks)
+}
+
+// InvalidSlippageToleranceError is returned when the slippage tolerance of a quote is outside of [0, 1).
+type InvalidSlippageToleranceError struct {
+	SlippageTolerance osmomath.Dec
+}
+
+func (e InvalidSlippageToleranceError) Error() string {
+	return fmt.Sprintf("slippage tolerance must be in [0, 1), was (%s)", e.SlippageTolerance)
+}
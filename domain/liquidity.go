@@ -0,0 +1,63 @@
+package domain
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/sqsdomain"
+)
+
+// ComputePoolOSMOLiquidity returns the liquidity of the given pool denominated in OSMO
+// as the sum of the OSMO values of its balances computed via coinOSMOValue.
+// Pools that do not expose their balances (i.e. do not implement RoutableResultPool) have zero liquidity.
+// It is shared by the pricing route liquidity enforcement and the route results. Note that the router
+// filters the pools by the total value locked computed at ingest instead (see FilterPoolsByMinLiquidity
+// in the router use case) since it filters the pools prior to pricing them, so the two might diverge
+// until the next ingest.
+// Returns error if the OSMO value of any of the balances fails to compute.
+func ComputePoolOSMOLiquidity(pool sqsdomain.RoutablePool, coinOSMOValue CoinOSMOValueFunc) (osmomath.Int, error) {
+	resultPool, ok := pool.(RoutableResultPool)
+	if !ok {
+		return osmomath.ZeroInt(), nil
+	}
+
+	return ComputeOSMOLiquidity(resultPool.GetBalances(), coinOSMOValue)
+}
+
+// ComputeOSMOLiquidity returns the sum of the OSMO values of the given balances computed via coinOSMOValue.
+// Returns error if the OSMO value of any of the balances fails to compute.
+func ComputeOSMOLiquidity(balances sdk.Coins, coinOSMOValue CoinOSMOValueFunc) (osmomath.Int, error) {
+	osmoLiquidity := osmomath.ZeroInt()
+	for _, balance := range balances {
+		balanceOSMOValue, err := coinOSMOValue(balance)
+		if err != nil {
+			return osmomath.Int{}, err
+		}
+
+		osmoLiquidity = osmoLiquidity.Add(balanceOSMOValue)
+	}
+
+	return osmoLiquidity, nil
+}
+
+// NewCoinOSMOValueFunc returns the CoinOSMOValueFunc that descales the coin amount
+// by the chain scaling factor of its denom and multiplies it by the OSMO price of the denom.
+// The OSMO value is truncated to the whole OSMO.
+func NewCoinOSMOValueFunc(getScalingFactor func(denom string) (osmomath.Dec, error), getOSMOPrice func(denom string) (osmomath.BigDec, error)) CoinOSMOValueFunc {
+	return func(coin sdk.Coin) (osmomath.Int, error) {
+		scalingFactor, err := getScalingFactor(coin.Denom)
+		if err != nil {
+			return osmomath.Int{}, err
+		}
+
+		osmoPrice, err := getOSMOPrice(coin.Denom)
+		if err != nil {
+			return osmomath.Int{}, err
+		}
+
+		// Note that the scaling factor might be shared so it must not be mutated.
+		descaledAmount := coin.Amount.ToLegacyDec().QuoMut(scalingFactor)
+
+		return osmomath.BigDecFromDec(descaledAmount).MulMut(osmoPrice).Dec().TruncateInt(), nil
+	}
+}
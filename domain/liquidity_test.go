@@ -0,0 +1,107 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/domain"
+	"github.com/osmosis-labs/sqs/domain/mocks"
+	"github.com/osmosis-labs/sqs/router/usecase/routertesting"
+)
+
+// TestComputePoolOSMOLiquidity tests that the pool OSMO liquidity is the sum of the OSMO values
+// of its balances descaled by the scaling factors and that the errors are propagated.
+func TestComputePoolOSMOLiquidity(t *testing.T) {
+	var (
+		denomOne = routertesting.DenomOne
+		denomTwo = routertesting.DenomTwo
+	)
+
+	scalingFactors := map[string]osmomath.Dec{
+		denomOne: osmomath.NewDec(1_000_000),
+		denomTwo: osmomath.NewDec(1_000_000_000_000),
+	}
+	getScalingFactor := func(denom string) (osmomath.Dec, error) {
+		scalingFactor, ok := scalingFactors[denom]
+		if !ok {
+			return osmomath.Dec{}, errors.New("unknown scaling factor")
+		}
+		return scalingFactor, nil
+	}
+
+	osmoPrices := map[string]osmomath.BigDec{
+		denomOne: osmomath.NewBigDec(2),
+		denomTwo: osmomath.MustNewBigDecFromStr("0.5"),
+	}
+	getOSMOPrice := func(denom string) (osmomath.BigDec, error) {
+		osmoPrice, ok := osmoPrices[denom]
+		if !ok {
+			return osmomath.BigDec{}, errors.New("unknown price")
+		}
+		return osmoPrice, nil
+	}
+
+	coinOSMOValue := domain.NewCoinOSMOValueFunc(getScalingFactor, getOSMOPrice)
+
+	testCases := []struct {
+		name     string
+		balances sdk.Coins
+
+		expectedLiquidity osmomath.Int
+		expectErr         bool
+	}{
+		{
+			name: "two balances",
+			balances: sdk.NewCoins(
+				// 3 * 2 = 6 OSMO
+				sdk.NewCoin(denomOne, osmomath.NewInt(3_000_000)),
+				// 10 * 0.5 = 5 OSMO
+				sdk.NewCoin(denomTwo, osmomath.NewInt(10_000_000_000_000)),
+			),
+			expectedLiquidity: osmomath.NewInt(11),
+		},
+		{
+			name: "fractional value is truncated",
+			balances: sdk.NewCoins(
+				// 1.5 * 2 = 3 OSMO
+				sdk.NewCoin(denomOne, osmomath.NewInt(1_500_000)),
+				// 1.5 * 0.5 = 0.75 OSMO
+				sdk.NewCoin(denomTwo, osmomath.NewInt(1_500_000_000_000)),
+			),
+			expectedLiquidity: osmomath.NewInt(3),
+		},
+		{
+			name:              "no balances",
+			balances:          sdk.NewCoins(),
+			expectedLiquidity: osmomath.ZeroInt(),
+		},
+		{
+			name:      "unknown denom",
+			balances:  sdk.NewCoins(sdk.NewCoin("unknown", osmomath.NewInt(1))),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			pool := &mocks.MockRoutablePool{
+				ID:       1,
+				Balances: tc.balances,
+			}
+
+			liquidity, err := domain.ComputePoolOSMOLiquidity(pool, coinOSMOValue)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedLiquidity.String(), liquidity.String())
+		})
+	}
+}
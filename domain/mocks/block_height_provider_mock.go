@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/osmosis-labs/sqs/domain"
+)
+
+// BlockHeightProviderMock is a mock of domain.BlockHeightProvider with fixed heights.
+// ChainHeightErr is returned by GetChainHeight if set.
+type BlockHeightProviderMock struct {
+	PoolDataHeight uint64
+	ChainHeight    uint64
+	ChainHeightErr error
+}
+
+var _ domain.BlockHeightProvider = &BlockHeightProviderMock{}
+
+// GetPoolDataHeight implements domain.BlockHeightProvider.
+func (b *BlockHeightProviderMock) GetPoolDataHeight() uint64 {
+	return b.PoolDataHeight
+}
+
+// GetChainHeight implements domain.BlockHeightProvider.
+func (b *BlockHeightProviderMock) GetChainHeight(ctx context.Context) (uint64, error) {
+	if b.ChainHeightErr != nil {
+		return 0, b.ChainHeightErr
+	}
+	return b.ChainHeight, nil
+}
@@ -133,6 +133,11 @@ func (mp *MockRoutablePool) GetTakerFee() math.LegacyDec {
 var _ sqsdomain.PoolI = &MockRoutablePool{}
 var _ sqsdomain.RoutablePool = &MockRoutablePool{}
 
+// GetBalances implements domain.RoutableResultPool.
+func (mp *MockRoutablePool) GetBalances() sdk.Coins {
+	return mp.Balances
+}
+
 // GetId implements sqsdomain.PoolI.
 func (mp *MockRoutablePool) GetId() uint64 {
 	return mp.ID
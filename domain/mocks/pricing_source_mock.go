@@ -0,0 +1,176 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/domain"
+	"github.com/osmosis-labs/sqs/domain/cache"
+)
+
+// PricingSourceMock is a mock of domain.ChainPricingSource.
+// The methods with the corresponding function field set delegate to it. The rest panic.
+type PricingSourceMock struct {
+	GetPriceFunc    func(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error)
+	GetUSDPriceFunc func(ctx context.Context, baseDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error)
+	// DefaultQuoteDenomFunc is the mock of DefaultQuoteDenom.
+	DefaultQuoteDenomFunc func() string
+	// RefreshDefaultQuotePricesFunc is the mock of RefreshDefaultQuotePrices.
+	RefreshDefaultQuotePricesFunc func(ctx context.Context, baseDenoms []string, opts ...domain.PricingOption) error
+	// SetWorkerTrackedDenomsFunc is the mock of SetWorkerTrackedDenoms.
+	SetWorkerTrackedDenomsFunc func(denoms map[string]struct{})
+	// WarmReverseDefaultQuotePricesFunc is the mock of WarmReverseDefaultQuotePrices.
+	WarmReverseDefaultQuotePricesFunc func() error
+}
+
+var _ domain.ChainPricingSource = &PricingSourceMock{}
+
+// GetPrice implements domain.PricingSource.
+func (p *PricingSourceMock) GetPrice(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	if p.GetPriceFunc != nil {
+		return p.GetPriceFunc(ctx, baseDenom, quoteDenom, opts...)
+	}
+	panic("unimplemented")
+}
+
+// GetPriceWithConfidence implements domain.PricingSource.
+func (p *PricingSourceMock) GetPriceWithConfidence(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (domain.PriceWithConfidence, error) {
+	panic("unimplemented")
+}
+
+// GetPriceByHumanDenom implements domain.PricingSource.
+func (p *PricingSourceMock) GetPriceByHumanDenom(ctx context.Context, baseHumanDenom string, quoteHumanDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	panic("unimplemented")
+}
+
+// GetUSDPrice implements domain.PricingSource.
+func (p *PricingSourceMock) GetUSDPrice(ctx context.Context, baseDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	if p.GetUSDPriceFunc != nil {
+		return p.GetUSDPriceFunc(ctx, baseDenom, opts...)
+	}
+	panic("unimplemented")
+}
+
+// GetPriceWithSlippage implements domain.PricingSource.
+func (p *PricingSourceMock) GetPriceWithSlippage(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, osmomath.BigDec, error) {
+	panic("unimplemented")
+}
+
+// ComputePriceForRoute implements domain.PricingSource.
+func (p *PricingSourceMock) ComputePriceForRoute(ctx context.Context, route domain.SplitRoute, baseDenom string, quoteDenom string) (osmomath.BigDec, error) {
+	panic("unimplemented")
+}
+
+// GetPoolSharePrice implements domain.PricingSource.
+func (p *PricingSourceMock) GetPoolSharePrice(ctx context.Context, poolShareDenom string, quoteDenom string) (osmomath.BigDec, error) {
+	panic("unimplemented")
+}
+
+// GetBasketPrice implements domain.PricingSource.
+func (p *PricingSourceMock) GetBasketPrice(ctx context.Context, components []domain.BasketComponent, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	panic("unimplemented")
+}
+
+// DetectArbitrage implements domain.PricingSource.
+func (p *PricingSourceMock) DetectArbitrage(ctx context.Context, denomA, denomB, denomC string) (osmomath.Dec, error) {
+	panic("unimplemented")
+}
+
+// SetWorkerTrackedDenoms implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) SetWorkerTrackedDenoms(denoms map[string]struct{}) {
+	if p.SetWorkerTrackedDenomsFunc != nil {
+		p.SetWorkerTrackedDenomsFunc(denoms)
+		return
+	}
+	panic("unimplemented")
+}
+
+// SetBlockHeightProvider implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) SetBlockHeightProvider(provider domain.BlockHeightProvider) {
+	panic("unimplemented")
+}
+
+// SetTWAPProvider implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) SetTWAPProvider(provider domain.TWAPProvider) {
+	panic("unimplemented")
+}
+
+// MarkPoolSuspect implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) MarkPoolSuspect(poolID uint64, until time.Time) {
+	panic("unimplemented")
+}
+
+// ClearPoolSuspect implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) ClearPoolSuspect(poolID uint64) {
+	panic("unimplemented")
+}
+
+// SuspectPools implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) SuspectPools() map[uint64]time.Time {
+	panic("unimplemented")
+}
+
+// GetPriceAndRoute implements domain.PricingSource.
+func (p *PricingSourceMock) GetPriceAndRoute(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, domain.SplitRoute, error) {
+	panic("unimplemented")
+}
+
+// ExplainPrice implements domain.PricingSource.
+func (p *PricingSourceMock) ExplainPrice(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (domain.PriceExplanation, error) {
+	panic("unimplemented")
+}
+
+// ListCachedPairs implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) ListCachedPairs() []domain.CachedPricePair {
+	panic("unimplemented")
+}
+
+// ExportCacheSnapshot implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) ExportCacheSnapshot() ([]byte, error) {
+	panic("unimplemented")
+}
+
+// ImportCacheSnapshot implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) ImportCacheSnapshot(data []byte) error {
+	panic("unimplemented")
+}
+
+// RefreshDefaultQuotePrices implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) RefreshDefaultQuotePrices(ctx context.Context, baseDenoms []string, opts ...domain.PricingOption) error {
+	if p.RefreshDefaultQuotePricesFunc != nil {
+		return p.RefreshDefaultQuotePricesFunc(ctx, baseDenoms, opts...)
+	}
+	panic("unimplemented")
+}
+
+// WarmReverseDefaultQuotePrices implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) WarmReverseDefaultQuotePrices() error {
+	if p.WarmReverseDefaultQuotePricesFunc != nil {
+		return p.WarmReverseDefaultQuotePricesFunc()
+	}
+	panic("unimplemented")
+}
+
+// GetPriceAsync implements domain.PricingSource.
+func (p *PricingSourceMock) GetPriceAsync(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) <-chan domain.PriceResultOrError {
+	panic("unimplemented")
+}
+
+// DefaultQuoteDenom implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) DefaultQuoteDenom() string {
+	if p.DefaultQuoteDenomFunc != nil {
+		return p.DefaultQuoteDenomFunc()
+	}
+	panic("unimplemented")
+}
+
+// PricingDebugInfo implements domain.PricingSourceAdmin.
+func (p *PricingSourceMock) PricingDebugInfo() domain.PricingDebugInfo {
+	panic("unimplemented")
+}
+
+// InitializeCache implements domain.PricingSource.
+func (p *PricingSourceMock) InitializeCache(*cache.Cache) {
+	panic("unimplemented")
+}
@@ -0,0 +1,70 @@
+package mocks
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/domain"
+)
+
+// MockQuote is a mock of domain.Quote with the data set via its fields.
+type MockQuote struct {
+	AmountIn     sdk.Coin
+	AmountOut    osmomath.Int
+	Route        []domain.SplitRoute
+	EstimatedGas uint64
+}
+
+var _ domain.Quote = &MockQuote{}
+
+// GetAmountIn implements domain.Quote.
+func (m *MockQuote) GetAmountIn() sdk.Coin {
+	return m.AmountIn
+}
+
+// GetAmountOut implements domain.Quote.
+func (m *MockQuote) GetAmountOut() osmomath.Int {
+	return m.AmountOut
+}
+
+// GetRoute implements domain.Quote.
+func (m *MockQuote) GetRoute() []domain.SplitRoute {
+	return m.Route
+}
+
+// GetEffectiveSpreadFactor implements domain.Quote.
+func (m *MockQuote) GetEffectiveSpreadFactor() osmomath.Dec {
+	return osmomath.ZeroDec()
+}
+
+// GetPriceImpact implements domain.Quote.
+func (m *MockQuote) GetPriceImpact() osmomath.Dec {
+	return osmomath.ZeroDec()
+}
+
+// IsRouteSearchTruncated implements domain.Quote.
+func (m *MockQuote) IsRouteSearchTruncated() bool {
+	return false
+}
+
+// GetEstimatedGas implements domain.Quote.
+func (m *MockQuote) GetEstimatedGas() uint64 {
+	return m.EstimatedGas
+}
+
+// IsEffectivePriceSkipped implements domain.Quote.
+func (m *MockQuote) IsEffectivePriceSkipped() bool {
+	return false
+}
+
+// PrepareResult implements domain.Quote.
+func (m *MockQuote) PrepareResult(ctx context.Context, scalingFactor osmomath.Dec, opts ...domain.PrepareResultOption) ([]domain.SplitRoute, osmomath.Dec, error) {
+	return m.Route, osmomath.ZeroDec(), nil
+}
+
+// String implements domain.Quote.
+func (m *MockQuote) String() string {
+	return ""
+}
@@ -0,0 +1,142 @@
+package mocks
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/domain"
+	"github.com/osmosis-labs/sqs/domain/mvc"
+	"github.com/osmosis-labs/sqs/sqsdomain"
+)
+
+// RouterUsecaseMock is a mock of mvc.RouterUsecase.
+// The methods with the corresponding function field set delegate to it.
+// GetConfig returns Config. GetSortedPools returns SortedPools. The rest panic.
+type RouterUsecaseMock struct {
+	Config      domain.RouterConfig
+	SortedPools []sqsdomain.PoolI
+
+	GetOptimalQuoteFunc   func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error)
+	GetRankedQuotesFunc   func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, k int, opts ...domain.RouterOption) ([]domain.Quote, error)
+	GetPoolSpotPriceFunc  func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error)
+	GetPoolSpotPricesFunc func(ctx context.Context, requests []domain.SpotPriceRequest) ([]osmomath.BigDec, []error)
+}
+
+var _ mvc.RouterUsecase = &RouterUsecaseMock{}
+
+// GetOptimalQuote implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetOptimalQuote(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+	if r.GetOptimalQuoteFunc != nil {
+		return r.GetOptimalQuoteFunc(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+	panic("unimplemented")
+}
+
+// GetRankedQuotes implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetRankedQuotes(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, k int, opts ...domain.RouterOption) ([]domain.Quote, error) {
+	if r.GetRankedQuotesFunc != nil {
+		return r.GetRankedQuotesFunc(ctx, tokenIn, tokenOutDenom, k, opts...)
+	}
+	panic("unimplemented")
+}
+
+// EstimatePriceImpact implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) EstimatePriceImpact(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (osmomath.Dec, error) {
+	panic("unimplemented")
+}
+
+// GetQuoteWithSplitComparison implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetQuoteWithSplitComparison(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, domain.Quote, int, error) {
+	panic("unimplemented")
+}
+
+// GetQuoteWithSlippage implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetQuoteWithSlippage(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, slippageTolerance osmomath.Dec, opts ...domain.RouterOption) (domain.Quote, osmomath.Int, error) {
+	panic("unimplemented")
+}
+
+// GetBestSingleRouteQuote implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetBestSingleRouteQuote(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string) (domain.Quote, error) {
+	panic("unimplemented")
+}
+
+// GetCustomDirectQuote implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetCustomDirectQuote(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, poolID uint64) (domain.Quote, error) {
+	panic("unimplemented")
+}
+
+// GetCandidateRoutes implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetCandidateRoutes(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string) (sqsdomain.CandidateRoutes, error) {
+	panic("unimplemented")
+}
+
+// GetTakerFee implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetTakerFee(poolID uint64) ([]sqsdomain.TakerFeeForPair, error) {
+	panic("unimplemented")
+}
+
+// SetTakerFees implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) SetTakerFees(takerFees sqsdomain.TakerFeeMap) {
+	panic("unimplemented")
+}
+
+// GetPoolSpotPrice implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetPoolSpotPrice(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+	if r.GetPoolSpotPriceFunc != nil {
+		return r.GetPoolSpotPriceFunc(ctx, poolID, quoteAsset, baseAsset)
+	}
+	panic("unimplemented")
+}
+
+// RegisterCustomPoolSpotPriceHandler implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) RegisterCustomPoolSpotPriceHandler(codeID uint64, handler domain.SpotPriceHandler) {
+	panic("unimplemented")
+}
+
+// GetPoolSpotPrices implements mvc.RouterUsecase.
+// If GetPoolSpotPricesFunc is not set, delegates to GetPoolSpotPrice for each request.
+func (r *RouterUsecaseMock) GetPoolSpotPrices(ctx context.Context, requests []domain.SpotPriceRequest) ([]osmomath.BigDec, []error) {
+	if r.GetPoolSpotPricesFunc != nil {
+		return r.GetPoolSpotPricesFunc(ctx, requests)
+	}
+
+	spotPrices := make([]osmomath.BigDec, len(requests))
+	errs := make([]error, len(requests))
+	for i, request := range requests {
+		spotPrices[i], errs[i] = r.GetPoolSpotPrice(ctx, request.PoolID, request.QuoteDenom, request.BaseDenom)
+	}
+
+	return spotPrices, errs
+}
+
+// GetCachedCandidateRoutes implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetCachedCandidateRoutes(ctx context.Context, tokenInDenom, tokenOutDenom string) (sqsdomain.CandidateRoutes, bool, error) {
+	panic("unimplemented")
+}
+
+// StoreRouterStateFiles implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) StoreRouterStateFiles() error {
+	panic("unimplemented")
+}
+
+// GetRouterState implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetRouterState() (domain.RouterState, error) {
+	panic("unimplemented")
+}
+
+// GetSortedPools implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetSortedPools() []sqsdomain.PoolI {
+	return r.SortedPools
+}
+
+// GetConfig implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) GetConfig() domain.RouterConfig {
+	return r.Config
+}
+
+// SetSortedPools implements mvc.RouterUsecase.
+func (r *RouterUsecaseMock) SetSortedPools(pools []sqsdomain.PoolI) {
+	panic("unimplemented")
+}
@@ -0,0 +1,98 @@
+package mocks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/domain"
+	"github.com/osmosis-labs/sqs/domain/mvc"
+)
+
+// TokensUsecaseMock is a mock of mvc.TokensUsecase.
+// It resolves human denoms, scaling factors and pool volumes from the configured maps.
+type TokensUsecaseMock struct {
+	// ChainDenoms maps human denoms to chain denoms.
+	ChainDenoms map[string]string
+	// ScalingFactors maps chain denoms to their scaling factors.
+	ScalingFactors map[string]osmomath.Dec
+	// PoolVolumes maps pool IDs to their recent trade volume.
+	PoolVolumes map[uint64]osmomath.Int
+}
+
+var _ mvc.TokensUsecase = &TokensUsecaseMock{}
+
+// GetMetadataByChainDenom implements mvc.TokensUsecase.
+func (t *TokensUsecaseMock) GetMetadataByChainDenom(denom string) (domain.Token, error) {
+	panic("unimplemented")
+}
+
+// GetFullTokenMetadata implements mvc.TokensUsecase.
+func (t *TokensUsecaseMock) GetFullTokenMetadata() (map[string]domain.Token, error) {
+	panic("unimplemented")
+}
+
+// GetChainDenom implements mvc.TokensUsecase.
+func (t *TokensUsecaseMock) GetChainDenom(humanDenom string) (string, error) {
+	chainDenom, ok := t.ChainDenoms[humanDenom]
+	if !ok {
+		return "", domain.UnknownHumanDenomError{HumanDenom: humanDenom}
+	}
+	return chainDenom, nil
+}
+
+// GetChainDenoms implements mvc.TokensUsecase.
+func (t *TokensUsecaseMock) GetChainDenoms(humanDenoms []string) (map[string]string, []error) {
+	chainDenoms := make(map[string]string, len(humanDenoms))
+	errs := make([]error, len(humanDenoms))
+	for i, humanDenom := range humanDenoms {
+		chainDenom, err := t.GetChainDenom(humanDenom)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		chainDenoms[humanDenom] = chainDenom
+	}
+	return chainDenoms, errs
+}
+
+// GetChainScalingFactorByDenomMut implements mvc.TokensUsecase.
+func (t *TokensUsecaseMock) GetChainScalingFactorByDenomMut(denom string) (osmomath.Dec, error) {
+	scalingFactor, ok := t.ScalingFactors[denom]
+	if !ok {
+		return osmomath.Dec{}, fmt.Errorf("scaling factor for denom (%s) is not found", denom)
+	}
+	return scalingFactor, nil
+}
+
+// GetSpotPriceScalingFactorByDenom implements mvc.TokensUsecase.
+func (t *TokensUsecaseMock) GetSpotPriceScalingFactorByDenom(baseDenom, quoteDenom string) (osmomath.Dec, error) {
+	panic("unimplemented")
+}
+
+// GetPrices implements mvc.TokensUsecase.
+func (t *TokensUsecaseMock) GetPrices(ctx context.Context, baseDenoms []string, quoteDenoms []string, pricingSourceType domain.PricingSourceType, opts ...domain.PricingOption) (map[string]map[string]any, error) {
+	panic("unimplemented")
+}
+
+// RegisterPricingStrategy implements mvc.TokensUsecase.
+func (t *TokensUsecaseMock) RegisterPricingStrategy(source domain.PricingSourceType, strategy domain.PricingSource) {
+	panic("unimplemented")
+}
+
+// IsValidChainDenom implements mvc.TokensUsecase.
+func (t *TokensUsecaseMock) IsValidChainDenom(chainDenom string) bool {
+	_, ok := t.ScalingFactors[chainDenom]
+	return ok
+}
+
+// GetPoolVolume implements mvc.TokensUsecase.
+func (t *TokensUsecaseMock) GetPoolVolume(poolID uint64) (osmomath.Int, bool) {
+	volume, ok := t.PoolVolumes[poolID]
+	return volume, ok
+}
+
+// SetPoolVolumes implements mvc.TokensUsecase.
+func (t *TokensUsecaseMock) SetPoolVolumes(poolVolumes map[uint64]osmomath.Int) {
+	t.PoolVolumes = poolVolumes
+}
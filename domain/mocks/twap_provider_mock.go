@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/domain"
+)
+
+// TWAPProviderMock is a mock of domain.TWAPProvider with fixed pool TWAPs.
+// The TWAPs are only available over Window. The pools missing from PoolTWAPs have no TWAP available.
+type TWAPProviderMock struct {
+	PoolTWAPs map[uint64]osmomath.BigDec
+	Window    time.Duration
+}
+
+var _ domain.TWAPProvider = &TWAPProviderMock{}
+
+// GetPoolTWAP implements domain.TWAPProvider.
+func (t *TWAPProviderMock) GetPoolTWAP(ctx context.Context, poolID uint64, quoteDenom string, baseDenom string, window time.Duration) (osmomath.BigDec, bool) {
+	if window != t.Window {
+		return osmomath.BigDec{}, false
+	}
+
+	twap, ok := t.PoolTWAPs[poolID]
+	return twap, ok
+}
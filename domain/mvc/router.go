@@ -14,6 +14,19 @@ import (
 type RouterUsecase interface {
 	// GetOptimalQuote returns the optimal quote for the given tokenIn and tokenOutDenom.
 	GetOptimalQuote(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error)
+	// GetRankedQuotes returns up to k single route quotes for the given tokenIn and tokenOutDenom
+	// sorted by amount out in descending order. Each quote is prepared and has a unique pool path.
+	GetRankedQuotes(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, k int, opts ...domain.RouterOption) ([]domain.Quote, error)
+	// EstimatePriceImpact returns the price impact of swapping the given tokenIn for tokenOutDenom
+	// over the best single route without preparing the quote.
+	EstimatePriceImpact(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (osmomath.Dec, error)
+	// GetQuoteWithSplitComparison returns the optimal split quote and the best single route quote for the given tokenIn
+	// and tokenOutDenom alongside the improvement in amount out of the split quote over the single route quote in basis points.
+	GetQuoteWithSplitComparison(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (splitQuote domain.Quote, singleRouteQuote domain.Quote, savingsBps int, err error)
+	// GetQuoteWithSlippage returns the optimal quote for the given tokenIn and tokenOutDenom alongside the min amount
+	// received given the slippage tolerance, i.e. the amount out times one minus the tolerance, truncated.
+	// The slippage tolerance must be in [0, 1).
+	GetQuoteWithSlippage(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, slippageTolerance osmomath.Dec, opts ...domain.RouterOption) (quote domain.Quote, minReceived osmomath.Int, err error)
 	// GetBestSingleRouteQuote returns the best single route quote for the given tokenIn and tokenOutDenom.
 	GetBestSingleRouteQuote(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string) (domain.Quote, error)
 	// GetCustomDirectQuote returns the custom direct quote for the given tokenIn, tokenOutDenom and poolID.
@@ -28,6 +41,13 @@ type RouterUsecase interface {
 	SetTakerFees(takerFees sqsdomain.TakerFeeMap)
 	// GetPoolSpotPrice returns the spot price of a pool.
 	GetPoolSpotPrice(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error)
+	// RegisterCustomPoolSpotPriceHandler registers the handler computing the spot prices of the CosmWasm pools
+	// with the given code ID. GetPoolSpotPrice(...) consults the registered handlers before the default computation.
+	// Overwrites the handler previously registered for the code ID.
+	RegisterCustomPoolSpotPriceHandler(codeID uint64, handler domain.SpotPriceHandler)
+	// GetPoolSpotPrices returns the spot prices for the given requests, fetching them concurrently.
+	// The returned spot prices and errors are in the same order as the requests.
+	GetPoolSpotPrices(ctx context.Context, requests []domain.SpotPriceRequest) ([]osmomath.BigDec, []error)
 	// GetCachedCandidateRoutes returns the candidate routes for the given tokenIn and tokenOutDenom from cache.
 	// It does not recompute the routes if they are not present in cache.
 	// Since we may cache zero routes, it returns false if the routes are not present in cache. Returns true otherwise.
@@ -18,6 +18,11 @@ type TokensUsecase interface {
 	// GetChainDenom returns chain denom by human denom
 	GetChainDenom(humanDenom string) (string, error)
 
+	// GetChainDenoms returns the chain denoms of the given human denoms keyed by the human denoms as given.
+	// The human denoms that fail to resolve are omitted from the map. Their errors are returned
+	// at their indexes in the errors of the same length as the human denoms. The resolved ones have nil errors.
+	GetChainDenoms(humanDenoms []string) (map[string]string, []error)
+
 	// GetChainScalingFactorByDenomMut returns a chain scaling factor for a given denom
 	// and a boolean flag indicating whether the scaling factor was found or not.
 	// Note that the returned decimal is a shared resource and must not be mutated.
@@ -40,4 +45,13 @@ type TokensUsecase interface {
 	RegisterPricingStrategy(source domain.PricingSourceType, strategy domain.PricingSource)
 
 	IsValidChainDenom(chainDenom string) bool
+
+	// GetPoolVolume returns the recent trade volume of the pool with the given ID
+	// and a boolean flag indicating whether the volume is known.
+	GetPoolVolume(poolID uint64) (osmomath.Int, bool)
+
+	// SetPoolVolumes replaces the recent trade volumes of the pools keyed by pool ID.
+	// The map must not be mutated after the call. It is called by the ingest use case on every block
+	// with the volumes estimated from the pool balance changes over the recent blocks.
+	SetPoolVolumes(poolVolumes map[uint64]osmomath.Int)
 }
@@ -1,5 +1,13 @@
 package domain
 
+import (
+	"context"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+
+	"github.com/osmosis-labs/sqs/sqsdomain"
+)
+
 // CosmWasmPoolRouterConfig is the config for the CosmWasm pools in the router
 type CosmWasmPoolRouterConfig struct {
 	// code IDs for the transmuter pool type
@@ -9,3 +17,10 @@ type CosmWasmPoolRouterConfig struct {
 	// node URI
 	NodeURI string
 }
+
+// SpotPriceHandler computes the spot prices of the CosmWasm pools with a custom code ID.
+// It allows pricing the new CosmWasm pool variants without a release of the pool implementations.
+type SpotPriceHandler interface {
+	// CalcSpotPrice returns the spot price of the base asset in terms of the quote asset in the given pool.
+	CalcSpotPrice(ctx context.Context, pool sqsdomain.PoolI, takerFee osmomath.Dec, quoteAsset, baseAsset string) (osmomath.BigDec, error)
+}
@@ -2,9 +2,14 @@ package domain
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	"github.com/osmosis-labs/osmosis/osmomath"
+	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
 	"github.com/osmosis-labs/sqs/domain/cache"
 )
 
@@ -27,31 +32,301 @@ type PricingSource interface {
 	// GetPrice returns the price given a base and a quote denom or otherwise error, if any.
 	// It attempts to find the price from the cache first, and if not found, it will proceed
 	// to recomputing it via ComputePrice().
+	// If the quote denom is empty, the price is computed against the default quote denom
+	// which may be overridden per call via WithDefaultQuoteDenom(...).
 	GetPrice(ctx context.Context, baseDenom string, quoteDenom string, opts ...PricingOption) (osmomath.BigDec, error)
 
+	// GetPriceWithConfidence returns the price like GetPrice(...) alongside whether it is low-confidence,
+	// i.e. computed with the default scaling factor (see WithDefaultScalingFactor(...)).
+	GetPriceWithConfidence(ctx context.Context, baseDenom string, quoteDenom string, opts ...PricingOption) (PriceWithConfidence, error)
+
+	// GetPriceByHumanDenom returns the price given a base and a quote human denom (e.g. "atom", "usdc").
+	// It resolves both human denoms to chain denoms and delegates to GetPrice(...).
+	// Returns error if either of the human denoms is unknown.
+	GetPriceByHumanDenom(ctx context.Context, baseHumanDenom string, quoteHumanDenom string, opts ...PricingOption) (osmomath.BigDec, error)
+
+	// GetUSDPrice returns the USD price of the given base denom.
+	// It prices the base denom against the chain denom of the default quote human denom, rather than the composite quote
+	// if configured, and applies the default quote USD rate if configured.
+	// Returns error if the default quote USD rate is unset but the default quote human denom is not USD-pegged.
+	GetUSDPrice(ctx context.Context, baseDenom string, opts ...PricingOption) (osmomath.BigDec, error)
+
+	// GetPriceAsync computes the price of the base denom in the quote denom in the background.
+	// Returns a channel that delivers exactly one result once the computation completes.
+	// The background computations are bounded by the configured max concurrent async computes.
+	GetPriceAsync(ctx context.Context, baseDenom string, quoteDenom string, opts ...PricingOption) <-chan PriceResultOrError
+
+	// GetPriceWithSlippage returns the price of the base denom in the quote denom before the swap
+	// and the effective price after the swap of the quote coin used for pricing along the top route.
+	// The divergence between the two is the slippage of the pricing quote.
+	// The price before the swap is computed like GetPrice(...).
+	// Returns error if the price is not computed along a single route, for example, along a pinned route
+	// or in the composite quote.
+	GetPriceWithSlippage(ctx context.Context, baseDenom string, quoteDenom string, opts ...PricingOption) (spotPriceBefore osmomath.BigDec, effectivePrice osmomath.BigDec, err error)
+
+	// ComputePriceForRoute computes the price of the base denom in the quote denom along the given route
+	// without selecting the route. The route must swap from the quote denom to the base denom.
+	// Only the pool spot prices are recomputed and the scaling factors are applied. The price is not cached.
+	ComputePriceForRoute(ctx context.Context, route SplitRoute, baseDenom string, quoteDenom string) (osmomath.BigDec, error)
+
+	// GetPoolSharePrice returns the price of one share of the pool with the given share denom
+	// (e.g. "gamm/pool/1") in the quote denom. The pool balances are priced in the quote denom
+	// via GetPrice(...) and their sum is divided by the total shares.
+	// Returns error if the denom is not a pool share denom, if the pool is not found
+	// or has no fungible shares, or if any of the balances fails to be priced.
+	GetPoolSharePrice(ctx context.Context, poolShareDenom string, quoteDenom string) (osmomath.BigDec, error)
+
+	// GetBasketPrice returns the price of the basket of the given weighted components in the quote denom.
+	// It is the sum of the component prices computed via GetPrice(...) weighted by the component weights.
+	// Returns InvalidBasketWeightsError if the weights are not positive or do not sum to one within a tolerance.
+	GetBasketPrice(ctx context.Context, components []BasketComponent, quoteDenom string, opts ...PricingOption) (osmomath.BigDec, error)
+
+	// DetectArbitrage returns the gain of the triangular cycle denomA -> denomB -> denomC -> denomA.
+	// It is the product of the three pairwise prices computed via GetPrice(...) around the loop.
+	// A gain meaningfully above one indicates an arbitrage opportunity. The threshold is left to the caller
+	// since transient gains slightly off one are normal due to the fees and the price impact of the pricing quotes.
+	DetectArbitrage(ctx context.Context, denomA, denomB, denomC string) (osmomath.Dec, error)
+
+	// GetPriceAndRoute returns the price of the base denom in terms of the quote denom alongside the route
+	// it was computed along. The route pools are prepared for output. The price is always recomputed
+	// so that the route is the exact one used for the price. The computation is the same as the one
+	// of GetPrice, including the pinned routes, the circuit breaker and the pool data freshness check,
+	// so the computed price is cached as usual. An empty quote denom implies the default quote denom.
+	// The route is nil if the price is not computed along a single route, for example, over the pinned pools,
+	// as the weighted average of the routes or in the composite quote.
+	// Returns the price of one and a nil route if the base and quote denoms are equal.
+	GetPriceAndRoute(ctx context.Context, baseDenom string, quoteDenom string, opts ...PricingOption) (osmomath.BigDec, SplitRoute, error)
+
+	// ExplainPrice recomputes the price of the base denom in terms of the quote denom bypassing the cache
+	// and returns the breakdown of the computation for auditing. The computation is the same as the one
+	// of GetPrice so the computed price is cached as usual. An empty quote denom implies the default quote denom.
+	// Returns error if the price fails to compute.
+	ExplainPrice(ctx context.Context, baseDenom string, quoteDenom string, opts ...PricingOption) (PriceExplanation, error)
+
 	// InitializeCache initialize the cache for the pricing source to a given value.
 	// Panics if cache is already set.
 	InitializeCache(*cache.Cache)
 }
 
+// PricingSourceAdmin defines the administration and introspection of the pricing source
+// beyond the pricing itself, such as the cache management, the pool suspicion and the providers
+// of the auxiliary pricing data. It is not routed by the pricing source type.
+type PricingSourceAdmin interface {
+	// SetWorkerTrackedDenoms sets the base denoms whose default quote prices are refreshed by the background pricing worker.
+	// Only their default quote prices are cached indefinitely. Others use the configured cache expiry.
+	// The map must not be mutated after the call.
+	SetWorkerTrackedDenoms(denoms map[string]struct{})
+
+	// SetBlockHeightProvider sets the provider of the heights used for detecting the stale pool data.
+	// The pool data freshness is only checked if the provider is set and the max pool data staleness is configured.
+	SetBlockHeightProvider(provider BlockHeightProvider)
+
+	// SetTWAPProvider sets the provider of the pool TWAPs used by the prices computed with WithTWAPPricing(...).
+	// If the provider is not set, the pool spot prices are used.
+	SetTWAPProvider(provider TWAPProvider)
+
+	// MarkPoolSuspect marks the given pool as suspected of manipulation until the given time.
+	// Until then, the routes passing through the pool are recomputed with the pool excluded,
+	// including the routes reused across the computations. The routes that avoid the pool are computed as usual.
+	// The cached prices stored alongside their routes, i.e. those refreshed by the pricing worker, are invalidated
+	// if their routes pass through the pool. The other cached prices are kept until they expire.
+	// The pinned routes are used as configured.
+	// Marking an already suspect pool overwrites its expiry.
+	MarkPoolSuspect(poolID uint64, until time.Time)
+
+	// ClearPoolSuspect removes the given pool from the suspect pools before its expiry.
+	ClearPoolSuspect(poolID uint64)
+
+	// SuspectPools returns the currently suspect pool IDs mapped to the time until which they are suspect.
+	SuspectPools() map[uint64]time.Time
+
+	// ListCachedPairs returns the base and quote denom pairs with unexpired cached prices.
+	ListCachedPairs() []CachedPricePair
+
+	// ExportCacheSnapshot serializes the unexpired cached prices with their remaining TTLs
+	// so that the warmed cache can be persisted across restarts.
+	ExportCacheSnapshot() ([]byte, error)
+
+	// ImportCacheSnapshot restores the cached prices from the snapshot produced by ExportCacheSnapshot(...).
+	// The entries whose TTL has elapsed since the export are dropped.
+	// Returns error if the snapshot is malformed.
+	ImportCacheSnapshot(data []byte) error
+
+	// WarmReverseDefaultQuotePrices caches the inverse of the cached default quote prices of the worker-tracked
+	// base denoms under the reversed pairs and deletes the previously warmed reverse prices of the base denoms
+	// no longer warmed. Meant to be called by the pricing worker after the forward prices are warmed.
+	WarmReverseDefaultQuotePrices() error
+
+	// RefreshDefaultQuotePrices recomputes the indefinitely cached default quote prices of the given base denoms.
+	// The prices are recomputed from the pool spot prices along the routes stored when they were warmed
+	// without the route search. Falls back to the full recompute with the given options if there is no stored route
+	// or if the pools of the stored route changed.
+	// Returns the context error once the context is done without refreshing the remaining base denoms.
+	RefreshDefaultQuotePrices(ctx context.Context, baseDenoms []string, opts ...PricingOption) error
+
+	// DefaultQuoteDenom returns the chain denom of the configured default quote.
+	// The prices against it are the ones cached indefinitely for the worker-tracked base denoms.
+	// It is unaffected by the per-call overrides via WithDefaultQuoteDenom(...).
+	DefaultQuoteDenom() string
+
+	// PricingDebugInfo returns the effective pricing config alongside the live pricing stats
+	// for operational introspection.
+	PricingDebugInfo() PricingDebugInfo
+}
+
+// ChainPricingSource defines the pricing source by routing through on-chain pools
+// alongside its administration.
+type ChainPricingSource interface {
+	PricingSource
+	PricingSourceAdmin
+}
+
 // DefaultMinLiquidityOption defines the default min liquidity option.
 // Per the config file set at start-up
 const DefaultMinLiquidityOption = -1
 
+// NoPricePrecision defines the price precision option that implies
+// no rounding of the prices.
+const NoPricePrecision = -1
+
 // PricingOptions defines the options for retrieving the prices.
 type PricingOptions struct {
 	// RecomputePrices defines whether to recompute the prices or attempt to retrieve
 	// them from cache first.
 	// If set to false, the prices might still be recomputed if the cache is empty.
+	// Defaults to PricingConfig.AlwaysRecompute.
 	RecomputePrices bool
+	// RecomputeIfZero defines whether to treat a cached zero price as a cache miss
+	// and recompute it. This self-heals cache entries poisoned by transient routing failures.
+	RecomputeIfZero bool
 	// MinLiquidity defines the minimum liquidity required to consider a pool for pricing.
+	// The prices with a min liquidity other than the configured one or zero are always recomputed and never cached.
 	MinLiquidity int
+	// VolumeWeightedPricing defines whether to enable split routes and compute the price
+	// as the average of the route prices weighted by the amount in of each route.
+	// Volume-weighted prices are always recomputed and never cached.
+	VolumeWeightedPricing bool
+	// PricePrecision defines the number of decimal places to round the prices to.
+	// NoPricePrecision implies full precision.
+	PricePrecision int
+	// Height defines the chain height at which the prices are computed.
+	// Zero implies that the height is unknown.
+	Height uint64
+	// RawChainPrice defines whether to return the raw chain price prior to applying
+	// the scaling factors. The properly scaled price is still cached.
+	// Raw chain prices are always recomputed. Intended for debugging the scaling factors.
+	RawChainPrice bool
+	// MaxRoutes overrides the configured max routes for computing the prices.
+	// Zero implies the configured value. Prices with the override are always recomputed and never cached.
+	MaxRoutes int
+	// MaxPoolsPerRoute overrides the configured max pools per route for computing the prices.
+	// Zero implies the configured value. Prices with the override are always recomputed and never cached.
+	MaxPoolsPerRoute int
+	// MidPriceOnly defines whether to compute the price purely as the product of the pool spot prices
+	// along the top route without any trade simulation. Returns error rather than falling back
+	// to dividing the quote amounts if any spot price is unavailable.
+	// Mid prices are always recomputed and take precedence over volume-weighted pricing.
+	MidPriceOnly bool
+	// TraceAttributes are the caller-provided attributes set on the pricing computation span
+	// so that the downstream router spans are correlated with the caller.
+	// The span is a no-op if no tracer provider is configured.
+	TraceAttributes map[string]string
+	// EnforceRouteLiquidity defines whether to reject the prices whose selected route has a pool
+	// with less OSMO liquidity than MinLiquidity. The pool liquidity is computed from its balances.
+	// Unlike the candidate pool filtering, this accounts for the bottleneck pool of the selected route.
+	// Prices with route liquidity enforcement are always recomputed.
+	EnforceRouteLiquidity bool
+	// ForceCompute defines whether to skip the shortcut pricing the equal base and quote denoms at one.
+	// Instead, the scaling factors are applied to the chain price of one so that a discrepancy
+	// from one reveals the scaling factor bugs for the denom.
+	// Diagnostic only. Forced prices are always recomputed and never cached.
+	ForceCompute bool
+	// RateLimitKey is the client key that the price recomputations are rate limited by
+	// if the rate limiter is configured. Cache hits are exempt.
+	// Empty implies no rate limiting, for example, for the internal callers.
+	RateLimitKey string
+	// PricingSource is the pricing source requested by the caller from the pricing source router.
+	// Nil implies the default pricing source of the router.
+	PricingSource *PricingSourceType
+	// StaleOnError defines whether to serve the cached price, even if expired within the stale grace period,
+	// when the price computation fails. The stale price is returned alongside an error wrapping ErrStaleData.
+	StaleOnError bool
+	// DefaultQuoteDenom overrides the configured default quote chain denom that the prices are computed against
+	// if no quote denom is given. The prices against the override use the configured cache expiry
+	// since only the prices against the configured default quote denom are refreshed by the background pricing worker.
+	// Empty implies the configured default quote denom.
+	DefaultQuoteDenom string
+	// LastKnownGoodFallback defines whether to serve the last successfully computed price, regardless of its age,
+	// when the price computation fails and no stale cached price is served.
+	// The last known good price is returned alongside an error wrapping ErrStaleData.
+	LastKnownGoodFallback bool
+	// DefaultScalingFactor is the scaling factor used in place of the unknown scaling factors of the denoms.
+	// The prices computed with it are low-confidence so they are flagged by GetPriceWithConfidence(...).
+	// The prices with the default scaling factor are always recomputed and never cached.
+	// Nil implies that the unknown scaling factors fail the price computation.
+	DefaultScalingFactor osmomath.Dec
+	// ForceAlternativeMethod defines whether to bypass the spot price method and always compute the price
+	// by dividing the quote amount in by the amount out of the router-selected route(s).
+	// It does not apply to the pinned routes and mid prices. Diagnostic only.
+	// Prices with the forced alternative method are always recomputed and never cached.
+	ForceAlternativeMethod bool
+	// FeeInclusivePricing defines whether to net out the fees of the pricing route(s) from the price
+	// as price * (1 - route fee) so that it reflects the realizable value. The route fee compounds
+	// the spread and taker fees of the pools along a route as 1 - ∏(1 - (spread factor + taker fee)),
+	// pro-rated across the routes by their amount in. Pinned routes only account for the spread factors.
+	// Fee-inclusive prices are always recomputed and never cached.
+	FeeInclusivePricing bool
+	// PrecisionProvider is consulted for the scaling factors of the denoms before the on-chain scaling factors.
+	// This allows overriding the precision of the tokens with incorrect on-chain metadata.
+	// Prices with a precision provider are always recomputed and never cached.
+	// Nil implies that only the on-chain scaling factors are used.
+	PrecisionProvider PrecisionProvider
+	// MedianPricingRoutes is the number of the top ranked routes whose spot prices are aggregated
+	// into their liquidity-weighted median for manipulation resistance. The routes through the same pools
+	// are de-duplicated. Each route is weighted by the OSMO liquidity of its least liquid pool.
+	// It takes precedence over volume-weighted pricing while mid prices and the forced alternative method
+	// take precedence over it. Median prices are always recomputed and never cached.
+	// Zero implies that the price is computed along the optimal route(s).
+	MedianPricingRoutes int
+	// TWAPWindow is the window of the time-weighted average prices used instead of the pool spot prices
+	// for manipulation resistance (see PricingSourceAdmin.SetTWAPProvider). The pools without a TWAP over the window
+	// fall back to their spot prices. TWAP prices are always recomputed and never cached.
+	// Zero implies that the pool spot prices are used.
+	TWAPWindow time.Duration
+	// TiedRoutesTolerance is the relative tolerance within which the amounts out of the ranked routes
+	// are tied with the amount out of the top route. The price is the geometric mean of the spot prices
+	// of the tied routes so that it does not jitter as the near-identical routes swap ranks.
+	// The median pricing, mid prices and the forced alternative method take precedence over it
+	// while it takes precedence over volume-weighted pricing.
+	// Tied routes prices are always recomputed and never cached.
+	// It is within [0, 1) (see WithGeoMeanAcrossTiedRoutes).
+	// Nil implies that the price is computed along the optimal route(s).
+	TiedRoutesTolerance osmomath.Dec
+	// ComputeBudget bounds the wall-clock duration of GetPrice. The price computation is abandoned
+	// once the budget is exhausted in favor of the freshest available price (see WithComputeBudget).
+	// Zero implies no bound.
+	ComputeBudget time.Duration
+}
+
+// PrecisionProvider provides the scaling factors of the denoms from an off-chain source.
+type PrecisionProvider interface {
+	// GetScalingFactor returns the scaling factor of the given denom and true if the provider has it.
+	// Returns false otherwise so that the on-chain scaling factor is used.
+	GetScalingFactor(denom string) (osmomath.Dec, bool)
+}
+
+// RateLimiter limits the rate of the calls per key.
+type RateLimiter interface {
+	// Allow returns true if the call for the given key is within its rate limit
+	// and consumes from its budget. Returns false otherwise.
+	Allow(key string) bool
 }
 
 // DefaultPricingOptions defines the default options for retrieving the prices.
 var DefaultPricingOptions = PricingOptions{
 	RecomputePrices: false,
 	MinLiquidity:    DefaultMinLiquidityOption,
+	PricePrecision:  NoPricePrecision,
 }
 
 // PricingOption configures the pricing options.
@@ -64,6 +339,255 @@ func WithRecomputePrices() PricingOption {
 	}
 }
 
+// WithUseCache configures the pricing options to attempt retrieving the prices from cache first.
+// Overrides PricingConfig.AlwaysRecompute for a single call.
+func WithUseCache() PricingOption {
+	return func(o *PricingOptions) {
+		o.RecomputePrices = false
+	}
+}
+
+// WithRecomputeIfZero configures the pricing options to recompute the prices
+// if the cached price is zero.
+func WithRecomputeIfZero() PricingOption {
+	return func(o *PricingOptions) {
+		o.RecomputeIfZero = true
+	}
+}
+
+// WithHeight configures the pricing options with the chain height at which the prices are computed.
+func WithHeight(height uint64) PricingOption {
+	return func(o *PricingOptions) {
+		o.Height = height
+	}
+}
+
+// WithRawChainPrice configures the pricing options to return the raw chain price
+// prior to applying the scaling factors.
+func WithRawChainPrice() PricingOption {
+	return func(o *PricingOptions) {
+		o.RawChainPrice = true
+	}
+}
+
+// WithMidPriceOnly configures the pricing options to compute the impact-free mid price
+// from the pool spot prices only.
+func WithMidPriceOnly() PricingOption {
+	return func(o *PricingOptions) {
+		o.MidPriceOnly = true
+	}
+}
+
+// WithEnforceRouteLiquidity configures the pricing options to reject the prices
+// whose selected route has a pool with less OSMO liquidity than the min liquidity.
+func WithEnforceRouteLiquidity() PricingOption {
+	return func(o *PricingOptions) {
+		o.EnforceRouteLiquidity = true
+	}
+}
+
+// WithForceCompute configures the pricing options to skip the equal denom shortcut.
+// It is a diagnostic option for validating that the scaling factors of a denom
+// priced against itself round-trip to exactly one.
+func WithForceCompute() PricingOption {
+	return func(o *PricingOptions) {
+		o.ForceCompute = true
+	}
+}
+
+// WithForceAlternativeMethod configures the pricing options to bypass the spot price method
+// and compute the price by dividing the quote amount in by the amount out.
+// It is a diagnostic option for comparing the outputs of the two methods for the same pair.
+func WithForceAlternativeMethod() PricingOption {
+	return func(o *PricingOptions) {
+		o.ForceAlternativeMethod = true
+	}
+}
+
+// WithFeeInclusivePricing configures the pricing options to net out the route fees from the price.
+// See PricingOptions.FeeInclusivePricing for the formula. Prices are fee-exclusive by default.
+func WithFeeInclusivePricing() PricingOption {
+	return func(o *PricingOptions) {
+		o.FeeInclusivePricing = true
+	}
+}
+
+// WithMedianPricing configures the pricing options to compute the price as the liquidity-weighted median
+// of the spot prices of up to k top ranked routes. See PricingOptions.MedianPricingRoutes.
+// Non-positive k disables the median pricing.
+func WithMedianPricing(k int) PricingOption {
+	return func(o *PricingOptions) {
+		if k < 0 {
+			k = 0
+		}
+		o.MedianPricingRoutes = k
+	}
+}
+
+// WithTWAPPricing configures the pricing options to use the pool TWAPs over the given window
+// instead of the pool spot prices. See PricingOptions.TWAPWindow.
+// Non-positive window disables the TWAP pricing.
+func WithTWAPPricing(window time.Duration) PricingOption {
+	return func(o *PricingOptions) {
+		if window < 0 {
+			window = 0
+		}
+		o.TWAPWindow = window
+	}
+}
+
+// WithGeoMeanAcrossTiedRoutes configures the pricing options to compute the price as the geometric mean
+// of the spot prices of the ranked routes whose amounts out are within the given relative tolerance
+// of the top route amount out. See PricingOptions.TiedRoutesTolerance.
+// Nil, negative or at least one tolerance disables the tied routes pricing
+// since the tolerance of one would tie all of the ranked routes regardless of their amounts out.
+func WithGeoMeanAcrossTiedRoutes(tolerance osmomath.Dec) PricingOption {
+	return func(o *PricingOptions) {
+		if tolerance.IsNil() || tolerance.IsNegative() || tolerance.GTE(osmomath.OneDec()) {
+			o.TiedRoutesTolerance = osmomath.Dec{}
+			return
+		}
+		o.TiedRoutesTolerance = tolerance
+	}
+}
+
+// WithComputeBudget configures the pricing options to return the best-effort price within the given
+// wall-clock budget, trading accuracy for guaranteed latency. The prices are resolved in the following order:
+//  1. The unexpired cached price unless the recompute is requested, as without the budget.
+//  2. The recomputed price if the computation completes within the remaining budget.
+//  3. Once the budget is exhausted, the cached price, even if expired within the stale grace period.
+//  4. Once the budget is exhausted, the last successfully computed price, regardless of its age.
+//
+// The fallback prices (3 and 4) are returned alongside an error wrapping both ErrComputeBudgetExhausted
+// and ErrStaleData so that the callers must opt into them via errors.Is(err, ErrComputeBudgetExhausted).
+// If there is no fallback price, only the error wrapping ErrComputeBudgetExhausted is returned.
+// The prices that are never cached, such as the volume-weighted and raw chain prices, have no fallback.
+// The computation errors within the budget are returned as without the budget.
+// The abandoned computations are not recorded as failures by the circuit breaker.
+// Note that the callers treating any error as a failure, such as GetUSDPrice(...), GetBasketPrice(...)
+// and TokensUsecase.GetPrices(...), drop the fallback prices.
+// Only the GetPrice(...) and GetPriceAsync(...) callers can opt into them.
+// Non-positive budget disables the bound.
+func WithComputeBudget(budget time.Duration) PricingOption {
+	return func(o *PricingOptions) {
+		if budget < 0 {
+			budget = 0
+		}
+		o.ComputeBudget = budget
+	}
+}
+
+// WithPrecisionProvider configures the pricing options to consult the given precision provider
+// for the scaling factors before falling back to the on-chain scaling factors.
+func WithPrecisionProvider(precisionProvider PrecisionProvider) PricingOption {
+	return func(o *PricingOptions) {
+		o.PrecisionProvider = precisionProvider
+	}
+}
+
+// WithRateLimitKey configures the pricing options to rate limit the price recomputations
+// by the given client key.
+func WithRateLimitKey(key string) PricingOption {
+	return func(o *PricingOptions) {
+		o.RateLimitKey = key
+	}
+}
+
+// WithPricingSource configures the pricing options to request the given pricing source
+// from the pricing source router.
+func WithPricingSource(pricingSource PricingSourceType) PricingOption {
+	return func(o *PricingOptions) {
+		o.PricingSource = &pricingSource
+	}
+}
+
+// WithStaleOnError configures the pricing options to serve the cached price, even if expired
+// within the configured stale grace period, when the price computation fails.
+// The stale price is returned alongside an error wrapping both ErrStaleData and the computation error
+// so that the callers must opt into the stale prices via errors.Is(err, ErrStaleData).
+// Volume-weighted and raw chain prices are never served stale since they are not cached.
+func WithStaleOnError() PricingOption {
+	return func(o *PricingOptions) {
+		o.StaleOnError = true
+	}
+}
+
+// WithLastKnownGoodFallback configures the pricing options to serve the last successfully computed price,
+// regardless of its age within the retention (see PricingConfig.LastKnownGoodRetentionMs),
+// when the price computation fails and no stale cached price is served (see WithStaleOnError).
+// The last known good price is returned alongside an error wrapping both ErrStaleData and the computation error.
+// Volume-weighted and raw chain prices are never served from the last known good prices.
+func WithLastKnownGoodFallback() PricingOption {
+	return func(o *PricingOptions) {
+		o.LastKnownGoodFallback = true
+	}
+}
+
+// WithDefaultQuoteDenom configures the pricing options to price against the given quote chain denom
+// instead of the configured default quote denom if no quote denom is given.
+// The override must be a valid chain denom. Otherwise, InvalidDefaultQuoteDenomError is returned.
+// It does not apply to GetUSDPrice(...) since the USD rate is configured for the configured default quote denom.
+func WithDefaultQuoteDenom(denom string) PricingOption {
+	return func(o *PricingOptions) {
+		o.DefaultQuoteDenom = denom
+	}
+}
+
+// WithDefaultScalingFactor configures the pricing options to use the given scaling factor
+// in place of the unknown scaling factors of the denoms rather than failing the price computation.
+// The non-positive scaling factor is replaced by one.
+// The resulting prices are low-confidence. They are returned like any other price so that GetUSDPrice(...),
+// GetBasketPrice(...) and TokensUsecase.GetPrices(...) serve them. GetPriceWithConfidence(...) flags them.
+func WithDefaultScalingFactor(scalingFactor osmomath.Dec) PricingOption {
+	return func(o *PricingOptions) {
+		if scalingFactor.IsNil() || !scalingFactor.IsPositive() {
+			o.DefaultScalingFactor = osmomath.OneDec()
+			return
+		}
+		o.DefaultScalingFactor = scalingFactor.Clone()
+	}
+}
+
+// WithTraceAttributes configures the pricing options to set the given attributes
+// on the pricing computation span.
+func WithTraceAttributes(attrs map[string]string) PricingOption {
+	return func(o *PricingOptions) {
+		o.TraceAttributes = attrs
+	}
+}
+
+// WithPricingMaxRoutes configures the pricing options to override the configured max routes.
+func WithPricingMaxRoutes(maxRoutes int) PricingOption {
+	return func(o *PricingOptions) {
+		o.MaxRoutes = maxRoutes
+	}
+}
+
+// WithPricingMaxPoolsPerRoute configures the pricing options to override the configured max pools per route.
+func WithPricingMaxPoolsPerRoute(maxPoolsPerRoute int) PricingOption {
+	return func(o *PricingOptions) {
+		o.MaxPoolsPerRoute = maxPoolsPerRoute
+	}
+}
+
+// WithVolumeWeightedPricing configures the pricing options to compute the volume-weighted
+// price across all routes of the split quote.
+func WithVolumeWeightedPricing() PricingOption {
+	return func(o *PricingOptions) {
+		o.VolumeWeightedPricing = true
+	}
+}
+
+// WithPricePrecision configures the pricing options to round the prices
+// to the given number of decimal places.
+// Tiny positive prices are never rounded to zero. Instead, the precision is increased
+// until the price is non-zero.
+func WithPricePrecision(decimals int) PricingOption {
+	return func(o *PricingOptions) {
+		o.PricePrecision = decimals
+	}
+}
+
 // WithMinLiquidity configures the min liquidity option.
 func WithMinLiquidity(minLiquidity int) PricingOption {
 	return func(o *PricingOptions) {
@@ -81,28 +605,384 @@ type PricingConfig struct {
 	// The number of milliseconds to cache the pricing data for.
 	CacheExpiryMs int `mapstructure:"cache-expiry-ms"`
 
+	// The number of milliseconds between purges of the expired pricing cache entries.
+	// Zero implies that expired entries are only removed when looked up past the stale grace period,
+	// overwritten or evicted.
+	CachePurgeIntervalMs int `mapstructure:"cache-purge-interval-ms"`
+
+	// CacheUnpriceable defines whether to cache the pairs that no route is found for
+	// so that the subsequent requests within UnpriceableTTLMs fail fast with ErrUnpriceable
+	// rather than repeatedly retrying the expensive route search.
+	CacheUnpriceable bool `mapstructure:"cache-unpriceable"`
+	// The number of milliseconds to cache the unpriceable pairs for, independently from CacheExpiryMs
+	// so that the pairs that become priceable recover sooner than the cached prices expire.
+	// Defaults to one second if not positive.
+	UnpriceableTTLMs int `mapstructure:"unpriceable-ttl-ms"`
+
+	// The number of milliseconds to retain the last known good prices for since they were last computed
+	// so that the prices of the pairs that are no longer priced are dropped (see WithLastKnownGoodFallback).
+	// Defaults to one day if not positive.
+	LastKnownGoodRetentionMs int `mapstructure:"last-known-good-retention-ms"`
+
+	// The number of milliseconds to retain the expired pricing cache entries for
+	// so that they can be served stale if the price computation fails (see WithStaleOnError).
+	// Zero implies that the expired entries are never served.
+	StaleGracePeriodMs int `mapstructure:"stale-grace-period-ms"`
+
+	// The max number of the pricing cache entries beyond which the least recently used ones are evicted.
+	// The entries without expiration (tracked by the pricing worker) are never evicted.
+	// Zero implies that the pricing cache is unbounded.
+	MaxCacheEntries int `mapstructure:"max-cache-entries"`
+	// The number of shards the pricing cache keys are partitioned across to reduce the lock contention
+	// under high concurrency. The max cache entries are split evenly across the shards.
+	// Non-positive implies a single shard.
+	CacheShards int `mapstructure:"cache-shards"`
+
 	// The default quote chain denom.
 	DefaultSource PricingSourceType `mapstructure:"default-source"`
 
 	// The default quote chain denom.
 	DefaultQuoteHumanDenom string `mapstructure:"default-quote-human-denom"`
 
+	// The USD rate of the default quote human denom as a decimal string (e.g. "0.998").
+	// If set, it is applied to the prices against the default quote human denom to convert them to USD.
+	// Required if the default quote human denom is not USD-pegged.
+	DefaultQuoteUSDRate string `mapstructure:"default-quote-usd-rate"`
+
 	MaxPoolsPerRoute int `mapstructure:"max-pools-per-route"`
 	MaxRoutes        int `mapstructure:"max-routes"`
+
+	// StablecoinQuoteDenoms are the chain denoms of the stablecoin quotes that are probed with
+	// a multiple of one token so that the pricing avoids selecting low liquidity routes.
+	// The other quote denoms are probed with one token.
+	// If unset, all of the quote denoms are probed with the multiple as before the config was introduced.
+	// Empty disables the multiple for all of the quote denoms.
+	StablecoinQuoteDenoms []string `mapstructure:"stablecoin-quote-denoms"`
+
+	// RequiredIntermediateDenom is the chain denom that the pricing routes are required to pass through
+	// for consistent pricing across the denoms. It does not apply to pricing the denom itself or against it.
+	// Empty implies no constraint.
+	RequiredIntermediateDenom string `mapstructure:"required-intermediate-denom"`
 	// Denominated in OSMO (not uosmo)
 	MinOSMOLiquidity int `mapstructure:"min-osmo-liquidity"`
+
+	// The min and max computed prices as decimal strings (e.g. "0.000000000000000001").
+	// The prices outside of the bounds are rejected with PriceOutOfRangeError and never cached.
+	// This guards against the nonsensical prices of the tokens with extreme scaling factors.
+	// Empty implies that the respective bound is not enforced.
+	MinPrice string `mapstructure:"min-price"`
+	MaxPrice string `mapstructure:"max-price"`
+	// PriceBounds maps the base denoms to their min and max prices against the default quote denom.
+	// They apply on top of the min and max price so that the operators can bound the denoms
+	// with different expected price ranges. The base denoms are case-insensitive.
+	// The denoms without a bound are only subject to the min and max price.
+	PriceBounds map[string]PriceBound `mapstructure:"price-bounds"`
+
+	// ReferenceQuoteDenom is the chain denom of the secondary quote used to cross-check the computed prices.
+	// If set, the price of base in quote is compared against the price of base in reference
+	// divided by the price of quote in reference. The divergence beyond the threshold is counted
+	// by the sqs_pricing_reference_divergence_total metric while the primary price is still returned.
+	// The cross-check runs in the background over the cached reference prices only, so it is skipped
+	// until both reference prices are cached. Empty disables the cross-check.
+	ReferenceQuoteDenom string `mapstructure:"reference-quote-denom"`
+	// ReferenceDivergenceThreshold is the max relative divergence between the primary and the cross-checked
+	// prices as a decimal string (e.g. "0.05" for 5%). Defaults to 5% if empty.
+	ReferenceDivergenceThreshold string `mapstructure:"reference-divergence-threshold"`
+
+	// AlwaysRecompute defines the default of PricingOptions.RecomputePrices.
+	// If set, the prices are recomputed on every request unless the caller opts into
+	// the cache via WithUseCache(). If unset, the cache is used unless the caller
+	// opts out via WithRecomputePrices().
+	AlwaysRecompute bool `mapstructure:"always-recompute"`
+
+	// The number of milliseconds to cache the pool spot prices used for pricing for.
+	// Should be tied to the block time so that the spot prices are reused within a block.
+	// Zero disables the spot price cache.
+	SpotPriceCacheExpiryMs int `mapstructure:"spot-price-cache-expiry-ms"`
+
+	// The max number of retries of the transient pool spot price errors before falling back
+	// to the alternative pricing method. Zero disables retries.
+	SpotPriceMaxRetries int `mapstructure:"spot-price-max-retries"`
+	// The number of milliseconds to wait before the first spot price retry.
+	// The backoff doubles with every subsequent retry.
+	SpotPriceRetryBackoffMs int `mapstructure:"spot-price-retry-backoff-ms"`
+
+	// PinnedRoutes maps the pairs formatted as "<base>|<quote>" to the ordered pool IDs
+	// swapping from the quote to the base denom. The prices of the pinned pairs are computed
+	// over the pinned pools, bypassing the route selection. The pairs are case-insensitive.
+	PinnedRoutes map[string][]uint64 `mapstructure:"pinned-routes"`
+
+	// DenomCategories maps the base denoms to their category names (e.g. "stablecoin", "major", "long-tail")
+	// so that the sqs_pricing_requests_total metric is labeled by the category rather than the denom
+	// to keep its cardinality bounded. The denoms are case-insensitive. The uncategorized denoms fall into "other".
+	DenomCategories map[string]string `mapstructure:"denom-categories"`
+
+	// CompositeQuote is the basket of the chain denoms that replaces the default quote denom if set,
+	// for example, an index of USDC and USDT that smooths over a single stablecoin de-peg.
+	// The price in the composite quote is the average of the prices in its components weighted by their weights.
+	// The weights must be positive and sum to one. The composite quote is identified by a synthetic denom
+	// under which its prices are cached (see PricingSourceAdmin.DefaultQuoteDenom). The default quote USD rate does not apply to it
+	// since the USD prices are quoted in the default quote human denom (see PricingSource.GetUSDPrice).
+	// Empty implies that the default quote human denom is the default quote.
+	CompositeQuote []WeightedDenom `mapstructure:"composite-quote"`
+
+	// RateLimitPerSecond is the rate per second at which the price recomputations are allowed
+	// per rate limit key (see WithRateLimitKey). The cache hits are exempt.
+	// Zero disables the rate limiting.
+	RateLimitPerSecond float64 `mapstructure:"rate-limit-per-second"`
+	// RateLimitBurst is the max number of the price recomputations allowed in a burst per rate limit key.
+	// Defaults to one if not positive.
+	RateLimitBurst int `mapstructure:"rate-limit-burst"`
+
+	// CircuitBreakerFailureThreshold is the number of consecutive pricing failures for a base denom
+	// within the cooldown window after which the pricing of that denom is short-circuited
+	// with the last error for the cooldown period.
+	// Zero disables the circuit breaker.
+	CircuitBreakerFailureThreshold int `mapstructure:"circuit-breaker-failure-threshold"`
+	// CircuitBreakerCooldownMs is the number of milliseconds to short-circuit the pricing of a base denom
+	// once the circuit opens. After it elapses, a single probe is allowed to close the circuit.
+	// It also defines the window within which the consecutive failures are counted.
+	CircuitBreakerCooldownMs int `mapstructure:"circuit-breaker-cooldown-ms"`
+
+	// MaxConcurrentAsyncComputes is the max number of the price computations started via GetPriceAsync(...)
+	// that run concurrently. The excess computations wait for a slot or the context cancellation.
+	// Zero implies no limit.
+	MaxConcurrentAsyncComputes int `mapstructure:"max-concurrent-async-computes"`
+	// MaxConcurrentRouteComputes is the max number of the route spot price computations of the volume-weighted
+	// split pricing that run concurrently across all of the price computations. Zero implies no limit.
+	MaxConcurrentRouteComputes int `mapstructure:"max-concurrent-route-computes"`
+
+	// WorkerUpdateTimeoutMs is the number of milliseconds after which the background pricing worker
+	// aborts a price update. Zero implies the default of two minutes.
+	WorkerUpdateTimeoutMs int `mapstructure:"worker-update-timeout-ms"`
+	// WorkerFullRecomputeHeightInterval is the number of heights between the price updates of the background
+	// pricing worker that recompute the prices with the route search. The updates in between refresh the prices
+	// along their stored routes (see PricingSourceAdmin.RefreshDefaultQuotePrices). Zero implies the default of 50.
+	WorkerFullRecomputeHeightInterval uint64 `mapstructure:"worker-full-recompute-height-interval"`
+
+	// MaxPoolDataStalenessBlocks is the max number of blocks that the height of the pool data used for pricing
+	// may lag the chain height by. Beyond it, the price computations fail with StalePoolDataError.
+	// Requires the block height provider (see PricingSourceAdmin.SetBlockHeightProvider). Zero disables the check.
+	MaxPoolDataStalenessBlocks uint64 `mapstructure:"max-pool-data-staleness-blocks"`
+
+	// VolumeWeightedRouteSelection defines whether to bias the pricing route selection toward
+	// the routes through pools with higher recent trade volume (see TokensUsecase.SetPoolVolumes).
+	// The bias only applies among the routes whose amount out is within a small tolerance
+	// of the best amount out so that the output-maximizing objective remains primary.
+	VolumeWeightedRouteSelection bool `mapstructure:"volume-weighted-route-selection"`
+
+	// EnableRouteReuse defines whether to reuse the pricing routes within the same route update
+	// height window (see RouterConfig.RouteUpdateHeightInterval). If enabled, only the spot prices
+	// are recomputed for the reused routes rather than enumerating the routes again.
+	// Only applies when the height is provided via WithHeight(...).
+	EnableRouteReuse bool `mapstructure:"enable-route-reuse"`
+
+	// AllowedPoolTypes restricts the pools used for pricing to the given types.
+	// For example, this allows excluding CosmWasm pools whose spot prices require network requests.
+	// Empty implies that all pool types are allowed.
+	AllowedPoolTypes []poolmanagertypes.PoolType `mapstructure:"allowed-pool-types"`
+}
+
+// PriceBound is the min and max price of a base denom against the default quote denom
+// as decimal strings (e.g. "0.000000000000000001"). Empty implies that the respective bound is not enforced.
+type PriceBound struct {
+	Min string `mapstructure:"min"`
+	Max string `mapstructure:"max"`
+}
+
+// BlockHeightProvider provides the heights for detecting the stale pool data.
+type BlockHeightProvider interface {
+	// GetPoolDataHeight returns the height of the latest ingested pool data.
+	GetPoolDataHeight() uint64
+	// GetChainHeight returns the latest chain height.
+	GetChainHeight(ctx context.Context) (uint64, error)
+}
+
+// TWAPProvider provides the time-weighted average prices of the pools.
+type TWAPProvider interface {
+	// GetPoolTWAP returns the TWAP of the base denom in terms of the quote denom in the given pool
+	// over the given window ending now and true if it is available. Returns false otherwise
+	// so that the pool spot price is used.
+	GetPoolTWAP(ctx context.Context, poolID uint64, quoteDenom string, baseDenom string, window time.Duration) (osmomath.BigDec, bool)
+}
+
+// PriceWithConfidence is the price returned by GetPriceWithConfidence(...).
+type PriceWithConfidence struct {
+	Price osmomath.BigDec
+	// IsLowConfidence is true if the price is computed with the default scaling factor
+	// in place of an unknown scaling factor.
+	IsLowConfidence bool
+}
+
+// PriceResultOrError is the result of the price computation started via GetPriceAsync(...).
+// Err is set if the computation failed. The price might be set alongside the errors
+// that the callers opt into, for example, ErrStaleData.
+type PriceResultOrError struct {
+	Price osmomath.BigDec
+	Err   error
+}
+
+// CachedPricePair is a base and quote denom pair with a cached price.
+type CachedPricePair struct {
+	BaseDenom  string          `json:"base_denom"`
+	QuoteDenom string          `json:"quote_denom"`
+	Price      osmomath.BigDec `json:"price"`
+	// TTLRemaining is the time remaining until the cached price expires.
+	// Zero implies no expiration.
+	TTLRemaining time.Duration `json:"ttl_remaining"`
+}
+
+// WeightedDenom is a chain denom with its weight as a decimal string (e.g. "0.5") in the pricing config.
+type WeightedDenom struct {
+	Denom  string `mapstructure:"denom"`
+	Weight string `mapstructure:"weight"`
+}
+
+// BasketComponent is a denom in a basket with its weight.
+// The weights of the basket components must sum to one.
+type BasketComponent struct {
+	Denom  string
+	Weight osmomath.Dec
+}
+
+// PriceExplanation is the breakdown of a price computation.
+type PriceExplanation struct {
+	BaseDenom  string `json:"base_denom"`
+	QuoteDenom string `json:"quote_denom"`
+	// QuoteCoin is the quote coin swapped into the base denom to select the routes.
+	QuoteCoin sdk.Coin `json:"quote_coin"`
+	// Routes are the routes whose pool spot prices were computed in the order they were computed.
+	// There are several routes for the volume-weighted and the median pricing.
+	Routes []PriceExplanationRoute `json:"routes"`
+	// IsPinnedRoute is true if the price is computed along the configured pinned route.
+	IsPinnedRoute bool `json:"is_pinned_route"`
+	// IsAlternativeMethodUsed is true if the chain price is the quote amount in divided by the amount out
+	// rather than the product of the pool spot prices.
+	IsAlternativeMethodUsed bool `json:"is_alternative_method_used"`
+	// ChainPrice is the price before applying the route fee and the scaling factors.
+	ChainPrice osmomath.BigDec `json:"chain_price"`
+	// IsChainPriceTruncated is true if the chain price is truncated to zero.
+	IsChainPriceTruncated bool `json:"is_chain_price_truncated"`
+	// RouteFee is the fee netted out of the chain price. Nil unless the fee-inclusive pricing is requested.
+	RouteFee osmomath.Dec `json:"route_fee"`
+	// PrecisionScalingFactor descales the chain price to the real price.
+	PrecisionScalingFactor osmomath.BigDec `json:"precision_scaling_factor"`
+	// IsDefaultScalingFactorUsed is true if the scaling factor of either denom is unknown
+	// so the default scaling factor is used instead (see WithDefaultScalingFactor).
+	IsDefaultScalingFactorUsed bool `json:"is_default_scaling_factor_used"`
+	// IsPriceRounded is true if the scaled price is rounded to the requested price precision.
+	IsPriceRounded bool `json:"is_price_rounded"`
+	// Price is the final price.
+	Price osmomath.BigDec `json:"price"`
+}
+
+// PriceExplanationRoute is a route of a price computation.
+type PriceExplanationRoute struct {
+	// Pools are the route pools in the swap order from the quote denom to the base denom.
+	Pools []PriceExplanationPool `json:"pools"`
+	// ChainPrice is the product of the pool spot prices.
+	ChainPrice osmomath.BigDec `json:"chain_price"`
+}
+
+// PriceExplanationPool is the spot price of a route pool.
+type PriceExplanationPool struct {
+	PoolID     uint64          `json:"pool_id"`
+	QuoteDenom string          `json:"quote_denom"`
+	BaseDenom  string          `json:"base_denom"`
+	SpotPrice  osmomath.BigDec `json:"spot_price"`
+}
+
+// PricingDebugInfo is the effective pricing config and the live pricing stats.
+type PricingDebugInfo struct {
+	Config PricingConfig `json:"config"`
+	// DefaultQuoteDenom is the chain denom of the default quote.
+	DefaultQuoteDenom string `json:"default_quote_denom"`
+	// CacheSize is the number of the unexpired pricing cache entries.
+	CacheSize int `json:"cache_size"`
+	// CacheHits and CacheMisses are the totals since start-up.
+	CacheHits   uint64 `json:"cache_hits"`
+	CacheMisses uint64 `json:"cache_misses"`
+	// InFlightComputes is the number of the price computations currently in progress.
+	// The deduplicated concurrent computations are counted once.
+	InFlightComputes int64 `json:"in_flight_computes"`
+}
+
+// pricingCacheKeySeparator separates the base and the quote denoms in the pricing cache key.
+// It is not a valid character in the chain denoms so that the keys are parsed back unambiguously.
+// The denoms containing it are rejected by FormatPricingCacheKey.
+const pricingCacheKeySeparator = "|"
+
+// FormatPricingCacheKey formats the cache key for the given base and quote denoms.
+// The key is directional so that the prices of the same pair in opposite directions do not collide.
+// See ParsePricingCacheKey for the inverse.
+// Returns error if either of the denoms contains the separator since the key would be ambiguous.
+func FormatPricingCacheKey(baseDenom, quoteDenom string) (string, error) {
+	if strings.Contains(baseDenom, pricingCacheKeySeparator) || strings.Contains(quoteDenom, pricingCacheKeySeparator) {
+		return "", fmt.Errorf("base denom (%s) or quote denom (%s) contains the pricing cache key separator (%s)", baseDenom, quoteDenom, pricingCacheKeySeparator)
+	}
+
+	return baseDenom + pricingCacheKeySeparator + quoteDenom, nil
+}
+
+// MustFormatPricingCacheKey is equivalent to FormatPricingCacheKey but panics on error.
+func MustFormatPricingCacheKey(baseDenom, quoteDenom string) string {
+	key, err := FormatPricingCacheKey(baseDenom, quoteDenom)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// ChainPricingCacheNamespace is the pricing cache key namespace of the chain pricing source.
+// It prevents the keys of the pricing sources sharing a single cache from colliding.
+const ChainPricingCacheNamespace = "chain"
+
+// FormatPricingCacheKeyWithNamespace formats the cache key for the given base and quote denoms
+// prefixed by the namespace of the pricing source. See ParsePricingCacheKeyWithNamespace for the inverse.
+// Returns error if the namespace or either of the denoms contains the separator.
+func FormatPricingCacheKeyWithNamespace(namespace, baseDenom, quoteDenom string) (string, error) {
+	if strings.Contains(namespace, pricingCacheKeySeparator) {
+		return "", fmt.Errorf("pricing cache key namespace (%s) contains the pricing cache key separator (%s)", namespace, pricingCacheKeySeparator)
+	}
+
+	key, err := FormatPricingCacheKey(baseDenom, quoteDenom)
+	if err != nil {
+		return "", err
+	}
+
+	return namespace + pricingCacheKeySeparator + key, nil
+}
+
+// MustFormatPricingCacheKeyWithNamespace is equivalent to FormatPricingCacheKeyWithNamespace but panics on error.
+func MustFormatPricingCacheKeyWithNamespace(namespace, baseDenom, quoteDenom string) string {
+	key, err := FormatPricingCacheKeyWithNamespace(namespace, baseDenom, quoteDenom)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// ParsePricingCacheKeyWithNamespace parses the base and quote denoms from the cache key
+// formatted by FormatPricingCacheKeyWithNamespace with the given namespace.
+// Returns error if the key is malformed or belongs to a different namespace.
+func ParsePricingCacheKeyWithNamespace(namespace, key string) (baseDenom string, quoteDenom string, err error) {
+	prefix := namespace + pricingCacheKeySeparator
+	if !strings.HasPrefix(key, prefix) {
+		return "", "", fmt.Errorf("pricing cache key (%s) does not belong to the namespace (%s)", key, namespace)
+	}
+
+	return ParsePricingCacheKey(strings.TrimPrefix(key, prefix))
 }
 
-// FormatCacheKey formats the cache key for the given denoms.
-func FormatPricingCacheKey(a, b string) string {
-	if a < b {
-		a, b = b, a
+// ParsePricingCacheKey parses the base and quote denoms from the cache key formatted by FormatPricingCacheKey.
+// Returns error if the key is malformed. That is, if it does not contain exactly one separator.
+func ParsePricingCacheKey(key string) (baseDenom string, quoteDenom string, err error) {
+	denoms := strings.Split(key, pricingCacheKeySeparator)
+	if len(denoms) != 2 {
+		return "", "", fmt.Errorf("invalid pricing cache key (%s), expected exactly one separator (%s)", key, pricingCacheKeySeparator)
 	}
 
-	var sb strings.Builder
-	sb.WriteString(a)
-	sb.WriteString(b)
-	return sb.String()
+	return denoms[0], denoms[1], nil
 }
 
 type PricingWorker interface {
@@ -0,0 +1,150 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osmosis-labs/sqs/domain"
+)
+
+// TestPricingCacheKey_RoundTrip tests that parsing the formatted pricing cache key
+// returns the original base and quote denoms in order.
+func TestPricingCacheKey_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name       string
+		baseDenom  string
+		quoteDenom string
+	}{
+		{"native denoms", "uosmo", "uion"},
+		{"reversed order", "uion", "uosmo"},
+		{"IBC denoms", "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", "ibc/498A0751C798A0D9A389AA3691123DADA57DAA4FE165D5C75894505B876BA6E4"},
+		{"token factory denom", "factory/osmo1z0qrq605sjgcqpylfl4aa6s90x738j7m58wyatt0tdzflg2ha26q67k743/wbtc", "uosmo"},
+		{"special characters", "a.b:c_d-e", "x/y.z"},
+		{"escape in denoms", "base\\denom", "quote\\"},
+		{"empty denoms", "", ""},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := domain.FormatPricingCacheKey(tc.baseDenom, tc.quoteDenom)
+			require.NoError(t, err)
+
+			baseDenom, quoteDenom, err := domain.ParsePricingCacheKey(key)
+			require.NoError(t, err)
+			require.Equal(t, tc.baseDenom, baseDenom)
+			require.Equal(t, tc.quoteDenom, quoteDenom)
+		})
+	}
+}
+
+// TestFormatPricingCacheKey_NoCollisions tests that the distinct pathological denom pairs
+// format into distinct pricing cache keys.
+func TestFormatPricingCacheKey_NoCollisions(t *testing.T) {
+	pairs := [][2]string{
+		{"ibc/AB", "C"},
+		{"ibc/A", "BC"},
+		{"ibc", "/ABC"},
+		{"ibc/", "ABC"},
+		{"factory/osmo1/a", "b"},
+		{"factory/osmo1", "a/b"},
+		{"factory", "osmo1/a/b"},
+		{"a:b", "c"},
+		{"a", ":bc"},
+		{"a\\", "b"},
+		{"a", "\\b"},
+		{"", "ab"},
+		{"ab", ""},
+	}
+
+	keys := make(map[string][2]string, len(pairs))
+	for _, pair := range pairs {
+		key, err := domain.FormatPricingCacheKey(pair[0], pair[1])
+		require.NoError(t, err)
+
+		existingPair, ok := keys[key]
+		require.False(t, ok, "pairs %v and %v collide on key (%s)", existingPair, pair, key)
+		keys[key] = pair
+	}
+}
+
+// TestFormatPricingCacheKey_SeparatorInDenom tests that the denoms containing the separator are rejected.
+func TestFormatPricingCacheKey_SeparatorInDenom(t *testing.T) {
+	testCases := []struct {
+		name       string
+		baseDenom  string
+		quoteDenom string
+	}{
+		{"separator in base denom", "base|denom", "uosmo"},
+		{"separator in quote denom", "uosmo", "|quote"},
+		{"separator only", "|", "|"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := domain.FormatPricingCacheKey(tc.baseDenom, tc.quoteDenom)
+			require.Error(t, err)
+		})
+	}
+}
+
+// TestParsePricingCacheKey_Invalid tests that malformed pricing cache keys are rejected.
+func TestParsePricingCacheKey_Invalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		key  string
+	}{
+		{"missing separator", "uosmouion"},
+		{"multiple separators", "uosmo|uion|uatom"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := domain.ParsePricingCacheKey(tc.key)
+			require.Error(t, err)
+		})
+	}
+}
+
+// TestPricingCacheKeyWithNamespace tests that the same pair formats into distinct keys across the namespaces
+// and that the keys are parsed back only within their own namespace.
+func TestPricingCacheKeyWithNamespace(t *testing.T) {
+	const (
+		baseDenom       = "uosmo"
+		quoteDenom      = "uion"
+		otherNamespace  = "coingecko"
+		prefixNamespace = "cha"
+	)
+
+	chainKey, err := domain.FormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, baseDenom, quoteDenom)
+	require.NoError(t, err)
+
+	otherKey, err := domain.FormatPricingCacheKeyWithNamespace(otherNamespace, baseDenom, quoteDenom)
+	require.NoError(t, err)
+
+	require.NotEqual(t, chainKey, otherKey)
+	require.NotEqual(t, domain.MustFormatPricingCacheKey(baseDenom, quoteDenom), chainKey)
+
+	parsedBaseDenom, parsedQuoteDenom, err := domain.ParsePricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, chainKey)
+	require.NoError(t, err)
+	require.Equal(t, baseDenom, parsedBaseDenom)
+	require.Equal(t, quoteDenom, parsedQuoteDenom)
+
+	// The keys of the other namespaces are rejected, including the namespaces sharing a prefix.
+	_, _, err = domain.ParsePricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, otherKey)
+	require.Error(t, err)
+
+	_, _, err = domain.ParsePricingCacheKeyWithNamespace(prefixNamespace, chainKey)
+	require.Error(t, err)
+
+	// The keys without a namespace are rejected.
+	_, _, err = domain.ParsePricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, domain.MustFormatPricingCacheKey(baseDenom, quoteDenom))
+	require.Error(t, err)
+
+	// The namespaces containing the separator are rejected.
+	_, err = domain.FormatPricingCacheKeyWithNamespace("chain|other", baseDenom, quoteDenom)
+	require.Error(t, err)
+}
@@ -7,12 +7,15 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/osmosis-labs/sqs/domain"
 	"github.com/osmosis-labs/sqs/domain/mocks"
+	"github.com/osmosis-labs/sqs/router/usecase"
 	"github.com/osmosis-labs/sqs/router/usecase/pools"
 	"github.com/osmosis-labs/sqs/router/usecase/route"
 	"github.com/osmosis-labs/sqs/router/usecase/routertesting"
 	"github.com/osmosis-labs/sqs/sqsdomain"
 
+	"github.com/osmosis-labs/osmosis/osmomath"
 	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
 )
 
@@ -138,6 +141,47 @@ func (s *RouterTestSuite) TestPrepareResultPools() {
 	}
 }
 
+// Validates that split routes are formatted as a denom path prefixed by the split percentage.
+func (s *RouterTestSuite) TestFormatSplitRoute() {
+	splitRoute := &usecase.RouteWithOutAmount{
+		RouteImpl: WithRoutePools(emptyRoute, []sqsdomain.RoutablePool{
+			mocks.WithTokenOutDenom(mocks.WithPoolID(DefaultPool, 123), DenomTwo),
+			mocks.WithTokenOutDenom(mocks.WithPoolID(DefaultPool, 456), DenomThree),
+		}),
+		InAmount: osmomath.NewInt(50),
+	}
+
+	testcases := map[string]struct {
+		totalAmountIn osmomath.Int
+
+		expected string
+	}{
+		"whole percentage": {
+			totalAmountIn: osmomath.NewInt(100),
+
+			expected: "50% [" + DenomOne + " -> pool123 -> " + DenomTwo + " -> pool456 -> " + DenomThree + "]",
+		},
+		"fractional percentage": {
+			totalAmountIn: osmomath.NewInt(300),
+
+			expected: "16.66% [" + DenomOne + " -> pool123 -> " + DenomTwo + " -> pool456 -> " + DenomThree + "]",
+		},
+		"zero total omits percentage": {
+			totalAmountIn: osmomath.ZeroInt(),
+
+			expected: "[" + DenomOne + " -> pool123 -> " + DenomTwo + " -> pool456 -> " + DenomThree + "]",
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		s.Run(name, func() {
+			actual := domain.FormatSplitRoute(splitRoute, DenomOne, tc.totalAmountIn)
+			s.Require().Equal(tc.expected, actual)
+		})
+	}
+}
+
 func WithRoutePools(r route.RouteImpl, pools []sqsdomain.RoutablePool) route.RouteImpl {
 	return routertesting.WithRoutePools(r, pools)
 }
@@ -2,11 +2,15 @@ package domain
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/osmosis-labs/sqs/sqsdomain"
 
 	"github.com/osmosis-labs/osmosis/osmomath"
+	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
 )
 
 type RoutableResultPool interface {
@@ -21,6 +25,9 @@ type Route interface {
 	// The reason for this is that making network requests to chain is expensive.
 	// As a result, we want to minimize the number of requests we make.
 	ContainsGeneralizedCosmWasmPool() bool
+	// ContainsPoolType returns true if the route contains a pool of the given type.
+	// Note that both the transmuter and the generalized cosmwasm pools are of the cosmwasm type.
+	ContainsPoolType(poolType poolmanagertypes.PoolType) bool
 	GetPools() []sqsdomain.RoutablePool
 	// CalculateTokenOutByTokenIn calculates the token out amount given the token in amount.
 	// Returns error if the calculation fails.
@@ -36,17 +43,63 @@ type Route interface {
 	// Computes the spot price of the route.
 	// Returns the spot price before swap and effective spot price.
 	// The token in is the base token and the token out is the quote token.
-	PrepareResultPools(ctx context.Context, tokenIn sdk.Coin) ([]sqsdomain.RoutablePool, osmomath.Dec, osmomath.Dec, error)
+	// With WithDryRun, the final quote logic run is skipped and the spot price before swap
+	// is returned in place of the effective spot price.
+	PrepareResultPools(ctx context.Context, tokenIn sdk.Coin, opts ...PrepareResultOption) ([]sqsdomain.RoutablePool, osmomath.Dec, osmomath.Dec, error)
 
 	String() string
 }
 
+// SpotPriceRequest defines a request for the spot price of a pool
+// with the given quote and base denoms.
+type SpotPriceRequest struct {
+	PoolID     uint64
+	QuoteDenom string
+	BaseDenom  string
+}
+
+// CoinOSMOValueFunc returns the value of the given coin denominated in OSMO.
+// For example, it might descale the coin amount by the chain scaling factor of its denom
+// and multiply it by the OSMO price of the denom.
+type CoinOSMOValueFunc func(coin sdk.Coin) (osmomath.Int, error)
+
+// PoolVolumeFunc returns the recent trade volume of the pool with the given ID
+// and a flag indicating whether the volume is known.
+type PoolVolumeFunc func(poolID uint64) (osmomath.Int, bool)
+
 type SplitRoute interface {
 	Route
 	GetAmountIn() osmomath.Int
 	GetAmountOut() osmomath.Int
 }
 
+// FormatSplitRoute returns a concise human-readable representation of the split route
+// in the form of "50% [USDC -> pool123 -> ATOM -> pool456 -> OSMO]".
+// The percentage is the amount in of the split route relative to totalAmountIn.
+// If totalAmountIn is not positive, the percentage is omitted.
+func FormatSplitRoute(splitRoute SplitRoute, tokenInDenom string, totalAmountIn osmomath.Int) string {
+	var sb strings.Builder
+
+	if !totalAmountIn.IsNil() && totalAmountIn.IsPositive() {
+		// Percentage in basis points to render up to two decimals.
+		percentageBps := splitRoute.GetAmountIn().MulRaw(10_000).Quo(totalAmountIn).Int64()
+		if percentageBps%100 == 0 {
+			sb.WriteString(fmt.Sprintf("%d%% ", percentageBps/100))
+		} else {
+			sb.WriteString(fmt.Sprintf("%d.%02d%% ", percentageBps/100, percentageBps%100))
+		}
+	}
+
+	sb.WriteString("[")
+	sb.WriteString(tokenInDenom)
+	for _, pool := range splitRoute.GetPools() {
+		sb.WriteString(fmt.Sprintf(" -> pool%d -> %s", pool.GetId(), pool.GetTokenOutDenom()))
+	}
+	sb.WriteString("]")
+
+	return sb.String()
+}
+
 type Quote interface {
 	GetAmountIn() sdk.Coin
 	GetAmountOut() osmomath.Int
@@ -54,12 +107,26 @@ type Quote interface {
 	GetEffectiveSpreadFactor() osmomath.Dec
 	GetPriceImpact() osmomath.Dec
 
+	// IsRouteSearchTruncated returns true if the candidate route search was cut short
+	// by the routing timeout. In that case, the quote is the best one over the routes found
+	// before the timeout fired and might not be optimal.
+	IsRouteSearchTruncated() bool
+
+	// GetEstimatedGas returns the estimated gas cost of executing the quote routes.
+	// It is the sum of the gas costs of the pools across the routes by their pool types.
+	// Populated by PrepareResult.
+	GetEstimatedGas() uint64
+
+	// IsEffectivePriceSkipped returns true if the quote was prepared with WithDryRun.
+	// In that case, the effective spot price was not computed and the price impact is zero.
+	IsEffectivePriceSkipped() bool
+
 	// PrepareResult mutates the quote to prepare
 	// it with the data formatted for output to the client.
 	// scalingFactor is the spot price scaling factor according to chain precision.
 	// scalingFactor of zero is a valid value. It might occur if we do not have precision information
 	// for the tokens. In that case, we invalidate spot price by setting it to zero.
-	PrepareResult(ctx context.Context, scalingFactor osmomath.Dec) ([]SplitRoute, osmomath.Dec, error)
+	PrepareResult(ctx context.Context, scalingFactor osmomath.Dec, opts ...PrepareResultOption) ([]SplitRoute, osmomath.Dec, error)
 
 	String() string
 }
@@ -79,6 +146,115 @@ type RouterConfig struct {
 	RankedRouteCacheExpirySeconds    int `mapstructure:"ranked-route-cache-expiry-seconds"`
 	// Flag indicating whether we should have a cache for overwrite routes enabled.
 	EnableOverwriteRoutesCache bool `mapstructure:"enable-overwrite-routes-cache"`
+	// PoolTypeGasCosts maps the pool type names (e.g. "balancer" or "concentrated", case-insensitive)
+	// to the estimated gas cost of swapping through a pool of that type.
+	// It is used to estimate the gas cost of executing the quote routes (see GetPoolTypeGasCosts).
+	// The pool types missing from it default to DefaultPoolTypeGasCosts.
+	PoolTypeGasCosts map[string]uint64 `mapstructure:"pool-type-gas-costs"`
+	// OSMODenom is the chain denom of OSMO that the token in is valued in for the min liquidity multiple.
+	// Defaults to DefaultOSMODenom if empty.
+	OSMODenom string `mapstructure:"osmo-denom"`
+}
+
+// DefaultOSMODenom is the chain denom of OSMO used if the router config does not configure one.
+const DefaultOSMODenom = "uosmo"
+
+// GetOSMODenom returns the configured chain denom of OSMO or DefaultOSMODenom if none is configured.
+func (c RouterConfig) GetOSMODenom() string {
+	if c.OSMODenom == "" {
+		return DefaultOSMODenom
+	}
+	return c.OSMODenom
+}
+
+// GetPoolTypeGasCosts returns the configured gas costs keyed by their pool types.
+// The pool type names that are unknown are skipped (see Validate).
+func (c RouterConfig) GetPoolTypeGasCosts() map[poolmanagertypes.PoolType]uint64 {
+	poolTypeGasCosts := make(map[poolmanagertypes.PoolType]uint64, len(c.PoolTypeGasCosts))
+	for poolTypeName, gasCost := range c.PoolTypeGasCosts {
+		if poolType, ok := parsePoolTypeName(poolTypeName); ok {
+			poolTypeGasCosts[poolType] = gasCost
+		}
+	}
+	return poolTypeGasCosts
+}
+
+// parsePoolTypeName returns the pool type of the given case-insensitive pool type name.
+// Returns false if the name is unknown.
+func parsePoolTypeName(poolTypeName string) (poolmanagertypes.PoolType, bool) {
+	for name, value := range poolmanagertypes.PoolType_value {
+		if strings.EqualFold(name, poolTypeName) {
+			return poolmanagertypes.PoolType(value), true
+		}
+	}
+	return 0, false
+}
+
+// defaultPoolTypeGasCosts are the default estimated gas costs of swapping through a pool of each type.
+// CosmWasm pools are the most expensive since the swap executes the contract.
+var defaultPoolTypeGasCosts = map[poolmanagertypes.PoolType]uint64{
+	poolmanagertypes.Balancer:     60_000,
+	poolmanagertypes.Stableswap:   80_000,
+	poolmanagertypes.Concentrated: 150_000,
+	poolmanagertypes.CosmWasm:     250_000,
+}
+
+// DefaultPoolTypeGasCosts returns a copy of the default estimated gas costs of swapping through a pool of each type
+// so that the callers cannot change the defaults.
+func DefaultPoolTypeGasCosts() map[poolmanagertypes.PoolType]uint64 {
+	poolTypeGasCosts := make(map[poolmanagertypes.PoolType]uint64, len(defaultPoolTypeGasCosts))
+	for poolType, gasCost := range defaultPoolTypeGasCosts {
+		poolTypeGasCosts[poolType] = gasCost
+	}
+	return poolTypeGasCosts
+}
+
+// Validate validates the router config invariants.
+// Returns a descriptive error on the first violated invariant.
+func (c RouterConfig) Validate() error {
+	if c.MaxPoolsPerRoute < 1 {
+		return fmt.Errorf("max-pools-per-route (%d) must be at least 1", c.MaxPoolsPerRoute)
+	}
+
+	if c.MaxRoutes < 1 {
+		return fmt.Errorf("max-routes (%d) must be at least 1", c.MaxRoutes)
+	}
+
+	if c.MaxSplitRoutes < 0 {
+		return fmt.Errorf("max-split-routes (%d) must be non-negative", c.MaxSplitRoutes)
+	}
+
+	if c.MaxSplitIterations < 0 {
+		return fmt.Errorf("max-split-iterations (%d) must be non-negative", c.MaxSplitIterations)
+	}
+
+	if c.MaxSplitRoutes != DisableSplitRoutes && c.MaxSplitIterations < c.MaxSplitRoutes {
+		return fmt.Errorf("max-split-iterations (%d) must be at least max-split-routes (%d) when split routes are enabled", c.MaxSplitIterations, c.MaxSplitRoutes)
+	}
+
+	if c.MinOSMOLiquidity < 0 {
+		return fmt.Errorf("min-osmo-liquidity (%d) must be non-negative", c.MinOSMOLiquidity)
+	}
+
+	if c.RouteUpdateHeightInterval < 0 {
+		return fmt.Errorf("route-update-height-interval (%d) must be non-negative", c.RouteUpdateHeightInterval)
+	}
+
+	if c.CandidateRouteCacheExpirySeconds < 0 {
+		return fmt.Errorf("candidate-route-cache-expiry-seconds (%d) must be non-negative", c.CandidateRouteCacheExpirySeconds)
+	}
+
+	if c.RankedRouteCacheExpirySeconds < 0 {
+		return fmt.Errorf("ranked-route-cache-expiry-seconds (%d) must be non-negative", c.RankedRouteCacheExpirySeconds)
+	}
+
+	for poolTypeName := range c.PoolTypeGasCosts {
+		if _, ok := parsePoolTypeName(poolTypeName); !ok {
+			return fmt.Errorf("pool-type-gas-costs has unknown pool type (%s)", poolTypeName)
+		}
+	}
+
+	return nil
 }
 
 type PoolsConfig struct {
@@ -88,6 +264,9 @@ type PoolsConfig struct {
 
 const DisableSplitRoutes = 0
 
+// BasisPointsPerUnit is the number of basis points in a unit.
+const BasisPointsPerUnit = 10_000
+
 type RouterState struct {
 	Pools     []sqsdomain.PoolI
 	TakerFees sqsdomain.TakerFeeMap
@@ -109,6 +288,56 @@ type RouterOptions struct {
 	// The number of milliseconds to cache candidate routes for before expiry.
 	CandidateRouteCacheExpirySeconds int
 	RankedRouteCacheExpirySeconds    int
+	// RoutingTimeout bounds the duration of the candidate route search.
+	// Zero implies no bound.
+	RoutingTimeout time.Duration
+	// AllowedPoolTypes restricts the candidate pools to the given pool types.
+	// Empty implies that all pool types are allowed.
+	AllowedPoolTypes []poolmanagertypes.PoolType
+	// MaxQuotePriceImpact is the max price impact of the optimal quote.
+	// If exceeded, the quote is rejected with PriceImpactTooHighError.
+	// Nil implies no bound.
+	MaxQuotePriceImpact osmomath.Dec
+	// PoolVolume biases the single route selection toward the routes through higher recent volume pools
+	// among the routes whose amount out is within VolumeBiasTolerance of the best amount out.
+	// Nil implies no bias.
+	PoolVolume PoolVolumeFunc
+	// VolumeBiasTolerance is the max relative shortfall in amount out from the best route
+	// for a route to be selected by its volume.
+	VolumeBiasTolerance osmomath.Dec
+	// TransferFees are the fees taken by the fee-on-transfer denoms whenever they enter or exit a pool.
+	// Nil implies no transfer fees.
+	TransferFees TransferFees
+	// RequiredIntermediateDenom restricts the candidate routes to the ones passing through the given denom.
+	// Empty implies no restriction.
+	RequiredIntermediateDenom string
+	// MinLiquidityMultiple filters out the candidate pools whose OSMO liquidity is less than
+	// the multiple of the OSMO value of the token in. Applies on top of MinOSMOLiquidity.
+	// Nil implies no filtering.
+	MinLiquidityMultiple osmomath.Dec
+	// ExcludedPoolIDs are the IDs of the pools that the candidate routes must not pass through.
+	// Empty implies no exclusion.
+	ExcludedPoolIDs []uint64
+}
+
+// TransferFees maps the fee-on-transfer denoms to the fraction of the amount taken on each transfer.
+type TransferFees map[string]osmomath.Dec
+
+// ChargeTransferFee returns the given coin with the transfer fee of its denom deducted.
+// The fee is rounded up so that the realizable amount is never overstated.
+// If the denom takes no transfer fee, returns the coin as is.
+func (t TransferFees) ChargeTransferFee(coin sdk.Coin) sdk.Coin {
+	fee, ok := t[coin.Denom]
+	if !ok || fee.IsZero() {
+		return coin
+	}
+
+	feeAmount := fee.MulInt(coin.Amount).Ceil().TruncateInt()
+	if feeAmount.GTE(coin.Amount) {
+		return sdk.NewCoin(coin.Denom, osmomath.ZeroInt())
+	}
+
+	return sdk.NewCoin(coin.Denom, coin.Amount.Sub(feeAmount))
 }
 
 // DefaultRouterOptions defines the default options for the router
@@ -149,3 +378,133 @@ func WithMaxSplitRoutes(maxSplitRoutes int) RouterOption {
 		o.MaxSplitRoutes = maxSplitRoutes
 	}
 }
+
+// WithRoutingTimeout configures the router options with the timeout for the candidate route search.
+// The timeout applies only to route enumeration and not to the quote computation over the found routes.
+// If the timeout fires, the best quote over the routes found so far is returned
+// with the route search truncated flag set.
+func WithRoutingTimeout(routingTimeout time.Duration) RouterOption {
+	return func(o *RouterOptions) {
+		o.RoutingTimeout = routingTimeout
+	}
+}
+
+// WithAllowedPoolTypes configures the router options with the pool types
+// that candidate pools are filtered by before constructing routes.
+// If no pool types are given, all pool types are allowed.
+func WithAllowedPoolTypes(allowedPoolTypes ...poolmanagertypes.PoolType) RouterOption {
+	return func(o *RouterOptions) {
+		o.AllowedPoolTypes = allowedPoolTypes
+	}
+}
+
+// WithMaxQuotePriceImpact configures the router options with the max price impact of the optimal quote.
+// If the price impact of the optimal quote exceeds it, PriceImpactTooHighError is returned.
+// Note that validating the price impact prepares the result of every quote, including the network calls
+// to the generalized CosmWasm pools, so it adds the cost of a full PrepareResult to each quote.
+func WithMaxQuotePriceImpact(maxPriceImpact osmomath.Dec) RouterOption {
+	return func(o *RouterOptions) {
+		o.MaxQuotePriceImpact = maxPriceImpact
+	}
+}
+
+// WithTransferFee configures the router options with the transfer fee in basis points
+// taken by the given denom whenever it enters or exits a pool in the route.
+// As a result, the quote amount out reflects the amount realizable after the transfer fees.
+// May be given multiple times to configure the fees of several denoms.
+// The fee is clamped to the range of [0, 10_000] basis points.
+func WithTransferFee(denom string, feeBps int) RouterOption {
+	return func(o *RouterOptions) {
+		if feeBps < 0 {
+			feeBps = 0
+		} else if feeBps > BasisPointsPerUnit {
+			feeBps = BasisPointsPerUnit
+		}
+
+		transferFees := make(TransferFees, len(o.TransferFees)+1)
+		for existingDenom, existingFee := range o.TransferFees {
+			transferFees[existingDenom] = existingFee
+		}
+		transferFees[denom] = osmomath.NewDec(int64(feeBps)).QuoInt64(BasisPointsPerUnit)
+
+		o.TransferFees = transferFees
+	}
+}
+
+// WithRequiredIntermediateDenom configures the router options to only consider the candidate routes
+// that pass through the given denom at some hop other than the last one.
+// The constraint is applied to the enumerated candidate routes so the routes found within
+// the max routes limit are filtered. If none of them satisfies it, NoRouteThroughIntermediateDenomError is returned.
+// The routes constrained by the intermediate denom are never cached.
+func WithRequiredIntermediateDenom(denom string) RouterOption {
+	return func(o *RouterOptions) {
+		o.RequiredIntermediateDenom = denom
+	}
+}
+
+// WithMinLiquidityMultiple configures the router options to filter out the candidate pools whose OSMO liquidity
+// is less than the given multiple of the OSMO value of the token in. For example, a multiple of 50 requires
+// the pool liquidity to be at least 50x the token in. The token in is valued by quoting it in the OSMO denom of the router config.
+// Both this and the absolute min OSMO liquidity apply so that a pool must pass both.
+// The routes filtered by the multiple are never cached. Non-positive multiples disable the filtering.
+func WithMinLiquidityMultiple(multiple osmomath.Dec) RouterOption {
+	return func(o *RouterOptions) {
+		if multiple.IsNil() || !multiple.IsPositive() {
+			o.MinLiquidityMultiple = osmomath.Dec{}
+			return
+		}
+		o.MinLiquidityMultiple = multiple
+	}
+}
+
+// WithExcludedPoolIDs configures the router options to exclude the pools with the given IDs
+// from the candidate routes. The routes computed with the excluded pools are never cached.
+func WithExcludedPoolIDs(poolIDs ...uint64) RouterOption {
+	return func(o *RouterOptions) {
+		o.ExcludedPoolIDs = poolIDs
+	}
+}
+
+// WithVolumeBiasedRouteSelection configures the router options to bias the single route selection
+// toward the routes through higher recent volume pools.
+// The amount out remains the primary objective: only the routes whose amount out is within
+// the given tolerance (e.g. 0.001 for 0.1%) of the best amount out are reordered by their volume.
+// The volume of a route is the lowest volume of its pools. Pools with unknown volume have zero volume.
+// Split routes are still optimized for the amount out.
+func WithVolumeBiasedRouteSelection(poolVolume PoolVolumeFunc, tolerance osmomath.Dec) RouterOption {
+	return func(o *RouterOptions) {
+		o.PoolVolume = poolVolume
+		o.VolumeBiasTolerance = tolerance
+	}
+}
+
+// PrepareResultOptions defines the options for preparing the quote and route results.
+type PrepareResultOptions struct {
+	// DryRun skips the final quote logic run that computes the effective spot price.
+	DryRun bool
+	// PoolTypeGasCosts are the gas costs per pool type used to estimate the gas cost of the quote.
+	// The pool types missing from it default to DefaultPoolTypeGasCosts.
+	PoolTypeGasCosts map[poolmanagertypes.PoolType]uint64
+}
+
+// PrepareResultOption configures the result preparation options.
+type PrepareResultOption func(*PrepareResultOptions)
+
+// WithDryRun configures the result preparation to skip re-running the quote logic
+// to compute the effective spot price. For generalized CosmWasm pools, this avoids
+// the network call made by each pool in the route. The spot price before swap is returned
+// in place of the effective spot price. Useful for latency-sensitive clients
+// that do not need the price impact.
+func WithDryRun() PrepareResultOption {
+	return func(o *PrepareResultOptions) {
+		o.DryRun = true
+	}
+}
+
+// WithPoolTypeGasCosts configures the result preparation to estimate the gas cost of the quote
+// with the given gas costs per pool type (see RouterConfig.PoolTypeGasCosts).
+func WithPoolTypeGasCosts(poolTypeGasCosts map[poolmanagertypes.PoolType]uint64) PrepareResultOption {
+	return func(o *PrepareResultOptions) {
+		o.PoolTypeGasCosts = poolTypeGasCosts
+	}
+}
@@ -0,0 +1,157 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
+	"github.com/osmosis-labs/sqs/domain"
+)
+
+// TestRouterConfig_Validate tests that each router config invariant is validated.
+func TestRouterConfig_Validate(t *testing.T) {
+	validConfig := domain.RouterConfig{
+		MaxPoolsPerRoute:   4,
+		MaxRoutes:          5,
+		MaxSplitRoutes:     3,
+		MaxSplitIterations: 10,
+		MinOSMOLiquidity:   100,
+	}
+
+	testCases := []struct {
+		name          string
+		modify        func(c *domain.RouterConfig)
+		expectedError bool
+	}{
+		{
+			name:   "valid config",
+			modify: func(c *domain.RouterConfig) {},
+		},
+		{
+			name: "valid config with split routes disabled and no split iterations",
+			modify: func(c *domain.RouterConfig) {
+				c.MaxSplitRoutes = domain.DisableSplitRoutes
+				c.MaxSplitIterations = 0
+			},
+		},
+		{
+			name: "valid config with split iterations equal to split routes",
+			modify: func(c *domain.RouterConfig) {
+				c.MaxSplitIterations = c.MaxSplitRoutes
+			},
+		},
+		{
+			name: "zero max pools per route",
+			modify: func(c *domain.RouterConfig) {
+				c.MaxPoolsPerRoute = 0
+			},
+			expectedError: true,
+		},
+		{
+			name: "zero max routes",
+			modify: func(c *domain.RouterConfig) {
+				c.MaxRoutes = 0
+			},
+			expectedError: true,
+		},
+		{
+			name: "negative max split routes",
+			modify: func(c *domain.RouterConfig) {
+				c.MaxSplitRoutes = -1
+			},
+			expectedError: true,
+		},
+		{
+			name: "negative max split iterations",
+			modify: func(c *domain.RouterConfig) {
+				c.MaxSplitRoutes = domain.DisableSplitRoutes
+				c.MaxSplitIterations = -1
+			},
+			expectedError: true,
+		},
+		{
+			name: "max split iterations below max split routes with split routes enabled",
+			modify: func(c *domain.RouterConfig) {
+				c.MaxSplitIterations = 0
+			},
+			expectedError: true,
+		},
+		{
+			name: "negative min OSMO liquidity",
+			modify: func(c *domain.RouterConfig) {
+				c.MinOSMOLiquidity = -1
+			},
+			expectedError: true,
+		},
+		{
+			name: "negative route update height interval",
+			modify: func(c *domain.RouterConfig) {
+				c.RouteUpdateHeightInterval = -1
+			},
+			expectedError: true,
+		},
+		{
+			name: "negative candidate route cache expiry",
+			modify: func(c *domain.RouterConfig) {
+				c.CandidateRouteCacheExpirySeconds = -1
+			},
+			expectedError: true,
+		},
+		{
+			name: "negative ranked route cache expiry",
+			modify: func(c *domain.RouterConfig) {
+				c.RankedRouteCacheExpirySeconds = -1
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid pool type gas costs",
+			modify: func(c *domain.RouterConfig) {
+				c.PoolTypeGasCosts = map[string]uint64{"balancer": 1_000, "CosmWasm": 2_000}
+			},
+		},
+		{
+			name: "unknown pool type gas cost",
+			modify: func(c *domain.RouterConfig) {
+				c.PoolTypeGasCosts = map[string]uint64{"unknown": 1_000}
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			config := validConfig
+			tc.modify(&config)
+
+			err := config.Validate()
+
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+// TestRouterConfig_GetPoolTypeGasCosts tests that the pool type gas costs configured by
+// the case-insensitive pool type names are keyed by their pool types.
+func TestRouterConfig_GetPoolTypeGasCosts(t *testing.T) {
+	config := domain.RouterConfig{
+		PoolTypeGasCosts: map[string]uint64{"balancer": 1_000, "CosmWasm": 2_000},
+	}
+
+	require.Equal(t, map[poolmanagertypes.PoolType]uint64{
+		poolmanagertypes.Balancer: 1_000,
+		poolmanagertypes.CosmWasm: 2_000,
+	}, config.GetPoolTypeGasCosts())
+
+	// The defaults cannot be changed via the returned copy.
+	defaultPoolTypeGasCosts := domain.DefaultPoolTypeGasCosts()
+	defaultPoolTypeGasCosts[poolmanagertypes.Balancer] = 0
+	require.NotZero(t, domain.DefaultPoolTypeGasCosts()[poolmanagertypes.Balancer])
+}
@@ -0,0 +1,546 @@
+// Package testutil provides the harnesses for the deterministic tests of the router consumers such as pricing.
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
+	"github.com/osmosis-labs/sqs/domain"
+	"github.com/osmosis-labs/sqs/domain/mocks"
+	"github.com/osmosis-labs/sqs/domain/mvc"
+	"github.com/osmosis-labs/sqs/router/usecase"
+	"github.com/osmosis-labs/sqs/router/usecase/route"
+	"github.com/osmosis-labs/sqs/sqsdomain"
+)
+
+// Recording is the record of the router interactions that is written to and read from a file as JSON.
+type Recording struct {
+	Quotes       []RecordedQuote        `json:"quotes"`
+	RankedQuotes []RecordedRankedQuotes `json:"ranked_quotes"`
+	SpotPrices   []RecordedSpotPrice    `json:"spot_prices"`
+}
+
+// RecordedQuote is the recorded call to GetOptimalQuote and its response.
+// Options is the canonical form of the router options of the call (see formatRouterOptions).
+// Err is the error if the call failed.
+type RecordedQuote struct {
+	TokenIn       sdk.Coin        `json:"token_in"`
+	TokenOutDenom string          `json:"token_out_denom"`
+	Options       string          `json:"options"`
+	AmountOut     osmomath.Int    `json:"amount_out"`
+	Routes        []RecordedRoute `json:"routes,omitempty"`
+	Err           *RecordedError  `json:"error,omitempty"`
+}
+
+// RecordedRankedQuotes is the recorded call to GetRankedQuotes and its response.
+// The ranked quotes only carry their token in, amount out and routes.
+// Err is the error if the call failed.
+type RecordedRankedQuotes struct {
+	TokenIn       sdk.Coin        `json:"token_in"`
+	TokenOutDenom string          `json:"token_out_denom"`
+	K             int             `json:"k"`
+	Options       string          `json:"options"`
+	Quotes        []RecordedQuote `json:"quotes,omitempty"`
+	Err           *RecordedError  `json:"error,omitempty"`
+}
+
+// RecordedError is the recorded error of a call.
+// Code identifies the recordable error (see recordableErrors) that the error matches so that
+// the replayed error matches it via errors.Is and errors.As the same way as the recorded one.
+// Details is the JSON of the matched typed error. Empty code implies that the error is not recordable
+// and that only its message is replayed.
+type RecordedError struct {
+	Message string          `json:"message"`
+	Code    string          `json:"code,omitempty"`
+	Details json.RawMessage `json:"details,omitempty"`
+}
+
+// recordableError is the error that is replayed with its identity.
+// Either the sentinel or the type of the typed error is set.
+type recordableError struct {
+	code      string
+	sentinel  error
+	errorType reflect.Type
+}
+
+// recordableErrors are the errors that the router consumers branch on.
+// The errors are matched in order so the more specific ones come first.
+var recordableErrors = []recordableError{
+	{code: "context_canceled", sentinel: context.Canceled},
+	{code: "context_deadline_exceeded", sentinel: context.DeadlineExceeded},
+	{code: "unpriceable", sentinel: domain.ErrUnpriceable},
+	{code: "empty_route_pools", sentinel: domain.ErrEmptyRoutePools},
+	{code: "nil_pool_in_route", sentinel: domain.ErrNilPoolInRoute},
+	{code: "price_impact_too_high", errorType: reflect.TypeOf(domain.PriceImpactTooHighError{})},
+	{code: "invalid_pool_type", errorType: reflect.TypeOf(domain.InvalidPoolTypeError{})},
+	{code: "unsupported_cosmwasm_pool_type", errorType: reflect.TypeOf(domain.UnsupportedCosmWasmPoolTypeError{})},
+	{code: "pool_not_found", errorType: reflect.TypeOf(domain.PoolNotFoundError{})},
+	{code: "concentrated_pool_no_tick_model", errorType: reflect.TypeOf(domain.ConcentratedPoolNoTickModelError{})},
+	{code: "concentrated_tick_model_not_set", errorType: reflect.TypeOf(domain.ConcentratedTickModelNotSetError{})},
+	{code: "taker_fee_not_found_for_denom_pair", errorType: reflect.TypeOf(domain.TakerFeeNotFoundForDenomPairError{})},
+	{code: "failed_to_cast_pool_model", errorType: reflect.TypeOf(domain.FailedToCastPoolModelError{})},
+	{code: "concentrated_no_liquidity", errorType: reflect.TypeOf(domain.ConcentratedNoLiquidityError{})},
+	{code: "concentrated_zero_current_sqrt_price", errorType: reflect.TypeOf(domain.ConcentratedZeroCurrentSqrtPriceError{})},
+	{code: "concentrated_current_tick_not_within_bucket", errorType: reflect.TypeOf(domain.ConcentratedCurrentTickNotWithinBucketError{})},
+	{code: "concentrated_current_tick_and_bucket_mismatch", errorType: reflect.TypeOf(domain.ConcentratedCurrentTickAndBucketMismatchError{})},
+}
+
+// replayedError is the error replayed from a recording with the recorded message
+// that wraps the recordable error it matched when recorded.
+type replayedError struct {
+	message string
+	err     error
+}
+
+func (e replayedError) Error() string {
+	return e.message
+}
+
+func (e replayedError) Unwrap() error {
+	return e.err
+}
+
+// RecordedRoute is the route of a recorded quote.
+type RecordedRoute struct {
+	AmountIn  osmomath.Int   `json:"amount_in"`
+	AmountOut osmomath.Int   `json:"amount_out"`
+	Pools     []RecordedPool `json:"pools"`
+}
+
+// RecordedPool is the pool of a recorded route with the data used by the route consumers.
+type RecordedPool struct {
+	ID            uint64                    `json:"id"`
+	Type          poolmanagertypes.PoolType `json:"type"`
+	Denoms        []string                  `json:"denoms"`
+	TokenOutDenom string                    `json:"token_out_denom"`
+	SpreadFactor  osmomath.Dec              `json:"spread_factor"`
+	TakerFee      osmomath.Dec              `json:"taker_fee"`
+}
+
+// RecordedSpotPrice is the recorded pool spot price computation and its result.
+// The spot price is a decimal string since it is a BigDec. Err is the error if the computation failed.
+type RecordedSpotPrice struct {
+	PoolID     uint64         `json:"pool_id"`
+	QuoteDenom string         `json:"quote_denom"`
+	BaseDenom  string         `json:"base_denom"`
+	SpotPrice  string         `json:"spot_price,omitempty"`
+	Err        *RecordedError `json:"error,omitempty"`
+}
+
+// RecordingRouterUsecase wraps a router usecase to record the optimal quotes, the ranked quotes
+// and the pool spot prices it computes.
+// The pool spot prices computed in batch via GetPoolSpotPrices are recorded per request.
+// The other methods are delegated to the wrapped router usecase without being recorded.
+type RecordingRouterUsecase struct {
+	mvc.RouterUsecase
+
+	mu        sync.Mutex
+	recording Recording
+}
+
+var _ mvc.RouterUsecase = &RecordingRouterUsecase{}
+
+// NewRecordingRouterUsecase returns a new recording router usecase wrapping the given router usecase.
+func NewRecordingRouterUsecase(routerUsecase mvc.RouterUsecase) *RecordingRouterUsecase {
+	return &RecordingRouterUsecase{
+		RouterUsecase: routerUsecase,
+	}
+}
+
+// GetOptimalQuote implements mvc.RouterUsecase.
+func (r *RecordingRouterUsecase) GetOptimalQuote(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+	quote, err := r.RouterUsecase.GetOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+
+	recordedQuote := RecordedQuote{
+		TokenIn:       tokenIn,
+		TokenOutDenom: tokenOutDenom,
+		Options:       formatRouterOptions(opts),
+		Err:           recordError(err),
+	}
+	if err == nil {
+		recordedQuote.AmountOut = quote.GetAmountOut()
+		recordedQuote.Routes = recordRoutes(quote.GetRoute())
+	}
+
+	r.mu.Lock()
+	r.recording.Quotes = append(r.recording.Quotes, recordedQuote)
+	r.mu.Unlock()
+
+	return quote, err
+}
+
+// GetRankedQuotes implements mvc.RouterUsecase.
+func (r *RecordingRouterUsecase) GetRankedQuotes(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, k int, opts ...domain.RouterOption) ([]domain.Quote, error) {
+	quotes, err := r.RouterUsecase.GetRankedQuotes(ctx, tokenIn, tokenOutDenom, k, opts...)
+
+	recordedRankedQuotes := RecordedRankedQuotes{
+		TokenIn:       tokenIn,
+		TokenOutDenom: tokenOutDenom,
+		K:             k,
+		Options:       formatRouterOptions(opts),
+		Err:           recordError(err),
+	}
+	if err == nil {
+		recordedRankedQuotes.Quotes = make([]RecordedQuote, 0, len(quotes))
+		for _, quote := range quotes {
+			recordedRankedQuotes.Quotes = append(recordedRankedQuotes.Quotes, RecordedQuote{
+				TokenIn:       quote.GetAmountIn(),
+				TokenOutDenom: tokenOutDenom,
+				AmountOut:     quote.GetAmountOut(),
+				Routes:        recordRoutes(quote.GetRoute()),
+			})
+		}
+	}
+
+	r.mu.Lock()
+	r.recording.RankedQuotes = append(r.recording.RankedQuotes, recordedRankedQuotes)
+	r.mu.Unlock()
+
+	return quotes, err
+}
+
+// GetPoolSpotPrice implements mvc.RouterUsecase.
+func (r *RecordingRouterUsecase) GetPoolSpotPrice(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+	spotPrice, err := r.RouterUsecase.GetPoolSpotPrice(ctx, poolID, quoteAsset, baseAsset)
+
+	r.recordSpotPrice(domain.SpotPriceRequest{PoolID: poolID, QuoteDenom: quoteAsset, BaseDenom: baseAsset}, spotPrice, err)
+
+	return spotPrice, err
+}
+
+// GetPoolSpotPrices implements mvc.RouterUsecase.
+func (r *RecordingRouterUsecase) GetPoolSpotPrices(ctx context.Context, requests []domain.SpotPriceRequest) ([]osmomath.BigDec, []error) {
+	spotPrices, errs := r.RouterUsecase.GetPoolSpotPrices(ctx, requests)
+
+	for i, request := range requests {
+		r.recordSpotPrice(request, spotPrices[i], errs[i])
+	}
+
+	return spotPrices, errs
+}
+
+// Recording returns a copy of the interactions recorded so far.
+func (r *RecordingRouterUsecase) Recording() Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Recording{
+		Quotes:       append([]RecordedQuote(nil), r.recording.Quotes...),
+		RankedQuotes: append([]RecordedRankedQuotes(nil), r.recording.RankedQuotes...),
+		SpotPrices:   append([]RecordedSpotPrice(nil), r.recording.SpotPrices...),
+	}
+}
+
+// WriteFile writes the interactions recorded so far to the given file as JSON.
+func (r *RecordingRouterUsecase) WriteFile(path string) error {
+	recordingBz, err := json.MarshalIndent(r.Recording(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, recordingBz, 0o600)
+}
+
+// recordSpotPrice records the given pool spot price computation and its result.
+func (r *RecordingRouterUsecase) recordSpotPrice(request domain.SpotPriceRequest, spotPrice osmomath.BigDec, err error) {
+	recordedSpotPrice := RecordedSpotPrice{
+		PoolID:     request.PoolID,
+		QuoteDenom: request.QuoteDenom,
+		BaseDenom:  request.BaseDenom,
+		Err:        recordError(err),
+	}
+	if err == nil && !spotPrice.IsNil() {
+		recordedSpotPrice.SpotPrice = spotPrice.String()
+	}
+
+	r.mu.Lock()
+	r.recording.SpotPrices = append(r.recording.SpotPrices, recordedSpotPrice)
+	r.mu.Unlock()
+}
+
+// recordRoutes returns the records of the given routes.
+func recordRoutes(routes []domain.SplitRoute) []RecordedRoute {
+	recordedRoutes := make([]RecordedRoute, 0, len(routes))
+	for _, splitRoute := range routes {
+		pools := splitRoute.GetPools()
+
+		recordedPools := make([]RecordedPool, 0, len(pools))
+		for _, pool := range pools {
+			recordedPools = append(recordedPools, RecordedPool{
+				ID:            pool.GetId(),
+				Type:          pool.GetType(),
+				Denoms:        pool.GetPoolDenoms(),
+				TokenOutDenom: pool.GetTokenOutDenom(),
+				SpreadFactor:  pool.GetSpreadFactor(),
+				TakerFee:      pool.GetTakerFee(),
+			})
+		}
+
+		recordedRoutes = append(recordedRoutes, RecordedRoute{
+			AmountIn:  splitRoute.GetAmountIn(),
+			AmountOut: splitRoute.GetAmountOut(),
+			Pools:     recordedPools,
+		})
+	}
+
+	return recordedRoutes
+}
+
+// ReplayingRouterUsecase serves the optimal quotes, the ranked quotes and the pool spot prices of a recording.
+// The calls are matched by their arguments including the router options. The router options are matched
+// by their values except for the pool volume function that is only matched by whether it is set.
+// If the same call is recorded multiple times, the latest record is served.
+// The recorded errors are replayed with their message and, for the recordable errors, their identity
+// so that they match the same errors via errors.Is and errors.As. The other errors only match by message.
+// The calls missing from the recording fail with ErrNotRecorded.
+// The other methods are delegated to the embedded RouterUsecaseMock that panics unless they are mocked.
+type ReplayingRouterUsecase struct {
+	*mocks.RouterUsecaseMock
+
+	quotes       map[string]RecordedQuote
+	rankedQuotes map[string]RecordedRankedQuotes
+	spotPrices   map[string]RecordedSpotPrice
+}
+
+var _ mvc.RouterUsecase = &ReplayingRouterUsecase{}
+
+// ErrNotRecorded is returned by ReplayingRouterUsecase for the calls missing from the recording.
+var ErrNotRecorded = errors.New("call is not recorded")
+
+// NewReplayingRouterUsecase returns a new replaying router usecase serving the given recording.
+func NewReplayingRouterUsecase(recording Recording) *ReplayingRouterUsecase {
+	r := &ReplayingRouterUsecase{
+		RouterUsecaseMock: &mocks.RouterUsecaseMock{},
+
+		quotes:       make(map[string]RecordedQuote, len(recording.Quotes)),
+		rankedQuotes: make(map[string]RecordedRankedQuotes, len(recording.RankedQuotes)),
+		spotPrices:   make(map[string]RecordedSpotPrice, len(recording.SpotPrices)),
+	}
+
+	for _, recordedQuote := range recording.Quotes {
+		r.quotes[formatQuoteKey(recordedQuote.TokenIn, recordedQuote.TokenOutDenom, recordedQuote.Options)] = recordedQuote
+	}
+
+	for _, recordedRankedQuotes := range recording.RankedQuotes {
+		r.rankedQuotes[formatRankedQuotesKey(recordedRankedQuotes.TokenIn, recordedRankedQuotes.TokenOutDenom, recordedRankedQuotes.K, recordedRankedQuotes.Options)] = recordedRankedQuotes
+	}
+
+	for _, recordedSpotPrice := range recording.SpotPrices {
+		r.spotPrices[formatSpotPriceKey(recordedSpotPrice.PoolID, recordedSpotPrice.QuoteDenom, recordedSpotPrice.BaseDenom)] = recordedSpotPrice
+	}
+
+	return r
+}
+
+// NewReplayingRouterUsecaseFromFile returns a new replaying router usecase serving the recording
+// written to the given file by RecordingRouterUsecase.WriteFile.
+func NewReplayingRouterUsecaseFromFile(path string) (*ReplayingRouterUsecase, error) {
+	recordingBz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var recording Recording
+	if err := json.Unmarshal(recordingBz, &recording); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recording (%s): %w", path, err)
+	}
+
+	return NewReplayingRouterUsecase(recording), nil
+}
+
+// GetOptimalQuote implements mvc.RouterUsecase.
+func (r *ReplayingRouterUsecase) GetOptimalQuote(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+	options := formatRouterOptions(opts)
+	recordedQuote, ok := r.quotes[formatQuoteKey(tokenIn, tokenOutDenom, options)]
+	if !ok {
+		return nil, fmt.Errorf("%w: optimal quote for token in (%s), token out denom (%s) and options (%s)", ErrNotRecorded, tokenIn, tokenOutDenom, options)
+	}
+
+	if recordedQuote.Err != nil {
+		return nil, replayError(recordedQuote.Err)
+	}
+
+	return replayQuote(recordedQuote), nil
+}
+
+// GetRankedQuotes implements mvc.RouterUsecase.
+func (r *ReplayingRouterUsecase) GetRankedQuotes(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, k int, opts ...domain.RouterOption) ([]domain.Quote, error) {
+	options := formatRouterOptions(opts)
+	recordedRankedQuotes, ok := r.rankedQuotes[formatRankedQuotesKey(tokenIn, tokenOutDenom, k, options)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d ranked quotes for token in (%s), token out denom (%s) and options (%s)", ErrNotRecorded, k, tokenIn, tokenOutDenom, options)
+	}
+
+	if recordedRankedQuotes.Err != nil {
+		return nil, replayError(recordedRankedQuotes.Err)
+	}
+
+	quotes := make([]domain.Quote, 0, len(recordedRankedQuotes.Quotes))
+	for _, recordedQuote := range recordedRankedQuotes.Quotes {
+		quotes = append(quotes, replayQuote(recordedQuote))
+	}
+
+	return quotes, nil
+}
+
+// GetPoolSpotPrice implements mvc.RouterUsecase.
+func (r *ReplayingRouterUsecase) GetPoolSpotPrice(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+	recordedSpotPrice, ok := r.spotPrices[formatSpotPriceKey(poolID, quoteAsset, baseAsset)]
+	if !ok {
+		return osmomath.BigDec{}, fmt.Errorf("%w: spot price of pool (%d) for %s (base) -> %s (quote)", ErrNotRecorded, poolID, baseAsset, quoteAsset)
+	}
+
+	if recordedSpotPrice.Err != nil {
+		return osmomath.BigDec{}, replayError(recordedSpotPrice.Err)
+	}
+
+	if recordedSpotPrice.SpotPrice == "" {
+		return osmomath.BigDec{}, nil
+	}
+
+	return osmomath.NewBigDecFromStr(recordedSpotPrice.SpotPrice)
+}
+
+// GetPoolSpotPrices implements mvc.RouterUsecase.
+func (r *ReplayingRouterUsecase) GetPoolSpotPrices(ctx context.Context, requests []domain.SpotPriceRequest) ([]osmomath.BigDec, []error) {
+	spotPrices := make([]osmomath.BigDec, len(requests))
+	errs := make([]error, len(requests))
+	for i, request := range requests {
+		spotPrices[i], errs[i] = r.GetPoolSpotPrice(ctx, request.PoolID, request.QuoteDenom, request.BaseDenom)
+	}
+
+	return spotPrices, errs
+}
+
+// replayQuote returns the quote of the given record.
+// The replayed pools only carry the recorded data so their swaps and spot prices are not computable.
+func replayQuote(recordedQuote RecordedQuote) domain.Quote {
+	routes := make([]domain.SplitRoute, 0, len(recordedQuote.Routes))
+	for _, recordedRoute := range recordedQuote.Routes {
+		pools := make([]sqsdomain.RoutablePool, 0, len(recordedRoute.Pools))
+		for _, recordedPool := range recordedRoute.Pools {
+			pools = append(pools, &mocks.MockRoutablePool{
+				ID:            recordedPool.ID,
+				PoolType:      recordedPool.Type,
+				Denoms:        recordedPool.Denoms,
+				TokenOutDenom: recordedPool.TokenOutDenom,
+				SpreadFactor:  recordedPool.SpreadFactor,
+				TakerFee:      recordedPool.TakerFee,
+			})
+		}
+
+		routes = append(routes, &usecase.RouteWithOutAmount{
+			RouteImpl: route.RouteImpl{
+				Pools: pools,
+			},
+			InAmount:  recordedRoute.AmountIn,
+			OutAmount: recordedRoute.AmountOut,
+		})
+	}
+
+	return &mocks.MockQuote{
+		AmountIn:  recordedQuote.TokenIn,
+		AmountOut: recordedQuote.AmountOut,
+		Route:     routes,
+	}
+}
+
+// recordError returns the record of the given error. Nil if the error is nil.
+func recordError(err error) *RecordedError {
+	if err == nil {
+		return nil
+	}
+
+	recordedError := &RecordedError{Message: err.Error()}
+	for _, recordable := range recordableErrors {
+		if recordable.sentinel != nil {
+			if errors.Is(err, recordable.sentinel) {
+				recordedError.Code = recordable.code
+				return recordedError
+			}
+			continue
+		}
+
+		target := reflect.New(recordable.errorType)
+		if !errors.As(err, target.Interface()) {
+			continue
+		}
+
+		details, marshalErr := json.Marshal(target.Elem().Interface())
+		if marshalErr != nil {
+			panic(fmt.Sprintf("failed to marshal recordable error (%s): %s", recordable.code, marshalErr))
+		}
+
+		recordedError.Code = recordable.code
+		recordedError.Details = details
+		return recordedError
+	}
+
+	return recordedError
+}
+
+// replayError returns the error replayed from the given record.
+// Panics if the record has an unknown code or malformed details
+// since the replay would otherwise silently diverge from the recording.
+func replayError(recordedError *RecordedError) error {
+	if recordedError.Code == "" {
+		return errors.New(recordedError.Message)
+	}
+
+	for _, recordable := range recordableErrors {
+		if recordable.code != recordedError.Code {
+			continue
+		}
+
+		if recordable.sentinel != nil {
+			return replayedError{message: recordedError.Message, err: recordable.sentinel}
+		}
+
+		target := reflect.New(recordable.errorType)
+		if err := json.Unmarshal(recordedError.Details, target.Interface()); err != nil {
+			panic(fmt.Sprintf("failed to unmarshal recorded error (%s): %s", recordedError.Code, err))
+		}
+
+		return replayedError{message: recordedError.Message, err: target.Elem().Interface().(error)}
+	}
+
+	panic(fmt.Sprintf("unknown recorded error code (%s)", recordedError.Code))
+}
+
+// formatRouterOptions returns the canonical form of the given router options.
+// The pool volume function is only formatted by whether it is set since functions are not comparable.
+func formatRouterOptions(opts []domain.RouterOption) string {
+	var options domain.RouterOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	hasPoolVolume := options.PoolVolume != nil
+	options.PoolVolume = nil
+
+	return fmt.Sprintf("%+v PoolVolume:%t", options, hasPoolVolume)
+}
+
+// formatQuoteKey formats the key matching the replayed quotes.
+func formatQuoteKey(tokenIn sdk.Coin, tokenOutDenom string, options string) string {
+	return fmt.Sprintf("%s|%s|%s", tokenIn, tokenOutDenom, options)
+}
+
+// formatRankedQuotesKey formats the key matching the replayed ranked quotes.
+func formatRankedQuotesKey(tokenIn sdk.Coin, tokenOutDenom string, k int, options string) string {
+	return fmt.Sprintf("%s|%s|%d|%s", tokenIn, tokenOutDenom, k, options)
+}
+
+// formatSpotPriceKey formats the key matching the replayed pool spot prices.
+func formatSpotPriceKey(poolID uint64, quoteDenom string, baseDenom string) string {
+	return fmt.Sprintf("%d|%s|%s", poolID, quoteDenom, baseDenom)
+}
@@ -0,0 +1,162 @@
+package testutil_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/domain"
+	"github.com/osmosis-labs/sqs/domain/mocks"
+	"github.com/osmosis-labs/sqs/domain/testutil"
+	"github.com/osmosis-labs/sqs/router/usecase"
+	"github.com/osmosis-labs/sqs/router/usecase/route"
+	"github.com/osmosis-labs/sqs/router/usecase/routertesting"
+	"github.com/osmosis-labs/sqs/sqsdomain"
+)
+
+// TestRecordAndReplayRouterUsecase tests that the optimal quotes, the ranked quotes and the pool spot prices
+// recorded to a file are served back by the replaying router usecase, including the errors with their identity,
+// and that the calls missing from the recording, including the calls with other router options, fail with ErrNotRecorded.
+func TestRecordAndReplayRouterUsecase(t *testing.T) {
+	var (
+		ctx = context.Background()
+
+		tokenIn         = sdk.NewCoin(routertesting.UOSMO, osmomath.NewInt(1_000_000))
+		failedTokenIn   = sdk.NewCoin(routertesting.UOSMO, osmomath.NewInt(2_000_000))
+		tokenOutDenom   = routertesting.ATOM
+		poolID          = uint64(1)
+		failedPoolID    = uint64(2)
+		spotPrice       = osmomath.MustNewBigDecFromStr("1.123456789012345678901234567890123456")
+		errFailedQuote  = fmt.Errorf("failed quote: %w", domain.ErrUnpriceable)
+		errFailedSpotPx = domain.PoolNotFoundError{PoolID: failedPoolID}
+		excludedPools   = domain.WithExcludedPoolIDs(failedPoolID)
+	)
+
+	newQuote := func(tokenIn sdk.Coin, tokenOutDenom string) domain.Quote {
+		return &mocks.MockQuote{
+			AmountIn:  tokenIn,
+			AmountOut: tokenIn.Amount.MulRaw(2),
+			Route: []domain.SplitRoute{
+				&usecase.RouteWithOutAmount{
+					RouteImpl: route.RouteImpl{
+						Pools: []sqsdomain.RoutablePool{
+							mocks.WithTokenOutDenom(mocks.WithPoolID(routertesting.DefaultPool, poolID), tokenOutDenom),
+						},
+					},
+					InAmount:  tokenIn.Amount,
+					OutAmount: tokenIn.Amount.MulRaw(2),
+				},
+			},
+		}
+	}
+
+	routerUsecase := &mocks.RouterUsecaseMock{
+		GetOptimalQuoteFunc: func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			if tokenIn.IsEqual(failedTokenIn) {
+				return nil, errFailedQuote
+			}
+
+			return newQuote(tokenIn, tokenOutDenom), nil
+		},
+		GetRankedQuotesFunc: func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, k int, opts ...domain.RouterOption) ([]domain.Quote, error) {
+			return []domain.Quote{newQuote(tokenIn, tokenOutDenom)}, nil
+		},
+		GetPoolSpotPriceFunc: func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+			if poolID == failedPoolID {
+				return osmomath.BigDec{}, errFailedSpotPx
+			}
+			return spotPrice, nil
+		},
+	}
+
+	recordingRouterUsecase := testutil.NewRecordingRouterUsecase(routerUsecase)
+
+	expectedQuote, err := recordingRouterUsecase.GetOptimalQuote(ctx, tokenIn, tokenOutDenom, excludedPools)
+	require.NoError(t, err)
+
+	_, err = recordingRouterUsecase.GetRankedQuotes(ctx, tokenIn, tokenOutDenom, 2, excludedPools)
+	require.NoError(t, err)
+
+	_, err = recordingRouterUsecase.GetOptimalQuote(ctx, failedTokenIn, tokenOutDenom)
+	require.ErrorIs(t, err, errFailedQuote)
+
+	spotPriceRequests := []domain.SpotPriceRequest{
+		{PoolID: poolID, QuoteDenom: routertesting.UOSMO, BaseDenom: tokenOutDenom},
+		{PoolID: failedPoolID, QuoteDenom: routertesting.UOSMO, BaseDenom: tokenOutDenom},
+	}
+	_, errs := recordingRouterUsecase.GetPoolSpotPrices(ctx, spotPriceRequests)
+	require.NoError(t, errs[0])
+	require.ErrorIs(t, errs[1], errFailedSpotPx)
+
+	recording := recordingRouterUsecase.Recording()
+	require.Len(t, recording.Quotes, 2)
+	require.Len(t, recording.RankedQuotes, 1)
+	require.Len(t, recording.SpotPrices, 2)
+
+	recordingPath := filepath.Join(t.TempDir(), "recording.json")
+	require.NoError(t, recordingRouterUsecase.WriteFile(recordingPath))
+
+	replayingRouterUsecase, err := testutil.NewReplayingRouterUsecaseFromFile(recordingPath)
+	require.NoError(t, err)
+
+	// Replayed quote matches the recorded one.
+	quote, err := replayingRouterUsecase.GetOptimalQuote(ctx, tokenIn, tokenOutDenom, excludedPools)
+	require.NoError(t, err)
+	require.Equal(t, expectedQuote.GetAmountIn(), quote.GetAmountIn())
+	require.Equal(t, expectedQuote.GetAmountOut().String(), quote.GetAmountOut().String())
+	require.Len(t, quote.GetRoute(), 1)
+
+	expectedRoute, replayedRoute := expectedQuote.GetRoute()[0], quote.GetRoute()[0]
+	require.Equal(t, expectedRoute.GetAmountIn().String(), replayedRoute.GetAmountIn().String())
+	require.Equal(t, expectedRoute.GetAmountOut().String(), replayedRoute.GetAmountOut().String())
+	require.Len(t, replayedRoute.GetPools(), 1)
+
+	expectedPool, replayedPool := expectedRoute.GetPools()[0], replayedRoute.GetPools()[0]
+	require.Equal(t, expectedPool.GetId(), replayedPool.GetId())
+	require.Equal(t, expectedPool.GetType(), replayedPool.GetType())
+	require.Equal(t, expectedPool.GetPoolDenoms(), replayedPool.GetPoolDenoms())
+	require.Equal(t, expectedPool.GetTokenOutDenom(), replayedPool.GetTokenOutDenom())
+	require.Equal(t, expectedPool.GetSpreadFactor().String(), replayedPool.GetSpreadFactor().String())
+	require.Equal(t, expectedPool.GetTakerFee().String(), replayedPool.GetTakerFee().String())
+
+	// Replayed ranked quotes match the recorded ones.
+	rankedQuotes, err := replayingRouterUsecase.GetRankedQuotes(ctx, tokenIn, tokenOutDenom, 2, excludedPools)
+	require.NoError(t, err)
+	require.Len(t, rankedQuotes, 1)
+	require.Equal(t, expectedQuote.GetAmountOut().String(), rankedQuotes[0].GetAmountOut().String())
+	require.Equal(t, expectedPool.GetId(), rankedQuotes[0].GetRoute()[0].GetPools()[0].GetId())
+
+	// Recorded errors are replayed with their message and identity.
+	_, err = replayingRouterUsecase.GetOptimalQuote(ctx, failedTokenIn, tokenOutDenom)
+	require.EqualError(t, err, errFailedQuote.Error())
+	require.ErrorIs(t, err, domain.ErrUnpriceable)
+
+	// Spot prices are replayed at full precision.
+	spotPrices, errs := replayingRouterUsecase.GetPoolSpotPrices(ctx, spotPriceRequests)
+	require.NoError(t, errs[0])
+	require.Equal(t, spotPrice.String(), spotPrices[0].String())
+	require.EqualError(t, errs[1], errFailedSpotPx.Error())
+
+	poolNotFoundError := domain.PoolNotFoundError{}
+	require.ErrorAs(t, errs[1], &poolNotFoundError)
+	require.Equal(t, failedPoolID, poolNotFoundError.PoolID)
+
+	// Unrecorded calls fail.
+	_, err = replayingRouterUsecase.GetOptimalQuote(ctx, tokenIn, routertesting.USDC, excludedPools)
+	require.ErrorIs(t, err, testutil.ErrNotRecorded)
+
+	// Calls with other router options are unrecorded.
+	_, err = replayingRouterUsecase.GetOptimalQuote(ctx, tokenIn, tokenOutDenom)
+	require.ErrorIs(t, err, testutil.ErrNotRecorded)
+
+	_, err = replayingRouterUsecase.GetRankedQuotes(ctx, tokenIn, tokenOutDenom, 3, excludedPools)
+	require.ErrorIs(t, err, testutil.ErrNotRecorded)
+
+	_, err = replayingRouterUsecase.GetPoolSpotPrice(ctx, poolID, tokenOutDenom, routertesting.UOSMO)
+	require.ErrorIs(t, err, testutil.ErrNotRecorded)
+}
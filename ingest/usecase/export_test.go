@@ -1,6 +1,18 @@
 package usecase
 
+import (
+	"github.com/osmosis-labs/osmosis/osmomath"
+)
+
 type (
 	IngestUseCaseImpl = ingestUseCase
 )
 
+var (
+	NewPoolVolumeTracker = newPoolVolumeTracker
+	EstimatePoolVolume   = estimatePoolVolume
+)
+
+func (t *poolVolumeTracker) RecordBlock(blockVolumes map[uint64]osmomath.Int) map[uint64]osmomath.Int {
+	return t.recordBlock(blockVolumes)
+}
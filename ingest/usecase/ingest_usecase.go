@@ -8,6 +8,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec"
 	"go.uber.org/zap"
 
+	"github.com/osmosis-labs/osmosis/osmomath"
 	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
 	"github.com/osmosis-labs/sqs/domain"
 	"github.com/osmosis-labs/sqs/domain/mvc"
@@ -25,6 +26,12 @@ type ingestUseCase struct {
 	poolsUseCase     mvc.PoolsUsecase
 	routerUsecase    mvc.RouterUsecase
 	chainInfoUseCase mvc.ChainInfoUsecase
+	// tokensUseCase receives the recent pool volumes estimated from the ingested pools.
+	// Nil if the pool volumes are not tracked.
+	tokensUseCase mvc.TokensUsecase
+
+	// poolVolumes sums the pool volumes estimated from the ingested pools over the recent blocks.
+	poolVolumes *poolVolumeTracker
 
 	// Worker that computes prices for all tokens with the default quote.
 	defaultQuotePriceUpdateWorker domain.PricingWorker
@@ -49,13 +56,18 @@ var (
 )
 
 // NewIngestUsecase will create a new pools use case object
-func NewIngestUsecase(poolsUseCase mvc.PoolsUsecase, routerUseCase mvc.RouterUsecase, chainInfoUseCase mvc.ChainInfoUsecase, codec codec.Codec, quotePriceUpdateWorker domain.PricingWorker, logger log.Logger) (mvc.IngestUsecase, error) {
+// If the tokens use case is non-nil, it is fed the recent pool volumes estimated from the ingested pools
+// (see TokensUsecase.SetPoolVolumes).
+func NewIngestUsecase(poolsUseCase mvc.PoolsUsecase, routerUseCase mvc.RouterUsecase, chainInfoUseCase mvc.ChainInfoUsecase, tokensUseCase mvc.TokensUsecase, codec codec.Codec, quotePriceUpdateWorker domain.PricingWorker, logger log.Logger) (mvc.IngestUsecase, error) {
 	return &ingestUseCase{
 		codec: codec,
 
 		chainInfoUseCase: chainInfoUseCase,
 		routerUsecase:    routerUseCase,
 		poolsUseCase:     poolsUseCase,
+		tokensUseCase:    tokensUseCase,
+
+		poolVolumes: newPoolVolumeTracker(poolVolumeWindowBlocks),
 
 		logger: logger,
 
@@ -76,11 +88,21 @@ func (p *ingestUseCase) ProcessBlockData(ctx context.Context, height uint64, tak
 		return err
 	}
 
+	// Estimate the pool volumes from the pools prior to overwriting them.
+	var blockPoolVolumes map[uint64]osmomath.Int
+	if p.tokensUseCase != nil {
+		blockPoolVolumes = p.estimateBlockPoolVolumes(pools)
+	}
+
 	// Store the pools
 	if err := p.poolsUseCase.StorePools(pools); err != nil {
 		return err
 	}
 
+	if p.tokensUseCase != nil {
+		p.tokensUseCase.SetPoolVolumes(p.poolVolumes.recordBlock(blockPoolVolumes))
+	}
+
 	// Get all pools (already updated with the newly ingested pools)
 	allPools, err := p.poolsUseCase.GetAllPools()
 	if err != nil {
@@ -106,6 +128,24 @@ func (p *ingestUseCase) ProcessBlockData(ctx context.Context, height uint64, tak
 	return nil
 }
 
+// estimateBlockPoolVolumes estimates the volumes of the given updated pools within the block
+// from their changes relative to the stored pools (see estimatePoolVolume).
+// The pools that are new in the block have no volume.
+func (p *ingestUseCase) estimateBlockPoolVolumes(updatedPools []sqsdomain.PoolI) map[uint64]osmomath.Int {
+	blockPoolVolumes := make(map[uint64]osmomath.Int, len(updatedPools))
+	for _, updatedPool := range updatedPools {
+		previousPool, err := p.poolsUseCase.GetPool(updatedPool.GetId())
+		if err != nil {
+			continue
+		}
+
+		if volume := estimatePoolVolume(previousPool, updatedPool); volume.IsPositive() {
+			blockPoolVolumes[updatedPool.GetId()] = volume
+		}
+	}
+	return blockPoolVolumes
+}
+
 // sortAndStorePools sorts the pools and stores them in the router.
 // TODO: instead of resorting all pools every block, we should put the updated pools in the correct position
 func (p *ingestUseCase) sortAndStorePools(pools []sqsdomain.PoolI) {
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"sync"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/sqsdomain"
+)
+
+// poolVolumeWindowBlocks is the number of the latest blocks that the recent pool volumes are summed over.
+// About an hour of blocks.
+const poolVolumeWindowBlocks = 600
+
+// poolVolumeTracker sums the estimated pool volumes over the window of the latest blocks.
+type poolVolumeTracker struct {
+	mu sync.Mutex
+
+	// blockVolumes is the ring buffer of the pool volumes of the blocks within the window.
+	blockVolumes []map[uint64]osmomath.Int
+	// nextBlockIndex is the index of the ring buffer that the next block volumes overwrite.
+	nextBlockIndex int
+	// windowVolumes are the pool volumes summed over the blocks within the window.
+	windowVolumes map[uint64]osmomath.Int
+}
+
+// newPoolVolumeTracker returns a pool volume tracker summing the volumes over the given number of blocks.
+func newPoolVolumeTracker(windowBlocks int) *poolVolumeTracker {
+	return &poolVolumeTracker{
+		blockVolumes:  make([]map[uint64]osmomath.Int, windowBlocks),
+		windowVolumes: make(map[uint64]osmomath.Int),
+	}
+}
+
+// recordBlock records the pool volumes of the latest block, evicting the oldest block from the window.
+// Returns a copy of the pool volumes summed over the window.
+func (t *poolVolumeTracker) recordBlock(blockVolumes map[uint64]osmomath.Int) map[uint64]osmomath.Int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for poolID, volume := range t.blockVolumes[t.nextBlockIndex] {
+		windowVolume := t.windowVolumes[poolID].Sub(volume)
+		if windowVolume.IsPositive() {
+			t.windowVolumes[poolID] = windowVolume
+		} else {
+			delete(t.windowVolumes, poolID)
+		}
+	}
+
+	for poolID, volume := range blockVolumes {
+		if windowVolume, ok := t.windowVolumes[poolID]; ok {
+			t.windowVolumes[poolID] = windowVolume.Add(volume)
+		} else {
+			t.windowVolumes[poolID] = volume
+		}
+	}
+
+	t.blockVolumes[t.nextBlockIndex] = blockVolumes
+	t.nextBlockIndex = (t.nextBlockIndex + 1) % len(t.blockVolumes)
+
+	windowVolumes := make(map[uint64]osmomath.Int, len(t.windowVolumes))
+	for poolID, volume := range t.windowVolumes {
+		windowVolumes[poolID] = volume
+	}
+	return windowVolumes
+}
+
+// estimatePoolVolume estimates the volume traded through the pool between its previous and updated states
+// in terms of its previous total value locked. It is the largest relative change in the balance of any
+// of the pool denoms times the total value locked. The estimate overstates the swapped value by a factor
+// that depends on the pool weights but is comparable across the pools of the same shape, which suffices
+// for ranking the pools by volume. Liquidity provision and withdrawal count as volume too.
+// Returns zero if the previous pool has no total value locked.
+func estimatePoolVolume(previousPool sqsdomain.PoolI, updatedPool sqsdomain.PoolI) osmomath.Int {
+	previousModel := previousPool.GetSQSPoolModel()
+	totalValueLocked := previousModel.TotalValueLockedUSDC
+	if totalValueLocked.IsNil() || !totalValueLocked.IsPositive() {
+		return osmomath.ZeroInt()
+	}
+
+	updatedBalances := updatedPool.GetSQSPoolModel().Balances
+
+	volume := osmomath.ZeroInt()
+	for _, previousBalance := range previousModel.Balances {
+		if !previousBalance.Amount.IsPositive() {
+			continue
+		}
+
+		balanceChange := updatedBalances.AmountOf(previousBalance.Denom).Sub(previousBalance.Amount).Abs()
+		denomVolume := balanceChange.Mul(totalValueLocked).Quo(previousBalance.Amount)
+		if denomVolume.GT(volume) {
+			volume = denomVolume
+		}
+	}
+
+	return volume
+}
@@ -0,0 +1,54 @@
+package usecase_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/ingest/usecase"
+	"github.com/osmosis-labs/sqs/sqsdomain"
+)
+
+// Validates that the pool volumes are summed over the window of the latest blocks.
+func TestPoolVolumeTracker_RecordBlock(t *testing.T) {
+	tracker := usecase.NewPoolVolumeTracker(2)
+
+	volumes := tracker.RecordBlock(map[uint64]osmomath.Int{1: osmomath.NewInt(10), 2: osmomath.NewInt(5)})
+	require.Equal(t, map[uint64]osmomath.Int{1: osmomath.NewInt(10), 2: osmomath.NewInt(5)}, volumes)
+
+	volumes = tracker.RecordBlock(map[uint64]osmomath.Int{1: osmomath.NewInt(3)})
+	require.Equal(t, map[uint64]osmomath.Int{1: osmomath.NewInt(13), 2: osmomath.NewInt(5)}, volumes)
+
+	// The first block leaves the window.
+	volumes = tracker.RecordBlock(map[uint64]osmomath.Int{})
+	require.Equal(t, map[uint64]osmomath.Int{1: osmomath.NewInt(3)}, volumes)
+
+	volumes = tracker.RecordBlock(nil)
+	require.Empty(t, volumes)
+}
+
+// Validates that the pool volume is the largest relative balance change times the previous total value locked.
+func TestEstimatePoolVolume(t *testing.T) {
+	newPool := func(totalValueLocked osmomath.Int, balances sdk.Coins) sqsdomain.PoolI {
+		return &sqsdomain.PoolWrapper{
+			SQSModel: sqsdomain.SQSPool{
+				TotalValueLockedUSDC: totalValueLocked,
+				Balances:             balances,
+			},
+		}
+	}
+
+	previousPool := newPool(osmomath.NewInt(1_000), sdk.NewCoins(sdk.NewInt64Coin("uatom", 100), sdk.NewInt64Coin("uosmo", 500)))
+
+	// 10% more uatom and 8% less uosmo.
+	updatedPool := newPool(osmomath.NewInt(1_000), sdk.NewCoins(sdk.NewInt64Coin("uatom", 110), sdk.NewInt64Coin("uosmo", 460)))
+	require.Equal(t, osmomath.NewInt(100).String(), usecase.EstimatePoolVolume(previousPool, updatedPool).String())
+
+	// Unchanged.
+	require.True(t, usecase.EstimatePoolVolume(previousPool, previousPool).IsZero())
+
+	// No total value locked.
+	require.True(t, usecase.EstimatePoolVolume(newPool(osmomath.Int{}, previousPool.GetSQSPoolModel().Balances), updatedPool).IsZero())
+}
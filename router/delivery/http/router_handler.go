@@ -60,6 +60,7 @@ func NewRouterHandler(e *echo.Echo, us mvc.RouterUsecase, tu mvc.TokensUsecase,
 // @Param  singleRoute  query  bool  false  "Boolean flag indicating whether to return single routes (no splits). False (splits enabled) by default."
 // @Param humanDenoms query bool true "Boolean flag indicating whether the given denoms are human readable or not. Human denoms get converted to chain internally"
 // @Param  applyExponents  query  bool  false  "Boolean flag indicating whether to apply exponents to the spot price. False by default."
+// @Param  dryRun  query  bool  false  "Boolean flag indicating whether to skip computing the effective spot price and price impact. False by default."
 // @Success 200  {object}  domain.Quote  "The computed best route quote"
 // @Router /router/quote [get]
 func (a *RouterHandler) GetOptimalQuote(c echo.Context) (err error) {
@@ -83,6 +84,15 @@ func (a *RouterHandler) GetOptimalQuote(c echo.Context) (err error) {
 		}
 	}
 
+	isDryRunStr := c.QueryParam("dryRun")
+	isDryRun := false
+	if isDryRunStr != "" {
+		isDryRun, err = strconv.ParseBool(isDryRunStr)
+		if err != nil {
+			return c.JSON(domain.GetStatusCode(err), domain.ResponseError{Message: err.Error()})
+		}
+	}
+
 	tokenOutDenom, tokenIn, err := getValidRoutingParameters(c)
 	if err != nil {
 		return c.JSON(domain.GetStatusCode(err), domain.ResponseError{Message: err.Error()})
@@ -112,7 +122,12 @@ func (a *RouterHandler) GetOptimalQuote(c echo.Context) (err error) {
 		scalingFactor = a.getSpotPriceScalingFactor(tokenInDenom, tokenOutDenom)
 	}
 
-	_, _, err = quote.PrepareResult(ctx, scalingFactor)
+	prepareResultOpts := []domain.PrepareResultOption{domain.WithPoolTypeGasCosts(a.RUsecase.GetConfig().GetPoolTypeGasCosts())}
+	if isDryRun {
+		prepareResultOpts = append(prepareResultOpts, domain.WithDryRun())
+	}
+
+	_, _, err = quote.PrepareResult(ctx, scalingFactor, prepareResultOpts...)
 	if err != nil {
 		return c.JSON(domain.GetStatusCode(err), domain.ResponseError{Message: err.Error()})
 	}
@@ -160,7 +175,7 @@ func (a *RouterHandler) GetDirectCustomQuote(c echo.Context) error {
 		scalingFactor = a.getSpotPriceScalingFactor(tokenIn.Denom, tokenOutDenom)
 	}
 
-	_, _, err = quote.PrepareResult(ctx, scalingFactor)
+	_, _, err = quote.PrepareResult(ctx, scalingFactor, domain.WithPoolTypeGasCosts(a.RUsecase.GetConfig().GetPoolTypeGasCosts()))
 	if err != nil {
 		return c.JSON(domain.GetStatusCode(err), domain.ResponseError{Message: err.Error()})
 	}
@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/osmosis-labs/sqs/log"
 	"github.com/osmosis-labs/sqs/sqsdomain"
@@ -17,6 +19,14 @@ type candidatePoolWrapper struct {
 
 // GetCandidateRoutes returns candidate routes from tokenInDenom to tokenOutDenom using BFS.
 func GetCandidateRoutes(pools []sqsdomain.PoolI, tokenIn sdk.Coin, tokenOutDenom string, maxRoutes, maxPoolsPerRoute int, logger log.Logger) (sqsdomain.CandidateRoutes, error) {
+	candidateRoutes, _, err := getCandidateRoutes(context.Background(), pools, tokenIn, tokenOutDenom, maxRoutes, maxPoolsPerRoute, logger)
+	return candidateRoutes, err
+}
+
+// getCandidateRoutes returns candidate routes from tokenInDenom to tokenOutDenom using BFS.
+// The search is bounded by the given context. If the context is done before the search completes,
+// the complete routes found so far are returned together with the truncated flag set to true.
+func getCandidateRoutes(ctx context.Context, pools []sqsdomain.PoolI, tokenIn sdk.Coin, tokenOutDenom string, maxRoutes, maxPoolsPerRoute int, logger log.Logger) (candidateRoutes sqsdomain.CandidateRoutes, isTruncated bool, err error) {
 	routes := make([][]candidatePoolWrapper, 0, maxRoutes)
 	// Preallocate third to avoid dynamic reallocations.
 	visited := make([]bool, len(pools))
@@ -26,6 +36,12 @@ func GetCandidateRoutes(pools []sqsdomain.PoolI, tokenIn sdk.Coin, tokenOutDenom
 	queue = append(queue, make([]candidatePoolWrapper, 0, maxPoolsPerRoute))
 
 	for len(queue) > 0 && len(routes) < maxRoutes {
+		// Stop the search if the routing timeout fires, keeping the routes found so far.
+		if ctx.Err() != nil {
+			isTruncated = true
+			break
+		}
+
 		currentRoute := queue[0]
 		queue[0] = nil // Clear the slice to avoid holding onto references
 		queue = queue[1:]
@@ -126,7 +142,12 @@ func GetCandidateRoutes(pools []sqsdomain.PoolI, tokenIn sdk.Coin, tokenOutDenom
 		}
 	}
 
-	return validateAndFilterRoutes(routes, tokenIn.Denom, logger)
+	candidateRoutes, err = validateAndFilterRoutes(routes, tokenIn.Denom, logger)
+	if err != nil {
+		return sqsdomain.CandidateRoutes{}, false, err
+	}
+
+	return candidateRoutes, isTruncated, nil
 }
 
 // Pool represents a pool in the decentralized exchange.
@@ -1,6 +1,8 @@
 package usecase_test
 
 import (
+	"context"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/osmosis-labs/osmosis/osmomath"
 	"github.com/osmosis-labs/sqs/log"
@@ -45,6 +47,41 @@ func (s *RouterTestSuite) TestGetCandidateRoutesBFS_OSMOATOM() {
 	s.validateExpectedPoolIDOneHopRoute(actualRoutes[1], 1265)
 }
 
+// Validates that the candidate route search stops when the context is done
+// and reports the search as truncated.
+func (s *RouterTestSuite) TestGetCandidateRoutesBFS_RoutingTimeout() {
+	var (
+		maxPoolsPerRoute = 5
+		maxRoutes        = 10
+	)
+
+	mainnetState := s.SetupMainnetState()
+
+	// Prepare valid and sorted pools
+	poolsAboveMinLiquidity := routertesting.PrepareValidSortedRouterPools(mainnetState.Pools, defaultRouterConfig.MinOSMOLiquidity)
+
+	s.Run("context done before search - truncated", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// System under test.
+		candidateRoutes, isTruncated, err := routerusecase.GetCandidateRoutesWithContext(ctx, poolsAboveMinLiquidity, sdk.NewCoin(UOSMO, one), ATOM, maxRoutes, maxPoolsPerRoute, noOpLogger)
+		s.Require().NoError(err)
+
+		s.Require().True(isTruncated)
+		s.Require().Empty(candidateRoutes.Routes)
+	})
+
+	s.Run("no timeout - not truncated", func() {
+		// System under test.
+		candidateRoutes, isTruncated, err := routerusecase.GetCandidateRoutesWithContext(context.Background(), poolsAboveMinLiquidity, sdk.NewCoin(UOSMO, one), ATOM, maxRoutes, maxPoolsPerRoute, noOpLogger)
+		s.Require().NoError(err)
+
+		s.Require().False(isTruncated)
+		s.Require().Equal(maxRoutes, len(candidateRoutes.Routes))
+	})
+}
+
 // Validates that the router returns the correct routes for the given token pair.
 // Inverting the swap direction should return the same routes.
 func (s *RouterTestSuite) TestGetCandidateRoutesBFS_OSMOstOSMO() {
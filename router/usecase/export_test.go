@@ -29,15 +29,32 @@ func ValidateAndFilterRoutes(candidateRoutes [][]candidatePoolWrapper, tokenInDe
 }
 
 func (r *routerUseCaseImpl) HandleRoutes(ctx context.Context, pools []sqsdomain.PoolI, tokenIn sdk.Coin, tokenOutDenom string, maxRoutes, maxPoolsPerRoute int) (candidateRoutes sqsdomain.CandidateRoutes, err error) {
-	return r.handleCandidateRoutes(ctx, pools, tokenIn, tokenOutDenom, maxRoutes, maxPoolsPerRoute)
+	candidateRoutes, _, err = r.handleCandidateRoutes(ctx, pools, tokenIn, tokenOutDenom, maxRoutes, maxPoolsPerRoute, noRoutingTimeout)
+	return candidateRoutes, err
+}
+
+func GetCandidateRoutesWithContext(ctx context.Context, pools []sqsdomain.PoolI, tokenIn sdk.Coin, tokenOutDenom string, maxRoutes, maxPoolsPerRoute int, logger log.Logger) (sqsdomain.CandidateRoutes, bool, error) {
+	return getCandidateRoutes(ctx, pools, tokenIn, tokenOutDenom, maxRoutes, maxPoolsPerRoute, logger)
 }
 
 func EstimateAndRankSingleRouteQuote(ctx context.Context, routes []route.RouteImpl, tokenIn sdk.Coin, logger log.Logger) (domain.Quote, []RouteWithOutAmount, error) {
 	return estimateAndRankSingleRouteQuote(ctx, routes, tokenIn, logger)
 }
 
+func SortRoutesByAmountOut(routes []RouteWithOutAmount) {
+	sortRoutesByAmountOut(routes)
+}
+
+func SortRoutesByVolumeWithinTolerance(routes []RouteWithOutAmount, poolVolume domain.PoolVolumeFunc, tolerance osmomath.Dec) {
+	sortRoutesByVolumeWithinTolerance(routes, poolVolume, tolerance)
+}
+
 func FilterDuplicatePoolIDRoutes(rankedRoutes []route.RouteImpl) []route.RouteImpl {
-	return filterDuplicatePoolIDRoutes(rankedRoutes)
+	routesWithAmountOut := make([]RouteWithOutAmount, 0, len(rankedRoutes))
+	for _, rankedRoute := range rankedRoutes {
+		routesWithAmountOut = append(routesWithAmountOut, RouteWithOutAmount{RouteImpl: rankedRoute})
+	}
+	return getRouteImpls(filterDuplicatePoolIDRoutes(routesWithAmountOut))
 }
 
 func ConvertRankedToCandidateRoutes(rankedRoutes []route.RouteImpl) sqsdomain.CandidateRoutes {
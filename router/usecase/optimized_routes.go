@@ -65,9 +65,7 @@ func estimateAndRankSingleRouteQuote(ctx context.Context, routes []route.RouteIm
 	}
 
 	// Sort by amount out in descending order
-	sort.Slice(routesWithAmountOut, func(i, j int) bool {
-		return routesWithAmountOut[i].OutAmount.GT(routesWithAmountOut[j].OutAmount)
-	})
+	sortRoutesByAmountOut(routesWithAmountOut)
 
 	bestRoute := routesWithAmountOut[0]
 
@@ -80,6 +78,100 @@ func estimateAndRankSingleRouteQuote(ctx context.Context, routes []route.RouteIm
 	return finalQuote, routesWithAmountOut, nil
 }
 
+// sortRoutesByAmountOut sorts the given routes deterministically in the following order:
+// 1. By amount out in descending order.
+// 2. For equal amount out, by the number of pools in the route in ascending order.
+// 3. For equal number of pools, by the sum of pool IDs in the route in ascending order.
+// This ensures that the route selection is deterministic given identical pool state.
+func sortRoutesByAmountOut(routes []RouteWithOutAmount) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		if !routes[i].OutAmount.Equal(routes[j].OutAmount) {
+			return routes[i].OutAmount.GT(routes[j].OutAmount)
+		}
+
+		poolsI, poolsJ := routes[i].GetPools(), routes[j].GetPools()
+		if len(poolsI) != len(poolsJ) {
+			return len(poolsI) < len(poolsJ)
+		}
+
+		return sumPoolIDs(poolsI) < sumPoolIDs(poolsJ)
+	})
+}
+
+// sortRoutesByVolumeWithinTolerance reorders the given routes sorted by amount out so that
+// the routes whose amount out is within the tolerance of the best amount out
+// are sorted by their volume in descending order. The rest of the routes retain their order.
+// The volume of a route is the lowest volume of its pools. Pools with unknown volume have zero volume.
+func sortRoutesByVolumeWithinTolerance(routes []RouteWithOutAmount, poolVolume domain.PoolVolumeFunc, tolerance osmomath.Dec) {
+	if len(routes) < 2 {
+		return
+	}
+
+	if tolerance.IsNil() {
+		tolerance = osmomath.ZeroDec()
+	}
+
+	minAmountOut := routes[0].OutAmount.ToLegacyDec().MulMut(osmomath.OneDec().Sub(tolerance))
+
+	numWithinTolerance := 1
+	for numWithinTolerance < len(routes) && routes[numWithinTolerance].OutAmount.ToLegacyDec().GTE(minAmountOut) {
+		numWithinTolerance++
+	}
+
+	routesWithinTolerance := routes[:numWithinTolerance]
+
+	routeVolumes := make([]osmomath.Int, len(routesWithinTolerance))
+	for i, route := range routesWithinTolerance {
+		routeVolumes[i] = getRouteVolume(route.GetPools(), poolVolume)
+	}
+
+	// Sort indexes rather than the routes so that the volumes stay aligned.
+	indexes := make([]int, len(routesWithinTolerance))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return routeVolumes[indexes[i]].GT(routeVolumes[indexes[j]])
+	})
+
+	sortedRoutes := make([]RouteWithOutAmount, len(routesWithinTolerance))
+	for i, index := range indexes {
+		sortedRoutes[i] = routesWithinTolerance[index]
+	}
+	copy(routesWithinTolerance, sortedRoutes)
+}
+
+// getRouteVolume returns the lowest volume of the given pools.
+// Pools with unknown volume have zero volume.
+func getRouteVolume(pools []sqsdomain.RoutablePool, poolVolume domain.PoolVolumeFunc) osmomath.Int {
+	routeVolume := osmomath.Int{}
+	for _, pool := range pools {
+		volume, ok := poolVolume(pool.GetId())
+		if !ok || volume.IsNil() {
+			return osmomath.ZeroInt()
+		}
+
+		if routeVolume.IsNil() || volume.LT(routeVolume) {
+			routeVolume = volume
+		}
+	}
+
+	if routeVolume.IsNil() {
+		return osmomath.ZeroInt()
+	}
+
+	return routeVolume
+}
+
+// sumPoolIDs returns the sum of the IDs of the given pools.
+func sumPoolIDs(pools []sqsdomain.RoutablePool) uint64 {
+	sum := uint64(0)
+	for _, pool := range pools {
+		sum += pool.GetId()
+	}
+	return sum
+}
+
 // validateAndFilterRoutes validates all routes. Specifically:
 // - all routes have at least one pool.
 // - all routes have the same final token out denom.
@@ -261,6 +261,148 @@ func (s *RouterTestSuite) TestGetBestSplitRoutesQuote() {
 	}
 }
 
+// Validates that routes with equal amount out are sorted deterministically
+// by the number of pools and then by the sum of pool IDs.
+func (s *RouterTestSuite) TestSortRoutesByAmountOut() {
+	newRoute := func(amountOut int64, poolIDs ...uint64) usecase.RouteWithOutAmount {
+		pools := make([]sqsdomain.RoutablePool, 0, len(poolIDs))
+		for _, poolID := range poolIDs {
+			pools = append(pools, &mocks.MockRoutablePool{ID: poolID})
+		}
+
+		return usecase.RouteWithOutAmount{
+			RouteImpl: route.RouteImpl{Pools: pools},
+			OutAmount: osmomath.NewInt(amountOut),
+		}
+	}
+
+	routes := []usecase.RouteWithOutAmount{
+		newRoute(100, 5, 6),
+		newRoute(100, 7),
+		newRoute(200, 9, 10, 11),
+		newRoute(100, 1, 3),
+		newRoute(100, 2),
+	}
+
+	usecase.SortRoutesByAmountOut(routes)
+
+	expectedPoolIDs := [][]uint64{
+		// highest amount out first regardless of the number of pools
+		{9, 10, 11},
+		// fewest pools, lowest sum of pool IDs
+		{2},
+		{7},
+		// lowest sum of pool IDs
+		{1, 3},
+		{5, 6},
+	}
+
+	s.Require().Len(routes, len(expectedPoolIDs))
+	for i, expected := range expectedPoolIDs {
+		pools := routes[i].GetPools()
+		s.Require().Len(pools, len(expected))
+		for j, pool := range pools {
+			s.Require().Equal(expected[j], pool.GetId())
+		}
+	}
+}
+
+// This test validates that the routes within the tolerance of the best amount out
+// are sorted by their volume while the rest of the routes retain their order.
+func (s *RouterTestSuite) TestSortRoutesByVolumeWithinTolerance() {
+	newRoute := func(amountOut int64, poolIDs ...uint64) usecase.RouteWithOutAmount {
+		pools := make([]sqsdomain.RoutablePool, 0, len(poolIDs))
+		for _, poolID := range poolIDs {
+			pools = append(pools, &mocks.MockRoutablePool{ID: poolID})
+		}
+
+		return usecase.RouteWithOutAmount{
+			RouteImpl: route.RouteImpl{Pools: pools},
+			OutAmount: osmomath.NewInt(amountOut),
+		}
+	}
+
+	poolVolumes := map[uint64]osmomath.Int{
+		1: osmomath.NewInt(100),
+		2: osmomath.NewInt(5000),
+		3: osmomath.NewInt(10000),
+		4: osmomath.NewInt(1000),
+		5: osmomath.NewInt(1_000_000),
+	}
+	poolVolume := func(poolID uint64) (osmomath.Int, bool) {
+		volume, ok := poolVolumes[poolID]
+		return volume, ok
+	}
+
+	tests := []struct {
+		name      string
+		routes    []usecase.RouteWithOutAmount
+		tolerance osmomath.Dec
+
+		expectedPoolIDs [][]uint64
+	}{
+		{
+			name: "higher volume route within tolerance is preferred",
+			routes: []usecase.RouteWithOutAmount{
+				newRoute(1000, 1),
+				newRoute(999, 2),
+				newRoute(995, 5),
+			},
+			tolerance: osmomath.MustNewDecFromStr("0.001"),
+
+			expectedPoolIDs: [][]uint64{{2}, {1}, {5}},
+		},
+		{
+			name: "route volume is the lowest pool volume",
+			routes: []usecase.RouteWithOutAmount{
+				newRoute(1000, 2),
+				newRoute(1000, 3, 4),
+				newRoute(1000, 3, 5),
+			},
+			tolerance: osmomath.MustNewDecFromStr("0.001"),
+
+			expectedPoolIDs: [][]uint64{{3, 5}, {2}, {3, 4}},
+		},
+		{
+			name: "unknown pool volume is zero",
+			routes: []usecase.RouteWithOutAmount{
+				newRoute(1000, 6),
+				newRoute(1000, 1),
+				newRoute(1000, 5, 6),
+			},
+			tolerance: osmomath.ZeroDec(),
+
+			expectedPoolIDs: [][]uint64{{1}, {6}, {5, 6}},
+		},
+		{
+			name: "zero tolerance only reorders equal amounts out",
+			routes: []usecase.RouteWithOutAmount{
+				newRoute(1000, 1),
+				newRoute(999, 5),
+			},
+			tolerance: osmomath.ZeroDec(),
+
+			expectedPoolIDs: [][]uint64{{1}, {5}},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		s.Run(tc.name, func() {
+			usecase.SortRoutesByVolumeWithinTolerance(tc.routes, poolVolume, tc.tolerance)
+
+			s.Require().Len(tc.routes, len(tc.expectedPoolIDs))
+			for i, expected := range tc.expectedPoolIDs {
+				pools := tc.routes[i].GetPools()
+				s.Require().Len(pools, len(expected))
+				for j, pool := range pools {
+					s.Require().Equal(expected[j], pool.GetId())
+				}
+			}
+		})
+	}
+}
+
 // This test ensures strict route validation.
 // See individual test cases for details.
 func (s *RouterTestSuite) TestValidateAndFilterRoutes() {
@@ -661,7 +803,8 @@ func (s *RouterTestSuite) TestGetCustomQuote_GetCustomDirectQuote_Mainnet_UOSMOU
 	poolsUsecase := poolsusecase.NewPoolsUsecase(&domain.PoolsConfig{}, "node-uri-placeholder", routerRepositoryMock)
 	poolsUsecase.StorePools(mainnetState.Pools)
 
-	routerUsecase := routerusecase.NewRouterUsecase(routerRepositoryMock, poolsUsecase, config, emptyCosmWasmPoolsRouterConfig, &log.NoOpLogger{}, cache.New(), cache.New())
+	routerUsecase, err := routerusecase.NewRouterUsecase(routerRepositoryMock, poolsUsecase, config, emptyCosmWasmPoolsRouterConfig, &log.NoOpLogger{}, cache.New(), cache.New())
+	s.Require().NoError(err)
 
 	// This pool ID is second best: https://app.osmosis.zone/pool/2
 	// The top one is https://app.osmosis.zone/pool/1110 which is not selected
@@ -11,6 +11,7 @@ import (
 	"github.com/osmosis-labs/sqs/router/usecase/route"
 
 	"github.com/osmosis-labs/osmosis/osmomath"
+	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
 )
 
 type quoteImpl struct {
@@ -20,6 +21,13 @@ type quoteImpl struct {
 	EffectiveFee            osmomath.Dec        "json:\"effective_fee\""
 	PriceImpact             osmomath.Dec        "json:\"price_impact\""
 	InBaseOutQuoteSpotPrice osmomath.Dec        "json:\"in_base_out_quote_spot_price\""
+	RouteSearchTruncated    bool                "json:\"route_search_truncated,omitempty\""
+	EffectivePriceSkipped   bool                "json:\"effective_price_skipped,omitempty\""
+	EstimatedGas            uint64              "json:\"estimated_gas\""
+
+	// isPriceImpactComputed is true if the price impact was already computed from the effective price
+	// when validating the quote so that preparing the result does not recompute the effective price.
+	isPriceImpactComputed bool
 }
 
 var (
@@ -34,9 +42,22 @@ var _ domain.Quote = &quoteImpl{}
 // Specifically:
 // It strips away unnecessary fields from each pool in the route.
 // Computes an effective spread factor from all routes.
+// Estimates the gas cost of executing the routes from the gas costs of their pool types.
+//
+// With domain.WithDryRun, the effective spot price is not computed. The price impact
+// is left at zero and the quote is flagged as having skipped the effective price.
+// If the price impact was already computed when validating the quote, it is reused
+// rather than recomputing the effective spot price.
 //
 // Returns the updated route and the effective spread factor.
-func (q *quoteImpl) PrepareResult(ctx context.Context, scalingFactor osmomath.Dec) ([]domain.SplitRoute, osmomath.Dec, error) {
+// Returns domain.ErrNilPoolInRoute if any of the routes contains a nil pool.
+// In that case, the quote is not mutated.
+func (q *quoteImpl) PrepareResult(ctx context.Context, scalingFactor osmomath.Dec, opts ...domain.PrepareResultOption) ([]domain.SplitRoute, osmomath.Dec, error) {
+	options := domain.PrepareResultOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	totalAmountIn := q.AmountIn.Amount.ToLegacyDec()
 	totalFeeAcrossRoutes := osmomath.ZeroDec()
 
@@ -45,7 +66,31 @@ func (q *quoteImpl) PrepareResult(ctx context.Context, scalingFactor osmomath.De
 
 	resultRoutes := make([]domain.SplitRoute, 0, len(q.Route))
 
+	// Validate the routes upfront so that the quote is never partially prepared.
+	for i, curRoute := range q.Route {
+		if err := validateNoNilPools(curRoute); err != nil {
+			return nil, osmomath.Dec{}, fmt.Errorf("route (%d): %w", i, err)
+		}
+	}
+
+	// The effective spot price only contributes to the price impact so it is skipped if the price impact is reused.
+	isPriceImpactReused := !options.DryRun && q.isPriceImpactComputed
+	poolOpts := opts
+	if isPriceImpactReused {
+		poolOpts = append(append([]domain.PrepareResultOption(nil), opts...), domain.WithDryRun())
+	}
+
+	// Resolve the gas costs once per quote rather than per pool.
+	poolTypeGasCosts := domain.DefaultPoolTypeGasCosts()
+	for poolType, gasCost := range options.PoolTypeGasCosts {
+		poolTypeGasCosts[poolType] = gasCost
+	}
+
+	estimatedGas := uint64(0)
+
 	for _, curRoute := range q.Route {
+		estimatedGas += estimateRouteGas(curRoute, poolTypeGasCosts)
+
 		routeTotalFee := osmomath.ZeroDec()
 		routeAmountInFraction := curRoute.GetAmountIn().ToLegacyDec().Quo(totalAmountIn)
 
@@ -66,7 +111,7 @@ func (q *quoteImpl) PrepareResult(ctx context.Context, scalingFactor osmomath.De
 		totalFeeAcrossRoutes.AddMut(routeTotalFee.MulMut(routeAmountInFraction))
 
 		amountInFraction := q.AmountIn.Amount.ToLegacyDec().MulMut(routeAmountInFraction).TruncateInt()
-		newPools, routeSpotPriceInBaseOutQuote, effectiveSpotPriceInBaseOutQuote, err := curRoute.PrepareResultPools(ctx, sdk.NewCoin(q.AmountIn.Denom, amountInFraction))
+		newPools, routeSpotPriceInBaseOutQuote, effectiveSpotPriceInBaseOutQuote, err := curRoute.PrepareResultPools(ctx, sdk.NewCoin(q.AmountIn.Denom, amountInFraction), poolOpts...)
 		if err != nil {
 			return nil, osmomath.Dec{}, err
 		}
@@ -85,17 +130,46 @@ func (q *quoteImpl) PrepareResult(ctx context.Context, scalingFactor osmomath.De
 	}
 
 	// Calculate price impact
-	if !totalSpotPriceInBaseOutQuote.IsZero() {
+	if options.DryRun {
+		q.PriceImpact = osmomath.ZeroDec()
+	} else if !isPriceImpactReused && !totalSpotPriceInBaseOutQuote.IsZero() {
 		q.PriceImpact = totalEffectiveSpotPriceInBaseOutQuote.Quo(totalSpotPriceInBaseOutQuote).SubMut(one)
 	}
 
 	q.EffectiveFee = totalFeeAcrossRoutes
 	q.Route = resultRoutes
 	q.InBaseOutQuoteSpotPrice = totalSpotPriceInBaseOutQuote
+	q.EffectivePriceSkipped = options.DryRun
+	q.EstimatedGas = estimatedGas
 
 	return q.Route, q.EffectiveFee, nil
 }
 
+// estimateRouteGas returns the estimated gas cost of executing the given route as the sum of the gas costs
+// of its pools by their pool types. The pool types missing from the given gas costs are free.
+func estimateRouteGas(route domain.SplitRoute, poolTypeGasCosts map[poolmanagertypes.PoolType]uint64) uint64 {
+	routeGas := uint64(0)
+	for _, pool := range route.GetPools() {
+		routeGas += poolTypeGasCosts[pool.GetType()]
+	}
+	return routeGas
+}
+
+// validateNoNilPools returns domain.ErrNilPoolInRoute if the given route is nil or contains a nil pool.
+func validateNoNilPools(splitRoute domain.SplitRoute) error {
+	if splitRoute == nil {
+		return domain.ErrNilPoolInRoute
+	}
+
+	for i, pool := range splitRoute.GetPools() {
+		if pool == nil {
+			return fmt.Errorf("%w at index (%d)", domain.ErrNilPoolInRoute, i)
+		}
+	}
+
+	return nil
+}
+
 // GetAmountIn implements Quote.
 func (q *quoteImpl) GetAmountIn() sdk.Coin {
 	return q.AmountIn
@@ -123,7 +197,8 @@ func (q *quoteImpl) String() string {
 	builder.WriteString(fmt.Sprintf("Quote: %s in for %s out \n", q.AmountIn, q.AmountOut))
 
 	for _, route := range q.Route {
-		builder.WriteString(route.String())
+		builder.WriteString(domain.FormatSplitRoute(route, q.AmountIn.Denom, q.AmountIn.Amount))
+		builder.WriteString("\n")
 	}
 
 	return builder.String()
@@ -133,3 +208,18 @@ func (q *quoteImpl) String() string {
 func (q *quoteImpl) GetPriceImpact() osmomath.Dec {
 	return q.PriceImpact
 }
+
+// GetEstimatedGas implements domain.Quote.
+func (q *quoteImpl) GetEstimatedGas() uint64 {
+	return q.EstimatedGas
+}
+
+// IsEffectivePriceSkipped implements domain.Quote.
+func (q *quoteImpl) IsEffectivePriceSkipped() bool {
+	return q.EffectivePriceSkipped
+}
+
+// IsRouteSearchTruncated implements domain.Quote.
+func (q *quoteImpl) IsRouteSearchTruncated() bool {
+	return q.RouteSearchTruncated
+}
@@ -315,6 +315,189 @@ func (s *RouterTestSuite) TestPrepareResult_PriceImpact() {
 	s.Require().Equal(expectedPriceImpact.String(), testQuote.GetPriceImpact().String())
 }
 
+// countingRoutablePool wraps a mock pool to count the CalculateTokenOutByTokenIn calls.
+type countingRoutablePool struct {
+	*mocks.MockRoutablePool
+
+	calculateTokenOutCalls int
+}
+
+// CalculateTokenOutByTokenIn implements sqsdomain.RoutablePool.
+func (p *countingRoutablePool) CalculateTokenOutByTokenIn(ctx context.Context, tokenIn sdk.Coin) (sdk.Coin, error) {
+	p.calculateTokenOutCalls++
+	return p.MockRoutablePool.CalculateTokenOutByTokenIn(ctx, tokenIn)
+}
+
+// Validates that PrepareResult with WithDryRun does not swap through the pools
+// and returns the spot price before swap with the effective price flagged as skipped.
+func (s *RouterTestSuite) TestPrepareResult_DryRun() {
+	tests := map[string]struct {
+		opts []domain.PrepareResultOption
+
+		expectedCalculateTokenOutCalls int
+		expectedEffectivePriceSkipped  bool
+	}{
+		"default recomputes the effective price": {
+			expectedCalculateTokenOutCalls: 1,
+		},
+		"dry run skips the effective price": {
+			opts: []domain.PrepareResultOption{domain.WithDryRun()},
+
+			expectedCalculateTokenOutCalls: 0,
+			expectedEffectivePriceSkipped:  true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		s.Run(name, func() {
+			// ETH -> USDC -> USDT over CosmWasm pools with the spot price of one.
+			poolOne := &countingRoutablePool{MockRoutablePool: &mocks.MockRoutablePool{ID: 1, PoolType: poolmanagertypes.CosmWasm, TokenOutDenom: USDC, TakerFee: osmomath.ZeroDec(), SpreadFactor: osmomath.ZeroDec()}}
+			poolTwo := &countingRoutablePool{MockRoutablePool: &mocks.MockRoutablePool{ID: 2, PoolType: poolmanagertypes.CosmWasm, TokenOutDenom: USDT, TakerFee: osmomath.ZeroDec(), SpreadFactor: osmomath.ZeroDec()}}
+
+			testQuote := &usecase.QuoteImpl{
+				AmountIn:  sdk.NewCoin(ETH, totalInAmount),
+				AmountOut: totalInAmount,
+				Route: []domain.SplitRoute{
+					&usecase.RouteWithOutAmount{
+						RouteImpl: route.RouteImpl{
+							Pools: []sqsdomain.RoutablePool{poolOne, poolTwo},
+						},
+						InAmount:  totalInAmount,
+						OutAmount: totalInAmount,
+					},
+				},
+			}
+
+			// System under test.
+			_, _, err := testQuote.PrepareResult(context.TODO(), defaultSpotPriceScalingFactor, tc.opts...)
+			s.Require().NoError(err)
+
+			s.Require().Equal(tc.expectedCalculateTokenOutCalls, poolOne.calculateTokenOutCalls)
+			s.Require().Equal(tc.expectedCalculateTokenOutCalls, poolTwo.calculateTokenOutCalls)
+
+			s.Require().Equal(tc.expectedEffectivePriceSkipped, testQuote.IsEffectivePriceSkipped())
+			s.Require().Equal(osmomath.OneDec().String(), testQuote.InBaseOutQuoteSpotPrice.String())
+			s.Require().True(testQuote.GetPriceImpact().IsZero())
+		})
+	}
+}
+
+// Validates that PrepareResult estimates the gas cost of the quote as the sum of the gas costs
+// of the pools across the routes, defaulting the pool types missing from the configured gas costs.
+func (s *RouterTestSuite) TestPrepareResult_EstimatedGas() {
+	newCosmWasmPool := func(id uint64, tokenOutDenom string) *mocks.MockRoutablePool {
+		return &mocks.MockRoutablePool{ID: id, PoolType: poolmanagertypes.CosmWasm, TokenOutDenom: tokenOutDenom, TakerFee: osmomath.ZeroDec(), SpreadFactor: osmomath.ZeroDec()}
+	}
+
+	newRoute := func(amountIn osmomath.Int, pools ...sqsdomain.RoutablePool) domain.SplitRoute {
+		return &usecase.RouteWithOutAmount{
+			RouteImpl: route.RouteImpl{Pools: pools},
+			InAmount:  amountIn,
+			OutAmount: amountIn,
+		}
+	}
+
+	defaultCosmWasmGas := domain.DefaultPoolTypeGasCosts()[poolmanagertypes.CosmWasm]
+
+	tests := map[string]struct {
+		routes           []domain.SplitRoute
+		poolTypeGasCosts map[poolmanagertypes.PoolType]uint64
+
+		expectedEstimatedGas uint64
+	}{
+		"single hop": {
+			routes: []domain.SplitRoute{newRoute(totalInAmount, newCosmWasmPool(1, USDC))},
+
+			expectedEstimatedGas: defaultCosmWasmGas,
+		},
+		"multi hop": {
+			routes: []domain.SplitRoute{newRoute(totalInAmount, newCosmWasmPool(1, USDC), newCosmWasmPool(2, USDT))},
+
+			expectedEstimatedGas: 2 * defaultCosmWasmGas,
+		},
+		"split routes": {
+			routes: []domain.SplitRoute{
+				newRoute(totalInAmount.QuoRaw(2), newCosmWasmPool(1, USDT)),
+				newRoute(totalInAmount.QuoRaw(2), newCosmWasmPool(2, USDC), newCosmWasmPool(3, USDT)),
+			},
+
+			expectedEstimatedGas: 3 * defaultCosmWasmGas,
+		},
+		"configured gas costs": {
+			routes:           []domain.SplitRoute{newRoute(totalInAmount, newCosmWasmPool(1, USDC), newCosmWasmPool(2, USDT))},
+			poolTypeGasCosts: map[poolmanagertypes.PoolType]uint64{poolmanagertypes.CosmWasm: 1_000},
+
+			expectedEstimatedGas: 2_000,
+		},
+		"gas costs missing the pool type": {
+			routes:           []domain.SplitRoute{newRoute(totalInAmount, newCosmWasmPool(1, USDC))},
+			poolTypeGasCosts: map[poolmanagertypes.PoolType]uint64{poolmanagertypes.Balancer: 1_000},
+
+			expectedEstimatedGas: defaultCosmWasmGas,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		s.Run(name, func() {
+			testQuote := &usecase.QuoteImpl{
+				AmountIn:  sdk.NewCoin(ETH, totalInAmount),
+				AmountOut: totalInAmount,
+				Route:     tc.routes,
+			}
+
+			// System under test.
+			_, _, err := testQuote.PrepareResult(context.TODO(), defaultSpotPriceScalingFactor, domain.WithPoolTypeGasCosts(tc.poolTypeGasCosts))
+			s.Require().NoError(err)
+
+			s.Require().Equal(tc.expectedEstimatedGas, testQuote.GetEstimatedGas())
+		})
+	}
+}
+
+// Validates that PrepareResult returns ErrNilPoolInRoute rather than panicking
+// if a route contains a nil pool and that the quote is left unmutated.
+func (s *RouterTestSuite) TestPrepareResult_NilPool() {
+	validPool := mocks.WithTokenOutDenom(DefaultMockPool, USDC)
+
+	routes := []domain.SplitRoute{
+		&usecase.RouteWithOutAmount{
+			RouteImpl: route.RouteImpl{
+				Pools: []sqsdomain.RoutablePool{validPool},
+			},
+			InAmount:  totalInAmount.QuoRaw(2),
+			OutAmount: totalOutAmount.QuoRaw(2),
+		},
+		&usecase.RouteWithOutAmount{
+			RouteImpl: route.RouteImpl{
+				Pools: []sqsdomain.RoutablePool{validPool, nil},
+			},
+			InAmount:  totalInAmount.QuoRaw(2),
+			OutAmount: totalOutAmount.QuoRaw(2),
+		},
+	}
+
+	testQuote := &usecase.QuoteImpl{
+		AmountIn:     sdk.NewCoin(ETH, totalInAmount),
+		AmountOut:    totalOutAmount,
+		Route:        routes,
+		EffectiveFee: osmomath.ZeroDec(),
+	}
+
+	// System under test.
+	_, _, err := testQuote.PrepareResult(context.TODO(), defaultSpotPriceScalingFactor)
+	s.Require().ErrorIs(err, domain.ErrNilPoolInRoute)
+
+	// The quote is not partially prepared.
+	s.Require().Equal(routes, testQuote.GetRoute())
+	s.Require().Equal(osmomath.ZeroDec().String(), testQuote.GetEffectiveSpreadFactor().String())
+
+	// The nil pool is rejected by the route directly too.
+	_, _, _, err = routes[1].PrepareResultPools(context.TODO(), sdk.NewCoin(ETH, totalInAmount))
+	s.Require().ErrorIs(err, domain.ErrNilPoolInRoute)
+}
+
 // validateRoutes validates that the given routes are equal.
 // Specifically, validates:
 // - Pools
@@ -13,6 +13,7 @@ import (
 	"github.com/osmosis-labs/sqs/router/usecase/pools"
 
 	"github.com/osmosis-labs/osmosis/osmomath"
+	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
 )
 
 var _ domain.Route = &RouteImpl{}
@@ -25,6 +26,9 @@ type RouteImpl struct {
 	// The reason for this is that making network requests to chain is expensive.
 	// As a result, we want to minimize the number of requests we make.
 	HasGeneralizedCosmWasmPool bool "json:\"has-cw-pool\""
+	// TransferFees are the fees taken by the fee-on-transfer denoms
+	// whenever they enter or exit a pool in the route.
+	TransferFees domain.TransferFees "json:\"-\""
 }
 
 var (
@@ -50,12 +54,28 @@ var (
 // Note that it mutates the route.
 // Returns spot price before swap and the effective spot price
 // with token in as base and token out as quote.
-func (r RouteImpl) PrepareResultPools(ctx context.Context, tokenIn sdk.Coin) ([]sqsdomain.RoutablePool, osmomath.Dec, osmomath.Dec, error) {
+// With domain.WithDryRun, the pools are not swapped through so that no network call
+// is made by generalized CosmWasm pools. The spot price before swap is returned in place
+// of the effective spot price.
+// Returns domain.ErrNilPoolInRoute if the route contains a nil pool.
+func (r RouteImpl) PrepareResultPools(ctx context.Context, tokenIn sdk.Coin, opts ...domain.PrepareResultOption) ([]sqsdomain.RoutablePool, osmomath.Dec, osmomath.Dec, error) {
+	options := domain.PrepareResultOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var (
 		routeSpotPriceInBaseOutQuote     = osmomath.OneDec()
 		effectiveSpotPriceInBaseOutQuote = osmomath.OneDec()
 	)
 
+	// Validate the pools upfront since the error handling below might access any of them.
+	for i, pool := range r.Pools {
+		if pool == nil {
+			return nil, osmomath.Dec{}, osmomath.Dec{}, fmt.Errorf("%w at index (%d)", domain.ErrNilPoolInRoute, i)
+		}
+	}
+
 	newPools := make([]sqsdomain.RoutablePool, 0, len(r.Pools))
 
 	for _, pool := range r.Pools {
@@ -71,19 +91,35 @@ func (r RouteImpl) PrepareResultPools(ctx context.Context, tokenIn sdk.Coin) ([]
 			spotPriceErrorResultCounter.WithLabelValues(tokenIn.Denom, pool.GetTokenOutDenom(), routeTokenOutDenom).Inc()
 		}
 
-		// Charge taker fee
-		tokenIn = pool.ChargeTakerFeeExactIn(tokenIn)
+		// Note, in the future we may want to increase the precision of the spot price
+		routeSpotPriceInBaseOutQuote.MulMut(spotPriceInBaseOutQuote.Dec())
 
-		tokenOut, err := pool.CalculateTokenOutByTokenIn(ctx, tokenIn)
-		if err != nil {
-			return nil, osmomath.Dec{}, osmomath.Dec{}, err
-		}
+		var tokenOut sdk.Coin
+		if options.DryRun {
+			// Only the denom is needed to compute the spot price of the next pool.
+			tokenOut = sdk.Coin{Denom: pool.GetTokenOutDenom(), Amount: tokenIn.Amount}
+		} else {
+			// The effective price is measured from the amount before the transfer fee
+			// so that the fee shows up in the price impact.
+			tokenInBeforeTransferFee := tokenIn
 
-		// Update effective spot price
-		effectiveSpotPriceInBaseOutQuote.MulMut(tokenOut.Amount.ToLegacyDec().QuoMut(tokenIn.Amount.ToLegacyDec()))
+			// Charge transfer fee on entering the pool
+			tokenIn = r.TransferFees.ChargeTransferFee(tokenIn)
 
-		// Note, in the future we may want to increase the precision of the spot price
-		routeSpotPriceInBaseOutQuote.MulMut(spotPriceInBaseOutQuote.Dec())
+			// Charge taker fee
+			tokenIn = pool.ChargeTakerFeeExactIn(tokenIn)
+
+			tokenOut, err = pool.CalculateTokenOutByTokenIn(ctx, tokenIn)
+			if err != nil {
+				return nil, osmomath.Dec{}, osmomath.Dec{}, err
+			}
+
+			// Charge transfer fee on exiting the pool
+			tokenOut = r.TransferFees.ChargeTransferFee(tokenOut)
+
+			// Update effective spot price
+			effectiveSpotPriceInBaseOutQuote.MulMut(tokenOut.Amount.ToLegacyDec().QuoMut(tokenInBeforeTransferFee.Amount.ToLegacyDec()))
+		}
 
 		newPool := pools.NewRoutableResultPool(
 			pool.GetId(),
@@ -98,15 +134,48 @@ func (r RouteImpl) PrepareResultPools(ctx context.Context, tokenIn sdk.Coin) ([]
 
 		tokenIn = tokenOut
 	}
+
+	if options.DryRun {
+		effectiveSpotPriceInBaseOutQuote = routeSpotPriceInBaseOutQuote.Clone()
+	}
+
 	return newPools, routeSpotPriceInBaseOutQuote, effectiveSpotPriceInBaseOutQuote, nil
 }
 
+// PrepareResultPoolsWithLiquidity is equivalent to PrepareResultPools
+// but also returns the liquidity of each pool in the route denominated in OSMO.
+// The liquidity is computed from the pool balances by converting each balance via coinOSMOValue.
+// Pools that do not expose their balances (i.e. do not implement domain.RoutableResultPool)
+// have zero liquidity.
+// Returns error if fails to prepare the result pools or to convert any of the balances.
+func (r RouteImpl) PrepareResultPoolsWithLiquidity(ctx context.Context, tokenIn sdk.Coin, coinOSMOValue domain.CoinOSMOValueFunc) ([]sqsdomain.RoutablePool, osmomath.Dec, osmomath.Dec, []osmomath.Int, error) {
+	poolsOSMOLiquidity := make([]osmomath.Int, 0, len(r.Pools))
+
+	// Compute liquidity from the original pools since the result pools do not retain balances.
+	for _, pool := range r.Pools {
+		poolOSMOLiquidity, err := domain.ComputePoolOSMOLiquidity(pool, coinOSMOValue)
+		if err != nil {
+			return nil, osmomath.Dec{}, osmomath.Dec{}, nil, err
+		}
+
+		poolsOSMOLiquidity = append(poolsOSMOLiquidity, poolOSMOLiquidity)
+	}
+
+	newPools, routeSpotPriceInBaseOutQuote, effectiveSpotPriceInBaseOutQuote, err := r.PrepareResultPools(ctx, tokenIn)
+	if err != nil {
+		return nil, osmomath.Dec{}, osmomath.Dec{}, nil, err
+	}
+
+	return newPools, routeSpotPriceInBaseOutQuote, effectiveSpotPriceInBaseOutQuote, poolsOSMOLiquidity, nil
+}
+
 // GetPools implements Route.
 func (r *RouteImpl) GetPools() []sqsdomain.RoutablePool {
 	return r.Pools
 }
 
 // CalculateTokenOutByTokenIn implements Route.
+// The transfer fees of the route are deducted whenever a fee-on-transfer denom enters or exits a pool.
 func (r *RouteImpl) CalculateTokenOutByTokenIn(ctx context.Context, tokenIn sdk.Coin) (tokenOut sdk.Coin, err error) {
 	defer func() {
 		// TODO: cover this by test
@@ -117,6 +186,9 @@ func (r *RouteImpl) CalculateTokenOutByTokenIn(ctx context.Context, tokenIn sdk.
 	}()
 
 	for _, pool := range r.Pools {
+		// Charge transfer fee on entering the pool
+		tokenIn = r.TransferFees.ChargeTransferFee(tokenIn)
+
 		// Charge taker fee
 		tokenIn = pool.ChargeTakerFeeExactIn(tokenIn)
 		tokenInAmt := tokenIn.Amount.ToLegacyDec()
@@ -130,6 +202,9 @@ func (r *RouteImpl) CalculateTokenOutByTokenIn(ctx context.Context, tokenIn sdk.
 			return sdk.Coin{}, err
 		}
 
+		// Charge transfer fee on exiting the pool
+		tokenOut = r.TransferFees.ChargeTransferFee(tokenOut)
+
 		tokenIn = tokenOut
 	}
 
@@ -164,3 +239,16 @@ func (r *RouteImpl) GetTokenOutDenom() string {
 func (r *RouteImpl) ContainsGeneralizedCosmWasmPool() bool {
 	return r.HasGeneralizedCosmWasmPool
 }
+
+// ContainsPoolType implements domain.Route.
+// Unlike ContainsGeneralizedCosmWasmPool, it scans the pools so that it does not
+// distinguish the generalized cosmwasm pools from the other cosmwasm pools.
+func (r *RouteImpl) ContainsPoolType(poolType poolmanagertypes.PoolType) bool {
+	for _, pool := range r.Pools {
+		if pool.GetType() == poolType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -7,6 +7,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/osmosis-labs/sqs/domain"
 	"github.com/osmosis-labs/sqs/domain/mocks"
 	"github.com/osmosis-labs/sqs/router/usecase/pools"
 	"github.com/osmosis-labs/sqs/router/usecase/route"
@@ -216,6 +217,177 @@ func (s *RouterTestSuite) TestPrepareResultPools() {
 	}
 }
 
+// Validates that PrepareResultPoolsWithLiquidity returns the liquidity of each pool
+// converted via the given callback in addition to the result pools.
+func (s *RouterTestSuite) TestPrepareResultPoolsWithLiquidity() {
+	s.Setup()
+
+	balancerPoolID := s.PrepareBalancerPoolWithCoins(sdk.NewCoins(
+		sdk.NewCoin(DenomOne, sdk.NewInt(2_000_000_000)),
+		sdk.NewCoin(DenomTwo, sdk.NewInt(1_000_000_000)),
+	)...)
+
+	balancerPool, err := s.App.PoolManagerKeeper.GetPool(s.Ctx, balancerPoolID)
+	s.Require().NoError(err)
+
+	poolWithBalances := mocks.WithChainPoolModel(mocks.WithTokenOutDenom(DefaultPool, DenomOne), balancerPool)
+	poolWithBalances.Balances = sdk.NewCoins(
+		sdk.NewCoin(DenomOne, sdk.NewInt(2_000_000)),
+		sdk.NewCoin(DenomTwo, sdk.NewInt(1_000_000)),
+	)
+
+	testRoute := WithRoutePools(emptyRoute, []sqsdomain.RoutablePool{poolWithBalances})
+
+	// Descale by 10^6 and value each denom at 2 OSMO.
+	coinOSMOValue := func(coin sdk.Coin) (osmomath.Int, error) {
+		return coin.Amount.QuoRaw(1_000_000).MulRaw(2), nil
+	}
+
+	actualPools, _, _, poolsOSMOLiquidity, err := testRoute.PrepareResultPoolsWithLiquidity(context.TODO(), sdk.NewCoin(DenomTwo, DefaultAmt0), coinOSMOValue)
+	s.Require().NoError(err)
+
+	s.Require().Len(actualPools, 1)
+	s.Require().Equal(poolWithBalances.GetId(), actualPools[0].GetId())
+
+	// (2 + 1) * 2 = 6
+	s.Require().Len(poolsOSMOLiquidity, 1)
+	s.Require().Equal(osmomath.NewInt(6).String(), poolsOSMOLiquidity[0].String())
+}
+
+// Validates that CalculateTokenOutByTokenIn deducts the transfer fees whenever
+// a fee-on-transfer denom enters or exits a pool in the route.
+func (s *RouterTestSuite) TestCalculateTokenOutByTokenIn_TransferFees() {
+	// Pass-through pools with no taker fee isolate the transfer fees.
+	newPassThroughPool := func(id uint64, tokenOutDenom string) *mocks.MockRoutablePool {
+		return &mocks.MockRoutablePool{
+			ID:            id,
+			PoolType:      poolmanagertypes.CosmWasm,
+			TokenOutDenom: tokenOutDenom,
+			TakerFee:      osmomath.ZeroDec(),
+		}
+	}
+
+	// DenomOne -> pool 1 -> DenomTwo -> pool 2 -> DenomThree
+	routePools := []sqsdomain.RoutablePool{
+		newPassThroughPool(1, DenomTwo),
+		newPassThroughPool(2, DenomThree),
+	}
+
+	tests := map[string]struct {
+		tokenIn      sdk.Coin
+		transferFees []domain.RouterOption
+
+		expectedTokenOut sdk.Coin
+	}{
+		"no transfer fees": {
+			tokenIn: sdk.NewCoin(DenomOne, osmomath.NewInt(1_000_000)),
+
+			expectedTokenOut: sdk.NewCoin(DenomThree, osmomath.NewInt(1_000_000)),
+		},
+		"fee on token in is charged on entering the first pool": {
+			tokenIn:      sdk.NewCoin(DenomOne, osmomath.NewInt(1_000_000)),
+			transferFees: []domain.RouterOption{domain.WithTransferFee(DenomOne, 100)},
+
+			expectedTokenOut: sdk.NewCoin(DenomThree, osmomath.NewInt(990_000)),
+		},
+		"fee on intermediate denom is charged on exiting and entering a pool": {
+			tokenIn:      sdk.NewCoin(DenomOne, osmomath.NewInt(1_000_000)),
+			transferFees: []domain.RouterOption{domain.WithTransferFee(DenomTwo, 100)},
+
+			// 1_000_000 * 0.99 * 0.99
+			expectedTokenOut: sdk.NewCoin(DenomThree, osmomath.NewInt(980_100)),
+		},
+		"fee on token out is charged on exiting the last pool": {
+			tokenIn:      sdk.NewCoin(DenomOne, osmomath.NewInt(1_000_000)),
+			transferFees: []domain.RouterOption{domain.WithTransferFee(DenomThree, 100)},
+
+			expectedTokenOut: sdk.NewCoin(DenomThree, osmomath.NewInt(990_000)),
+		},
+		"fees on multiple denoms": {
+			tokenIn:      sdk.NewCoin(DenomOne, osmomath.NewInt(1_000_000)),
+			transferFees: []domain.RouterOption{domain.WithTransferFee(DenomOne, 100), domain.WithTransferFee(DenomThree, 50)},
+
+			// 1_000_000 * 0.99 * 0.995
+			expectedTokenOut: sdk.NewCoin(DenomThree, osmomath.NewInt(985_050)),
+		},
+		"fee is rounded up": {
+			tokenIn:      sdk.NewCoin(DenomOne, osmomath.NewInt(1_000_001)),
+			transferFees: []domain.RouterOption{domain.WithTransferFee(DenomOne, 100)},
+
+			// ceil(10_000.01) = 10_001
+			expectedTokenOut: sdk.NewCoin(DenomThree, osmomath.NewInt(990_000)),
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		s.Run(name, func() {
+			options := domain.RouterOptions{}
+			for _, opt := range tc.transferFees {
+				opt(&options)
+			}
+
+			testRoute := WithRoutePools(emptyRoute, routePools)
+			testRoute.TransferFees = options.TransferFees
+
+			tokenOut, err := testRoute.CalculateTokenOutByTokenIn(context.TODO(), tc.tokenIn)
+			s.Require().NoError(err)
+			s.Require().Equal(tc.expectedTokenOut.String(), tokenOut.String())
+
+			// The fees only ever decrease the amount out.
+			noFeeRoute := WithRoutePools(emptyRoute, routePools)
+			noFeeTokenOut, err := noFeeRoute.CalculateTokenOutByTokenIn(context.TODO(), tc.tokenIn)
+			s.Require().NoError(err)
+			s.Require().True(tokenOut.Amount.LTE(noFeeTokenOut.Amount))
+		})
+	}
+}
+
+// Validates that the effective spot price of PrepareResultPools is measured from the amount in
+// before the transfer fee so that the entry fee shows up in the price impact.
+func (s *RouterTestSuite) TestPrepareResultPools_TransferFees() {
+	testRoute := WithRoutePools(emptyRoute, []sqsdomain.RoutablePool{
+		&mocks.MockRoutablePool{
+			ID:            1,
+			PoolType:      poolmanagertypes.CosmWasm,
+			TokenOutDenom: DenomTwo,
+			TakerFee:      osmomath.ZeroDec(),
+		},
+	})
+
+	options := domain.RouterOptions{}
+	domain.WithTransferFee(DenomOne, 100)(&options)
+	testRoute.TransferFees = options.TransferFees
+
+	_, _, effectiveSpotPriceInBaseOutQuote, err := testRoute.PrepareResultPools(context.TODO(), sdk.NewCoin(DenomOne, osmomath.NewInt(1_000_000)))
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.MustNewDecFromStr("0.99").String(), effectiveSpotPriceInBaseOutQuote.String())
+}
+
+// Validates that ContainsPoolType detects the pool types of routes with mixed pool types.
+func (s *RouterTestSuite) TestContainsPoolType() {
+	newPoolOfType := func(id uint64, poolType poolmanagertypes.PoolType) *mocks.MockRoutablePool {
+		return &mocks.MockRoutablePool{
+			ID:       id,
+			PoolType: poolType,
+		}
+	}
+
+	mixedRoute := WithRoutePools(emptyRoute, []sqsdomain.RoutablePool{
+		newPoolOfType(1, poolmanagertypes.Balancer),
+		newPoolOfType(2, poolmanagertypes.Concentrated),
+		newPoolOfType(3, poolmanagertypes.CosmWasm),
+	})
+
+	s.Require().True(mixedRoute.ContainsPoolType(poolmanagertypes.Balancer))
+	s.Require().True(mixedRoute.ContainsPoolType(poolmanagertypes.Concentrated))
+	s.Require().True(mixedRoute.ContainsPoolType(poolmanagertypes.CosmWasm))
+	s.Require().False(mixedRoute.ContainsPoolType(poolmanagertypes.Stableswap))
+
+	// Empty route contains no pool types.
+	s.Require().False(emptyRoute.ContainsPoolType(poolmanagertypes.Balancer))
+}
+
 func WithRoutePools(r route.RouteImpl, pools []sqsdomain.RoutablePool) route.RouteImpl {
 	return routertesting.WithRoutePools(r, pools)
 }
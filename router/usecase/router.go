@@ -26,9 +26,15 @@ const (
 	noTotalValueLockedError = ""
 )
 
-// filterPoolsByMinLiquidity filters the given pools by the minimum liquidity.
+// FilterPoolsByMinLiquidity filters the given pools by the minimum liquidity.
+// The liquidity of a pool is its total value locked computed at ingest rather than
+// domain.ComputePoolOSMOLiquidity(...) since the pools are filtered prior to pricing them.
 func FilterPoolsByMinLiquidity(pools []sqsdomain.PoolI, minLiquidity int) []sqsdomain.PoolI {
-	minLiquidityInt := osmomath.NewInt(int64(minLiquidity))
+	return filterPoolsByMinLiquidityInt(pools, osmomath.NewInt(int64(minLiquidity)))
+}
+
+// filterPoolsByMinLiquidityInt filters the given pools by the minimum liquidity as in FilterPoolsByMinLiquidity(...).
+func filterPoolsByMinLiquidityInt(pools []sqsdomain.PoolI, minLiquidityInt osmomath.Int) []sqsdomain.PoolI {
 	filteredPools := make([]sqsdomain.PoolI, 0, len(pools))
 	for _, pool := range pools {
 		if pool.GetTotalValueLockedUSDC().GTE(minLiquidityInt) {
@@ -38,6 +44,83 @@ func FilterPoolsByMinLiquidity(pools []sqsdomain.PoolI, minLiquidity int) []sqsd
 	return filteredPools
 }
 
+// FilterPoolsByType filters the given pools by the allowed pool types.
+// If no pool types are allowed explicitly, returns the given pools as is.
+func FilterPoolsByType(pools []sqsdomain.PoolI, allowedPoolTypes []poolmanagertypes.PoolType) []sqsdomain.PoolI {
+	if len(allowedPoolTypes) == 0 {
+		return pools
+	}
+
+	allowedPoolTypesMap := make(map[poolmanagertypes.PoolType]struct{}, len(allowedPoolTypes))
+	for _, poolType := range allowedPoolTypes {
+		allowedPoolTypesMap[poolType] = struct{}{}
+	}
+
+	filteredPools := make([]sqsdomain.PoolI, 0, len(pools))
+	for _, pool := range pools {
+		if _, ok := allowedPoolTypesMap[pool.GetType()]; ok {
+			filteredPools = append(filteredPools, pool)
+		}
+	}
+	return filteredPools
+}
+
+// FilterPoolsByID filters out the pools with the given excluded IDs.
+// If no excluded IDs are given, returns the given pools as is.
+func FilterPoolsByID(pools []sqsdomain.PoolI, excludedPoolIDs []uint64) []sqsdomain.PoolI {
+	if len(excludedPoolIDs) == 0 {
+		return pools
+	}
+
+	excludedPoolIDsMap := make(map[uint64]struct{}, len(excludedPoolIDs))
+	for _, poolID := range excludedPoolIDs {
+		excludedPoolIDsMap[poolID] = struct{}{}
+	}
+
+	filteredPools := make([]sqsdomain.PoolI, 0, len(pools))
+	for _, pool := range pools {
+		if _, ok := excludedPoolIDsMap[pool.GetId()]; !ok {
+			filteredPools = append(filteredPools, pool)
+		}
+	}
+	return filteredPools
+}
+
+// FilterCandidateRoutesByIntermediateDenom filters the given candidate routes by whether they
+// pass through the given intermediate denom, i.e. whether any pool but the last swaps to it.
+// If no intermediate denom is given, returns the given candidate routes as is.
+func FilterCandidateRoutesByIntermediateDenom(candidateRoutes sqsdomain.CandidateRoutes, intermediateDenom string) sqsdomain.CandidateRoutes {
+	if intermediateDenom == "" {
+		return candidateRoutes
+	}
+
+	filteredCandidateRoutes := sqsdomain.CandidateRoutes{
+		Routes:        make([]sqsdomain.CandidateRoute, 0, len(candidateRoutes.Routes)),
+		UniquePoolIDs: make(map[uint64]struct{}),
+	}
+
+	for _, candidateRoute := range candidateRoutes.Routes {
+		isThroughIntermediateDenom := false
+		for i := 0; i < len(candidateRoute.Pools)-1; i++ {
+			if candidateRoute.Pools[i].TokenOutDenom == intermediateDenom {
+				isThroughIntermediateDenom = true
+				break
+			}
+		}
+
+		if !isThroughIntermediateDenom {
+			continue
+		}
+
+		filteredCandidateRoutes.Routes = append(filteredCandidateRoutes.Routes, candidateRoute)
+		for _, pool := range candidateRoute.Pools {
+			filteredCandidateRoutes.UniquePoolIDs[pool.ID] = struct{}{}
+		}
+	}
+
+	return filteredCandidateRoutes
+}
+
 // ValidateAndSortPools filters and sorts the given pools for use in the router
 // according to the given configuration.
 // Filters out pools that have no tvl error set and have zero liquidity.
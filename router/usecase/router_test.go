@@ -13,6 +13,7 @@ import (
 	"github.com/osmosis-labs/sqs/sqsdomain"
 
 	"github.com/osmosis-labs/osmosis/osmomath"
+	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
 )
 
 type RouterTestSuite struct {
@@ -250,3 +251,72 @@ func getPoolIDs(pools []sqsdomain.PoolI) []uint64 {
 	}
 	return sortedPoolIDs
 }
+
+// Validates that FilterPoolsByType only keeps the pools of the allowed types
+// and that no allowed pool types implies no filtering.
+func (s *RouterTestSuite) TestFilterPoolsByType() {
+	mainnetState := s.SetupMainnetState()
+
+	pools := mainnetState.Pools
+
+	// No allowed pool types returns all pools.
+	s.Require().Len(routerusecase.FilterPoolsByType(pools, nil), len(pools))
+
+	allowedPoolTypes := []poolmanagertypes.PoolType{poolmanagertypes.Balancer, poolmanagertypes.Concentrated}
+
+	filteredPools := routerusecase.FilterPoolsByType(pools, allowedPoolTypes)
+	s.Require().NotEmpty(filteredPools)
+	s.Require().Less(len(filteredPools), len(pools))
+
+	for _, pool := range filteredPools {
+		s.Require().Contains(allowedPoolTypes, pool.GetType())
+	}
+}
+
+// Validates that FilterPoolsByID filters out the pools with the excluded IDs
+// and that no excluded IDs implies no filtering.
+func (s *RouterTestSuite) TestFilterPoolsByID() {
+	mainnetState := s.SetupMainnetState()
+
+	pools := mainnetState.Pools
+
+	// No excluded pool IDs returns all pools.
+	s.Require().Len(routerusecase.FilterPoolsByID(pools, nil), len(pools))
+
+	excludedPoolIDs := []uint64{pools[0].GetId(), pools[1].GetId()}
+
+	filteredPools := routerusecase.FilterPoolsByID(pools, excludedPoolIDs)
+	s.Require().Len(filteredPools, len(pools)-len(excludedPoolIDs))
+
+	for _, pool := range filteredPools {
+		s.Require().NotContains(excludedPoolIDs, pool.GetId())
+	}
+}
+
+// Validates that FilterCandidateRoutesByIntermediateDenom only keeps the routes passing through
+// the intermediate denom at a hop other than the last one and that no denom implies no filtering.
+func (s *RouterTestSuite) TestFilterCandidateRoutesByIntermediateDenom() {
+	candidateRoutes := sqsdomain.CandidateRoutes{
+		Routes: []sqsdomain.CandidateRoute{
+			// Direct route.
+			{Pools: []sqsdomain.CandidatePool{{ID: 1, TokenOutDenom: DenomThree}}},
+			// Through the intermediate denom.
+			{Pools: []sqsdomain.CandidatePool{{ID: 2, TokenOutDenom: DenomTwo}, {ID: 3, TokenOutDenom: DenomThree}}},
+			// Through another denom.
+			{Pools: []sqsdomain.CandidatePool{{ID: 4, TokenOutDenom: DenomFour}, {ID: 5, TokenOutDenom: DenomThree}}},
+			// Through the intermediate denom at the middle hop.
+			{Pools: []sqsdomain.CandidatePool{{ID: 4, TokenOutDenom: DenomFour}, {ID: 6, TokenOutDenom: DenomTwo}, {ID: 3, TokenOutDenom: DenomThree}}},
+		},
+		UniquePoolIDs: map[uint64]struct{}{1: {}, 2: {}, 3: {}, 4: {}, 5: {}, 6: {}},
+	}
+
+	// No intermediate denom returns all routes.
+	s.Require().Equal(candidateRoutes, routerusecase.FilterCandidateRoutesByIntermediateDenom(candidateRoutes, ""))
+
+	filteredRoutes := routerusecase.FilterCandidateRoutesByIntermediateDenom(candidateRoutes, DenomTwo)
+	s.Require().Equal([]sqsdomain.CandidateRoute{candidateRoutes.Routes[1], candidateRoutes.Routes[3]}, filteredRoutes.Routes)
+	s.Require().Equal(map[uint64]struct{}{2: {}, 3: {}, 4: {}, 6: {}}, filteredRoutes.UniquePoolIDs)
+
+	// The token out denom is not intermediate.
+	s.Require().Empty(routerusecase.FilterCandidateRoutesByIntermediateDenom(candidateRoutes, DenomThree).Routes)
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/osmosis-labs/osmosis/osmomath"
 	"github.com/osmosis-labs/osmosis/osmoutils"
+	cosmwasmpooltypes "github.com/osmosis-labs/osmosis/v24/x/cosmwasmpool/types"
 	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
 	"github.com/osmosis-labs/sqs/domain"
 	"github.com/osmosis-labs/sqs/domain/cache"
@@ -39,8 +40,15 @@ type routerUseCaseImpl struct {
 
 	sortedPoolsMu sync.RWMutex
 	sortedPools   []sqsdomain.PoolI
+	// sortedPoolDenoms is the set of the denoms of the sorted pools.
+	// Guarded by sortedPoolsMu.
+	sortedPoolDenoms map[string]struct{}
 
 	candidateRouteCache *cache.Cache
+
+	// customPoolSpotPriceHandlers maps the CosmWasm pool code IDs to their custom spot price handlers.
+	customPoolSpotPriceHandlersMu sync.RWMutex
+	customPoolSpotPriceHandlers   map[uint64]domain.SpotPriceHandler
 }
 
 const (
@@ -51,6 +59,12 @@ const (
 	noOrderOfMagnitude = ""
 
 	denomSeparatorChar = "|"
+
+	// uosmoPerOSMO is the number of uosmo in one OSMO.
+	uosmoPerOSMO = 1_000_000
+
+	// noRoutingTimeout signifies that the candidate route search is not bounded in time.
+	noRoutingTimeout time.Duration = 0
 )
 
 var (
@@ -85,7 +99,12 @@ func init() {
 }
 
 // NewRouterUsecase will create a new pools use case object
-func NewRouterUsecase(routerRepository routerrepo.RouterRepository, poolsUsecase mvc.PoolsUsecase, config domain.RouterConfig, cosmWasmPoolsConfig domain.CosmWasmPoolRouterConfig, logger log.Logger, rankedRouteCache *cache.Cache, candidateRouteCache *cache.Cache) mvc.RouterUsecase {
+// Returns error if the router config is invalid.
+func NewRouterUsecase(routerRepository routerrepo.RouterRepository, poolsUsecase mvc.PoolsUsecase, config domain.RouterConfig, cosmWasmPoolsConfig domain.CosmWasmPoolRouterConfig, logger log.Logger, rankedRouteCache *cache.Cache, candidateRouteCache *cache.Cache) (mvc.RouterUsecase, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid router config: %w", err)
+	}
+
 	return &routerUseCaseImpl{
 		routerRepository:    routerRepository,
 		poolsUsecase:        poolsUsecase,
@@ -96,9 +115,12 @@ func NewRouterUsecase(routerRepository routerrepo.RouterRepository, poolsUsecase
 		rankedRouteCache:    rankedRouteCache,
 		candidateRouteCache: candidateRouteCache,
 
-		sortedPools:   make([]sqsdomain.PoolI, 0),
-		sortedPoolsMu: sync.RWMutex{},
-	}
+		sortedPools:      make([]sqsdomain.PoolI, 0),
+		sortedPoolDenoms: make(map[string]struct{}),
+		sortedPoolsMu:    sync.RWMutex{},
+
+		customPoolSpotPriceHandlers: make(map[uint64]domain.SpotPriceHandler),
+	}, nil
 }
 
 // GetOptimalQuote returns the optimal quote by estimating the optimal route(s) through pools
@@ -111,9 +133,291 @@ func NewRouterUsecase(routerRepository routerrepo.RouterRepository, poolsUsecase
 // In the future, we will support caching of ranked routes that are constructed from candidate and sorted
 // by the decreasing amount out within an order of magnitude of token in. Similarly, We will also support optimal split caching
 // Returns error if:
+// - tokenIn amount is not positive (NonPositiveTokenInError)
+// - tokenOutDenom is empty or not in any of the pools (UnknownTokenOutDenomError)
 // - fails to estimate direct quotes for ranked routes
 // - fails to retrieve candidate routes
 func (r *routerUseCaseImpl) GetOptimalQuote(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+	if err := r.validateQuoteInputs(tokenIn, tokenOutDenom); err != nil {
+		return nil, err
+	}
+
+	options := r.getRouterOptions(opts...)
+
+	topSingleRouteQuote, rankedRoutes, isSearchTruncated, err := r.rankRoutes(ctx, tokenIn, tokenOutDenom, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if isSearchTruncated {
+		r.logger.Debug("candidate route search truncated by routing timeout", zap.Duration("routing_timeout", options.RoutingTimeout))
+
+		setRouteSearchTruncated(topSingleRouteQuote)
+	}
+
+	if len(rankedRoutes) == 1 || options.MaxSplitRoutes == domain.DisableSplitRoutes {
+		if err := validateQuotePriceImpact(ctx, topSingleRouteQuote, options.MaxQuotePriceImpact); err != nil {
+			return nil, err
+		}
+
+		return topSingleRouteQuote, nil
+	}
+
+	// Filter out generalized cosmWasm pool routes
+	splitCandidateRoutes := filterOutGeneralizedCosmWasmPoolRoutes(getRouteImpls(rankedRoutes))
+
+	// If filtering leads to a single route left, return it.
+	if len(splitCandidateRoutes) == 1 {
+		if err := validateQuotePriceImpact(ctx, topSingleRouteQuote, options.MaxQuotePriceImpact); err != nil {
+			return nil, err
+		}
+
+		return topSingleRouteQuote, nil
+	}
+
+	// Compute split route quote
+	topSplitQuote, err := getSplitQuote(ctx, splitCandidateRoutes, tokenIn)
+	if err != nil {
+		return nil, err
+	}
+
+	if isSearchTruncated {
+		setRouteSearchTruncated(topSplitQuote)
+	}
+
+	finalQuote := topSingleRouteQuote
+
+	// If the split route quote is better than the single route quote, return the split route quote
+	if topSplitQuote.GetAmountOut().GT(topSingleRouteQuote.GetAmountOut()) {
+		routes := topSplitQuote.GetRoute()
+
+		r.logger.Debug("split route selected", zap.Int("route_count", len(routes)))
+
+		finalQuote = topSplitQuote
+	}
+
+	r.logger.Debug("single route selected", zap.Stringer("route", finalQuote.GetRoute()[0]))
+
+	if finalQuote.GetAmountOut().IsZero() {
+		return nil, errors.New("best we can do is no tokens out")
+	}
+
+	if err := validateQuotePriceImpact(ctx, finalQuote, options.MaxQuotePriceImpact); err != nil {
+		return nil, err
+	}
+
+	return finalQuote, nil
+}
+
+// GetRankedQuotes returns up to k single route quotes for the given tokenIn and tokenOutDenom
+// sorted by amount out in descending order.
+// Each quote is prepared via PrepareResult before being returned.
+// Routes with the same pool path are de-duplicated so that the same pool path is never returned twice.
+// Unlike GetOptimalQuote, split routes are not considered since every returned quote
+// corresponds to exactly one route.
+// Returns error if:
+// - k is not positive
+// - tokenIn amount is not positive (NonPositiveTokenInError)
+// - tokenOutDenom is empty or not in any of the pools (UnknownTokenOutDenomError)
+// - fails to rank routes
+// - fails to prepare any of the quotes
+func (r *routerUseCaseImpl) GetRankedQuotes(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, k int, opts ...domain.RouterOption) ([]domain.Quote, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("number of ranked quotes must be positive, was (%d)", k)
+	}
+
+	if err := r.validateQuoteInputs(tokenIn, tokenOutDenom); err != nil {
+		return nil, err
+	}
+
+	options := r.getRouterOptions(opts...)
+
+	_, rankedRoutes, isSearchTruncated, err := r.rankRoutes(ctx, tokenIn, tokenOutDenom, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// The amounts out are already estimated by the ranking. The routes are re-sorted by amount out
+	// since the ranking might be biased by the pool volume.
+	routesWithAmountOut := make([]RouteWithOutAmount, len(rankedRoutes))
+	copy(routesWithAmountOut, rankedRoutes)
+	sortRoutesByAmountOut(routesWithAmountOut)
+
+	quotes := make([]domain.Quote, 0, k)
+	seenPoolPaths := make(map[string]struct{}, len(routesWithAmountOut))
+
+	for i := range routesWithAmountOut {
+		if len(quotes) == k {
+			break
+		}
+
+		routeWithAmountOut := routesWithAmountOut[i]
+
+		poolPath := formatRoutePoolPath(routeWithAmountOut.GetPools())
+		if _, ok := seenPoolPaths[poolPath]; ok {
+			continue
+		}
+		seenPoolPaths[poolPath] = struct{}{}
+
+		quote := &quoteImpl{
+			AmountIn:             tokenIn,
+			AmountOut:            routeWithAmountOut.OutAmount,
+			Route:                []domain.SplitRoute{&routeWithAmountOut},
+			RouteSearchTruncated: isSearchTruncated,
+		}
+
+		if _, _, err := quote.PrepareResult(ctx, osmomath.OneDec(), domain.WithPoolTypeGasCosts(r.defaultConfig.GetPoolTypeGasCosts())); err != nil {
+			return nil, err
+		}
+
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, nil
+}
+
+// EstimatePriceImpact returns the price impact of swapping the given tokenIn for tokenOutDenom
+// over the best single route without preparing the quote. This allows for cheaply estimating
+// the price impact curve by calling it with increasing amounts.
+// The price impact is the effective price divided by the spot price before the swap minus one
+// so that it is negative when the swap moves the price against the trader.
+// Unlike the price impact of a prepared quote, the taker fees are included in the effective price.
+// Returns error if:
+// - tokenIn amount is not positive (NonPositiveTokenInError)
+// - tokenOutDenom is empty or not in any of the pools (UnknownTokenOutDenomError)
+// - fails to rank routes
+// - fails to estimate the spot price or the amount out of the best route
+func (r *routerUseCaseImpl) EstimatePriceImpact(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (osmomath.Dec, error) {
+	if err := r.validateQuoteInputs(tokenIn, tokenOutDenom); err != nil {
+		return osmomath.Dec{}, err
+	}
+
+	options := r.getRouterOptions(opts...)
+
+	topSingleRouteQuote, _, _, err := r.rankRoutes(ctx, tokenIn, tokenOutDenom, options)
+	if err != nil {
+		return osmomath.Dec{}, err
+	}
+
+	routes := topSingleRouteQuote.GetRoute()
+	if len(routes) == 0 {
+		return osmomath.Dec{}, fmt.Errorf("no route found for token in (%s) and token out denom (%s)", tokenIn.Denom, tokenOutDenom)
+	}
+
+	return estimateRoutePriceImpact(ctx, routes[0], tokenIn)
+}
+
+// GetQuoteWithSplitComparison implements mvc.RouterUsecase.
+// The single route quote is computed with the same options but with the split routes disabled.
+// The savings are the difference in amount out between the split and the single route quotes
+// relative to the single route quote in basis points, truncated. They are zero if split routes are disabled.
+// Returns error if either of the quotes fails to compute or if the single route amount out is not positive.
+func (r *routerUseCaseImpl) GetQuoteWithSplitComparison(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, domain.Quote, int, error) {
+	splitQuote, err := r.GetOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Copy so that the caller's options are not mutated.
+	singleRouteOpts := make([]domain.RouterOption, 0, len(opts)+1)
+	singleRouteOpts = append(singleRouteOpts, opts...)
+	singleRouteOpts = append(singleRouteOpts, domain.WithDisableSplitRoutes())
+
+	singleRouteQuote, err := r.GetOptimalQuote(ctx, tokenIn, tokenOutDenom, singleRouteOpts...)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	singleRouteAmountOut := singleRouteQuote.GetAmountOut()
+	if singleRouteAmountOut.IsNil() || !singleRouteAmountOut.IsPositive() {
+		return nil, nil, 0, fmt.Errorf("single route amount out must be positive, was (%s)", singleRouteAmountOut)
+	}
+
+	savingsBps := splitQuote.GetAmountOut().Sub(singleRouteAmountOut).MulRaw(domain.BasisPointsPerUnit).Quo(singleRouteAmountOut).Int64()
+
+	return splitQuote, singleRouteQuote, int(savingsBps), nil
+}
+
+// GetQuoteWithSlippage implements mvc.RouterUsecase.
+// The tolerance is validated before the quote is computed.
+// Returns error if:
+// - the slippage tolerance is nil or outside of [0, 1)
+// - fails to compute the optimal quote
+func (r *routerUseCaseImpl) GetQuoteWithSlippage(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, slippageTolerance osmomath.Dec, opts ...domain.RouterOption) (domain.Quote, osmomath.Int, error) {
+	if slippageTolerance.IsNil() || slippageTolerance.IsNegative() || slippageTolerance.GTE(osmomath.OneDec()) {
+		return nil, osmomath.Int{}, domain.InvalidSlippageToleranceError{SlippageTolerance: slippageTolerance}
+	}
+
+	quote, err := r.GetOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	if err != nil {
+		return nil, osmomath.Int{}, err
+	}
+
+	minReceived := quote.GetAmountOut().ToLegacyDec().MulMut(osmomath.OneDec().Sub(slippageTolerance)).TruncateInt()
+
+	return quote, minReceived, nil
+}
+
+// estimateRoutePriceImpact returns the price impact of swapping the given tokenIn over the given route.
+// The spot price of the route is the product of the spot prices of its pools
+// and the effective price is the amount out divided by the amount in.
+// Returns error if the route has no pools, contains a nil pool or if the spot price or the amount out fail to compute.
+func estimateRoutePriceImpact(ctx context.Context, route domain.Route, tokenIn sdk.Coin) (osmomath.Dec, error) {
+	pools := route.GetPools()
+	if len(pools) == 0 {
+		return osmomath.Dec{}, fmt.Errorf("route for token in (%s) has no pools", tokenIn.Denom)
+	}
+
+	spotPriceInBaseOutQuote := osmomath.OneBigDec()
+	curTokenInDenom := tokenIn.Denom
+	for i, pool := range pools {
+		if pool == nil {
+			return osmomath.Dec{}, fmt.Errorf("%w at index (%d)", domain.ErrNilPoolInRoute, i)
+		}
+
+		poolSpotPrice, err := pool.CalcSpotPrice(ctx, curTokenInDenom, pool.GetTokenOutDenom())
+		if err != nil {
+			return osmomath.Dec{}, err
+		}
+
+		spotPriceInBaseOutQuote.MulMut(poolSpotPrice)
+		curTokenInDenom = pool.GetTokenOutDenom()
+	}
+
+	if spotPriceInBaseOutQuote.IsZero() {
+		return osmomath.Dec{}, fmt.Errorf("spot price of route for token in (%s) is zero", tokenIn.Denom)
+	}
+
+	tokenOut, err := route.CalculateTokenOutByTokenIn(ctx, tokenIn)
+	if err != nil {
+		return osmomath.Dec{}, err
+	}
+
+	// No amount out implies that the entire amount in is lost.
+	if tokenOut.Amount.IsNil() {
+		return osmomath.OneDec().Neg(), nil
+	}
+
+	effectivePriceInBaseOutQuote := osmomath.NewBigDecFromBigInt(tokenOut.Amount.BigInt()).QuoMut(osmomath.NewBigDecFromBigInt(tokenIn.Amount.BigInt()))
+
+	return effectivePriceInBaseOutQuote.QuoMut(spotPriceInBaseOutQuote).SubMut(osmomath.OneBigDec()).Dec(), nil
+}
+
+// formatRoutePoolPath returns a string key uniquely identifying the ordered pool path of a route.
+func formatRoutePoolPath(pools []sqsdomain.RoutablePool) string {
+	poolPath := ""
+	for i, pool := range pools {
+		if i > 0 {
+			poolPath += denomSeparatorChar
+		}
+		poolPath += strconv.FormatUint(pool.GetId(), 10)
+	}
+	return poolPath
+}
+
+// getRouterOptions returns the router options constructed from the default router config
+// with the given options applied on top.
+func (r *routerUseCaseImpl) getRouterOptions(opts ...domain.RouterOption) domain.RouterOptions {
 	options := domain.RouterOptions{
 		MaxPoolsPerRoute:                 r.defaultConfig.MaxPoolsPerRoute,
 		MaxRoutes:                        r.defaultConfig.MaxRoutes,
@@ -129,39 +433,76 @@ func (r *routerUseCaseImpl) GetOptimalQuote(ctx context.Context, tokenIn sdk.Coi
 		opt(&options)
 	}
 
+	return options
+}
+
+// rankRoutes returns the top single route quote and the routes ranked by amount out
+// alongside their estimated amounts out for the given token in and token out denom.
+// Uses cached ranked routes if present. Otherwise, computes candidate routes and ranks them.
+// Returns true if the candidate route search was truncated by the routing timeout.
+func (r *routerUseCaseImpl) rankRoutes(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, options domain.RouterOptions) (topSingleRouteQuote domain.Quote, rankedRoutes []RouteWithOutAmount, isSearchTruncated bool, err error) {
 	// Get an order of magnitude for the token in amount
 	// This is used for caching ranked routes as these might differ depending on the amount swapped in.
 	tokenInOrderOfMagnitude := GetPrecomputeOrderOfMagnitude(tokenIn.Amount)
 
 	candidateRankedRoutes, err := r.GetCachedRankedRoutes(ctx, tokenIn.Denom, tokenOutDenom, tokenInOrderOfMagnitude)
 	if err != nil {
-		return nil, err
+		return nil, nil, false, err
 	}
 
-	var (
-		topSingleRouteQuote domain.Quote
-		rankedRoutes        []route.RouteImpl
-	)
-
 	// If we call this function with MinOSMOLiquidity == 0, it's for pricing, we need to be able to call this as
 	// some pools have TVL incorrectly calculated as zero. For example, BRNCH / STRDST (1288).
 	// As a result, they are incorrectly excluded despite having appropriate liquidity.
 	// So we want to calculate price, but we never cache routes for pricing the are below the minOSMOLiquidity value, as these are returned to users.
-	if options.MinOSMOLiquidity == 0 {
+	// Similarly, we never cache routes constructed from pools filtered by type since the caches are shared
+	// with the requests that allow all pool types. The same applies to the routes constrained by the intermediate denom
+	// and to the routes through the pools filtered by the liquidity relative to the token in or excluded by ID.
+	// The routes ranked with the transfer fees are not cached either since the fees change the ranking.
+	if options.MinOSMOLiquidity == 0 || len(options.AllowedPoolTypes) > 0 || options.RequiredIntermediateDenom != "" || !options.MinLiquidityMultiple.IsNil() || len(options.ExcludedPoolIDs) > 0 || len(options.TransferFees) > 0 {
 		pools := r.getSortedPoolsShallowCopy()
 
+		// Zero implies no filtering, so we skip the iterations.
+		if options.MinOSMOLiquidity > 0 {
+			pools = FilterPoolsByMinLiquidity(pools, options.MinOSMOLiquidity)
+		}
+
+		if !options.MinLiquidityMultiple.IsNil() {
+			pools, err = r.filterPoolsByMinLiquidityMultiple(ctx, pools, tokenIn, options.MinLiquidityMultiple)
+			if err != nil {
+				return nil, nil, false, err
+			}
+		}
+
+		pools = FilterPoolsByType(pools, options.AllowedPoolTypes)
+		pools = FilterPoolsByID(pools, options.ExcludedPoolIDs)
+
+		searchCtx, cancel := newRouteSearchContext(options.RoutingTimeout)
+		defer cancel()
+
 		// Compute candidate routes.
-		candidateRoutes, err := GetCandidateRoutes(pools, tokenIn, tokenOutDenom, options.MaxRoutes, options.MaxPoolsPerRoute, r.logger)
+		var candidateRoutes sqsdomain.CandidateRoutes
+		candidateRoutes, isSearchTruncated, err = getCandidateRoutes(searchCtx, pools, tokenIn, tokenOutDenom, options.MaxRoutes, options.MaxPoolsPerRoute, r.logger)
 		if err != nil {
 			r.logger.Error("error getting candidate routes for pricing", zap.Error(err))
-			return nil, err
+			return nil, nil, false, err
+		}
+
+		if options.RequiredIntermediateDenom != "" {
+			candidateRoutes = FilterCandidateRoutesByIntermediateDenom(candidateRoutes, options.RequiredIntermediateDenom)
+			if len(candidateRoutes.Routes) == 0 {
+				return nil, nil, false, domain.NoRouteThroughIntermediateDenomError{
+					TokenInDenom:      tokenIn.Denom,
+					TokenOutDenom:     tokenOutDenom,
+					IntermediateDenom: options.RequiredIntermediateDenom,
+				}
+			}
 		}
 
 		// Get the route with out caching.
-		topSingleRouteQuote, rankedRoutes, err = r.rankRoutesByDirectQuote(ctx, candidateRoutes, tokenIn, tokenOutDenom, options.MaxRoutes)
+		topSingleRouteQuote, rankedRoutes, err = r.rankRoutesByDirectQuote(ctx, candidateRoutes, tokenIn, tokenOutDenom, options.MaxRoutes, options.TransferFees)
 		if err != nil {
 			r.logger.Error("error ranking routes for pricing", zap.Error(err))
-			return nil, err
+			return nil, nil, false, err
 		}
 	} else if len(candidateRankedRoutes.Routes) == 0 {
 		poolsAboveMinLiquidity := r.getSortedPoolsShallowCopy()
@@ -173,61 +514,62 @@ func (r *routerUseCaseImpl) GetOptimalQuote(ctx context.Context, tokenIn sdk.Coi
 
 		r.logger.Info("filtered pools", zap.Int("num_pools", len(poolsAboveMinLiquidity)))
 
-		topSingleRouteQuote, rankedRoutes, err = r.computeAndRankRoutesByDirectQuote(ctx, poolsAboveMinLiquidity, tokenIn, tokenOutDenom, options)
+		topSingleRouteQuote, rankedRoutes, isSearchTruncated, err = r.computeAndRankRoutesByDirectQuote(ctx, poolsAboveMinLiquidity, tokenIn, tokenOutDenom, options)
 	} else {
 		// Otherwise, simply compute quotes over cached ranked routes
-		topSingleRouteQuote, rankedRoutes, err = r.rankRoutesByDirectQuote(ctx, candidateRankedRoutes, tokenIn, tokenOutDenom, options.MaxRoutes)
+		topSingleRouteQuote, rankedRoutes, err = r.rankRoutesByDirectQuote(ctx, candidateRankedRoutes, tokenIn, tokenOutDenom, options.MaxRoutes, options.TransferFees)
 	}
 	if err != nil {
-		return nil, err
+		return nil, nil, false, err
 	}
 
-	if len(rankedRoutes) == 1 || options.MaxSplitRoutes == domain.DisableSplitRoutes {
-		return topSingleRouteQuote, nil
+	// Note that the ranked routes are biased after caching so that the caches stay volume-agnostic.
+	if options.PoolVolume != nil && len(rankedRoutes) > 1 {
+		topSingleRouteQuote, rankedRoutes = rankRoutesByVolume(rankedRoutes, tokenIn, options)
 	}
 
-	// Filter out generalized cosmWasm pool routes
-	rankedRoutes = filterOutGeneralizedCosmWasmPoolRoutes(rankedRoutes)
-
-	// If filtering leads to a single route left, return it.
-	if len(rankedRoutes) == 1 {
-		return topSingleRouteQuote, nil
-	}
+	return topSingleRouteQuote, rankedRoutes, isSearchTruncated, err
+}
 
-	// Compute split route quote
-	topSplitQuote, err := getSplitQuote(ctx, rankedRoutes, tokenIn)
+// filterPoolsByMinLiquidityMultiple filters out the pools whose OSMO liquidity is less than
+// the given multiple of the OSMO value of the token in.
+// Returns error if the token in fails to be valued in OSMO.
+func (r *routerUseCaseImpl) filterPoolsByMinLiquidityMultiple(ctx context.Context, pools []sqsdomain.PoolI, tokenIn sdk.Coin, multiple osmomath.Dec) ([]sqsdomain.PoolI, error) {
+	tokenInOSMOValue, err := r.getOSMOValue(ctx, tokenIn)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to value token in (%s) in OSMO for the min liquidity multiple: %w", tokenIn, err)
 	}
 
-	finalQuote := topSingleRouteQuote
-
-	// If the split route quote is better than the single route quote, return the split route quote
-	if topSplitQuote.GetAmountOut().GT(topSingleRouteQuote.GetAmountOut()) {
-		routes := topSplitQuote.GetRoute()
+	minLiquidity := multiple.Mul(tokenInOSMOValue).Ceil().TruncateInt()
 
-		r.logger.Debug("split route selected", zap.Int("route_count", len(routes)))
-
-		finalQuote = topSplitQuote
-	}
+	return filterPoolsByMinLiquidityInt(pools, minLiquidity), nil
+}
 
-	r.logger.Debug("single route selected", zap.Stringer("route", finalQuote.GetRoute()[0]))
+// getOSMOValue returns the value of the given coin in OSMO (not uosmo).
+// The coins other than OSMO are valued by the amount out of their optimal quote in the configured OSMO denom.
+func (r *routerUseCaseImpl) getOSMOValue(ctx context.Context, coin sdk.Coin) (osmomath.Dec, error) {
+	osmoDenom := r.defaultConfig.GetOSMODenom()
 
-	if finalQuote.GetAmountOut().IsZero() {
-		return nil, errors.New("best we can do is no tokens out")
+	uosmoAmount := coin.Amount
+	if coin.Denom != osmoDenom {
+		quote, err := r.GetOptimalQuote(ctx, coin, osmoDenom)
+		if err != nil {
+			return osmomath.Dec{}, err
+		}
+		uosmoAmount = quote.GetAmountOut()
 	}
 
-	return finalQuote, nil
+	return uosmoAmount.ToLegacyDec().QuoInt64(uosmoPerOSMO), nil
 }
 
 // filterDuplicatePoolIDRoutes filters routes that contain duplicate pool IDs.
 // CONTRACT: rankedRoutes are sorted in decreasing order by amount out
 // from first to last.
-func filterDuplicatePoolIDRoutes(rankedRoutes []route.RouteImpl) []route.RouteImpl {
+func filterDuplicatePoolIDRoutes(rankedRoutes []RouteWithOutAmount) []RouteWithOutAmount {
 	// We use two maps for all routes and for the current route.
 	// This is so that if a route ends up getting filtered, its pool IDs are not added to the combined map.
 	combinedPoolIDsMap := make(map[uint64]struct{})
-	filteredRankedRoutes := make([]route.RouteImpl, 0)
+	filteredRankedRoutes := make([]RouteWithOutAmount, 0)
 
 	for _, route := range rankedRoutes {
 		pools := route.GetPools()
@@ -266,13 +608,34 @@ func filterDuplicatePoolIDRoutes(rankedRoutes []route.RouteImpl) []route.RouteIm
 	return filteredRankedRoutes
 }
 
+// rankRoutesByVolume re-ranks the given routes sorted by amount out, biasing the order toward the routes through
+// higher volume pools within the volume bias tolerance of the best amount out.
+// The given routes are not mutated.
+// Returns the top single route quote and the re-ranked routes.
+func rankRoutesByVolume(rankedRoutes []RouteWithOutAmount, tokenIn sdk.Coin, options domain.RouterOptions) (domain.Quote, []RouteWithOutAmount) {
+	routesWithAmountOut := make([]RouteWithOutAmount, len(rankedRoutes))
+	copy(routesWithAmountOut, rankedRoutes)
+
+	sortRoutesByVolumeWithinTolerance(routesWithAmountOut, options.PoolVolume, options.VolumeBiasTolerance)
+
+	bestRoute := routesWithAmountOut[0]
+
+	topSingleRouteQuote := &quoteImpl{
+		AmountIn:  tokenIn,
+		AmountOut: bestRoute.OutAmount,
+		Route:     []domain.SplitRoute{&bestRoute},
+	}
+
+	return topSingleRouteQuote, routesWithAmountOut
+}
+
 // rankRoutesByDirectQuote ranks the given candidate routes by estimating direct quotes over each route.
-// Returns the top quote as well as the ranked routes in decrease order of amount out.
+// Returns the top quote as well as the ranked routes with their amounts out in decrease order of amount out.
 // Returns error if:
 // - fails to read taker fees
 // - fails to convert candidate routes to routes
 // - fails to estimate direct quotes
-func (r *routerUseCaseImpl) rankRoutesByDirectQuote(ctx context.Context, candidateRoutes sqsdomain.CandidateRoutes, tokenIn sdk.Coin, tokenOutDenom string, maxRoutes int) (domain.Quote, []route.RouteImpl, error) {
+func (r *routerUseCaseImpl) rankRoutesByDirectQuote(ctx context.Context, candidateRoutes sqsdomain.CandidateRoutes, tokenIn sdk.Coin, tokenOutDenom string, maxRoutes int, transferFees domain.TransferFees) (domain.Quote, []RouteWithOutAmount, error) {
 	// Note that retrieving pools and taker fees is done in separate transactions.
 	// This is fine because taker fees don't change often.
 	routes, err := r.poolsUsecase.GetRoutesFromCandidates(candidateRoutes, tokenIn.Denom, tokenOutDenom)
@@ -280,32 +643,43 @@ func (r *routerUseCaseImpl) rankRoutesByDirectQuote(ctx context.Context, candida
 		return nil, nil, err
 	}
 
-	topQuote, routes, err := estimateDirectQuote(ctx, routes, tokenIn, maxRoutes, r.logger)
+	// Transfer fees are deducted when estimating the quotes over the routes.
+	for i := range routes {
+		routes[i].TransferFees = transferFees
+	}
+
+	topQuote, rankedRoutes, err := estimateDirectQuote(ctx, routes, tokenIn, maxRoutes, r.logger)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%s, tokenOutDenom (%s)", err, tokenOutDenom)
 	}
 
-	return topQuote, routes, nil
+	return topQuote, rankedRoutes, nil
 }
 
 // computeAndRankRoutesByDirectQuote computes candidate routes and ranks them by token out after estimating direct quotes.
-func (r *routerUseCaseImpl) computeAndRankRoutesByDirectQuote(ctx context.Context, pools []sqsdomain.PoolI, tokenIn sdk.Coin, tokenOutDenom string, routingOptions domain.RouterOptions) (domain.Quote, []route.RouteImpl, error) {
+// Returns true if the candidate route search was truncated by the routing timeout.
+// Routes from a truncated search are not cached.
+func (r *routerUseCaseImpl) computeAndRankRoutesByDirectQuote(ctx context.Context, pools []sqsdomain.PoolI, tokenIn sdk.Coin, tokenOutDenom string, routingOptions domain.RouterOptions) (domain.Quote, []RouteWithOutAmount, bool, error) {
 	tokenInOrderOfMagnitude := GetPrecomputeOrderOfMagnitude(tokenIn.Amount)
 
 	// If top routes are not present in cache, retrieve unranked candidate routes
-	candidateRoutes, err := r.handleCandidateRoutes(ctx, pools, tokenIn, tokenOutDenom, routingOptions.MaxRoutes, routingOptions.MaxPoolsPerRoute)
+	candidateRoutes, isSearchTruncated, err := r.handleCandidateRoutes(ctx, pools, tokenIn, tokenOutDenom, routingOptions.MaxRoutes, routingOptions.MaxPoolsPerRoute, routingOptions.RoutingTimeout)
 	if err != nil {
 		r.logger.Error("error handling routes", zap.Error(err))
-		return nil, nil, err
+		return nil, nil, false, err
 	}
 
 	// Get request path for metrics
 	requestURLPath, err := domain.GetURLPathFromContext(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, false, err
 	}
 
-	if len(candidateRoutes.Routes) > 0 {
+	if isSearchTruncated {
+		if len(candidateRoutes.Routes) == 0 {
+			return nil, nil, false, fmt.Errorf("no candidate routes found before routing timeout (%s)", routingOptions.RoutingTimeout)
+		}
+	} else if len(candidateRoutes.Routes) > 0 {
 		cacheWrite.WithLabelValues(requestURLPath, candidateRouteCacheLabel, tokenIn.Denom, tokenOutDenom, noOrderOfMagnitude).Inc()
 
 		r.candidateRouteCache.Set(formatCandidateRouteCacheKey(tokenIn.Denom, tokenOutDenom), candidateRoutes, time.Duration(routingOptions.CandidateRouteCacheExpirySeconds)*time.Second)
@@ -315,64 +689,66 @@ func (r *routerUseCaseImpl) computeAndRankRoutesByDirectQuote(ctx context.Contex
 
 		r.rankedRouteCache.Set(formatRankedRouteCacheKey(tokenIn.Denom, tokenOutDenom, tokenInOrderOfMagnitude), candidateRoutes, time.Duration(routingOptions.RankedRouteCacheExpirySeconds/4)*time.Second)
 
-		return nil, nil, fmt.Errorf("no candidate routes found")
+		return nil, nil, false, fmt.Errorf("no candidate routes found")
 	}
 
 	// Rank candidate routes by estimating direct quotes
-	topSingleRouteQuote, rankedRoutes, err := r.rankRoutesByDirectQuote(ctx, candidateRoutes, tokenIn, tokenOutDenom, routingOptions.MaxRoutes)
+	topSingleRouteQuote, rankedRoutes, err := r.rankRoutesByDirectQuote(ctx, candidateRoutes, tokenIn, tokenOutDenom, routingOptions.MaxRoutes, routingOptions.TransferFees)
 	if err != nil {
 		r.logger.Error("error getting ranked routes", zap.Error(err))
-		return nil, nil, err
+		return nil, nil, false, err
 	}
 
 	if len(rankedRoutes) == 0 {
-		return nil, nil, fmt.Errorf("no ranked routes found")
+		return nil, nil, false, fmt.Errorf("no ranked routes found")
 	}
 
 	// Update ranked routes with filtered ranked routes
 	rankedRoutes = filterDuplicatePoolIDRoutes(rankedRoutes)
 
 	// Convert ranked routes back to candidate for caching
-	candidateRoutes = convertRankedToCandidateRoutes(rankedRoutes)
+	candidateRoutes = convertRankedToCandidateRoutes(getRouteImpls(rankedRoutes))
 
-	if len(rankedRoutes) > 0 {
+	if len(rankedRoutes) > 0 && !isSearchTruncated {
 		cacheWrite.WithLabelValues(requestURLPath, rankedRouteCacheLabel, tokenIn.Denom, tokenOutDenom, strconv.FormatInt(int64(tokenInOrderOfMagnitude), 10)).Inc()
 
 		r.rankedRouteCache.Set(formatRankedRouteCacheKey(tokenIn.Denom, tokenOutDenom, tokenInOrderOfMagnitude), candidateRoutes, time.Duration(routingOptions.RankedRouteCacheExpirySeconds)*time.Second)
 	}
 
-	return topSingleRouteQuote, rankedRoutes, nil
+	return topSingleRouteQuote, rankedRoutes, isSearchTruncated, nil
 }
 
 // estimateDirectQuote estimates and returns the direct quote for the given routes, token in and token out denom.
-// Also, returns the routes ranked by amount out in decreasing order.
+// Also, returns the routes with their amounts out ranked by amount out in decreasing order.
+// The routes that fail to estimate are dropped.
 // Returns error if:
 // - fails to estimate direct quotes
-func estimateDirectQuote(ctx context.Context, routes []route.RouteImpl, tokenIn sdk.Coin, maxRoutes int, logger log.Logger) (domain.Quote, []route.RouteImpl, error) {
+func estimateDirectQuote(ctx context.Context, routes []route.RouteImpl, tokenIn sdk.Coin, maxRoutes int, logger log.Logger) (domain.Quote, []RouteWithOutAmount, error) {
 	topQuote, routesSortedByAmtOut, err := estimateAndRankSingleRouteQuote(ctx, routes, tokenIn, logger)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	numRoutes := len(routesSortedByAmtOut)
-
 	// If split routes are disabled, return a single the top route
-	if maxRoutes == 0 && numRoutes > 0 {
-		numRoutes = 1
-		// If there are more routes than the max split routes, keep only the top routes
-	} else if len(routesSortedByAmtOut) > maxRoutes {
-		// Keep only top routes for splits
-		routes = routes[:maxRoutes]
-		numRoutes = maxRoutes
+	if maxRoutes == 0 && len(routesSortedByAmtOut) > 0 {
+		return topQuote, routesSortedByAmtOut[:1], nil
 	}
 
-	// Convert routes sorted by amount out to routes
-	for i := 0; i < numRoutes; i++ {
-		// Update routes with the top routes
-		routes[i] = routesSortedByAmtOut[i].RouteImpl
+	// If there are more routes than the max split routes, keep only the top routes
+	if len(routesSortedByAmtOut) > maxRoutes {
+		routesSortedByAmtOut = routesSortedByAmtOut[:maxRoutes]
 	}
 
-	return topQuote, routes, nil
+	return topQuote, routesSortedByAmtOut, nil
+}
+
+// getRouteImpls returns the routes of the given routes with amounts out in the same order.
+func getRouteImpls(routesWithAmountOut []RouteWithOutAmount) []route.RouteImpl {
+	routes := make([]route.RouteImpl, 0, len(routesWithAmountOut))
+	for _, routeWithAmountOut := range routesWithAmountOut {
+		routes = append(routes, routeWithAmountOut.RouteImpl)
+	}
+	return routes
 }
 
 // GetBestSingleRouteQuote returns the best single route quote to be done directly without a split.
@@ -380,7 +756,7 @@ func (r *routerUseCaseImpl) GetBestSingleRouteQuote(ctx context.Context, tokenIn
 	// Filter pools by minimum liquidity
 	poolsAboveMinLiquidity := FilterPoolsByMinLiquidity(r.getSortedPoolsShallowCopy(), r.defaultConfig.MinOSMOLiquidity)
 
-	candidateRoutes, err := r.handleCandidateRoutes(ctx, poolsAboveMinLiquidity, tokenIn, tokenOutDenom, r.defaultConfig.MaxRoutes, r.defaultConfig.MaxPoolsPerRoute)
+	candidateRoutes, _, err := r.handleCandidateRoutes(ctx, poolsAboveMinLiquidity, tokenIn, tokenOutDenom, r.defaultConfig.MaxRoutes, r.defaultConfig.MaxPoolsPerRoute, noRoutingTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -449,7 +825,7 @@ func (r *routerUseCaseImpl) GetCustomDirectQuote(ctx context.Context, tokenIn sd
 
 // GetCandidateRoutes implements domain.RouterUsecase.
 func (r *routerUseCaseImpl) GetCandidateRoutes(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string) (sqsdomain.CandidateRoutes, error) {
-	candidateRoutes, err := r.handleCandidateRoutes(ctx, r.getSortedPoolsShallowCopy(), tokenIn, tokenOutDenom, r.defaultConfig.MaxRoutes, r.defaultConfig.MaxPoolsPerRoute)
+	candidateRoutes, _, err := r.handleCandidateRoutes(ctx, r.getSortedPoolsShallowCopy(), tokenIn, tokenOutDenom, r.defaultConfig.MaxRoutes, r.defaultConfig.MaxPoolsPerRoute, noRoutingTimeout)
 	if err != nil {
 		return sqsdomain.CandidateRoutes{}, err
 	}
@@ -554,12 +930,14 @@ func (r *routerUseCaseImpl) GetCachedRankedRoutes(ctx context.Context, tokenInDe
 
 // handleCandidateRoutes attempts to retrieve candidate routes from the cache. If no routes are cached, it will
 // compute, persist in cache and return them.
-// Returns routes on success
+// The computation is bounded by the routing timeout. Zero routing timeout implies no bound.
+// Returns routes on success and a flag indicating whether the computation was truncated by the routing timeout.
+// Truncated routes are not persisted in cache.
 // Errors if:
 // - there is an error retrieving routes from cache
 // - there are no routes cached and there is an error computing them
 // - fails to persist the computed routes in cache
-func (r *routerUseCaseImpl) handleCandidateRoutes(ctx context.Context, pools []sqsdomain.PoolI, tokenIn sdk.Coin, tokenOutDenom string, maxRoutes, maxPoolsPerRoutes int) (candidateRoutes sqsdomain.CandidateRoutes, err error) {
+func (r *routerUseCaseImpl) handleCandidateRoutes(ctx context.Context, pools []sqsdomain.PoolI, tokenIn sdk.Coin, tokenOutDenom string, maxRoutes, maxPoolsPerRoutes int, routingTimeout time.Duration) (candidateRoutes sqsdomain.CandidateRoutes, isTruncated bool, err error) {
 	r.logger.Debug("getting routes")
 
 	// Check cache for routes if enabled
@@ -567,7 +945,7 @@ func (r *routerUseCaseImpl) handleCandidateRoutes(ctx context.Context, pools []s
 	if r.defaultConfig.RouteCacheEnabled {
 		candidateRoutes, isFoundCached, err = r.GetCachedCandidateRoutes(ctx, tokenIn.Denom, tokenOutDenom)
 		if err != nil {
-			return sqsdomain.CandidateRoutes{}, err
+			return sqsdomain.CandidateRoutes{}, false, err
 		}
 	}
 
@@ -577,15 +955,18 @@ func (r *routerUseCaseImpl) handleCandidateRoutes(ctx context.Context, pools []s
 	if !isFoundCached {
 		r.logger.Debug("calculating routes")
 
-		candidateRoutes, err = GetCandidateRoutes(pools, tokenIn, tokenOutDenom, maxRoutes, maxPoolsPerRoutes, r.logger)
+		searchCtx, cancel := newRouteSearchContext(routingTimeout)
+		defer cancel()
+
+		candidateRoutes, isTruncated, err = getCandidateRoutes(searchCtx, pools, tokenIn, tokenOutDenom, maxRoutes, maxPoolsPerRoutes, r.logger)
 		if err != nil {
-			return sqsdomain.CandidateRoutes{}, err
+			return sqsdomain.CandidateRoutes{}, false, err
 		}
 
-		r.logger.Info("calculated routes", zap.Int("num_routes", len(candidateRoutes.Routes)))
+		r.logger.Info("calculated routes", zap.Int("num_routes", len(candidateRoutes.Routes)), zap.Bool("is_truncated", isTruncated))
 
 		// Persist routes
-		if r.defaultConfig.RouteCacheEnabled {
+		if r.defaultConfig.RouteCacheEnabled && !isTruncated {
 			cacheDurationSeconds := r.defaultConfig.CandidateRouteCacheExpirySeconds
 			if len(candidateRoutes.Routes) == 0 {
 				// If there are no routes, we want to cache the result for a shorter duration
@@ -598,7 +979,65 @@ func (r *routerUseCaseImpl) handleCandidateRoutes(ctx context.Context, pools []s
 		}
 	}
 
-	return candidateRoutes, nil
+	return candidateRoutes, isTruncated, nil
+}
+
+// validateQuotePriceImpact returns PriceImpactTooHighError if the price impact of the given quote
+// exceeds maxPriceImpact. Nil maxPriceImpact implies no bound.
+// The price impact is computed by preparing a shallow copy of the quote so that the routes
+// of the given quote are not mutated. The computed price impact is set on the given quote
+// so that preparing its result does not recompute the effective price.
+func validateQuotePriceImpact(ctx context.Context, quote domain.Quote, maxPriceImpact osmomath.Dec) error {
+	if maxPriceImpact.IsNil() {
+		return nil
+	}
+
+	q, ok := quote.(*quoteImpl)
+	if !ok {
+		return fmt.Errorf("invalid quote type (%T), expected (%T)", quote, &quoteImpl{})
+	}
+
+	preparedQuote := *q
+	if _, _, err := preparedQuote.PrepareResult(ctx, osmomath.OneDec()); err != nil {
+		return err
+	}
+
+	priceImpact := preparedQuote.GetPriceImpact()
+	q.PriceImpact = priceImpact
+	q.isPriceImpactComputed = true
+
+	if priceImpact.IsNil() {
+		return nil
+	}
+
+	// Price impact is negative when the effective price is worse than the spot price.
+	if priceImpact.Abs().GT(maxPriceImpact) {
+		return domain.PriceImpactTooHighError{
+			MaxPriceImpact:    maxPriceImpact,
+			ActualPriceImpact: priceImpact,
+		}
+	}
+
+	return nil
+}
+
+// newRouteSearchContext returns the context bounding the candidate route search.
+// It is derived from the background context so that the routing timeout
+// applies independently of the parent request context.
+// Zero routing timeout implies no bound.
+func newRouteSearchContext(routingTimeout time.Duration) (context.Context, context.CancelFunc) {
+	if routingTimeout <= noRoutingTimeout {
+		return context.Background(), func() {}
+	}
+
+	return context.WithTimeout(context.Background(), routingTimeout)
+}
+
+// setRouteSearchTruncated marks the quote as computed over a truncated candidate route search.
+func setRouteSearchTruncated(quote domain.Quote) {
+	if q, ok := quote.(*quoteImpl); ok {
+		q.RouteSearchTruncated = true
+	}
 }
 
 // StoreRouterStateFiles implements domain.RouterUsecase.
@@ -705,6 +1144,14 @@ func (r *routerUseCaseImpl) GetPoolSpotPrice(ctx context.Context, poolID uint64,
 		return osmomath.BigDec{}, fmt.Errorf("taker fee not found for pool %d, denom in (%s), denom out (%s)", poolID, quoteAsset, baseAsset)
 	}
 
+	handler, pool, ok, err := r.getCustomPoolSpotPriceHandler(poolID)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+	if ok {
+		return handler.CalcSpotPrice(ctx, pool, poolTakerFee, quoteAsset, baseAsset)
+	}
+
 	spotPrice, err := r.poolsUsecase.GetPoolSpotPrice(ctx, poolID, poolTakerFee, quoteAsset, baseAsset)
 	if err != nil {
 		return osmomath.BigDec{}, err
@@ -713,13 +1160,110 @@ func (r *routerUseCaseImpl) GetPoolSpotPrice(ctx context.Context, poolID uint64,
 	return spotPrice, nil
 }
 
+// RegisterCustomPoolSpotPriceHandler implements mvc.RouterUsecase.
+// Note that the pools with the code ID must still be allowed by the CosmWasm pool config to be routed through.
+func (r *routerUseCaseImpl) RegisterCustomPoolSpotPriceHandler(codeID uint64, handler domain.SpotPriceHandler) {
+	r.customPoolSpotPriceHandlersMu.Lock()
+	defer r.customPoolSpotPriceHandlersMu.Unlock()
+
+	r.customPoolSpotPriceHandlers[codeID] = handler
+}
+
+// getCustomPoolSpotPriceHandler returns the custom spot price handler registered for the code ID
+// of the given pool alongside the pool. Returns false if the pool is not a CosmWasm pool
+// or no handler is registered for its code ID. Returns error if the pool is not found.
+func (r *routerUseCaseImpl) getCustomPoolSpotPriceHandler(poolID uint64) (domain.SpotPriceHandler, sqsdomain.PoolI, bool, error) {
+	r.customPoolSpotPriceHandlersMu.RLock()
+	defer r.customPoolSpotPriceHandlersMu.RUnlock()
+
+	// Avoid retrieving the pool in the common case of no custom handlers.
+	if len(r.customPoolSpotPriceHandlers) == 0 {
+		return nil, nil, false, nil
+	}
+
+	pool, err := r.poolsUsecase.GetPool(poolID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if pool.GetType() != poolmanagertypes.CosmWasm {
+		return nil, nil, false, nil
+	}
+
+	cosmWasmPool, ok := pool.GetUnderlyingPool().(cosmwasmpooltypes.CosmWasmExtension)
+	if !ok {
+		return nil, nil, false, nil
+	}
+
+	handler, ok := r.customPoolSpotPriceHandlers[cosmWasmPool.GetCodeId()]
+	return handler, pool, ok, nil
+}
+
+// GetPoolSpotPrices implements mvc.RouterUsecase.
+func (r *routerUseCaseImpl) GetPoolSpotPrices(ctx context.Context, requests []domain.SpotPriceRequest) ([]osmomath.BigDec, []error) {
+	spotPrices := make([]osmomath.BigDec, len(requests))
+	errs := make([]error, len(requests))
+
+	var wg sync.WaitGroup
+	for i, request := range requests {
+		wg.Add(1)
+
+		go func(i int, request domain.SpotPriceRequest) {
+			defer wg.Done()
+
+			spotPrices[i], errs[i] = r.GetPoolSpotPrice(ctx, request.PoolID, request.QuoteDenom, request.BaseDenom)
+		}(i, request)
+	}
+
+	wg.Wait()
+
+	return spotPrices, errs
+}
+
 // SetSortedPools implements mvc.RouterUsecase.
 func (r *routerUseCaseImpl) SetSortedPools(pools []sqsdomain.PoolI) {
+	sortedPoolDenoms := make(map[string]struct{})
+	for _, pool := range pools {
+		for _, denom := range pool.GetPoolDenoms() {
+			sortedPoolDenoms[denom] = struct{}{}
+		}
+	}
+
 	r.sortedPoolsMu.Lock()
 	r.sortedPools = pools
+	r.sortedPoolDenoms = sortedPoolDenoms
 	r.sortedPoolsMu.Unlock()
 }
 
+// isSortedPoolDenom returns true if the given denom is in any of the sorted pools.
+// Returns true if no pools are loaded yet since the denom cannot be checked.
+func (r *routerUseCaseImpl) isSortedPoolDenom(denom string) bool {
+	r.sortedPoolsMu.RLock()
+	defer r.sortedPoolsMu.RUnlock()
+
+	if len(r.sortedPoolDenoms) == 0 {
+		return true
+	}
+
+	_, ok := r.sortedPoolDenoms[denom]
+	return ok
+}
+
+// validateQuoteInputs returns NonPositiveTokenInError if the token in amount is not positive
+// and UnknownTokenOutDenomError if the token out denom is empty or not in any of the sorted pools.
+// The token out denom membership is not checked until the pools are loaded.
+func (r *routerUseCaseImpl) validateQuoteInputs(tokenIn sdk.Coin, tokenOutDenom string) error {
+	if tokenIn.Amount.IsNil() || !tokenIn.Amount.IsPositive() {
+		return domain.NonPositiveTokenInError{TokenInDenom: tokenIn.Denom, TokenInAmount: tokenIn.Amount}
+	}
+
+	if tokenOutDenom == "" || !r.isSortedPoolDenom(tokenOutDenom) {
+		return domain.UnknownTokenOutDenomError{TokenOutDenom: tokenOutDenom}
+	}
+
+	return nil
+}
+
 // SetTakerFees implements mvc.RouterUsecase.
 func (r *routerUseCaseImpl) SetTakerFees(takerFees sqsdomain.TakerFeeMap) {
 	r.routerRepository.SetTakerFees(takerFees)
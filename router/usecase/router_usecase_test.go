@@ -2,6 +2,7 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -21,7 +22,9 @@ import (
 	"github.com/osmosis-labs/sqs/sqsdomain"
 
 	"github.com/osmosis-labs/osmosis/osmomath"
+	cosmwasmpooltypes "github.com/osmosis-labs/osmosis/v24/x/cosmwasmpool/types"
 	"github.com/osmosis-labs/osmosis/v24/x/gamm/pool-models/balancer"
+	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
 )
 
 const (
@@ -240,9 +243,11 @@ func (s *RouterTestSuite) TestHandleRoutes() {
 				Pools: tc.repositoryPools,
 			}
 
-			routerUseCase := usecase.NewRouterUsecase(routerRepositoryMock, poolsUseCaseMock, domain.RouterConfig{
-				RouteCacheEnabled: !tc.isCacheDisabled,
-			}, emptyCosmWasmPoolsRouterConfig, &log.NoOpLogger{}, cache.New(), candidateRouteCache)
+			routerConfig := defaultRouterConfig
+			routerConfig.RouteCacheEnabled = !tc.isCacheDisabled
+
+			routerUseCase, err := usecase.NewRouterUsecase(routerRepositoryMock, poolsUseCaseMock, routerConfig, emptyCosmWasmPoolsRouterConfig, &log.NoOpLogger{}, cache.New(), candidateRouteCache)
+			s.Require().NoError(err)
 
 			// Validate and sort pools
 			sortedPools := usecase.ValidateAndSortPools(tc.repositoryPools, emptyCosmWasmPoolsRouterConfig, []uint64{}, noOpLogger)
@@ -739,6 +744,416 @@ func (s *RouterTestSuite) TestPriceImpactRoute_Fractions() {
 	s.Require().True(priceImpact.LT(osmomath.MustNewDecFromStr("0.07")))
 }
 
+// Validates that GetRankedQuotes returns at most k quotes sorted by amount out in descending order
+// with no duplicate pool paths.
+func (s *RouterTestSuite) TestGetRankedQuotes() {
+	const k = 3
+
+	mainnetState := s.SetupMainnetState()
+	mainnetUsecase := s.SetupRouterAndPoolsUsecase(mainnetState)
+
+	quotes, err := mainnetUsecase.Router.GetRankedQuotes(context.Background(), sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000)), ATOM, k)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(quotes)
+	s.Require().LessOrEqual(len(quotes), k)
+
+	seenPoolPaths := make(map[string]struct{})
+	for i, quote := range quotes {
+		routes := quote.GetRoute()
+		s.Require().Len(routes, 1)
+
+		if i > 0 {
+			s.Require().True(quotes[i-1].GetAmountOut().GTE(quote.GetAmountOut()))
+		}
+
+		poolIDs := make([]uint64, 0, len(routes[0].GetPools()))
+		for _, pool := range routes[0].GetPools() {
+			poolIDs = append(poolIDs, pool.GetId())
+		}
+
+		poolPath := fmt.Sprint(poolIDs)
+		_, exists := seenPoolPaths[poolPath]
+		s.Require().False(exists)
+		seenPoolPaths[poolPath] = struct{}{}
+	}
+
+	// Non-positive k is invalid.
+	_, err = mainnetUsecase.Router.GetRankedQuotes(context.Background(), sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000)), ATOM, 0)
+	s.Require().Error(err)
+
+	// Non-positive amount in is invalid.
+	_, err = mainnetUsecase.Router.GetRankedQuotes(context.Background(), sdk.NewCoin(UOSMO, osmomath.ZeroInt()), ATOM, k)
+	s.Require().ErrorAs(err, &domain.NonPositiveTokenInError{})
+
+	// Unknown token out denom is invalid.
+	_, err = mainnetUsecase.Router.GetRankedQuotes(context.Background(), sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000)), "unknown", k)
+	s.Require().ErrorAs(err, &domain.UnknownTokenOutDenomError{})
+}
+
+// Validates that EstimatePriceImpact returns a non-positive price impact that grows in magnitude
+// with the amount in and that it rejects non-positive amounts.
+func (s *RouterTestSuite) TestEstimatePriceImpact() {
+	mainnetState := s.SetupMainnetState()
+	mainnetUsecase := s.SetupRouterAndPoolsUsecase(mainnetState)
+
+	smallPriceImpact, err := mainnetUsecase.Router.EstimatePriceImpact(context.Background(), sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000)), ATOM)
+	s.Require().NoError(err)
+	s.Require().True(smallPriceImpact.IsNegative())
+
+	largePriceImpact, err := mainnetUsecase.Router.EstimatePriceImpact(context.Background(), sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000_000_000)), ATOM)
+	s.Require().NoError(err)
+	s.Require().True(largePriceImpact.LT(smallPriceImpact))
+	s.Require().True(largePriceImpact.GTE(osmomath.OneDec().Neg()))
+
+	// Non-positive amount in is invalid.
+	_, err = mainnetUsecase.Router.EstimatePriceImpact(context.Background(), sdk.NewCoin(UOSMO, osmomath.ZeroInt()), ATOM)
+	s.Require().ErrorAs(err, &domain.NonPositiveTokenInError{})
+
+	// Unknown token out denom is invalid.
+	_, err = mainnetUsecase.Router.EstimatePriceImpact(context.Background(), sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000)), "unknown")
+	s.Require().ErrorAs(err, &domain.UnknownTokenOutDenomError{})
+}
+
+// Validates that GetOptimalQuote fails fast on the invalid token in amounts and token out denoms.
+func (s *RouterTestSuite) TestGetOptimalQuote_InvalidInputs() {
+	mainnetState := s.SetupMainnetState()
+	mainnetUsecase := s.SetupRouterAndPoolsUsecase(mainnetState)
+
+	testCases := []struct {
+		name          string
+		tokenIn       sdk.Coin
+		tokenOutDenom string
+		expectedErr   error
+	}{
+		{
+			name:          "zero token in",
+			tokenIn:       sdk.Coin{Denom: UOSMO, Amount: osmomath.ZeroInt()},
+			tokenOutDenom: ATOM,
+			expectedErr:   domain.NonPositiveTokenInError{TokenInDenom: UOSMO, TokenInAmount: osmomath.ZeroInt()},
+		},
+		{
+			name:          "negative token in",
+			tokenIn:       sdk.Coin{Denom: UOSMO, Amount: osmomath.NewInt(-1)},
+			tokenOutDenom: ATOM,
+			expectedErr:   domain.NonPositiveTokenInError{TokenInDenom: UOSMO, TokenInAmount: osmomath.NewInt(-1)},
+		},
+		{
+			name:          "empty token out denom",
+			tokenIn:       sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000)),
+			tokenOutDenom: "",
+			expectedErr:   domain.UnknownTokenOutDenomError{TokenOutDenom: ""},
+		},
+		{
+			name:          "unknown token out denom",
+			tokenIn:       sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000)),
+			tokenOutDenom: "unknown",
+			expectedErr:   domain.UnknownTokenOutDenomError{TokenOutDenom: "unknown"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.Run(tc.name, func() {
+			_, err := mainnetUsecase.Router.GetOptimalQuote(context.Background(), tc.tokenIn, tc.tokenOutDenom)
+			s.Require().Error(err)
+			s.Require().Equal(tc.expectedErr.Error(), err.Error())
+
+			switch tc.expectedErr.(type) {
+			case domain.NonPositiveTokenInError:
+				s.Require().ErrorAs(err, &domain.NonPositiveTokenInError{})
+			case domain.UnknownTokenOutDenomError:
+				s.Require().ErrorAs(err, &domain.UnknownTokenOutDenomError{})
+			}
+		})
+	}
+}
+
+// Validates that the token out denom membership is not checked until the pools are loaded
+// so that the quotes prior to the first ingest fail on the missing routes instead.
+func (s *RouterTestSuite) TestGetOptimalQuote_NoPoolsLoaded() {
+	routerUsecase, err := usecase.NewRouterUsecase(routerrepo.New(), &mocks.PoolsUsecaseMock{}, defaultRouterConfig, emptyCosmWasmPoolsRouterConfig, &log.NoOpLogger{}, cache.New(), cache.New())
+	s.Require().NoError(err)
+
+	_, err = routerUsecase.GetOptimalQuote(context.Background(), sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000)), ATOM)
+	s.Require().Error(err)
+	s.Require().False(errors.As(err, &domain.UnknownTokenOutDenomError{}))
+
+	// The token in amount is still validated.
+	_, err = routerUsecase.GetOptimalQuote(context.Background(), sdk.Coin{Denom: UOSMO, Amount: osmomath.ZeroInt()}, ATOM)
+	s.Require().ErrorAs(err, &domain.NonPositiveTokenInError{})
+}
+
+// Validates that GetQuoteWithSplitComparison returns the split and the single route quotes
+// with the savings of the split quote in basis points.
+func (s *RouterTestSuite) TestGetQuoteWithSplitComparison() {
+	mainnetState := s.SetupMainnetState()
+	mainnetUsecase := s.SetupRouterAndPoolsUsecase(mainnetState)
+
+	// Large swap to make the split worthwhile.
+	splitQuote, singleRouteQuote, savingsBps, err := mainnetUsecase.Router.GetQuoteWithSplitComparison(context.Background(), sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000_000_000)), ATOM)
+	s.Require().NoError(err)
+	s.Require().Len(singleRouteQuote.GetRoute(), 1)
+	s.Require().True(splitQuote.GetAmountOut().GTE(singleRouteQuote.GetAmountOut()))
+
+	expectedSavingsBps := splitQuote.GetAmountOut().Sub(singleRouteQuote.GetAmountOut()).MulRaw(10_000).Quo(singleRouteQuote.GetAmountOut()).Int64()
+	s.Require().Equal(int(expectedSavingsBps), savingsBps)
+	s.Require().GreaterOrEqual(savingsBps, 0)
+
+	// Split routes disabled yield no savings.
+	_, _, savingsBps, err = mainnetUsecase.Router.GetQuoteWithSplitComparison(context.Background(), sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000_000_000)), ATOM, domain.WithDisableSplitRoutes())
+	s.Require().NoError(err)
+	s.Require().Zero(savingsBps)
+}
+
+// Validates that GetQuoteWithSlippage returns the min received amount given the slippage tolerance
+// and that it rejects the tolerances outside of [0, 1).
+func (s *RouterTestSuite) TestGetQuoteWithSlippage() {
+	mainnetState := s.SetupMainnetState()
+	mainnetUsecase := s.SetupRouterAndPoolsUsecase(mainnetState)
+
+	tokenIn := sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000_000))
+
+	testCases := []struct {
+		name              string
+		slippageTolerance osmomath.Dec
+		expectErr         bool
+	}{
+		{
+			name:              "zero tolerance",
+			slippageTolerance: osmomath.ZeroDec(),
+		},
+		{
+			name:              "one percent tolerance",
+			slippageTolerance: osmomath.NewDecWithPrec(1, 2),
+		},
+		{
+			name:              "max tolerance",
+			slippageTolerance: osmomath.MustNewDecFromStr("0.999999999999999999"),
+		},
+		{
+			name:              "negative tolerance",
+			slippageTolerance: osmomath.NewDecWithPrec(-1, 2),
+			expectErr:         true,
+		},
+		{
+			name:              "tolerance of one",
+			slippageTolerance: osmomath.OneDec(),
+			expectErr:         true,
+		},
+		{
+			name:              "tolerance above one",
+			slippageTolerance: osmomath.NewDec(2),
+			expectErr:         true,
+		},
+		{
+			name:              "nil tolerance",
+			slippageTolerance: osmomath.Dec{},
+			expectErr:         true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.Run(tc.name, func() {
+			quote, minReceived, err := mainnetUsecase.Router.GetQuoteWithSlippage(context.Background(), tokenIn, ATOM, tc.slippageTolerance)
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Require().ErrorAs(err, &domain.InvalidSlippageToleranceError{})
+				return
+			}
+			s.Require().NoError(err)
+
+			expectedMinReceived := quote.GetAmountOut().ToLegacyDec().Mul(osmomath.OneDec().Sub(tc.slippageTolerance)).TruncateInt()
+			s.Require().Equal(expectedMinReceived.String(), minReceived.String())
+			s.Require().True(minReceived.LTE(quote.GetAmountOut()))
+		})
+	}
+}
+
+// Validates that GetOptimalQuote returns NoRouteThroughIntermediateDenomError
+// if none of the routes passes through the required intermediate denom.
+func (s *RouterTestSuite) TestGetOptimalQuote_RequiredIntermediateDenom() {
+	mainnetState := s.SetupMainnetState()
+	mainnetUsecase := s.SetupRouterAndPoolsUsecase(mainnetState)
+
+	_, err := mainnetUsecase.Router.GetOptimalQuote(context.Background(), sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000)), ATOM, domain.WithRequiredIntermediateDenom("unknown"))
+	s.Require().ErrorAs(err, &domain.NoRouteThroughIntermediateDenomError{})
+}
+
+// Validates that GetOptimalQuote rejects the quote with PriceImpactTooHighError
+// when its price impact exceeds the configured max price impact.
+func (s *RouterTestSuite) TestGetOptimalQuote_MaxQuotePriceImpact() {
+	mainnetState := s.SetupMainnetState()
+	mainnetUsecase := s.SetupRouterAndPoolsUsecase(mainnetState)
+
+	tokenIn := sdk.NewCoin(UOSMO, osmomath.NewInt(1_000_000_000_000))
+
+	// Tiny max price impact is exceeded by a large swap.
+	_, err := mainnetUsecase.Router.GetOptimalQuote(context.Background(), tokenIn, ATOM, domain.WithMaxQuotePriceImpact(osmomath.MustNewDecFromStr("0.000001")))
+	s.Require().Error(err)
+
+	var priceImpactErr domain.PriceImpactTooHighError
+	s.Require().ErrorAs(err, &priceImpactErr)
+	s.Require().True(priceImpactErr.ActualPriceImpact.Abs().GT(priceImpactErr.MaxPriceImpact))
+
+	// Max price impact of 100% is never exceeded.
+	quote, err := mainnetUsecase.Router.GetOptimalQuote(context.Background(), tokenIn, ATOM, domain.WithMaxQuotePriceImpact(osmomath.OneDec()))
+	s.Require().NoError(err)
+	s.Require().NotNil(quote)
+
+	// The price impact computed when validating is reused when preparing the result
+	// and matches the one computed for the quote without the max price impact.
+	_, _, err = quote.PrepareResult(context.Background(), osmomath.OneDec())
+	s.Require().NoError(err)
+	s.Require().False(quote.IsEffectivePriceSkipped())
+
+	unboundedQuote, err := mainnetUsecase.Router.GetOptimalQuote(context.Background(), tokenIn, ATOM)
+	s.Require().NoError(err)
+	_, _, err = unboundedQuote.PrepareResult(context.Background(), osmomath.OneDec())
+	s.Require().NoError(err)
+
+	s.Require().Equal(unboundedQuote.GetPriceImpact().String(), quote.GetPriceImpact().String())
+}
+
+// Validates that GetPoolSpotPrices returns the same spot prices and errors
+// as GetPoolSpotPrice for each request, in the order of the requests.
+func (s *RouterTestSuite) TestGetPoolSpotPrices() {
+	mainnetState := s.SetupMainnetState()
+	mainnetUsecase := s.SetupRouterAndPoolsUsecase(mainnetState)
+
+	requests := []domain.SpotPriceRequest{
+		{PoolID: poolIDOneBalancer, QuoteDenom: UOSMO, BaseDenom: ATOM},
+		{PoolID: poolIDOneBalancer, QuoteDenom: ATOM, BaseDenom: UOSMO},
+		// Denom that is not in the pool results in an error.
+		{PoolID: poolIDOneBalancer, QuoteDenom: UOSMO, BaseDenom: "unknown"},
+	}
+
+	spotPrices, errs := mainnetUsecase.Router.GetPoolSpotPrices(context.Background(), requests)
+	s.Require().Len(spotPrices, len(requests))
+	s.Require().Len(errs, len(requests))
+
+	for i, request := range requests {
+		expectedSpotPrice, expectedErr := mainnetUsecase.Router.GetPoolSpotPrice(context.Background(), request.PoolID, request.QuoteDenom, request.BaseDenom)
+		if expectedErr != nil {
+			s.Require().Error(errs[i])
+			continue
+		}
+
+		s.Require().NoError(errs[i])
+		s.Require().Equal(expectedSpotPrice.String(), spotPrices[i].String())
+	}
+
+	s.Require().Error(errs[2])
+}
+
+// Validates that the min liquidity multiple filters out the pools whose liquidity is less than
+// the multiple of the OSMO value of the token in, on top of the absolute min liquidity.
+func (s *RouterTestSuite) TestGetOptimalQuote_MinLiquidityMultiple() {
+	const (
+		// 1_000 OSMO
+		tokenInAmount = 1_000_000_000
+		multiple      = 50
+	)
+
+	mainnetState := s.SetupMainnetState()
+	mainnetUsecase := s.SetupRouterAndPoolsUsecase(mainnetState)
+
+	tokenIn := sdk.NewCoin(UOSMO, osmomath.NewInt(tokenInAmount))
+
+	quote, err := mainnetUsecase.Router.GetOptimalQuote(context.Background(), tokenIn, ATOM, domain.WithMinLiquidityMultiple(osmomath.NewDec(multiple)))
+	s.Require().NoError(err)
+
+	// The token in is worth 1_000 OSMO so the pools must have at least 50_000 OSMO of liquidity.
+	minLiquidity := osmomath.NewInt(tokenInAmount / 1_000_000 * multiple)
+	for _, route := range quote.GetRoute() {
+		for _, pool := range route.GetPools() {
+			routablePool, err := mainnetUsecase.Pools.GetPool(pool.GetId())
+			s.Require().NoError(err)
+			s.Require().True(routablePool.GetTotalValueLockedUSDC().GTE(minLiquidity), "pool (%d) liquidity is below the min", pool.GetId())
+		}
+	}
+
+	// No pool has the liquidity of the extreme multiple.
+	_, err = mainnetUsecase.Router.GetOptimalQuote(context.Background(), tokenIn, ATOM, domain.WithMinLiquidityMultiple(osmomath.NewDec(1_000_000_000)))
+	s.Require().Error(err)
+
+	// The non-positive multiple disables the filtering.
+	quoteWithoutMultiple, err := mainnetUsecase.Router.GetOptimalQuote(context.Background(), tokenIn, ATOM)
+	s.Require().NoError(err)
+
+	quoteWithZeroMultiple, err := mainnetUsecase.Router.GetOptimalQuote(context.Background(), tokenIn, ATOM, domain.WithMinLiquidityMultiple(osmomath.ZeroDec()))
+	s.Require().NoError(err)
+	s.Require().Equal(quoteWithoutMultiple.GetAmountOut().String(), quoteWithZeroMultiple.GetAmountOut().String())
+}
+
+// customSpotPriceHandler is a domain.SpotPriceHandler that returns a fixed spot price
+// and records the IDs of the pools it prices.
+type customSpotPriceHandler struct {
+	spotPrice osmomath.BigDec
+	poolIDs   []uint64
+}
+
+var _ domain.SpotPriceHandler = &customSpotPriceHandler{}
+
+// CalcSpotPrice implements domain.SpotPriceHandler.
+func (h *customSpotPriceHandler) CalcSpotPrice(ctx context.Context, pool sqsdomain.PoolI, takerFee osmomath.Dec, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+	h.poolIDs = append(h.poolIDs, pool.GetId())
+	return h.spotPrice, nil
+}
+
+// Validates that the spot prices of the CosmWasm pools with a registered code ID are computed
+// by the custom handler while the other pools use the default computation.
+func (s *RouterTestSuite) TestRegisterCustomPoolSpotPriceHandler() {
+	mainnetState := s.SetupMainnetState()
+	mainnetUsecase := s.SetupRouterAndPoolsUsecase(mainnetState)
+
+	var (
+		cosmWasmPool sqsdomain.PoolI
+		codeID       uint64
+	)
+	for _, pool := range mainnetUsecase.Router.GetSortedPools() {
+		if pool.GetType() != poolmanagertypes.CosmWasm {
+			continue
+		}
+
+		if cosmWasmExtension, ok := pool.GetUnderlyingPool().(cosmwasmpooltypes.CosmWasmExtension); ok {
+			cosmWasmPool = pool
+			codeID = cosmWasmExtension.GetCodeId()
+			break
+		}
+	}
+	s.Require().NotNil(cosmWasmPool)
+
+	poolDenoms := cosmWasmPool.GetPoolDenoms()
+	s.Require().GreaterOrEqual(len(poolDenoms), 2)
+	quoteDenom, baseDenom := poolDenoms[0], poolDenoms[1]
+
+	// Ensure that the taker fee of the pair is known.
+	mainnetUsecase.Router.SetTakerFees(sqsdomain.TakerFeeMap{
+		{Denom0: quoteDenom, Denom1: baseDenom}: osmomath.ZeroDec(),
+	})
+
+	balancerSpotPriceBefore, err := mainnetUsecase.Router.GetPoolSpotPrice(context.Background(), poolIDOneBalancer, UOSMO, ATOM)
+	s.Require().NoError(err)
+
+	handler := &customSpotPriceHandler{spotPrice: osmomath.NewBigDec(42)}
+	otherHandler := &customSpotPriceHandler{spotPrice: osmomath.NewBigDec(7)}
+	mainnetUsecase.Router.RegisterCustomPoolSpotPriceHandler(codeID, handler)
+	mainnetUsecase.Router.RegisterCustomPoolSpotPriceHandler(codeID+1, otherHandler)
+
+	spotPrice, err := mainnetUsecase.Router.GetPoolSpotPrice(context.Background(), cosmWasmPool.GetId(), quoteDenom, baseDenom)
+	s.Require().NoError(err)
+	s.Require().Equal(handler.spotPrice.String(), spotPrice.String())
+	s.Require().Equal([]uint64{cosmWasmPool.GetId()}, handler.poolIDs)
+	s.Require().Empty(otherHandler.poolIDs)
+
+	// The non-CosmWasm pools are unaffected.
+	balancerSpotPriceAfter, err := mainnetUsecase.Router.GetPoolSpotPrice(context.Background(), poolIDOneBalancer, UOSMO, ATOM)
+	s.Require().NoError(err)
+	s.Require().Equal(balancerSpotPriceBefore.String(), balancerSpotPriceAfter.String())
+	s.Require().Len(handler.poolIDs, 1)
+}
+
 // This is a sanity-check to ensure that the pools are sorted as intended and persisted
 // in the router usecase state.
 func (s *RouterTestSuite) TestSortPools() {
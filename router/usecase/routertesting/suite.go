@@ -1,6 +1,7 @@
 package routertesting
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -164,12 +165,13 @@ var (
 	}
 
 	DefaultPricingRouterConfig = domain.RouterConfig{
-		PreferredPoolIDs:  []uint64{},
-		MaxRoutes:         5,
-		MaxPoolsPerRoute:  3,
-		MaxSplitRoutes:    3,
-		MinOSMOLiquidity:  50,
-		RouteCacheEnabled: true,
+		PreferredPoolIDs:   []uint64{},
+		MaxRoutes:          5,
+		MaxPoolsPerRoute:   3,
+		MaxSplitRoutes:     3,
+		MaxSplitIterations: 10,
+		MinOSMOLiquidity:   50,
+		RouteCacheEnabled:  true,
 	}
 
 	DefaultPricingConfig = domain.PricingConfig{
@@ -327,7 +329,8 @@ func (s *RouterTestHelper) SetupRouterAndPoolsUsecase(mainnetState MockMainnetSt
 	err = poolsUsecase.StorePools(mainnetState.Pools)
 	s.Require().NoError(err)
 
-	routerUsecase := routerusecase.NewRouterUsecase(routerRepositoryMock, poolsUsecase, options.RouterConfig, poolsUsecase.GetCosmWasmPoolConfig(), logger, options.RankedRoutes, options.CandidateRoutes)
+	routerUsecase, err := routerusecase.NewRouterUsecase(routerRepositoryMock, poolsUsecase, options.RouterConfig, poolsUsecase.GetCosmWasmPoolConfig(), logger, options.RankedRoutes, options.CandidateRoutes)
+	s.Require().NoError(err)
 
 	// Validate and sort pools
 	sortedPools := routerusecase.ValidateAndSortPools(mainnetState.Pools, poolsUsecase.GetCosmWasmPoolConfig(), options.RouterConfig.PreferredPoolIDs, logger)
@@ -337,7 +340,7 @@ func (s *RouterTestHelper) SetupRouterAndPoolsUsecase(mainnetState MockMainnetSt
 	tokensUsecase := tokensusecase.NewTokensUsecase(mainnetState.TokensMetadata)
 
 	// Set up on-chain pricing strategy
-	pricingSource, err := pricing.NewPricingStrategy(options.PricingConfig, tokensUsecase, routerUsecase)
+	pricingSource, _, err := pricing.NewPricingStrategy(context.Background(), options.PricingConfig, tokensUsecase, routerUsecase, logger)
 	s.Require().NoError(err)
 
 	pricingSource = pricing.WithPricingCache(pricingSource, options.Pricing)
@@ -346,7 +349,7 @@ func (s *RouterTestHelper) SetupRouterAndPoolsUsecase(mainnetState MockMainnetSt
 
 	encCfg := app.MakeEncodingConfig()
 
-	ingestUsecase, err := ingestusecase.NewIngestUsecase(poolsUsecase, routerUsecase, nil, encCfg.Marshaler, nil, logger)
+	ingestUsecase, err := ingestusecase.NewIngestUsecase(poolsUsecase, routerUsecase, nil, nil, encCfg.Marshaler, nil, logger)
 	if err != nil {
 		panic(err)
 	}
@@ -149,11 +149,12 @@ func (a *TokensHandler) GetPrices(c echo.Context) (err error) {
 	}
 
 	if isHumanDenoms {
+		chainDenoms, errs := a.TUsecase.GetChainDenoms(baseDenoms)
 		for i, baseDenom := range baseDenoms {
-			baseDenoms[i], err = a.TUsecase.GetChainDenom(baseDenom)
-			if err != nil {
-				return c.JSON(http.StatusBadRequest, domain.ResponseError{Message: err.Error()})
+			if errs[i] != nil {
+				return c.JSON(http.StatusBadRequest, domain.ResponseError{Message: errs[i].Error()})
 			}
+			baseDenoms[i] = chainDenoms[baseDenom]
 		}
 	} else {
 		for _, baseDenom := range baseDenoms {
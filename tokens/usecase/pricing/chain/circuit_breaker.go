@@ -0,0 +1,141 @@
+package chainpricing
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/osmosis-labs/sqs/domain"
+)
+
+var openCircuitsGauge = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "sqs_pricing_open_circuits",
+		Help: "Number of base denoms with the pricing circuit breaker open",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(openCircuitsGauge)
+}
+
+// circuitBreaker tracks consecutive pricing failures per base denom.
+// After the failure threshold is reached within the cooldown window, the circuit opens
+// and the calls are short-circuited with the last error until the cooldown elapses.
+// Then, a single probe is allowed. If it succeeds, the circuit closes. Otherwise, it reopens.
+type circuitBreaker struct {
+	failureThreshold int
+	cooldown         time.Duration
+
+	mu     sync.Mutex
+	states map[string]*denomCircuitState
+}
+
+// denomCircuitState is the circuit breaker state of a single base denom.
+type denomCircuitState struct {
+	consecutiveFailures int
+	firstFailureTime    time.Time
+	lastErr             error
+
+	isOpen    bool
+	openedAt  time.Time
+	isProbing bool
+}
+
+// newCircuitBreaker returns a new circuit breaker.
+// Returns nil if the failure threshold is not positive, disabling the circuit breaker.
+func newCircuitBreaker(failureThreshold int, cooldown time.Duration) *circuitBreaker {
+	if failureThreshold <= 0 {
+		return nil
+	}
+
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		states:           make(map[string]*denomCircuitState),
+	}
+}
+
+// allow returns nil if the call for the given base denom may proceed.
+// Otherwise, returns domain.PricingCircuitOpenError.
+// If the cooldown elapsed, allows a single probe call.
+func (cb *circuitBreaker) allow(baseDenom string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	state, ok := cb.states[baseDenom]
+	if !ok || !state.isOpen {
+		return nil
+	}
+
+	if !state.isProbing && time.Since(state.openedAt) >= cb.cooldown {
+		state.isProbing = true
+		return nil
+	}
+
+	return domain.PricingCircuitOpenError{
+		BaseDenom: baseDenom,
+		LastErr:   state.lastErr,
+	}
+}
+
+// recordResult records the result of the call for the given base denom.
+func (cb *circuitBreaker) recordResult(baseDenom string, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	state, ok := cb.states[baseDenom]
+
+	if err == nil {
+		if ok {
+			if state.isOpen {
+				openCircuitsGauge.Dec()
+			}
+			delete(cb.states, baseDenom)
+		}
+		return
+	}
+
+	now := time.Now()
+
+	if !ok {
+		state = &denomCircuitState{}
+		cb.states[baseDenom] = state
+	}
+
+	state.lastErr = err
+
+	// Failed probe reopens the circuit for another cooldown.
+	if state.isOpen {
+		state.isProbing = false
+		state.openedAt = now
+		return
+	}
+
+	// Restart counting if the previous failures are outside of the window.
+	if state.consecutiveFailures == 0 || now.Sub(state.firstFailureTime) > cb.cooldown {
+		state.consecutiveFailures = 0
+		state.firstFailureTime = now
+	}
+
+	state.consecutiveFailures++
+
+	if state.consecutiveFailures >= cb.failureThreshold {
+		state.isOpen = true
+		state.openedAt = now
+		openCircuitsGauge.Inc()
+	}
+}
+
+// recordAbandoned records that the call for the given base denom was abandoned before completing,
+// for example, due to the exhausted compute budget. It is neither a success nor a failure
+// so it only releases the probe, if any, so that another probe is allowed.
+func (cb *circuitBreaker) recordAbandoned(baseDenom string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if state, ok := cb.states[baseDenom]; ok {
+		state.isProbing = false
+	}
+}
@@ -0,0 +1,47 @@
+package chainpricing
+
+import (
+	"context"
+	"time"
+
+	"github.com/osmosis-labs/sqs/domain"
+)
+
+var (
+	PricesReferenceDivergenceCounter = pricesReferenceDivergenceCounter
+	CacheMissReasonCounter           = cacheMissReasonCounter
+	PricesTWAPPoolPricesCounter      = pricesTWAPPoolPricesCounter
+	PricesRequestsCounter            = pricesRequestsCounter
+	CachePurgedEntriesCounter        = cachePurgedEntriesCounter
+	PricesPrecisionLossCounter       = pricesPrecisionLossCounter
+
+	PoolDataFreshnessCheckErrorsCounter = poolDataFreshnessCheckErrorsCounter
+)
+
+var HasPrecisionLoss = hasPrecisionLoss
+
+// NewTokenBucketRateLimiter returns a new token bucket rate limiter.
+func NewTokenBucketRateLimiter(ratePerSecond float64, burst int) domain.RateLimiter {
+	return newTokenBucketRateLimiter(ratePerSecond, burst)
+}
+
+// RateLimiterBucketsLen returns the number of the buckets of the given token bucket rate limiter.
+func RateLimiterBucketsLen(rateLimiter domain.RateLimiter) int {
+	tokenBucketRateLimiter := rateLimiter.(*tokenBucketRateLimiter)
+
+	tokenBucketRateLimiter.mu.Lock()
+	defer tokenBucketRateLimiter.mu.Unlock()
+
+	return len(tokenBucketRateLimiter.buckets)
+}
+
+// RouteCacheLen returns the number of the quotes in the route cache of the given chain pricing source.
+func RouteCacheLen(pricingSource domain.PricingSource) int {
+	return pricingSource.(*chainPricing).routeCache.Len()
+}
+
+// ValidatePinnedRoutesOnPoolLoad validates the pinned routes of the given chain pricing source
+// once the router pools are loaded, checking for the pools on the given interval until the context is cancelled.
+func ValidatePinnedRoutesOnPoolLoad(ctx context.Context, pricingSource domain.PricingSource, interval time.Duration) {
+	pricingSource.(*chainPricing).validatePinnedRoutesOnPoolLoad(ctx, interval)
+}
@@ -0,0 +1,65 @@
+package chainpricing
+
+import (
+	"sync"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+)
+
+// heightPricingEntry is the pricing route of a pair alongside the pool spot prices
+// along it computed at the same height.
+type heightPricingEntry struct {
+	routePoolIDs []uint64
+	routeFee     osmomath.Dec
+	// spotPrices are the pool spot prices along the route in the order of the route pools.
+	spotPrices []osmomath.BigDec
+}
+
+// heightPricingCache caches the pricing entries of the pairs computed at the latest height
+// so that the repeated price computations within the same block recompute the price
+// from the cached spot prices without any network call.
+// The entries are invalidated once an entry at a greater height is set.
+type heightPricingCache struct {
+	mu      sync.Mutex
+	height  uint64
+	entries map[string]heightPricingEntry
+}
+
+// newHeightPricingCache returns a new height pricing cache.
+func newHeightPricingCache() *heightPricingCache {
+	return &heightPricingCache{
+		entries: make(map[string]heightPricingEntry),
+	}
+}
+
+// get returns the pricing entry for the given key if it was computed at the given height.
+func (h *heightPricingCache) get(height uint64, key string) (heightPricingEntry, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if height != h.height {
+		return heightPricingEntry{}, false
+	}
+
+	entry, ok := h.entries[key]
+	return entry, ok
+}
+
+// set sets the pricing entry for the given key computed at the given height.
+// The entries at the lower heights are invalidated. The entries computed at
+// a height lower than the latest one are ignored since they are already outdated.
+func (h *heightPricingCache) set(height uint64, key string, entry heightPricingEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if height < h.height {
+		return
+	}
+
+	if height > h.height {
+		h.height = height
+		h.entries = make(map[string]heightPricingEntry)
+	}
+
+	h.entries[key] = entry
+}
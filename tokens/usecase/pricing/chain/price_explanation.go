@@ -0,0 +1,183 @@
+package chainpricing
+
+import (
+	"context"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/domain"
+)
+
+// priceExplanationRecorderKey is the context key of the price explanation recorder.
+type priceExplanationRecorderKey struct{}
+
+// priceExplanationRecorder collects the intermediates of a price computation into its explanation.
+// It is carried by the context so that the computation is shared with the unexplained prices.
+// The methods are no-ops on the nil recorder so that the computation records unconditionally.
+// The nested price computations, such as the OSMO valuation of the route liquidity, must detach it
+// via withoutPriceExplanationRecorder so that they are not recorded into the explanation.
+// The pool spot prices are recorded concurrently by the volume-weighted pricing.
+// The recorder also carries the single route that the price is computed along, if any, for GetPriceAndRoute.
+type priceExplanationRecorder struct {
+	mu          sync.Mutex
+	explanation domain.PriceExplanation
+
+	// priceRoute is the single route that the price is computed along. Nil if none.
+	priceRoute domain.SplitRoute
+	// priceRouteTokenIn is the token in that the price route is quoted for.
+	priceRouteTokenIn sdk.Coin
+}
+
+// withPriceExplanationRecorder returns the context carrying a new price explanation recorder alongside the recorder.
+func withPriceExplanationRecorder(ctx context.Context) (context.Context, *priceExplanationRecorder) {
+	recorder := &priceExplanationRecorder{}
+	return context.WithValue(ctx, priceExplanationRecorderKey{}, recorder), recorder
+}
+
+// withoutPriceExplanationRecorder returns the context that does not carry the price explanation recorder.
+func withoutPriceExplanationRecorder(ctx context.Context) context.Context {
+	if getPriceExplanationRecorder(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, priceExplanationRecorderKey{}, (*priceExplanationRecorder)(nil))
+}
+
+// getPriceExplanationRecorder returns the price explanation recorder carried by the given context.
+// Returns nil if there is none.
+func getPriceExplanationRecorder(ctx context.Context) *priceExplanationRecorder {
+	recorder, _ := ctx.Value(priceExplanationRecorderKey{}).(*priceExplanationRecorder)
+	return recorder
+}
+
+// recordRoute records the route with the given pool spot price requests and the spot prices in the same order.
+func (r *priceExplanationRecorder) recordRoute(spotPriceRequests []domain.SpotPriceRequest, spotPrices []osmomath.BigDec) {
+	if r == nil {
+		return
+	}
+
+	route := domain.PriceExplanationRoute{
+		Pools:      make([]domain.PriceExplanationPool, 0, len(spotPriceRequests)),
+		ChainPrice: multiplySpotPrices(spotPrices),
+	}
+	for i, request := range spotPriceRequests {
+		route.Pools = append(route.Pools, domain.PriceExplanationPool{
+			PoolID:     request.PoolID,
+			QuoteDenom: request.QuoteDenom,
+			BaseDenom:  request.BaseDenom,
+			SpotPrice:  spotPrices[i],
+		})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.explanation.Routes = append(r.explanation.Routes, route)
+}
+
+// recordScaling records the quote coin and the precision scaling factor.
+func (r *priceExplanationRecorder) recordScaling(quoteCoin sdk.Coin, precisionScalingFactor osmomath.BigDec, isDefaultScalingFactorUsed bool) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.explanation.QuoteCoin = quoteCoin
+	r.explanation.PrecisionScalingFactor = precisionScalingFactor
+	r.explanation.IsDefaultScalingFactorUsed = isDefaultScalingFactorUsed
+}
+
+// recordPinnedRoute records that the price is computed along the pinned route.
+func (r *priceExplanationRecorder) recordPinnedRoute() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.explanation.IsPinnedRoute = true
+}
+
+// recordAlternativeMethod records that the chain price is computed with the alternative method.
+func (r *priceExplanationRecorder) recordAlternativeMethod() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.explanation.IsAlternativeMethodUsed = true
+}
+
+// recordChainPrice records the chain price prior to netting out the route fee.
+func (r *priceExplanationRecorder) recordChainPrice(chainPrice osmomath.BigDec) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.explanation.ChainPrice = chainPrice
+	r.explanation.IsChainPriceTruncated = chainPrice.IsZero()
+}
+
+// recordRouteFee records the route fee netted out of the chain price.
+func (r *priceExplanationRecorder) recordRouteFee(routeFee osmomath.Dec) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.explanation.RouteFee = routeFee
+}
+
+// recordPrice records the final price alongside whether it is rounded from the given unrounded price.
+func (r *priceExplanationRecorder) recordPrice(unroundedPrice osmomath.BigDec, price osmomath.BigDec) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.explanation.Price = price
+	r.explanation.IsPriceRounded = !price.Equal(unroundedPrice)
+}
+
+// recordPriceRoute records the single route that the price is computed along alongside the token in it is quoted for.
+func (r *priceExplanationRecorder) recordPriceRoute(route domain.SplitRoute, tokenIn sdk.Coin) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.priceRoute = route
+	r.priceRouteTokenIn = tokenIn
+}
+
+// getPriceRoute returns the single route that the price is computed along alongside the token in it is quoted for.
+// Returns a nil route if the price is not computed along a single route.
+func (r *priceExplanationRecorder) getPriceRoute() (domain.SplitRoute, sdk.Coin) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.priceRoute, r.priceRouteTokenIn
+}
+
+// getExplanation returns the explanation recorded so far.
+func (r *priceExplanationRecorder) getExplanation() domain.PriceExplanation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.explanation
+}
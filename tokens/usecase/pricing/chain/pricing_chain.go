@@ -2,40 +2,341 @@ package chainpricing
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/osmosis-labs/osmosis/osmomath"
+	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
 	"github.com/osmosis-labs/sqs/domain"
 	"github.com/osmosis-labs/sqs/domain/cache"
 	"github.com/osmosis-labs/sqs/domain/mvc"
+	"github.com/osmosis-labs/sqs/log"
+	"github.com/osmosis-labs/sqs/sqsdomain"
+	"go.uber.org/zap"
 )
 
 type chainPricing struct {
 	TUsecase mvc.TokensUsecase
 	RUsecase mvc.RouterUsecase
 
-	cache         *cache.Cache
+	// config is the effective pricing config exposed via PricingDebugInfo.
+	config domain.PricingConfig
+
+	logger log.Logger
+
+	// cache is swapped atomically since InitializeCache might overlap serving the prices.
+	// Read it via getCache.
+	cache         atomic.Pointer[cache.Cache]
 	cacheExpiryNs time.Duration
 
 	defaultQuoteDenom string
+	// compositeQuote is the basket of the chain denoms that replaces the default quote denom.
+	// The default quote denom is its synthetic denom formatted by formatCompositeQuoteDenom.
+	// Empty if not configured.
+	compositeQuote []domain.BasketComponent
+	// usdQuoteDenom is the chain denom of the default quote human denom that the USD prices are quoted in.
+	// It differs from the default quote denom if the composite quote is configured
+	// since the USD rate and the peg apply to the default quote human denom rather than to the basket.
+	usdQuoteDenom string
+	// defaultQuoteUSDRate is the USD rate of the default quote human denom.
+	// Nil if not configured.
+	defaultQuoteUSDRate osmomath.Dec
+	// isDefaultQuoteUSDPegged is true if the default quote human denom is USD-pegged.
+	isDefaultQuoteUSDPegged bool
+
+	// referenceQuoteDenom is the secondary quote denom used to cross-check the computed prices.
+	// Empty if disabled.
+	referenceQuoteDenom string
+	// referenceDivergenceThreshold is the max relative divergence from the cross-checked price.
+	referenceDivergenceThreshold osmomath.BigDec
+
+	// minPrice and maxPrice bound the computed prices.
+	// Nil if not configured.
+	minPrice osmomath.BigDec
+	maxPrice osmomath.BigDec
+	// priceBounds maps the lower cased base denoms to their price bounds against the default quote denom.
+	priceBounds map[string]priceBound
+
+	// denomCategories maps the lower cased base denoms to their category names for the pricing request metric.
+	denomCategories map[string]string
 
 	maxPoolsPerRoute int
 	maxRoutes        int
-	minOSMOLiquidity int
+	// stablecoinQuoteDenoms is the set of the quote denoms that are probed with tokenInMultiplier tokens.
+	// Nil implies that all of the quote denoms are.
+	stablecoinQuoteDenoms map[string]struct{}
+	minOSMOLiquidity      int
+	allowedPoolTypes      []poolmanagertypes.PoolType
+	// requiredIntermediateDenom is the denom that the pricing routes must pass through.
+	// Empty if not configured.
+	requiredIntermediateDenom string
+	// alwaysRecompute is the default of the recompute prices option.
+	alwaysRecompute bool
+	// volumeWeightedRouteSelection biases the route selection toward higher volume pools.
+	volumeWeightedRouteSelection bool
+
+	// routeCache caches the pricing quotes for reuse within the same route update height window.
+	// Nil if route reuse is disabled.
+	routeCache *cache.Cache
+	// routeUpdateHeightInterval is the number of heights in a route update height window.
+	routeUpdateHeightInterval uint64
+	// latestRouteHeightWindow is the latest route update height window that a quote was cached for.
+	// The quotes cached for the lower windows are purged once it advances so that the route cache
+	// does not retain the quotes of the pairs that are no longer priced.
+	latestRouteHeightWindow atomic.Uint64
+	// heightPricingCache caches the pricing routes alongside the spot prices along them per height
+	// so that the repeated computations within the same block make no network calls.
+	// Nil if route reuse is disabled.
+	heightPricingCache *heightPricingCache
+
+	// spotPriceCache caches the pool spot prices to deduplicate the spot price lookups
+	// across the pricing computations within a block.
+	// Nil if disabled.
+	spotPriceCache       *cache.Cache
+	spotPriceCacheExpiry time.Duration
+
+	// spotPriceMaxRetries is the max number of retries of the transient pool spot price errors.
+	spotPriceMaxRetries int
+	// spotPriceRetryBackoff is the backoff before the first spot price retry.
+	spotPriceRetryBackoff time.Duration
+
+	// pinnedRoutes maps the pinned pairs formatted by formatPinnedRouteKey to the ordered pool IDs
+	// swapping from the quote to the base denom.
+	// The invalid pinned routes are removed once validated on the first pool load.
+	pinnedRoutes   map[string][]uint64
+	pinnedRoutesMu sync.RWMutex
+
+	// computeGroup deduplicates the concurrent price computations.
+	computeGroup singleflight.Group
+	// sharedComputes maps the keys of the in-flight deduplicated computations to their shared state.
+	sharedComputes   map[string]*sharedCompute
+	sharedComputesMu sync.Mutex
+
+	// rateLimiter limits the rate of the price recomputations per rate limit key.
+	// Nil if disabled.
+	rateLimiter domain.RateLimiter
+
+	// asyncComputeSlots bounds the concurrent computations started via GetPriceAsync.
+	// Nil if unbounded.
+	asyncComputeSlots chan struct{}
+	// routeComputeSlots bounds the concurrent route spot price computations of the split pricing.
+	// Nil if unbounded.
+	routeComputeSlots chan struct{}
+
+	// suspectPools are the pools suspected of manipulation that are excluded from the pricing routes
+	// until their expiry.
+	suspectPools *suspectPools
+
+	// circuitBreaker short-circuits the pricing of the base denoms that consistently fail.
+	// Nil if disabled.
+	circuitBreaker *circuitBreaker
+
+	// unpriceableTTL is the duration to cache the unpriceable pairs for.
+	// Zero if caching the unpriceable pairs is disabled.
+	unpriceableTTL time.Duration
+
+	// workerTrackedDenoms is the set of base denoms whose default quote prices are refreshed
+	// by the background pricing worker.
+	workerTrackedDenoms   map[string]struct{}
+	workerTrackedDenomsMu sync.RWMutex
+
+	// warmedReverseDenoms is the set of base denoms whose reverse default quote prices
+	// were cached by the last WarmReverseDefaultQuotePrices.
+	warmedReverseDenoms   map[string]struct{}
+	warmedReverseDenomsMu sync.Mutex
+
+	// blockHeightProvider provides the heights for detecting the stale pool data.
+	// Nil if not set, in which case the pool data freshness is not checked.
+	blockHeightProvider   domain.BlockHeightProvider
+	blockHeightProviderMu sync.RWMutex
+
+	// twapProvider provides the pool TWAPs for the prices computed with WithTWAPPricing(...).
+	// Nil if not set, in which case the pool spot prices are used.
+	twapProvider   domain.TWAPProvider
+	twapProviderMu sync.RWMutex
+
+	// maxPoolDataStalenessBlocks is the max number of blocks that the pool data height may lag the chain height by.
+	// Zero if the pool data freshness check is disabled.
+	maxPoolDataStalenessBlocks uint64
+
+	// lastKnownGoodPrices maps the cache keys to the last successfully computed prices.
+	// Unlike the cache, the entries do not expire but are overwritten on every successful computation.
+	// The entries not overwritten within lastKnownGoodRetention are dropped since their pairs are no longer priced.
+	lastKnownGoodPrices    map[string]cachedPrice
+	lastKnownGoodPricesMu  sync.RWMutex
+	lastKnownGoodRetention time.Duration
+
+	// servedAgeHistogram observes the age of the prices served from cache.
+	servedAgeHistogram *prometheus.HistogramVec
+
+	// routePoolCountHistogram observes the number of pools in the routes selected for pricing.
+	routePoolCountHistogram *prometheus.HistogramVec
+
+	// cacheHits, cacheMisses and inFlightComputes are the live stats exposed via PricingDebugInfo.
+	cacheHits        atomic.Uint64
+	cacheMisses      atomic.Uint64
+	inFlightComputes atomic.Int64
+}
+
+// sharedCompute is the state of a price computation shared by the concurrent callers.
+type sharedCompute struct {
+	// ctx is the context of the shared computation, detached from the cancellation of the callers.
+	ctx context.Context
+	// cancel cancels the shared computation once all of its callers have abandoned it.
+	cancel context.CancelFunc
+	// numWaiters is the number of the callers waiting on the shared computation.
+	numWaiters int
+}
+
+// cachedPrice is a price stored in cache alongside the time it was computed at
+// and, optionally, the route it was computed along.
+type cachedPrice struct {
+	price      osmomath.BigDec
+	computedAt time.Time
+	// routePoolIDs are the pool IDs of the route the price was computed along.
+	// Only set for the default quote prices cached indefinitely and computed along a single route.
+	routePoolIDs []uint64
+}
+
+// priceBound is the parsed min and max price of a base denom against the default quote denom.
+// The bounds are nil if unbounded.
+type priceBound struct {
+	min osmomath.BigDec
+	max osmomath.BigDec
+}
+
+// unpriceableMarker is cached in place of the price of the pairs that no route is found for.
+type unpriceableMarker struct{}
+
+// cacheSnapshot is the serialized form of the pricing cache.
+type cacheSnapshot struct {
+	// ExportedAt is the time the snapshot was exported at.
+	// The remaining TTLs of the entries are relative to it.
+	ExportedAt time.Time                `json:"exported_at"`
+	Entries    []domain.CachedPricePair `json:"entries"`
+}
+
+// reusableQuote is a pricing quote that is reusable
+// within the route update height window it was computed in.
+type reusableQuote struct {
+	heightWindow uint64
+	quote        domain.Quote
 }
 
-var _ domain.PricingSource = &chainPricing{}
+var _ domain.ChainPricingSource = &chainPricing{}
 
 const (
+	// osmoHumanDenom is the human denom of OSMO that the route liquidity is denominated in.
+	osmoHumanDenom = "osmo"
+
+	// poolShareDenomPrefix is the prefix of the fungible pool share denoms followed by the pool ID.
+	poolShareDenomPrefix = "gamm/pool/"
+	// poolShareExponent is the exponent of the pool share denoms.
+	poolShareExponent = 18
+
 	// We use multiplier so that stablecoin quotes avoid selecting low liquidity routes.
 	// USDC/USDT value of 10 should be sufficient to avoid low liquidity routes.
+	// It only applies to the stablecoin quote denoms.
 	tokenInMultiplier = 10
+
+	// maxPricePrecision is the max number of decimal places of the BigDec prices.
+	maxPricePrecision = 36
+
+	// minServedAgeBucketSeconds is the lower bound of the served age histogram buckets.
+	minServedAgeBucketSeconds = 0.1
+	// numServedAgeBuckets is the number of the served age histogram buckets.
+	numServedAgeBuckets = 10
+
+	// defaultUnpriceableTTL is the default duration to cache the unpriceable pairs for.
+	defaultUnpriceableTTL = time.Second
+
+	// defaultLastKnownGoodRetention is the default duration to retain the last known good prices for.
+	defaultLastKnownGoodRetention = 24 * time.Hour
+
+	// sharedComputeTimeout bounds the price computations shared by the concurrent callers
+	// since they are detached from the deadlines of the callers.
+	sharedComputeTimeout = 30 * time.Second
+
+	// pinnedRoutesValidationInterval is the interval to check whether the router pools are loaded
+	// to validate the pinned routes deferred from the startup.
+	pinnedRoutesValidationInterval = time.Second
+)
+
+// The reasons of the pricing cache misses.
+const (
+	// cacheMissReasonFirstTime is the miss of the price that is not cached,
+	// either because it has never been computed or because it has been evicted.
+	cacheMissReasonFirstTime = "first_time"
+	// cacheMissReasonExpired is the miss of the cached price that has expired within the stale grace period.
+	// The prices expired past the grace period are removed on lookup so they are first time misses.
+	cacheMissReasonExpired = "expired"
+	// cacheMissReasonForcedRecompute is the miss forced by the options that always recompute the price.
+	cacheMissReasonForcedRecompute = "forced_recompute"
+	// cacheMissReasonStale is the miss of the cached price deemed unusable, i.e. the zero price
+	// recomputed due to WithRecomputeIfZero().
+	cacheMissReasonStale = "stale"
+)
+
+// compositeQuoteDenomPrefix is the prefix of the synthetic denom of the composite quote.
+const compositeQuoteDenomPrefix = "composite:"
+
+// denomCategoryOther is the category of the denoms missing from the configured denom categories.
+const denomCategoryOther = "other"
+
+// The sources of the pool prices of the TWAP pricing.
+const (
+	// poolPriceSourceTWAP is the pool price from the TWAP provider.
+	poolPriceSourceTWAP = "twap"
+	// poolPriceSourceSpot is the pool spot price used when the TWAP is unavailable.
+	poolPriceSourceSpot = "spot"
 )
 
+// tracer traces the pricing computations.
+// It is a no-op unless the global tracer provider is configured.
+var tracer = otel.Tracer("sqs")
+
+// errLowConfidencePrice is returned alongside the prices computed with the default scaling factor
+// in place of an unknown scaling factor. It never escapes the public methods which flag the low-confidence
+// prices instead (see GetPriceWithConfidence).
+var errLowConfidencePrice = errors.New("low-confidence price computed with default scaling factor")
+
+// defaultReferenceDivergenceThreshold is the default max relative divergence between the primary
+// and the reference cross-checked prices.
+var defaultReferenceDivergenceThreshold = osmomath.MustNewBigDecFromStr("0.05")
+
+// volumeRouteSelectionTolerance is the max relative shortfall in amount out from the best route
+// for a route through higher volume pools to be selected for pricing.
+var volumeRouteSelectionTolerance = osmomath.MustNewDecFromStr("0.001")
+
+// precisionLossTolerance is the max relative deviation of the precision scaling factor computed
+// with Dec from the one computed with full BigDec precision.
+var precisionLossTolerance = osmomath.MustNewBigDecFromStr("0.000000001")
+
+// basketWeightSumTolerance is the max absolute deviation of the sum of the basket component weights from one.
+var basketWeightSumTolerance = osmomath.MustNewDecFromStr("0.000001")
+
+// usdPeggedHumanDenoms defines the human denoms that are assumed to be pegged to USD.
+var usdPeggedHumanDenoms = map[string]struct{}{
+	"usdc": {},
+	"usdt": {},
+}
+
 var (
 	cacheHitsCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -52,6 +353,14 @@ var (
 		[]string{"base", "quote"},
 	)
 
+	cacheMissReasonCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sqs_pricing_cache_miss_reason_total",
+			Help: "Total number of pricing cache misses by quote denom and reason",
+		},
+		[]string{"quote", "reason"},
+	)
+
 	pricesTruncationCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "sqs_pricing_truncation_total",
@@ -60,6 +369,74 @@ var (
 		[]string{"base", "quote"},
 	)
 
+	pricesDefaultScalingFactorCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sqs_pricing_default_scaling_factor_total",
+			Help: "Total number of low-confidence prices computed with the default scaling factor in place of an unknown scaling factor",
+		},
+		[]string{"base", "quote"},
+	)
+
+	pricesPrecisionLossCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sqs_pricing_precision_loss_total",
+			Help: "Total number of precision scaling factors computed with full precision due to the precision loss of Dec",
+		},
+		[]string{"base", "quote"},
+	)
+
+	cachePurgedEntriesCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sqs_pricing_cache_purged_entries_total",
+			Help: "Total number of expired pricing cache entries purged proactively",
+		},
+	)
+
+	pricesOutOfRangeCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sqs_pricing_out_of_range_total",
+			Help: "Total number of computed prices rejected for being invalid or out of the configured range",
+		},
+		[]string{"base", "quote"},
+	)
+
+	pricesReferenceDivergenceCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sqs_pricing_reference_divergence_total",
+			Help: "Total number of computed prices diverging from the prices cross-checked via the reference quote denom beyond the threshold",
+		},
+		[]string{"base", "quote"},
+	)
+
+	pricesEmptyRoutePoolsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sqs_pricing_empty_route_pools_total",
+			Help: "Total number of routes without pools selected for pricing",
+		},
+		[]string{"base", "quote"},
+	)
+
+	cachedEntriesGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "sqs_pricing_cache_entries",
+			Help: "Number of entries in the pricing cache including the expired entries not yet evicted",
+		},
+	)
+
+	poolDataLagGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "sqs_pricing_pool_data_lag_blocks",
+			Help: "Number of blocks that the height of the pool data used for pricing lags the chain height by",
+		},
+	)
+
+	poolDataFreshnessCheckErrorsCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sqs_pricing_pool_data_freshness_check_errors_total",
+			Help: "Total number of pool data freshness checks skipped due to failing to get the chain height",
+		},
+	)
+
 	pricesSpotPriceError = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "sqs_pricing_spot_price_error_total",
@@ -67,189 +444,3292 @@ var (
 		},
 		[]string{"base", "quote"},
 	)
+
+	pricesRequestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sqs_pricing_requests_total",
+			Help: "Total number of pricing requests by base denom category",
+		},
+		[]string{"category"},
+	)
+
+	pricesTWAPPoolPricesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sqs_pricing_twap_pool_prices_total",
+			Help: "Total number of pool prices of the TWAP pricing by source, i.e. the TWAP or the spot price fallback",
+		},
+		[]string{"source"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(cacheHitsCounter)
 	prometheus.MustRegister(cacheMissesCounter)
+	prometheus.MustRegister(cacheMissReasonCounter)
+	prometheus.MustRegister(cachePurgedEntriesCounter)
+	prometheus.MustRegister(pricesPrecisionLossCounter)
+	prometheus.MustRegister(pricesDefaultScalingFactorCounter)
+	prometheus.MustRegister(cachedEntriesGauge)
+	prometheus.MustRegister(poolDataLagGauge)
+	prometheus.MustRegister(poolDataFreshnessCheckErrorsCounter)
+	prometheus.MustRegister(pricesOutOfRangeCounter)
+	prometheus.MustRegister(pricesReferenceDivergenceCounter)
+	prometheus.MustRegister(pricesEmptyRoutePoolsCounter)
+	prometheus.MustRegister(pricesTWAPPoolPricesCounter)
+	prometheus.MustRegister(pricesRequestsCounter)
 }
 
-func New(routerUseCase mvc.RouterUsecase, tokenUseCase mvc.TokensUsecase, config domain.PricingConfig) domain.PricingSource {
-	chainDefaultHumanDenom, err := tokenUseCase.GetChainDenom(config.DefaultQuoteHumanDenom)
-	if err != nil {
-		panic(fmt.Sprintf("failed to get chain denom for default quote human denom (%s): %s", config.DefaultQuoteHumanDenom, err))
+// registerServedAgeHistogram registers the histogram of the age of the prices served from cache
+// with the buckets ranging from 100ms to the given cache TTL.
+// If already registered, returns the existing histogram.
+func registerServedAgeHistogram(cacheExpiry time.Duration) *prometheus.HistogramVec {
+	maxBucketSeconds := cacheExpiry.Seconds()
+	if maxBucketSeconds <= minServedAgeBucketSeconds {
+		// Fallback to the default buckets if the TTL is too short to span a range.
+		maxBucketSeconds = minServedAgeBucketSeconds * (1 << numServedAgeBuckets)
 	}
 
-	return &chainPricing{
-		RUsecase: routerUseCase,
-		TUsecase: tokenUseCase,
+	servedAgeHistogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sqs_pricing_served_age_seconds",
+			Help:    "Age of the prices served from the pricing cache in seconds",
+			Buckets: prometheus.ExponentialBucketsRange(minServedAgeBucketSeconds, maxBucketSeconds, numServedAgeBuckets),
+		},
+		[]string{},
+	)
 
-		cache:             cache.New(),
-		cacheExpiryNs:     time.Duration(config.CacheExpiryMs) * time.Millisecond,
-		maxPoolsPerRoute:  config.MaxPoolsPerRoute,
-		maxRoutes:         config.MaxRoutes,
-		minOSMOLiquidity:  config.MinOSMOLiquidity,
-		defaultQuoteDenom: chainDefaultHumanDenom,
+	if err := prometheus.Register(servedAgeHistogram); err != nil {
+		if alreadyRegisteredErr, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return alreadyRegisteredErr.ExistingCollector.(*prometheus.HistogramVec)
+		}
+		panic(err)
 	}
+
+	return servedAgeHistogram
 }
 
-// GetPrice implements pricing.PricingStrategy.
-func (c *chainPricing) GetPrice(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
-	options := domain.PricingOptions{
-		MinLiquidity: c.minOSMOLiquidity,
+// registerRoutePoolCountHistogram registers the histogram of the number of pools in the routes
+// selected for pricing with a bucket per pool count from 1 to the given max pools per route.
+// If already registered, returns the existing histogram.
+func registerRoutePoolCountHistogram(maxPoolsPerRoute int) *prometheus.HistogramVec {
+	numBuckets := maxPoolsPerRoute
+	if numBuckets < 1 {
+		numBuckets = 1
 	}
 
-	for _, opt := range opts {
-		opt(&options)
+	routePoolCountHistogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sqs_pricing_route_pool_count",
+			Help:    "Number of pools in the routes selected for pricing",
+			Buckets: prometheus.LinearBuckets(1, 1, numBuckets),
+		},
+		[]string{"quote"},
+	)
+
+	if err := prometheus.Register(routePoolCountHistogram); err != nil {
+		if alreadyRegisteredErr, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return alreadyRegisteredErr.ExistingCollector.(*prometheus.HistogramVec)
+		}
+		panic(err)
 	}
 
-	// Recompute prices if desired by configuration.
-	// Otherwise, look into cache first.
-	if options.RecomputePrices {
-		return c.computePrice(ctx, baseDenom, quoteDenom, options.MinLiquidity)
+	return routePoolCountHistogram
+}
+
+// New creates a new chain pricing source.
+// If the cache purge interval is configured, it starts a background goroutine
+// that purges the expired cache entries on the interval until the given context is cancelled.
+// Returns error if the default quote human denom is unknown, if the price bounds, the reference divergence
+// threshold or the composite quote are malformed or if the pinned routes are invalid.
+// The pinned routes are validated to connect the quote to the base denom if the router pools are loaded.
+// Otherwise, which is the case at boot, the validation is deferred until the first pool load
+// when the pinned routes that do not connect are logged and disabled.
+func New(ctx context.Context, routerUseCase mvc.RouterUsecase, tokenUseCase mvc.TokensUsecase, config domain.PricingConfig, logger log.Logger) (domain.ChainPricingSource, error) {
+	chainDefaultHumanDenom, err := tokenUseCase.GetChainDenom(config.DefaultQuoteHumanDenom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain denom for default quote human denom (%s): %w", config.DefaultQuoteHumanDenom, err)
 	}
 
-	// equal base and quote yield the price of one
-	if baseDenom == quoteDenom {
-		return osmomath.OneBigDec(), nil
+	var defaultQuoteUSDRate osmomath.Dec
+	if config.DefaultQuoteUSDRate != "" {
+		defaultQuoteUSDRate, err = osmomath.NewDecFromStr(config.DefaultQuoteUSDRate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse default quote USD rate (%s): %w", config.DefaultQuoteUSDRate, err)
+		}
 	}
 
-	cacheKey := domain.FormatPricingCacheKey(baseDenom, quoteDenom)
+	minPrice, err := parsePriceBound(config.MinPrice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse min price (%s): %w", config.MinPrice, err)
+	}
 
-	cachedValue, found := c.cache.Get(cacheKey)
-	if found {
-		// Cast cached value to correct type.
-		cachedBigDecPrice, ok := cachedValue.(osmomath.BigDec)
-		if !ok {
-			return osmomath.BigDec{}, fmt.Errorf("invalid type cached in pricing, expected BigDec, got (%T)", cachedValue)
-		}
+	maxPrice, err := parsePriceBound(config.MaxPrice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse max price (%s): %w", config.MaxPrice, err)
+	}
 
-		// Increase cache hits
-		cacheHitsCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
-		return cachedBigDecPrice, nil
-	} else if !found {
-		// Increase cache misses
-		cacheMissesCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+	priceBounds, err := parsePriceBounds(config.PriceBounds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse price bounds: %w", err)
 	}
 
-	// If cache miss occurs, we compute the price.
-	return c.computePrice(ctx, baseDenom, quoteDenom, options.MinLiquidity)
-}
+	referenceDivergenceThreshold := defaultReferenceDivergenceThreshold
+	if config.ReferenceDivergenceThreshold != "" {
+		referenceDivergenceThreshold, err = osmomath.NewBigDecFromStr(config.ReferenceDivergenceThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse reference divergence threshold (%s): %w", config.ReferenceDivergenceThreshold, err)
+		}
+	}
 
-// computePrice computes the price for a given base and quote denom
-func (c *chainPricing) computePrice(ctx context.Context, baseDenom string, quoteDenom string, minLiquidity int) (osmomath.BigDec, error) {
-	cacheKey := domain.FormatPricingCacheKey(baseDenom, quoteDenom)
+	defaultQuoteDenom := chainDefaultHumanDenom
 
-	if baseDenom == quoteDenom {
-		return osmomath.OneBigDec(), nil
+	var compositeQuote []domain.BasketComponent
+	if len(config.CompositeQuote) > 0 {
+		compositeQuote, err = parseCompositeQuote(config.CompositeQuote, tokenUseCase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse composite quote: %w", err)
+		}
+		defaultQuoteDenom = formatCompositeQuoteDenom(compositeQuote)
 	}
 
-	// Get on-chain scaling factor for base denom.
-	baseDenomScalingFactor, err := c.TUsecase.GetChainScalingFactorByDenomMut(baseDenom)
-	if err != nil {
-		return osmomath.BigDec{}, err
+	var pinnedRoutes map[string][]uint64
+	if len(config.PinnedRoutes) > 0 {
+		pinnedRoutes, err = parsePinnedRoutes(config.PinnedRoutes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse pinned routes: %w", err)
+		}
 	}
 
-	// Get on-chain scaling factor for quote denom.
-	quoteDenomScalingFactor, err := c.TUsecase.GetChainScalingFactorByDenomMut(quoteDenom)
-	if err != nil {
-		return osmomath.BigDec{}, err
+	// The pools are not loaded at boot until the first ingest so the validation is deferred.
+	isPinnedRoutesValidationDeferred := false
+	if len(pinnedRoutes) > 0 {
+		if pools := routerUseCase.GetSortedPools(); len(pools) > 0 {
+			if err := validatePinnedRoutes(pinnedRoutes, pools); err != nil {
+				return nil, fmt.Errorf("failed to validate pinned routes: %w", err)
+			}
+		} else {
+			isPinnedRoutesValidationDeferred = true
+		}
 	}
 
-	// Create a quote denom coin.
-	// We use multiplier so that stablecoin quotes avoid selecting low liquidity routes.
-	tenQuoteCoin := sdk.NewCoin(quoteDenom, osmomath.NewInt(tokenInMultiplier).Mul(quoteDenomScalingFactor.TruncateInt()))
+	_, isDefaultQuoteUSDPegged := usdPeggedHumanDenoms[strings.ToLower(config.DefaultQuoteHumanDenom)]
 
-	// Overwrite default config with custom values
-	// necessary for pricing.
-	routingOptions := []domain.RouterOption{
-		domain.WithMaxRoutes(c.maxRoutes),
-		domain.WithMaxPoolsPerRoute(c.maxPoolsPerRoute),
-		// Use the provided min liquidity value rather than the default
-		// Since it can be overridden by options in GetPrice(...)
-		domain.WithMinOSMOLiquidity(minLiquidity),
-		domain.WithDisableSplitRoutes(),
+	// Unset stablecoin quote denoms preserve probing all of the quote denoms with the multiplier.
+	var stablecoinQuoteDenoms map[string]struct{}
+	if config.StablecoinQuoteDenoms != nil {
+		stablecoinQuoteDenoms = make(map[string]struct{}, len(config.StablecoinQuoteDenoms))
+		for _, denom := range config.StablecoinQuoteDenoms {
+			stablecoinQuoteDenoms[denom] = struct{}{}
+		}
 	}
 
-	// Compute a quote for one quote coin.
-	quote, err := c.RUsecase.GetOptimalQuote(ctx, tenQuoteCoin, baseDenom, routingOptions...)
-	if err != nil {
-		return osmomath.BigDec{}, err
+	pricingSource := &chainPricing{
+		RUsecase: routerUseCase,
+		TUsecase: tokenUseCase,
+
+		config: config,
+
+		logger: logger,
+
+		lastKnownGoodPrices:    make(map[string]cachedPrice),
+		lastKnownGoodRetention: defaultLastKnownGoodRetention,
+		sharedComputes:         make(map[string]*sharedCompute),
+
+		cacheExpiryNs:    time.Duration(config.CacheExpiryMs) * time.Millisecond,
+		maxPoolsPerRoute: config.MaxPoolsPerRoute,
+		maxRoutes:        config.MaxRoutes,
+		minOSMOLiquidity: config.MinOSMOLiquidity,
+
+		stablecoinQuoteDenoms: stablecoinQuoteDenoms,
+		allowedPoolTypes:      config.AllowedPoolTypes,
+		alwaysRecompute:       config.AlwaysRecompute,
+
+		requiredIntermediateDenom: config.RequiredIntermediateDenom,
+
+		volumeWeightedRouteSelection: config.VolumeWeightedRouteSelection,
+
+		spotPriceMaxRetries:   config.SpotPriceMaxRetries,
+		spotPriceRetryBackoff: time.Duration(config.SpotPriceRetryBackoffMs) * time.Millisecond,
+
+		minPrice: minPrice,
+		maxPrice: maxPrice,
+
+		priceBounds: priceBounds,
+
+		denomCategories: parseDenomCategories(config.DenomCategories),
+
+		referenceQuoteDenom:          config.ReferenceQuoteDenom,
+		referenceDivergenceThreshold: referenceDivergenceThreshold,
+
+		pinnedRoutes: pinnedRoutes,
+
+		maxPoolDataStalenessBlocks: config.MaxPoolDataStalenessBlocks,
+
+		circuitBreaker: newCircuitBreaker(config.CircuitBreakerFailureThreshold, time.Duration(config.CircuitBreakerCooldownMs)*time.Millisecond),
+
+		servedAgeHistogram:      registerServedAgeHistogram(time.Duration(config.CacheExpiryMs) * time.Millisecond),
+		routePoolCountHistogram: registerRoutePoolCountHistogram(config.MaxPoolsPerRoute),
+		defaultQuoteDenom:       defaultQuoteDenom,
+		compositeQuote:          compositeQuote,
+
+		usdQuoteDenom:           chainDefaultHumanDenom,
+		defaultQuoteUSDRate:     defaultQuoteUSDRate,
+		isDefaultQuoteUSDPegged: isDefaultQuoteUSDPegged,
 	}
-	if quote == nil {
-		return osmomath.BigDec{}, fmt.Errorf("no quote found when computing pricing for %s (base) -> %s (quote)", baseDenom, quoteDenom)
+
+	// Assign conditionally so that the disabled rate limiter is a nil interface rather than a typed nil.
+	if rateLimiter := newTokenBucketRateLimiter(config.RateLimitPerSecond, config.RateLimitBurst); rateLimiter != nil {
+		pricingSource.rateLimiter = rateLimiter
 	}
 
-	routes := quote.GetRoute()
-	if len(routes) == 0 {
-		return osmomath.BigDec{}, fmt.Errorf("no route found when computing pricing for %s (base) -> %s (quote)", baseDenom, quoteDenom)
+	if config.MaxConcurrentAsyncComputes > 0 {
+		pricingSource.asyncComputeSlots = make(chan struct{}, config.MaxConcurrentAsyncComputes)
 	}
 
-	route := routes[0]
+	pricingSource.suspectPools = newSuspectPools()
 
-	chainPrice := osmomath.OneBigDec()
+	if config.MaxConcurrentRouteComputes > 0 {
+		pricingSource.routeComputeSlots = make(chan struct{}, config.MaxConcurrentRouteComputes)
+	}
 
-	pools := route.GetPools()
+	staleGracePeriod := time.Duration(config.StaleGracePeriodMs) * time.Millisecond
+	pricingCache := cache.NewShardedWithGracePeriod(config.CacheShards, config.MaxCacheEntries, staleGracePeriod)
+	trackCachedEntries(pricingCache)
+	pricingSource.cache.Store(pricingCache)
 
-	var (
-		tempQuoteDenom       = quoteDenom
-		tempBaseDenom        string
-		useAlternativeMethod = false
-	)
+	if config.SpotPriceCacheExpiryMs > 0 {
+		pricingSource.spotPriceCache = cache.New()
+		pricingSource.spotPriceCacheExpiry = time.Duration(config.SpotPriceCacheExpiryMs) * time.Millisecond
+	}
 
-	// If Astroport pool, use the alternative meth
+	if config.EnableRouteReuse {
+		pricingSource.routeCache = cache.New()
+		pricingSource.heightPricingCache = newHeightPricingCache()
 
-	for _, pool := range pools {
-		tempBaseDenom = pool.GetTokenOutDenom()
+		// Zero interval implies that each height is its own window.
+		pricingSource.routeUpdateHeightInterval = 1
+		if routeUpdateHeightInterval := routerUseCase.GetConfig().RouteUpdateHeightInterval; routeUpdateHeightInterval > 0 {
+			pricingSource.routeUpdateHeightInterval = uint64(routeUpdateHeightInterval)
+		}
+	}
 
-		// Get spot price for the pool.
-		poolSpotPrice, err := c.RUsecase.GetPoolSpotPrice(ctx, pool.GetId(), tempQuoteDenom, tempBaseDenom)
-		if err != nil || poolSpotPrice.IsNil() || poolSpotPrice.IsZero() {
-			// Increase price truncation counter
-			pricesSpotPriceError.WithLabelValues(baseDenom, quoteDenom).Inc()
+	if config.LastKnownGoodRetentionMs > 0 {
+		pricingSource.lastKnownGoodRetention = time.Duration(config.LastKnownGoodRetentionMs) * time.Millisecond
+	}
 
-			useAlternativeMethod = true
-			break
+	if config.CacheUnpriceable {
+		pricingSource.unpriceableTTL = defaultUnpriceableTTL
+		if config.UnpriceableTTLMs > 0 {
+			pricingSource.unpriceableTTL = time.Duration(config.UnpriceableTTLMs) * time.Millisecond
 		}
+	}
 
-		// Multiply spot price by the previous spot price.
-		chainPrice = chainPrice.MulMut(poolSpotPrice)
+	if config.CachePurgeIntervalMs > 0 {
+		go pricingSource.purgeExpiredPeriodically(ctx, time.Duration(config.CachePurgeIntervalMs)*time.Millisecond)
+	}
 
-		tempQuoteDenom = tempBaseDenom
+	if isPinnedRoutesValidationDeferred {
+		go pricingSource.validatePinnedRoutesOnPoolLoad(ctx, pinnedRoutesValidationInterval)
 	}
 
-	if useAlternativeMethod {
-		// Compute on-chain price for 1 unit of base denom and quote denom.
-		chainPrice = osmomath.NewBigDecFromBigInt(tenQuoteCoin.Amount.BigIntMut()).QuoMut(osmomath.NewBigDecFromBigInt(quote.GetAmountOut().BigIntMut()))
+	return pricingSource, nil
+}
+
+// GetPrice implements pricing.PricingStrategy.
+// The low-confidence prices are returned like any other price.
+func (c *chainPricing) GetPrice(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	price, err := c.getPrice(ctx, baseDenom, quoteDenom, opts...)
+	if errors.Is(err, errLowConfidencePrice) {
+		return price, nil
 	}
+	return price, err
+}
 
-	if chainPrice.IsZero() {
-		// Increase price truncation counter
-		pricesTruncationCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+// GetPriceWithConfidence implements domain.PricingSource.
+func (c *chainPricing) GetPriceWithConfidence(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (domain.PriceWithConfidence, error) {
+	price, err := c.getPrice(ctx, baseDenom, quoteDenom, opts...)
+	if errors.Is(err, errLowConfidencePrice) {
+		return domain.PriceWithConfidence{Price: price, IsLowConfidence: true}, nil
 	}
+	if err != nil {
+		// The fallback prices are returned alongside the errors that the callers opt into.
+		return domain.PriceWithConfidence{Price: price}, err
+	}
+	return domain.PriceWithConfidence{Price: price}, nil
+}
 
-	// Compute precision scaling factor.
-	precisionScalingFactor := osmomath.BigDecFromDec(osmomath.NewDec(tokenInMultiplier).MulMut(baseDenomScalingFactor.Quo(tenQuoteCoin.Amount.ToLegacyDec())))
+// getPrice returns the price of the base denom in the quote denom, looking into the cache first.
+// The low-confidence prices are returned alongside errLowConfidencePrice.
+func (c *chainPricing) getPrice(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	options := domain.PricingOptions{
+		RecomputePrices: c.alwaysRecompute,
+		MinLiquidity:    c.minOSMOLiquidity,
+		PricePrecision:  domain.NoPricePrecision,
+	}
 
-	// Apply scaling facors to descale the amounts to real amounts.
-	currentPrice := chainPrice.MulMut(precisionScalingFactor)
+	for _, opt := range opts {
+		opt(&options)
+	}
 
-	// Only store values that are valid.
-	if !currentPrice.IsNil() {
-		expirationTTL := c.cacheExpiryNs
-		// We pre-compute the price for the default quote denom in ingest handler via the background
-		// pricing worker. As a result, we store them indefinitely.
-		// We track the tokens that are modified within the block and update the prices only for those tokens.
-		if quoteDenom == c.defaultQuoteDenom {
-			expirationTTL = cache.NoExpirationTTL
+	pricesRequestsCounter.WithLabelValues(c.getDenomCategory(baseDenom)).Inc()
+
+	// Bound the total work, including the cache lookups, by the compute budget.
+	if options.ComputeBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.ComputeBudget)
+		defer cancel()
+	}
+
+	if quoteDenom == "" {
+		var err error
+		quoteDenom, err = c.resolveDefaultQuoteDenom(options)
+		if err != nil {
+			return osmomath.BigDec{}, err
 		}
-		c.cache.Set(cacheKey, currentPrice, expirationTTL)
 	}
 
-	return currentPrice, nil
-}
+	// Recompute prices if desired by configuration.
+	// Otherwise, look into cache first.
+	if c.isForcedRecompute(options) {
+		cacheMissReasonCounter.WithLabelValues(quoteDenom, cacheMissReasonForcedRecompute).Inc()
 
-// InitializeCache implements domain.PricingSource.
-func (c *chainPricing) InitializeCache(cache *cache.Cache) {
-	c.cache = cache
+		return c.computePriceWithinBudget(ctx, baseDenom, quoteDenom, options)
+	}
+
+	// equal base and quote yield the price of one
+	if baseDenom == quoteDenom {
+		return osmomath.OneBigDec(), nil
+	}
+
+	cacheKey, err := formatCacheKey(baseDenom, quoteDenom)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	missReason := cacheMissReasonFirstTime
+
+	cachedValue, cacheStatus := c.getCache().GetWithStatus(cacheKey)
+	if cacheStatus == cache.StatusExpired {
+		missReason = cacheMissReasonExpired
+	}
+
+	if cacheStatus == cache.StatusHit {
+		// Cast cached value to correct type.
+		// Prices seeded externally via InitializeCache are stored without the compute timestamp.
+		var (
+			cachedBigDecPrice osmomath.BigDec
+			computedAt        time.Time
+		)
+		switch v := cachedValue.(type) {
+		case cachedPrice:
+			cachedBigDecPrice, computedAt = v.price, v.computedAt
+		case osmomath.BigDec:
+			cachedBigDecPrice = v
+		case unpriceableMarker:
+			// Increase cache hits
+			cacheHitsCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+			c.cacheHits.Add(1)
+
+			return osmomath.BigDec{}, fmt.Errorf("%w for %s (base) -> %s (quote)", domain.ErrUnpriceable, baseDenom, quoteDenom)
+		default:
+			return osmomath.BigDec{}, fmt.Errorf("invalid type cached in pricing, expected BigDec, got (%T)", cachedValue)
+		}
+
+		// Treat zero price as a cache miss if desired by configuration.
+		if !options.RecomputeIfZero || !cachedBigDecPrice.IsZero() {
+			// Increase cache hits
+			cacheHitsCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+			c.cacheHits.Add(1)
+
+			if !computedAt.IsZero() {
+				c.servedAgeHistogram.WithLabelValues().Observe(time.Since(computedAt).Seconds())
+			}
+
+			return roundPrice(cachedBigDecPrice, options.PricePrecision), nil
+		}
+
+		missReason = cacheMissReasonStale
+	}
+
+	// Increase cache misses
+	cacheMissesCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+	cacheMissReasonCounter.WithLabelValues(quoteDenom, missReason).Inc()
+	c.cacheMisses.Add(1)
+
+	// If cache miss occurs, we compute the price.
+	return c.computePriceWithinBudget(ctx, baseDenom, quoteDenom, options)
+}
+
+// GetPriceAsync implements domain.PricingSource.
+// The returned channel is buffered so that the background computation never blocks on delivering its result,
+// even if the caller abandons the channel.
+func (c *chainPricing) GetPriceAsync(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) <-chan domain.PriceResultOrError {
+	resultCh := make(chan domain.PriceResultOrError, 1)
+
+	go func() {
+		defer close(resultCh)
+
+		if c.asyncComputeSlots != nil {
+			select {
+			case c.asyncComputeSlots <- struct{}{}:
+				defer func() { <-c.asyncComputeSlots }()
+			case <-ctx.Done():
+				resultCh <- domain.PriceResultOrError{Err: ctx.Err()}
+				return
+			}
+		}
+
+		price, err := c.GetPrice(ctx, baseDenom, quoteDenom, opts...)
+		resultCh <- domain.PriceResultOrError{Price: price, Err: err}
+	}()
+
+	return resultCh
+}
+
+// resolveDefaultQuoteDenom returns the default quote denom override of the given options if set.
+// Otherwise, returns the configured default quote denom.
+// Returns InvalidDefaultQuoteDenomError if the override is not a valid chain denom.
+func (c *chainPricing) resolveDefaultQuoteDenom(options domain.PricingOptions) (string, error) {
+	if options.DefaultQuoteDenom == "" {
+		return c.defaultQuoteDenom, nil
+	}
+
+	if !c.TUsecase.IsValidChainDenom(options.DefaultQuoteDenom) {
+		return "", domain.InvalidDefaultQuoteDenomError{Denom: options.DefaultQuoteDenom}
+	}
+
+	return options.DefaultQuoteDenom, nil
+}
+
+// computePriceWithinBudget computes the price with the stale fallback (see computePriceWithStaleFallback)
+// within the compute budget of the given options. The given context must be bounded by the compute budget.
+// The computation runs in the background so that it is abandoned rather than awaited once the budget is exhausted.
+// The abandoned computation is canceled so that it stops early unless it is shared with other callers
+// (see computePriceDeduplicated).
+// Once the budget is exhausted, returns the cached price, even if expired within the stale grace period,
+// or the last known good price alongside an error wrapping domain.ErrComputeBudgetExhausted and domain.ErrStaleData.
+// Returns error wrapping domain.ErrComputeBudgetExhausted if there is no fallback price.
+// Computes the price directly if no compute budget is set.
+func (c *chainPricing) computePriceWithinBudget(ctx context.Context, baseDenom string, quoteDenom string, options domain.PricingOptions) (osmomath.BigDec, error) {
+	if options.ComputeBudget <= 0 {
+		return c.computePriceWithStaleFallback(ctx, baseDenom, quoteDenom, options)
+	}
+
+	// Buffered so that the abandoned computation never blocks on delivering its result.
+	resultCh := make(chan domain.PriceResultOrError, 1)
+	go func() {
+		price, err := c.computePriceWithStaleFallback(ctx, baseDenom, quoteDenom, options)
+		resultCh <- domain.PriceResultOrError{Price: price, Err: err}
+	}()
+
+	select {
+	case result := <-resultCh:
+		// The computation might observe the exhausted budget before it is observed here.
+		if ctx.Err() == nil || !isContextError(result.Err) {
+			return result.Price, result.Err
+		}
+	case <-ctx.Done():
+	}
+
+	budgetErr := fmt.Errorf("%w (%s) for %s (base) -> %s (quote): %w", domain.ErrComputeBudgetExhausted, options.ComputeBudget, baseDenom, quoteDenom, ctx.Err())
+
+	// The prices that are never cached have no fallback since the cached prices are computed differently.
+	if options.RawChainPrice || !c.isCacheablePricing(options) {
+		return osmomath.BigDec{}, budgetErr
+	}
+
+	cacheKey, err := formatCacheKey(baseDenom, quoteDenom)
+	if err != nil {
+		return osmomath.BigDec{}, budgetErr
+	}
+
+	if stalePrice, found := c.getStaleCachedPrice(cacheKey); found {
+		return roundPrice(stalePrice, options.PricePrecision), fmt.Errorf("%w: %w", domain.ErrStaleData, budgetErr)
+	}
+
+	if lastKnownGoodPrice, found := c.getLastKnownGoodPrice(cacheKey); found {
+		return roundPrice(lastKnownGoodPrice.price, options.PricePrecision), fmt.Errorf("%w (last known good computed at %s): %w", domain.ErrStaleData, lastKnownGoodPrice.computedAt.UTC().Format(time.RFC3339), budgetErr)
+	}
+
+	return osmomath.BigDec{}, budgetErr
+}
+
+// computePriceWithStaleFallback computes the price. If the computation fails and serving stale prices
+// is requested, returns the cached price, even if expired within the stale grace period,
+// alongside an error wrapping domain.ErrStaleData and the computation error.
+// Otherwise, if the last known good fallback is requested, returns the last successfully computed price
+// alongside an error wrapping domain.ErrStaleData and the computation error.
+// Otherwise, returns the computation error.
+func (c *chainPricing) computePriceWithStaleFallback(ctx context.Context, baseDenom string, quoteDenom string, options domain.PricingOptions) (osmomath.BigDec, error) {
+	price, err := c.computePriceWithRateLimit(ctx, baseDenom, quoteDenom, options)
+	if !isPricingFailure(err) || options.VolumeWeightedPricing || options.RawChainPrice {
+		return price, err
+	}
+
+	cacheKey, keyErr := formatCacheKey(baseDenom, quoteDenom)
+	if keyErr != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	if options.StaleOnError {
+		if stalePrice, found := c.getStaleCachedPrice(cacheKey); found {
+			return roundPrice(stalePrice, options.PricePrecision), fmt.Errorf("%w for %s (base) -> %s (quote): %w", domain.ErrStaleData, baseDenom, quoteDenom, err)
+		}
+	}
+
+	if options.LastKnownGoodFallback {
+		if lastKnownGoodPrice, found := c.getLastKnownGoodPrice(cacheKey); found {
+			return roundPrice(lastKnownGoodPrice.price, options.PricePrecision), fmt.Errorf("%w (last known good computed at %s) for %s (base) -> %s (quote): %w", domain.ErrStaleData, lastKnownGoodPrice.computedAt.UTC().Format(time.RFC3339), baseDenom, quoteDenom, err)
+		}
+	}
+
+	return osmomath.BigDec{}, err
+}
+
+// getStaleCachedPrice returns the cached price of the given cache key, even if expired within the stale grace period.
+// Returns false if not found.
+func (c *chainPricing) getStaleCachedPrice(cacheKey string) (osmomath.BigDec, bool) {
+	cachedValue, _, found := c.getCache().GetStale(cacheKey)
+	if !found {
+		return osmomath.BigDec{}, false
+	}
+
+	switch v := cachedValue.(type) {
+	case cachedPrice:
+		return v.price, true
+	case osmomath.BigDec:
+		return v, true
+	default:
+		return osmomath.BigDec{}, false
+	}
+}
+
+// getLastKnownGoodPrice returns the last successfully computed price of the given cache key.
+// Returns false if not found or computed before the retention.
+func (c *chainPricing) getLastKnownGoodPrice(cacheKey string) (cachedPrice, bool) {
+	c.lastKnownGoodPricesMu.RLock()
+	defer c.lastKnownGoodPricesMu.RUnlock()
+
+	lastKnownGoodPrice, found := c.lastKnownGoodPrices[cacheKey]
+	if !found || time.Since(lastKnownGoodPrice.computedAt) > c.lastKnownGoodRetention {
+		return cachedPrice{}, false
+	}
+
+	return lastKnownGoodPrice, true
+}
+
+// pruneLastKnownGoodPrices drops the last known good prices computed before the retention.
+// Returns the number of dropped prices.
+func (c *chainPricing) pruneLastKnownGoodPrices() int {
+	c.lastKnownGoodPricesMu.Lock()
+	defer c.lastKnownGoodPricesMu.Unlock()
+
+	prunedCount := 0
+	for cacheKey, lastKnownGoodPrice := range c.lastKnownGoodPrices {
+		if time.Since(lastKnownGoodPrice.computedAt) > c.lastKnownGoodRetention {
+			delete(c.lastKnownGoodPrices, cacheKey)
+			prunedCount++
+		}
+	}
+
+	return prunedCount
+}
+
+// setLastKnownGoodPrice overwrites the last successfully computed price of the given cache key.
+func (c *chainPricing) setLastKnownGoodPrice(cacheKey string, price cachedPrice) {
+	c.lastKnownGoodPricesMu.Lock()
+	defer c.lastKnownGoodPricesMu.Unlock()
+
+	c.lastKnownGoodPrices[cacheKey] = price
+}
+
+// computePriceWithRateLimit computes the price unless the rate limit of the rate limit key is exhausted.
+// Returns domain.ErrRateLimited if so.
+func (c *chainPricing) computePriceWithRateLimit(ctx context.Context, baseDenom string, quoteDenom string, options domain.PricingOptions) (osmomath.BigDec, error) {
+	if c.rateLimiter != nil && options.RateLimitKey != "" && !c.rateLimiter.Allow(options.RateLimitKey) {
+		return osmomath.BigDec{}, domain.ErrRateLimited
+	}
+
+	return c.computePriceDeduplicated(ctx, baseDenom, quoteDenom, options)
+}
+
+// computePriceDeduplicated computes the price so that the concurrent computations
+// of the same pair with the same options share a single computation and its result.
+// This applies to recomputations too so that the concurrent recomputes are deduplicated.
+// The shared computation runs detached from the cancellation of the callers, bounded by sharedComputeTimeout,
+// so that a caller abandoning the request does not fail the computation for the others.
+// Each caller stops waiting on the cancellation of its own context and the shared computation
+// is canceled once all of its callers have stopped waiting.
+func (c *chainPricing) computePriceDeduplicated(ctx context.Context, baseDenom string, quoteDenom string, options domain.PricingOptions) (osmomath.BigDec, error) {
+	// The precision providers cannot be formatted into the key so the computations with them are not deduplicated.
+	if options.PrecisionProvider != nil {
+		return c.computePriceWithCircuitBreaker(ctx, baseDenom, quoteDenom, options)
+	}
+
+	key := formatComputePriceKey(baseDenom, quoteDenom, options)
+
+	c.sharedComputesMu.Lock()
+	shared, ok := c.sharedComputes[key]
+	if !ok {
+		sharedCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedComputeTimeout)
+		shared = &sharedCompute{ctx: sharedCtx, cancel: cancel}
+		c.sharedComputes[key] = shared
+	}
+	shared.numWaiters++
+	resultCh := c.computeGroup.DoChan(key, func() (interface{}, error) {
+		c.inFlightComputes.Add(1)
+		defer c.inFlightComputes.Add(-1)
+
+		return c.computePriceWithCircuitBreaker(shared.ctx, baseDenom, quoteDenom, options)
+	})
+	c.sharedComputesMu.Unlock()
+
+	defer c.leaveSharedCompute(key, shared)
+
+	select {
+	case result := <-resultCh:
+		if isPricingFailure(result.Err) {
+			return osmomath.BigDec{}, result.Err
+		}
+
+		// The low-confidence prices are returned alongside the error.
+		return result.Val.(osmomath.BigDec), result.Err
+	case <-ctx.Done():
+		return osmomath.BigDec{}, ctx.Err()
+	}
+}
+
+// leaveSharedCompute unregisters a caller from the given shared computation of the given key.
+// The last caller to leave cancels the computation and forgets it so that the subsequent callers
+// never join the canceled computation.
+func (c *chainPricing) leaveSharedCompute(key string, shared *sharedCompute) {
+	c.sharedComputesMu.Lock()
+	defer c.sharedComputesMu.Unlock()
+
+	shared.numWaiters--
+	if shared.numWaiters > 0 {
+		return
+	}
+
+	shared.cancel()
+	if c.sharedComputes[key] == shared {
+		delete(c.sharedComputes, key)
+		c.computeGroup.Forget(key)
+	}
+}
+
+// isPricingFailure returns true if the given error fails the price computation.
+// The low-confidence prices are returned alongside errLowConfidencePrice so it is not a failure.
+func isPricingFailure(err error) bool {
+	return err != nil && !errors.Is(err, errLowConfidencePrice)
+}
+
+// formatHeightPricingCacheKey formats the key of the height pricing cache from the denoms
+// and the options that affect the route selection.
+func formatHeightPricingCacheKey(tenQuoteCoin sdk.Coin, baseDenom string, options domain.PricingOptions) string {
+	return fmt.Sprintf("%s|%q|%d|%d|%d|%t", tenQuoteCoin, baseDenom, options.MinLiquidity, options.MaxRoutes, options.MaxPoolsPerRoute, options.EnforceRouteLiquidity)
+}
+
+// formatRouteCacheKey formats the key of the route cache from the denoms
+// and the options that affect the route selection.
+func formatRouteCacheKey(tokenInDenom string, baseDenom string, options domain.PricingOptions) string {
+	return fmt.Sprintf("%q|%q|%d|%t|%d|%d|%t|%t|%t|%d|%s|%s",
+		tokenInDenom, baseDenom, options.MinLiquidity, options.VolumeWeightedPricing && !options.MidPriceOnly, options.MaxRoutes, options.MaxPoolsPerRoute,
+		options.EnforceRouteLiquidity, options.ForceAlternativeMethod, options.FeeInclusivePricing, options.MedianPricingRoutes, options.TWAPWindow, options.TiedRoutesTolerance)
+}
+
+// formatComputePriceKey formats the key deduplicating the price computations
+// from the denoms and the options that affect the computed price.
+func formatComputePriceKey(baseDenom string, quoteDenom string, options domain.PricingOptions) string {
+	return fmt.Sprintf("%q|%q|%d|%t|%d|%d|%t|%d|%d|%t|%t|%t|%s|%t|%t|%d|%s|%s",
+		baseDenom, quoteDenom, options.MinLiquidity, options.VolumeWeightedPricing, options.PricePrecision, options.Height, options.RawChainPrice,
+		options.MaxRoutes, options.MaxPoolsPerRoute, options.MidPriceOnly, options.EnforceRouteLiquidity, options.ForceCompute, options.DefaultScalingFactor,
+		options.ForceAlternativeMethod, options.FeeInclusivePricing, options.MedianPricingRoutes, options.TWAPWindow, options.TiedRoutesTolerance)
+}
+
+// computePriceWithCircuitBreaker computes the price unless the circuit breaker is open for the base denom.
+// Records the result in the circuit breaker if enabled.
+func (c *chainPricing) computePriceWithCircuitBreaker(ctx context.Context, baseDenom string, quoteDenom string, options domain.PricingOptions) (osmomath.BigDec, error) {
+	if c.circuitBreaker == nil {
+		return c.computePrice(ctx, baseDenom, quoteDenom, options)
+	}
+
+	if err := c.circuitBreaker.allow(baseDenom); err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	price, err := c.computePrice(ctx, baseDenom, quoteDenom, options)
+
+	// The abandoned computations, for example, due to the exhausted compute budget, say nothing about the base denom.
+	if isContextError(err) {
+		c.circuitBreaker.recordAbandoned(baseDenom)
+		return price, err
+	}
+
+	// The low-confidence prices are recorded as successes.
+	// The stale pool data is not specific to the base denom so it is not recorded as a failure either.
+	failureErr := err
+	if !isPricingFailure(err) || errors.As(err, &domain.StalePoolDataError{}) {
+		failureErr = nil
+	}
+	c.circuitBreaker.recordResult(baseDenom, failureErr)
+
+	return price, err
+}
+
+// GetPriceByHumanDenom implements domain.PricingSource.
+func (c *chainPricing) GetPriceByHumanDenom(ctx context.Context, baseHumanDenom string, quoteHumanDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	chainDenoms, errs := c.TUsecase.GetChainDenoms([]string{baseHumanDenom, quoteHumanDenom})
+	if errs[0] != nil {
+		return osmomath.BigDec{}, fmt.Errorf("failed to get chain denom for base human denom (%s): %w", baseHumanDenom, errs[0])
+	}
+	if errs[1] != nil {
+		return osmomath.BigDec{}, fmt.Errorf("failed to get chain denom for quote human denom (%s): %w", quoteHumanDenom, errs[1])
+	}
+
+	return c.GetPrice(ctx, chainDenoms[baseHumanDenom], chainDenoms[quoteHumanDenom], opts...)
+}
+
+// GetUSDPrice implements domain.PricingSource.
+// The price is quoted in the chain denom of the default quote human denom, even if the composite quote is configured.
+func (c *chainPricing) GetUSDPrice(ctx context.Context, baseDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	if c.defaultQuoteUSDRate.IsNil() && !c.isDefaultQuoteUSDPegged {
+		return osmomath.BigDec{}, fmt.Errorf("default quote USD rate is unset but required since the default quote denom (%s) is not USD-pegged", c.usdQuoteDenom)
+	}
+
+	price, err := c.GetPrice(ctx, baseDenom, c.usdQuoteDenom, opts...)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	if c.defaultQuoteUSDRate.IsNil() {
+		return price, nil
+	}
+
+	// Note that the price might be shared with cache so it must not be mutated.
+	return price.Mul(osmomath.BigDecFromDec(c.defaultQuoteUSDRate)), nil
+}
+
+// computePrice computes the price for a given base and quote denom
+// If volume-weighted pricing is enabled, the price is the average of the prices of all split routes
+// weighted by their amount in. Otherwise, the price of the top route is used.
+func (c *chainPricing) computePrice(ctx context.Context, baseDenom string, quoteDenom string, options domain.PricingOptions) (osmomath.BigDec, error) {
+	isEqualDenom := baseDenom == quoteDenom
+	if isEqualDenom && !options.ForceCompute {
+		return osmomath.OneBigDec(), nil
+	}
+
+	if len(c.compositeQuote) > 0 && quoteDenom == c.defaultQuoteDenom {
+		// The composite price is not computed along a single route so the component computations are not recorded.
+		return c.computeCompositeQuotePrice(withoutPriceExplanationRecorder(ctx), baseDenom, options)
+	}
+
+	cacheKey, err := formatCacheKey(baseDenom, quoteDenom)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	// The downstream router calls inherit the span via the context.
+	ctx, span := startComputePriceSpan(ctx, baseDenom, quoteDenom, options.TraceAttributes)
+	defer span.End()
+
+	// Never compute from the pools that are stale due to the ingest lag since the cache TTL cannot detect it.
+	if err := c.validatePoolDataFreshness(ctx); err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	tenQuoteCoin, precisionScalingFactor, isDefaultScalingFactorUsed, err := c.getQuoteCoinAndPrecisionScalingFactorWithDefault(baseDenom, quoteDenom, options.DefaultScalingFactor, options.PrecisionProvider)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	explanationRecorder := getPriceExplanationRecorder(ctx)
+	explanationRecorder.recordScaling(tenQuoteCoin, precisionScalingFactor, isDefaultScalingFactorUsed)
+
+	var (
+		chainPrice   osmomath.BigDec
+		routePoolIDs []uint64
+	)
+	routeFee := osmomath.ZeroDec()
+	if isEqualDenom {
+		// The identity swap has the chain price of one so that only the scaling factors are exercised.
+		chainPrice = osmomath.OneBigDec()
+	} else if pinnedPoolIDs, ok := c.getPinnedRoute(baseDenom, quoteDenom); ok {
+		// Pinned routes bypass the route selection for deterministic pricing.
+		explanationRecorder.recordPinnedRoute()
+		chainPrice, err = c.computePinnedRouteChainPrice(ctx, pinnedPoolIDs, baseDenom, quoteDenom, options.TWAPWindow)
+		routePoolIDs = pinnedPoolIDs
+		if err == nil && options.FeeInclusivePricing {
+			routeFee = computePinnedRouteFee(c.RUsecase.GetSortedPools(), pinnedPoolIDs)
+		}
+	} else {
+		chainPrice, routeFee, routePoolIDs, err = c.computeOptimalRouteChainPrice(ctx, tenQuoteCoin, baseDenom, quoteDenom, options)
+	}
+	if err != nil {
+		// Back off from repeatedly searching the routes for the unpriceable pairs.
+		if c.unpriceableTTL > 0 && errors.Is(err, domain.ErrUnpriceable) {
+			c.setCachedValue(cacheKey, unpriceableMarker{}, c.unpriceableTTL)
+		}
+		return osmomath.BigDec{}, err
+	}
+
+	explanationRecorder.recordChainPrice(chainPrice)
+
+	// Net out the route fees as chain price * (1 - route fee) if requested.
+	if options.FeeInclusivePricing {
+		explanationRecorder.recordRouteFee(routeFee)
+		chainPrice = chainPrice.Mul(osmomath.BigDecFromDec(osmomath.OneDec().Sub(routeFee)))
+	}
+
+	if chainPrice.IsZero() {
+		// Increase price truncation counter
+		pricesTruncationCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+	}
+
+	// Apply scaling facors to descale the amounts to real amounts.
+	// Note that the chain price is not mutated so that it can be returned if the raw chain price is requested.
+	currentPrice := chainPrice.Mul(precisionScalingFactor)
+
+	// Only the returned price is rounded since the cache key does not include the per-call precision.
+	roundedPrice := roundPrice(currentPrice, options.PricePrecision)
+	explanationRecorder.recordPrice(currentPrice, roundedPrice)
+
+	// Never cache the nonsensical prices, for example, due to the extreme scaling factors.
+	if err := c.validatePriceRange(baseDenom, quoteDenom, currentPrice); err != nil {
+		// Increase out of range counter
+		pricesOutOfRangeCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+		return osmomath.BigDec{}, err
+	}
+
+	// Never cache the low-confidence prices.
+	if isDefaultScalingFactorUsed {
+		// Increase default scaling factor counter
+		pricesDefaultScalingFactorCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+
+		if options.RawChainPrice {
+			return chainPrice, fmt.Errorf("%w for %s (base) -> %s (quote)", errLowConfidencePrice, baseDenom, quoteDenom)
+		}
+		return roundedPrice, fmt.Errorf("%w for %s (base) -> %s (quote)", errLowConfidencePrice, baseDenom, quoteDenom)
+	}
+
+	// Only store values that are valid.
+	// Equal denom prices are never read from cache so they are not stored either.
+	if !currentPrice.IsNil() && !isEqualDenom && c.isCacheablePricing(options) {
+		expirationTTL := c.cacheExpiryNs
+		// We pre-compute the price for the default quote denom in ingest handler via the background
+		// pricing worker. As a result, we store them indefinitely.
+		// We track the tokens that are modified within the block and update the prices only for those tokens.
+		// The base denoms that are not tracked by the worker are never refreshed so they use the normal TTL.
+		// Similarly, the prices against the per-request default quote denom overrides use the normal TTL.
+		// The route of the indefinitely cached prices is stored so that RefreshDefaultQuotePrices
+		// can recompute them without the route search.
+		var cachedRoutePoolIDs []uint64
+		if quoteDenom == c.defaultQuoteDenom && c.isWorkerTrackedDenom(baseDenom) {
+			expirationTTL = cache.NoExpirationTTL
+			cachedRoutePoolIDs = routePoolIDs
+		}
+
+		computedPrice := cachedPrice{price: currentPrice, computedAt: time.Now(), routePoolIDs: cachedRoutePoolIDs}
+		c.setCachedValue(cacheKey, computedPrice, expirationTTL)
+		c.setLastKnownGoodPrice(cacheKey, computedPrice)
+	}
+
+	// The prices that are never cached are computed differently from the cached reference prices.
+	if c.referenceQuoteDenom != "" && c.isCacheablePricing(options) {
+		go c.checkReferenceDivergence(baseDenom, quoteDenom, currentPrice)
+	}
+
+	if options.RawChainPrice {
+		return chainPrice, nil
+	}
+
+	return roundedPrice, nil
+}
+
+// isCacheablePricing returns true if the prices computed with the given options may be cached.
+// Volume-weighted prices are never cached so that they do not overwrite the top route prices.
+// Similarly, the forced alternative method prices embed the price impact so they must not overwrite the spot prices.
+// Neither must the fee-inclusive prices overwrite the fee-exclusive prices
+// nor the prices with the precision provider overrides overwrite the prices with the chain precision.
+// The median prices, the TWAP prices and the tied routes prices must not overwrite the optimal route spot prices either.
+// Since the cache key is only the base and quote denoms, the prices with the route limit overrides, the default scaling factor
+// or a min liquidity other than the configured one are not cached either. The zero min liquidity is the exception
+// since it is used by the background pricing worker that computes the cached prices in the default quote denom.
+func (c *chainPricing) isCacheablePricing(options domain.PricingOptions) bool {
+	return !options.VolumeWeightedPricing && !options.ForceAlternativeMethod && !options.FeeInclusivePricing && options.PrecisionProvider == nil && options.MedianPricingRoutes == 0 && options.TWAPWindow == 0 && options.TiedRoutesTolerance.IsNil() &&
+		options.MaxRoutes == 0 && options.MaxPoolsPerRoute == 0 && options.DefaultScalingFactor.IsNil() &&
+		(options.MinLiquidity == c.minOSMOLiquidity || options.MinLiquidity == 0)
+}
+
+// isForcedRecompute returns true if the price computed with the given options is never served from cache.
+// The prices that are never cached (see isCacheablePricing) are always recomputed since the cached prices
+// are computed differently. Raw chain prices are always recomputed since the cache holds the scaled prices.
+// Mid prices are always recomputed since the cached prices might come from the alternative method.
+// Similarly, the cached prices might come from the routes not satisfying the route liquidity enforcement.
+// Forced prices are diagnostic so they are always recomputed.
+func (c *chainPricing) isForcedRecompute(options domain.PricingOptions) bool {
+	return options.RecomputePrices || !c.isCacheablePricing(options) || options.RawChainPrice || options.MidPriceOnly || options.EnforceRouteLiquidity || options.ForceCompute
+}
+
+// computeCompositeQuotePrice computes the price of the base denom in the composite quote as the average
+// of its prices in the composite quote components weighted by their weights.
+// The component prices are computed with the same options, except for the precision, so they are cached
+// under their own keys if cacheable. Only the returned composite price is rounded.
+// The composite price is cached like the prices in the default quote denom.
+// Returns error if the raw chain price is requested since the chain prices in the components are not comparable,
+// if the price in any of the components fails to compute or if the composite price is out of range.
+func (c *chainPricing) computeCompositeQuotePrice(ctx context.Context, baseDenom string, options domain.PricingOptions) (osmomath.BigDec, error) {
+	if options.RawChainPrice {
+		return osmomath.BigDec{}, fmt.Errorf("raw chain price of (%s) is undefined in the composite quote (%s)", baseDenom, c.defaultQuoteDenom)
+	}
+
+	cacheKey, err := formatCacheKey(baseDenom, c.defaultQuoteDenom)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	// The components are not rounded so that the composite price is not skewed by the rounding.
+	componentOptions := options
+	componentOptions.PricePrecision = domain.NoPricePrecision
+
+	compositePrice := osmomath.ZeroBigDec()
+	isLowConfidence := false
+	for _, component := range c.compositeQuote {
+		price, err := c.computePrice(ctx, baseDenom, component.Denom, componentOptions)
+		if isPricingFailure(err) {
+			return osmomath.BigDec{}, fmt.Errorf("failed to price (%s) in composite quote component (%s): %w", baseDenom, component.Denom, err)
+		}
+		isLowConfidence = isLowConfidence || err != nil
+
+		// Non-mutative since the price might be shared with the cache.
+		compositePrice = compositePrice.AddMut(price.Mul(osmomath.BigDecFromDec(component.Weight)))
+	}
+
+	roundedCompositePrice := roundPrice(compositePrice, options.PricePrecision)
+
+	if err := c.validatePriceRange(baseDenom, c.defaultQuoteDenom, compositePrice); err != nil {
+		// Increase out of range counter
+		pricesOutOfRangeCounter.WithLabelValues(baseDenom, c.defaultQuoteDenom).Inc()
+		return osmomath.BigDec{}, err
+	}
+
+	// Never cache the low-confidence prices.
+	if isLowConfidence {
+		return roundedCompositePrice, fmt.Errorf("%w for %s (base) -> %s (quote)", errLowConfidencePrice, baseDenom, c.defaultQuoteDenom)
+	}
+
+	if c.isCacheablePricing(options) {
+		// The composite prices are not computed along a single route so none is stored for the refresh.
+		expirationTTL := c.cacheExpiryNs
+		if c.isWorkerTrackedDenom(baseDenom) {
+			expirationTTL = cache.NoExpirationTTL
+		}
+
+		computedPrice := cachedPrice{price: compositePrice, computedAt: time.Now()}
+		c.setCachedValue(cacheKey, computedPrice, expirationTTL)
+		c.setLastKnownGoodPrice(cacheKey, computedPrice)
+	}
+
+	return roundedCompositePrice, nil
+}
+
+// checkReferenceDivergence cross-checks the given price of the base denom in the quote denom
+// against the price of the base denom in the reference quote denom divided by the price of the quote denom
+// in the reference quote denom. Increments the reference divergence counter if the relative divergence
+// exceeds the threshold. This is for monitoring only so it runs off the pricing path and only reads
+// the reference prices from the cache rather than computing them. The uncached reference prices skip the check.
+// No-op if the reference quote denom is not configured, if the denoms are equal
+// or if either denom is the reference quote denom.
+func (c *chainPricing) checkReferenceDivergence(baseDenom string, quoteDenom string, price osmomath.BigDec) {
+	if c.referenceQuoteDenom == "" || baseDenom == quoteDenom || baseDenom == c.referenceQuoteDenom || quoteDenom == c.referenceQuoteDenom {
+		return
+	}
+
+	if price.IsNil() || price.IsZero() {
+		return
+	}
+
+	baseReferencePrice, ok := c.getCachedPrice(baseDenom, c.referenceQuoteDenom)
+	if !ok {
+		return
+	}
+
+	quoteReferencePrice, ok := c.getCachedPrice(quoteDenom, c.referenceQuoteDenom)
+	if !ok || quoteReferencePrice.price.IsZero() {
+		return
+	}
+
+	crossPrice := baseReferencePrice.price.Quo(quoteReferencePrice.price)
+
+	divergence := price.Sub(crossPrice).Abs().QuoMut(price)
+	if divergence.GT(c.referenceDivergenceThreshold) {
+		// Increase reference divergence counter
+		pricesReferenceDivergenceCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+	}
+}
+
+// getCachedPrice returns the unexpired cached price of the base denom in the quote denom without computing it.
+// The prices seeded externally via InitializeCache are returned without the compute timestamp.
+// Returns false if not found.
+func (c *chainPricing) getCachedPrice(baseDenom string, quoteDenom string) (cachedPrice, bool) {
+	cacheKey, err := formatCacheKey(baseDenom, quoteDenom)
+	if err != nil {
+		return cachedPrice{}, false
+	}
+
+	cachedValue, found := c.getCache().Get(cacheKey)
+	if !found {
+		return cachedPrice{}, false
+	}
+
+	switch v := cachedValue.(type) {
+	case cachedPrice:
+		return v, true
+	case osmomath.BigDec:
+		return cachedPrice{price: v}, true
+	default:
+		return cachedPrice{}, false
+	}
+}
+
+// newCoinOSMOValueFunc returns the function valuing the coins in OSMO. The coins are descaled
+// by the chain scaling factors and valued at their cached OSMO prices.
+// Returns error if the OSMO chain denom is unknown.
+func (c *chainPricing) newCoinOSMOValueFunc(ctx context.Context) (domain.CoinOSMOValueFunc, error) {
+	osmoDenom, err := c.TUsecase.GetChainDenom(osmoHumanDenom)
+	if err != nil {
+		return nil, err
+	}
+
+	// The OSMO prices are not part of the explained computation.
+	ctx = withoutPriceExplanationRecorder(ctx)
+
+	return domain.NewCoinOSMOValueFunc(c.TUsecase.GetChainScalingFactorByDenomMut, func(denom string) (osmomath.BigDec, error) {
+		return c.GetPrice(ctx, denom, osmoDenom)
+	}), nil
+}
+
+// validateRouteLiquidity validates that each pool in the route has at least the min OSMO liquidity.
+// The pool liquidity is the OSMO value of its balances. The balances are descaled by the chain scaling factors
+// and valued at their cached OSMO prices. Pools that do not expose their balances have zero liquidity.
+// Returns RouteLiquidityTooLowError if any pool is below the min liquidity.
+// Returns error if the OSMO value of any balance fails to compute.
+func (c *chainPricing) validateRouteLiquidity(ctx context.Context, route domain.SplitRoute, baseDenom string, quoteDenom string, minLiquidity int) error {
+	coinOSMOValue, err := c.newCoinOSMOValueFunc(ctx)
+	if err != nil {
+		return err
+	}
+
+	minLiquidityInt := osmomath.NewInt(int64(minLiquidity))
+
+	for _, pool := range route.GetPools() {
+		poolOSMOLiquidity, err := domain.ComputePoolOSMOLiquidity(pool, coinOSMOValue)
+		if err != nil {
+			return err
+		}
+
+		if poolOSMOLiquidity.LT(minLiquidityInt) {
+			return domain.RouteLiquidityTooLowError{
+				BaseDenom:    baseDenom,
+				QuoteDenom:   quoteDenom,
+				PoolID:       pool.GetId(),
+				Liquidity:    poolOSMOLiquidity,
+				MinLiquidity: minLiquidity,
+			}
+		}
+	}
+
+	return nil
+}
+
+// validatePriceRange validates that the price is valid and within the configured bounds.
+// The prices against the default quote denom are additionally validated against the bound of the base denom, if any.
+// Returns PriceOutOfRangeError otherwise.
+func (c *chainPricing) validatePriceRange(baseDenom string, quoteDenom string, price osmomath.BigDec) error {
+	minPrice, maxPrice := c.minPrice, c.maxPrice
+	isOutOfRange := price.IsNil() || price.IsNegative() || isPriceOutOfBounds(price, minPrice, maxPrice)
+
+	if !isOutOfRange && quoteDenom == c.defaultQuoteDenom {
+		if bound, ok := c.priceBounds[strings.ToLower(baseDenom)]; ok {
+			minPrice, maxPrice = bound.min, bound.max
+			isOutOfRange = isPriceOutOfBounds(price, minPrice, maxPrice)
+		}
+	}
+
+	if isOutOfRange {
+		return domain.PriceOutOfRangeError{
+			BaseDenom:  baseDenom,
+			QuoteDenom: quoteDenom,
+			Price:      price,
+			MinPrice:   minPrice,
+			MaxPrice:   maxPrice,
+		}
+	}
+
+	return nil
+}
+
+// isPriceOutOfBounds returns true if the price is below the min price or above the max price.
+// The nil bounds are not enforced.
+func isPriceOutOfBounds(price osmomath.BigDec, minPrice osmomath.BigDec, maxPrice osmomath.BigDec) bool {
+	return (!minPrice.IsNil() && price.LT(minPrice)) || (!maxPrice.IsNil() && price.GT(maxPrice))
+}
+
+// getPinnedRoute returns the pinned pool IDs for the given base and quote denoms
+// and a boolean flag indicating whether the pair is pinned.
+func (c *chainPricing) getPinnedRoute(baseDenom string, quoteDenom string) ([]uint64, bool) {
+	c.pinnedRoutesMu.RLock()
+	defer c.pinnedRoutesMu.RUnlock()
+
+	if len(c.pinnedRoutes) == 0 {
+		return nil, false
+	}
+
+	pinnedRouteKey, err := formatPinnedRouteKey(baseDenom, quoteDenom)
+	if err != nil {
+		// The denoms that cannot be formatted into a key are never pinned.
+		return nil, false
+	}
+
+	pinnedPoolIDs, ok := c.pinnedRoutes[pinnedRouteKey]
+	return pinnedPoolIDs, ok
+}
+
+// validatePinnedRoutesOnPoolLoad validates the pinned routes once the router pools are loaded,
+// checking for the pools on the given interval until the context is cancelled.
+// The pinned routes that do not connect the quote denom to the base denom are logged and disabled
+// so that their pairs are priced along the selected routes rather than failing to price.
+func (c *chainPricing) validatePinnedRoutesOnPoolLoad(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pools := c.RUsecase.GetSortedPools()
+			if len(pools) == 0 {
+				continue
+			}
+
+			c.disableInvalidPinnedRoutes(pools)
+			return
+		}
+	}
+}
+
+// disableInvalidPinnedRoutes removes the pinned routes that do not connect the quote denom to the base denom
+// over the given pools, logging each of them.
+func (c *chainPricing) disableInvalidPinnedRoutes(pools []sqsdomain.PoolI) {
+	c.pinnedRoutesMu.Lock()
+	defer c.pinnedRoutesMu.Unlock()
+
+	for pinnedRouteKey, poolIDs := range c.pinnedRoutes {
+		if err := validatePinnedRoute(pinnedRouteKey, poolIDs, pools); err != nil {
+			c.logger.Error("disabling invalid pinned route", zap.String("pair", pinnedRouteKey), zap.Error(err))
+			delete(c.pinnedRoutes, pinnedRouteKey)
+		}
+	}
+}
+
+// computePinnedRouteChainPrice computes the chain price of the base denom in the quote denom
+// over the pinned pools without the route selection.
+// Returns error if the pinned pools do not connect the quote denom to the base denom
+// or if any of the pool spot prices fails to compute.
+func (c *chainPricing) computePinnedRouteChainPrice(ctx context.Context, pinnedPoolIDs []uint64, baseDenom string, quoteDenom string, twapWindow time.Duration) (osmomath.BigDec, error) {
+	spotPriceRequests, err := buildPinnedRouteSpotPriceRequests(c.RUsecase.GetSortedPools(), pinnedPoolIDs, baseDenom, quoteDenom)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(attribute.StringSlice("pricing.routes", []string{formatRoutePoolIDs(pinnedPoolIDs)}))
+	}
+
+	chainPrice, err := c.computeSpotPriceProduct(ctx, spotPriceRequests, twapWindow)
+	if err != nil {
+		// Increase spot price error counter
+		pricesSpotPriceError.WithLabelValues(baseDenom, quoteDenom).Inc()
+		return osmomath.BigDec{}, err
+	}
+
+	return chainPrice, nil
+}
+
+// computeOptimalRouteChainPrice computes the chain price of the base denom in the quote denom
+// over the route(s) selected by the router for the given quote coin.
+// If the spot prices fail to compute, falls back to the alternative method of dividing
+// the quote amount in by the amount out. The alternative method is used directly if forced by the options.
+// Returns the effective fee of the route(s) alongside the chain price (see computeRoutesFee).
+// Also returns the pool IDs of the route if the chain price is the spot price along a single route
+// so that it can be recomputed along the same route. Otherwise, the pool IDs are nil.
+func (c *chainPricing) computeOptimalRouteChainPrice(ctx context.Context, tenQuoteCoin sdk.Coin, baseDenom string, quoteDenom string, options domain.PricingOptions) (osmomath.BigDec, osmomath.Dec, []uint64, error) {
+	// The median of the ranked routes takes precedence over the optimal route(s).
+	if options.MedianPricingRoutes > 0 && !options.MidPriceOnly && !options.ForceAlternativeMethod {
+		chainPrice, routeFee, err := c.computeMedianChainPrice(ctx, tenQuoteCoin, baseDenom, quoteDenom, options)
+		return chainPrice, routeFee, nil, err
+	}
+
+	// The geometric mean of the tied routes takes precedence over the optimal route(s) too.
+	if !options.TiedRoutesTolerance.IsNil() && !options.MidPriceOnly && !options.ForceAlternativeMethod {
+		chainPrice, routeFee, err := c.computeTiedRoutesChainPrice(ctx, tenQuoteCoin, baseDenom, quoteDenom, options)
+		return chainPrice, routeFee, nil, err
+	}
+
+	// Split routes are only necessary for volume-weighted pricing.
+	// Mid prices are computed along the top route only.
+	isVolumeWeighted := options.VolumeWeightedPricing && !options.MidPriceOnly
+
+	// Only the spot prices along a single route are cached per height.
+	// The TWAPs are not spot prices so they are not cached either.
+	isHeightCacheable := c.heightPricingCache != nil && options.Height != 0 && !isVolumeWeighted && !options.ForceAlternativeMethod && options.TWAPWindow == 0
+	heightCacheKey := formatHeightPricingCacheKey(tenQuoteCoin, baseDenom, options)
+	// The explained prices are not read from the height cache since it does not retain the route pool denoms.
+	// Neither are the prices of GetPriceAndRoute since it does not retain the route either.
+	if isHeightCacheable && getPriceExplanationRecorder(ctx) == nil {
+		if entry, ok := c.heightPricingCache.get(options.Height, heightCacheKey); ok && !c.suspectPools.containsAny(entry.routePoolIDs, time.Now()) {
+			return multiplySpotPrices(entry.spotPrices), entry.routeFee, entry.routePoolIDs, nil
+		}
+	}
+
+	routingOptions := c.getPricingRouterOptions(baseDenom, quoteDenom, options, isVolumeWeighted)
+
+	// Compute a quote for one quote coin.
+	quote, err := c.getPricingQuote(ctx, tenQuoteCoin, baseDenom, quoteDenom, options, routingOptions)
+	if err != nil {
+		return osmomath.BigDec{}, osmomath.Dec{}, nil, err
+	}
+
+	routes := quote.GetRoute()
+	if !isVolumeWeighted {
+		// Only the top route is used for pricing.
+		routes = routes[:1]
+	}
+
+	annotateSpanWithRoutes(ctx, routes)
+
+	// Guard against the malformed routes that would otherwise yield the unscaled price of one.
+	for _, route := range routes {
+		numPools := len(route.GetPools())
+		if numPools == 0 {
+			// Increase empty route pools counter
+			pricesEmptyRoutePoolsCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+			return osmomath.BigDec{}, osmomath.Dec{}, nil, fmt.Errorf("%w when computing pricing for %s (base) -> %s (quote)", domain.ErrEmptyRoutePools, baseDenom, quoteDenom)
+		}
+
+		c.routePoolCountHistogram.WithLabelValues(quoteDenom).Observe(float64(numPools))
+
+		if options.EnforceRouteLiquidity && options.MinLiquidity > 0 {
+			if err := c.validateRouteLiquidity(ctx, route, baseDenom, quoteDenom, options.MinLiquidity); err != nil {
+				return osmomath.BigDec{}, osmomath.Dec{}, nil, err
+			}
+		}
+	}
+
+	routesFee := computeRoutesFee(routes)
+
+	// The volume-weighted price is not computed along a single route unless the quote has a single route.
+	if len(routes) == 1 {
+		getPriceExplanationRecorder(ctx).recordPriceRoute(routes[0], tenQuoteCoin)
+	}
+
+	// The forced alternative method bypasses the spot price method entirely.
+	// Mid prices must not embed the price impact so they are unaffected.
+	if options.ForceAlternativeMethod && !options.MidPriceOnly {
+		getPriceExplanationRecorder(ctx).recordAlternativeMethod()
+		return computeAlternativeChainPrice(tenQuoteCoin, quote), routesFee, nil, nil
+	}
+
+	var (
+		chainPrice osmomath.BigDec
+		spotPrices []osmomath.BigDec
+	)
+	if len(routes) == 1 {
+		spotPrices, err = c.getRouteSpotPrices(ctx, routes[0], quoteDenom, options.TWAPWindow)
+		if err == nil {
+			chainPrice = multiplySpotPrices(spotPrices)
+		}
+	} else {
+		chainPrice, err = c.computeVolumeWeightedSpotPrice(ctx, routes, quoteDenom, options.TWAPWindow)
+	}
+
+	// If spot price fails to compute, use the alternative method.
+	if err != nil {
+		// Increase spot price error counter
+		pricesSpotPriceError.WithLabelValues(baseDenom, quoteDenom).Inc()
+
+		// Mid prices must not embed the price impact of the alternative method.
+		// Neither must the abandoned requests fall back to it.
+		if options.MidPriceOnly || isContextError(err) {
+			return osmomath.BigDec{}, osmomath.Dec{}, nil, err
+		}
+
+		getPriceExplanationRecorder(ctx).recordAlternativeMethod()
+		return computeAlternativeChainPrice(tenQuoteCoin, quote), routesFee, nil, nil
+	}
+
+	var routePoolIDs []uint64
+	if len(routes) == 1 {
+		for _, pool := range routes[0].GetPools() {
+			routePoolIDs = append(routePoolIDs, pool.GetId())
+		}
+
+		if isHeightCacheable {
+			c.heightPricingCache.set(options.Height, heightCacheKey, heightPricingEntry{
+				routePoolIDs: routePoolIDs,
+				routeFee:     routesFee,
+				spotPrices:   spotPrices,
+			})
+		}
+	}
+
+	return chainPrice, routesFee, routePoolIDs, nil
+}
+
+// computeRoutesFee computes the effective fee of the given routes. The spread and taker fees of the pools
+// are compounded along each route as 1 - ∏(1 - (spread factor + taker fee)).
+// The route fees are pro-rated across the routes by their amount in.
+func computeRoutesFee(routes []domain.SplitRoute) osmomath.Dec {
+	totalAmountIn := osmomath.ZeroInt()
+	for _, route := range routes {
+		totalAmountIn = totalAmountIn.Add(route.GetAmountIn())
+	}
+
+	totalFee := osmomath.ZeroDec()
+	for _, route := range routes {
+		routeFee := osmomath.ZeroDec()
+		for _, pool := range route.GetPools() {
+			poolFee := pool.GetSpreadFactor().Add(pool.GetTakerFee())
+			routeFee = routeFee.Add(osmomath.OneDec().Sub(routeFee).Mul(poolFee))
+		}
+
+		// The single route carries the full amount in regardless of the amounts.
+		if len(routes) == 1 || !totalAmountIn.IsPositive() {
+			return routeFee
+		}
+
+		totalFee = totalFee.Add(routeFee.MulInt(route.GetAmountIn()).QuoInt(totalAmountIn))
+	}
+
+	return totalFee
+}
+
+// computePinnedRouteFee computes the effective fee of the pinned route from the spread factors of its pools
+// compounded as in computeRoutesFee. The taker fees are not accounted for since they are resolved by the router.
+func computePinnedRouteFee(pools []sqsdomain.PoolI, pinnedPoolIDs []uint64) osmomath.Dec {
+	spreadFactorsByPoolID := make(map[uint64]osmomath.Dec, len(pinnedPoolIDs))
+	for _, pool := range pools {
+		spreadFactorsByPoolID[pool.GetId()] = pool.GetSQSPoolModel().SpreadFactor
+	}
+
+	routeFee := osmomath.ZeroDec()
+	for _, poolID := range pinnedPoolIDs {
+		spreadFactor, ok := spreadFactorsByPoolID[poolID]
+		if !ok || spreadFactor.IsNil() {
+			continue
+		}
+		routeFee = routeFee.Add(osmomath.OneDec().Sub(routeFee).Mul(spreadFactor))
+	}
+
+	return routeFee
+}
+
+// routeMedianCandidate is the spot price of a ranked route alongside its weight in the median.
+type routeMedianCandidate struct {
+	route    domain.SplitRoute
+	price    osmomath.BigDec
+	weight   osmomath.Int
+	routeFee osmomath.Dec
+}
+
+// computeMedianChainPrice computes the chain price as the liquidity-weighted median of the spot prices
+// of up to the configured number of ranked routes. The routes through the same set of pools are de-duplicated.
+// Each route is weighted by the OSMO liquidity of its least liquid pool. The routes whose liquidity fails
+// to compute have zero weight. If none of the routes has positive weight, the routes are weighted equally.
+// The routes whose spot prices fail to compute are skipped.
+// Returns the chain price alongside the fee of the median route.
+// Returns error wrapping domain.ErrUnpriceable if no route is found or if none of the route spot prices computes.
+func (c *chainPricing) computeMedianChainPrice(ctx context.Context, tenQuoteCoin sdk.Coin, baseDenom string, quoteDenom string, options domain.PricingOptions) (osmomath.BigDec, osmomath.Dec, error) {
+	routingOptions := c.getPricingRouterOptions(baseDenom, quoteDenom, options, false)
+
+	quotes, err := c.getRankedQuotesAvoidingSuspectPools(ctx, tenQuoteCoin, baseDenom, options.MedianPricingRoutes, routingOptions)
+	if err != nil {
+		return osmomath.BigDec{}, osmomath.Dec{}, fmt.Errorf("ranked quotes for %s (base) -> %s (quote): %w", baseDenom, quoteDenom, err)
+	}
+
+	coinOSMOValue, err := c.newCoinOSMOValueFunc(ctx)
+	if err != nil {
+		return osmomath.BigDec{}, osmomath.Dec{}, err
+	}
+
+	candidates := make([]routeMedianCandidate, 0, len(quotes))
+	seenPoolSets := make(map[string]struct{}, len(quotes))
+
+	for _, quote := range quotes {
+		for _, route := range quote.GetRoute() {
+			// Stop computing the spot prices of the remaining routes once the request is abandoned.
+			select {
+			case <-ctx.Done():
+				return osmomath.BigDec{}, osmomath.Dec{}, fmt.Errorf("median pricing for %s (base) -> %s (quote): %w", baseDenom, quoteDenom, ctx.Err())
+			default:
+			}
+
+			pools := route.GetPools()
+			if len(pools) == 0 {
+				continue
+			}
+
+			poolSet := formatRoutePoolSet(pools)
+			if _, ok := seenPoolSets[poolSet]; ok {
+				continue
+			}
+			seenPoolSets[poolSet] = struct{}{}
+
+			routePrice, err := c.computeRouteSpotPrice(ctx, route, quoteDenom, options.TWAPWindow)
+			if err != nil {
+				if isContextError(err) {
+					return osmomath.BigDec{}, osmomath.Dec{}, err
+				}
+				continue
+			}
+
+			candidates = append(candidates, routeMedianCandidate{
+				route:    route,
+				price:    routePrice,
+				weight:   computeRouteOSMOLiquidity(route, coinOSMOValue),
+				routeFee: computeRoutesFee([]domain.SplitRoute{route}),
+			})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return osmomath.BigDec{}, osmomath.Dec{}, fmt.Errorf("%w: no route with a spot price found when computing median pricing for %s (base) -> %s (quote)", domain.ErrUnpriceable, baseDenom, quoteDenom)
+	}
+
+	median := computeWeightedMedian(candidates)
+	getPriceExplanationRecorder(ctx).recordPriceRoute(median.route, tenQuoteCoin)
+
+	return median.price, median.routeFee, nil
+}
+
+// computeTiedRoutesChainPrice computes the chain price as the geometric mean of the spot prices of the ranked routes
+// whose amounts out are within the tied routes tolerance of the top route amount out.
+// The number of the ranked routes is bounded by the max routes. The tied routes whose spot prices fail to compute
+// are skipped. The geometric mean is computed relative to the first spot price so that the product of the spot prices
+// does not lose precision for the extreme prices.
+// Returns the chain price alongside the fee of the top route.
+// Returns error wrapping domain.ErrUnpriceable if no route is found or if none of the tied route spot prices computes.
+func (c *chainPricing) computeTiedRoutesChainPrice(ctx context.Context, tenQuoteCoin sdk.Coin, baseDenom string, quoteDenom string, options domain.PricingOptions) (osmomath.BigDec, osmomath.Dec, error) {
+	routingOptions := c.getPricingRouterOptions(baseDenom, quoteDenom, options, false)
+
+	quotes, err := c.getRankedQuotesAvoidingSuspectPools(ctx, tenQuoteCoin, baseDenom, c.getPricingMaxRoutes(options), routingOptions)
+	if err != nil {
+		return osmomath.BigDec{}, osmomath.Dec{}, fmt.Errorf("ranked quotes for %s (base) -> %s (quote): %w", baseDenom, quoteDenom, err)
+	}
+
+	if len(quotes) == 0 || len(quotes[0].GetRoute()) == 0 {
+		return osmomath.BigDec{}, osmomath.Dec{}, fmt.Errorf("%w: no route found when computing tied routes pricing for %s (base) -> %s (quote)", domain.ErrUnpriceable, baseDenom, quoteDenom)
+	}
+
+	// The quotes are sorted by amount out in descending order so the ties are a prefix.
+	minTiedAmountOut := quotes[0].GetAmountOut().ToLegacyDec().MulMut(osmomath.OneDec().Sub(options.TiedRoutesTolerance))
+
+	var (
+		routePrices []osmomath.BigDec
+		tiedRoutes  []domain.SplitRoute
+	)
+	for _, quote := range quotes {
+		if quote.GetAmountOut().ToLegacyDec().LT(minTiedAmountOut) {
+			break
+		}
+
+		for _, route := range quote.GetRoute() {
+			if len(route.GetPools()) == 0 {
+				continue
+			}
+
+			routePrice, err := c.computeRouteSpotPrice(ctx, route, quoteDenom, options.TWAPWindow)
+			if err != nil {
+				if isContextError(err) {
+					return osmomath.BigDec{}, osmomath.Dec{}, err
+				}
+				continue
+			}
+
+			routePrices = append(routePrices, routePrice)
+			tiedRoutes = append(tiedRoutes, route)
+		}
+	}
+
+	if len(routePrices) == 0 {
+		return osmomath.BigDec{}, osmomath.Dec{}, fmt.Errorf("%w: no tied route with a spot price found when computing tied routes pricing for %s (base) -> %s (quote)", domain.ErrUnpriceable, baseDenom, quoteDenom)
+	}
+
+	chainPrice, err := computeGeometricMean(routePrices)
+	if err != nil {
+		return osmomath.BigDec{}, osmomath.Dec{}, fmt.Errorf("geometric mean of tied route prices for %s (base) -> %s (quote): %w", baseDenom, quoteDenom, err)
+	}
+
+	// The geometric mean of several tied routes is not computed along a single route.
+	if len(tiedRoutes) == 1 {
+		getPriceExplanationRecorder(ctx).recordPriceRoute(tiedRoutes[0], tenQuoteCoin)
+	}
+
+	return chainPrice, computeRoutesFee(quotes[0].GetRoute()), nil
+}
+
+// computeGeometricMean returns the geometric mean of the given positive prices computed as
+// p_0 * (∏ p_i / p_0)^(1/n) so that the ratios stay close to one for the near-identical prices.
+// Returns error if the root fails to converge.
+func computeGeometricMean(prices []osmomath.BigDec) (osmomath.BigDec, error) {
+	if len(prices) == 1 {
+		return prices[0], nil
+	}
+
+	reference := prices[0]
+
+	ratioProduct := osmomath.OneBigDec()
+	for _, price := range prices[1:] {
+		ratioProduct = ratioProduct.MulMut(price.Quo(reference))
+	}
+
+	ratioMean, err := ratioProduct.ApproxRoot(uint64(len(prices)))
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	return ratioMean.MulMut(reference), nil
+}
+
+// computeRouteOSMOLiquidity returns the OSMO liquidity of the least liquid pool of the given route.
+// Returns zero if the liquidity of any pool fails to compute.
+func computeRouteOSMOLiquidity(route domain.SplitRoute, coinOSMOValue domain.CoinOSMOValueFunc) osmomath.Int {
+	var routeLiquidity osmomath.Int
+	for _, pool := range route.GetPools() {
+		poolOSMOLiquidity, err := domain.ComputePoolOSMOLiquidity(pool, coinOSMOValue)
+		if err != nil {
+			return osmomath.ZeroInt()
+		}
+
+		if routeLiquidity.IsNil() || poolOSMOLiquidity.LT(routeLiquidity) {
+			routeLiquidity = poolOSMOLiquidity
+		}
+	}
+
+	if routeLiquidity.IsNil() {
+		return osmomath.ZeroInt()
+	}
+	return routeLiquidity
+}
+
+// computeWeightedMedian returns the candidate with the weighted median price, i.e. the lowest price
+// at which the cumulative weight of the candidates sorted by price reaches half of the total weight.
+// The candidates are weighted equally if none of them has positive weight.
+// The candidates must not be empty.
+func computeWeightedMedian(candidates []routeMedianCandidate) routeMedianCandidate {
+	sorted := make([]routeMedianCandidate, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].price.LT(sorted[j].price)
+	})
+
+	totalWeight := osmomath.ZeroInt()
+	for _, candidate := range sorted {
+		totalWeight = totalWeight.Add(candidate.weight)
+	}
+
+	if !totalWeight.IsPositive() {
+		for i := range sorted {
+			sorted[i].weight = osmomath.OneInt()
+		}
+		totalWeight = osmomath.NewInt(int64(len(sorted)))
+	}
+
+	cumulativeWeight := osmomath.ZeroInt()
+	for _, candidate := range sorted {
+		cumulativeWeight = cumulativeWeight.Add(candidate.weight)
+		if cumulativeWeight.MulRaw(2).GTE(totalWeight) {
+			return candidate
+		}
+	}
+
+	return sorted[len(sorted)-1]
+}
+
+// formatRoutePoolSet formats the sorted IDs of the given pools so that the routes
+// through the same set of pools format equally regardless of the pool order.
+func formatRoutePoolSet(pools []sqsdomain.RoutablePool) string {
+	poolIDs := make([]uint64, 0, len(pools))
+	for _, pool := range pools {
+		poolIDs = append(poolIDs, pool.GetId())
+	}
+	sort.Slice(poolIDs, func(i, j int) bool { return poolIDs[i] < poolIDs[j] })
+
+	return fmt.Sprint(poolIDs)
+}
+
+// computeAlternativeChainPrice computes the on-chain price for 1 unit of base denom and quote denom
+// by dividing the quote amount in by the amount out of the given quote.
+func computeAlternativeChainPrice(tenQuoteCoin sdk.Coin, quote domain.Quote) osmomath.BigDec {
+	// Note that the quote might be reused so its amount out must not be mutated.
+	return osmomath.NewBigDecFromBigInt(tenQuoteCoin.Amount.BigIntMut()).QuoMut(osmomath.NewBigDecFromBigInt(quote.GetAmountOut().BigInt()))
+}
+
+// getPricingRouterOptions returns the router options for computing the pricing quote
+// of the base denom in the quote denom. Split routes are disabled unless requested.
+func (c *chainPricing) getPricingRouterOptions(baseDenom string, quoteDenom string, options domain.PricingOptions, isSplitRoutes bool) []domain.RouterOption {
+	// Use the configured route limits unless overridden by options in GetPrice(...)
+	maxRoutes := c.getPricingMaxRoutes(options)
+	maxPoolsPerRoute := c.maxPoolsPerRoute
+	if options.MaxPoolsPerRoute > 0 {
+		maxPoolsPerRoute = options.MaxPoolsPerRoute
+	}
+
+	// Overwrite default config with custom values
+	// necessary for pricing.
+	routingOptions := []domain.RouterOption{
+		domain.WithMaxRoutes(maxRoutes),
+		domain.WithMaxPoolsPerRoute(maxPoolsPerRoute),
+		// Use the provided min liquidity value rather than the default
+		// Since it can be overridden by options in GetPrice(...)
+		domain.WithMinOSMOLiquidity(options.MinLiquidity),
+		domain.WithAllowedPoolTypes(c.allowedPoolTypes...),
+	}
+
+	// The denom cannot be intermediate in its own routes.
+	if c.requiredIntermediateDenom != "" && baseDenom != c.requiredIntermediateDenom && quoteDenom != c.requiredIntermediateDenom {
+		routingOptions = append(routingOptions, domain.WithRequiredIntermediateDenom(c.requiredIntermediateDenom))
+	}
+
+	if c.volumeWeightedRouteSelection {
+		routingOptions = append(routingOptions, domain.WithVolumeBiasedRouteSelection(c.TUsecase.GetPoolVolume, volumeRouteSelectionTolerance))
+	}
+
+	if !isSplitRoutes {
+		routingOptions = append(routingOptions, domain.WithDisableSplitRoutes())
+	}
+
+	return routingOptions
+}
+
+// withSuspectPoolsExcluded returns the given router options with the currently suspect pools excluded.
+// The excluded pools disable the router route caches so they are only excluded once a route passes through them.
+// The given router options are not mutated.
+func (c *chainPricing) withSuspectPoolsExcluded(routingOptions []domain.RouterOption) []domain.RouterOption {
+	suspectPoolIDs := c.suspectPools.activePoolIDs(time.Now())
+	return append(routingOptions[:len(routingOptions):len(routingOptions)], domain.WithExcludedPoolIDs(suspectPoolIDs...))
+}
+
+// getOptimalQuoteAvoidingSuspectPools returns the optimal quote of the given token in into the base denom.
+// If the quote passes through any of the suspect pools, it is recomputed with the suspect pools excluded.
+func (c *chainPricing) getOptimalQuoteAvoidingSuspectPools(ctx context.Context, tokenIn sdk.Coin, baseDenom string, routingOptions []domain.RouterOption) (domain.Quote, error) {
+	quote, err := c.RUsecase.GetOptimalQuote(ctx, tokenIn, baseDenom, routingOptions...)
+	if err != nil || quote == nil || !c.suspectPools.containsAny(getQuotePoolIDs(quote), time.Now()) {
+		return quote, err
+	}
+
+	return c.RUsecase.GetOptimalQuote(ctx, tokenIn, baseDenom, c.withSuspectPoolsExcluded(routingOptions)...)
+}
+
+// getRankedQuotesAvoidingSuspectPools returns up to k ranked quotes of the given token in into the base denom.
+// If any of the quotes passes through any of the suspect pools, they are recomputed with the suspect pools excluded.
+func (c *chainPricing) getRankedQuotesAvoidingSuspectPools(ctx context.Context, tokenIn sdk.Coin, baseDenom string, k int, routingOptions []domain.RouterOption) ([]domain.Quote, error) {
+	quotes, err := c.RUsecase.GetRankedQuotes(ctx, tokenIn, baseDenom, k, routingOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, quote := range quotes {
+		if c.suspectPools.containsAny(getQuotePoolIDs(quote), now) {
+			return c.RUsecase.GetRankedQuotes(ctx, tokenIn, baseDenom, k, c.withSuspectPoolsExcluded(routingOptions)...)
+		}
+	}
+
+	return quotes, nil
+}
+
+// getPricingMaxRoutes returns the max routes override of the given options if set.
+// Otherwise, returns the configured max routes.
+func (c *chainPricing) getPricingMaxRoutes(options domain.PricingOptions) int {
+	if options.MaxRoutes > 0 {
+		return options.MaxRoutes
+	}
+	return c.maxRoutes
+}
+
+// getPricingQuote returns the quote of the given quote coin into the base denom.
+// Returns error wrapping domain.ErrUnpriceable if no quote or route is found.
+func (c *chainPricing) getPricingQuote(ctx context.Context, tenQuoteCoin sdk.Coin, baseDenom string, quoteDenom string, options domain.PricingOptions, routingOptions []domain.RouterOption) (domain.Quote, error) {
+	quote, err := c.getQuote(ctx, tenQuoteCoin, baseDenom, options, routingOptions)
+	if err != nil {
+		return nil, fmt.Errorf("optimal quote for %s (base) -> %s (quote): %w", baseDenom, quoteDenom, err)
+	}
+	if quote == nil {
+		return nil, fmt.Errorf("%w: no quote found when computing pricing for %s (base) -> %s (quote)", domain.ErrUnpriceable, baseDenom, quoteDenom)
+	}
+
+	if len(quote.GetRoute()) == 0 {
+		return nil, fmt.Errorf("%w: no route found when computing pricing for %s (base) -> %s (quote)", domain.ErrUnpriceable, baseDenom, quoteDenom)
+	}
+
+	return quote, nil
+}
+
+// GetPriceWithSlippage implements domain.PricingSource.
+// The spot price before the swap is computed by computePrice so that it never drifts from GetPrice.
+// The effective price is the quote coin divided by the amount out of the route that the price is computed along,
+// descaled by the same scaling factor as the price.
+func (c *chainPricing) GetPriceWithSlippage(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, osmomath.BigDec, error) {
+	options := domain.PricingOptions{
+		MinLiquidity:   c.minOSMOLiquidity,
+		PricePrecision: domain.NoPricePrecision,
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if quoteDenom == "" {
+		var err error
+		quoteDenom, err = c.resolveDefaultQuoteDenom(options)
+		if err != nil {
+			return osmomath.BigDec{}, osmomath.BigDec{}, err
+		}
+	}
+
+	// Equal base and quote have no slippage unless forced.
+	if baseDenom == quoteDenom && !options.ForceCompute {
+		return osmomath.OneBigDec(), osmomath.OneBigDec(), nil
+	}
+
+	ctx, explanationRecorder := withPriceExplanationRecorder(ctx)
+
+	spotPriceBefore, err := c.computePriceWithCircuitBreaker(ctx, baseDenom, quoteDenom, options)
+	if isPricingFailure(err) {
+		return osmomath.BigDec{}, osmomath.BigDec{}, err
+	}
+
+	priceRoute, tokenIn := explanationRecorder.getPriceRoute()
+	if priceRoute == nil {
+		return osmomath.BigDec{}, osmomath.BigDec{}, fmt.Errorf("effective price of %s (base) -> %s (quote) is undefined since the price is not computed along a single route", baseDenom, quoteDenom)
+	}
+
+	amountOut := priceRoute.GetAmountOut()
+	if amountOut.IsNil() || !amountOut.IsPositive() {
+		return osmomath.BigDec{}, osmomath.BigDec{}, fmt.Errorf("%w: non-positive amount out when computing pricing for %s (base) -> %s (quote)", domain.ErrUnpriceable, baseDenom, quoteDenom)
+	}
+
+	// The route swaps the quote denom into the base denom so the effective chain price is the amount in per amount out.
+	effectivePrice := osmomath.NewBigDecFromBigInt(tokenIn.Amount.BigInt()).QuoMut(osmomath.NewBigDecFromBigInt(amountOut.BigInt()))
+	if !options.RawChainPrice {
+		// Apply scaling factors to descale the amounts to real amounts.
+		effectivePrice = effectivePrice.MulMut(explanationRecorder.getExplanation().PrecisionScalingFactor)
+	}
+
+	// The low-confidence prices are returned like any other price.
+	return spotPriceBefore, roundPrice(effectivePrice, options.PricePrecision), nil
+}
+
+// GetPriceAndRoute implements domain.PricingSource.
+// The price is computed by computePrice so that it never drifts from GetPrice, with the route
+// reported via the price explanation recorder of the context.
+func (c *chainPricing) GetPriceAndRoute(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, domain.SplitRoute, error) {
+	options := domain.PricingOptions{
+		MinLiquidity:   c.minOSMOLiquidity,
+		PricePrecision: domain.NoPricePrecision,
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if quoteDenom == "" {
+		var err error
+		quoteDenom, err = c.resolveDefaultQuoteDenom(options)
+		if err != nil {
+			return osmomath.BigDec{}, nil, err
+		}
+	}
+
+	// Equal base and quote yield the price of one without computation unless forced.
+	if baseDenom == quoteDenom && !options.ForceCompute {
+		return osmomath.OneBigDec(), nil, nil
+	}
+
+	ctx, explanationRecorder := withPriceExplanationRecorder(ctx)
+
+	price, err := c.computePriceWithCircuitBreaker(ctx, baseDenom, quoteDenom, options)
+	if isPricingFailure(err) {
+		return osmomath.BigDec{}, nil, err
+	}
+
+	priceRoute, tokenIn := explanationRecorder.getPriceRoute()
+	if priceRoute == nil {
+		return price, nil, nil
+	}
+
+	// The quote logic is not re-run since the effective spot price is not needed.
+	preparedPools, _, _, err := priceRoute.PrepareResultPools(ctx, tokenIn, domain.WithDryRun())
+	if err != nil {
+		return osmomath.BigDec{}, nil, err
+	}
+
+	// The low-confidence prices are returned like any other price.
+	return price, &preparedRoute{SplitRoute: priceRoute, pools: preparedPools}, nil
+}
+
+// preparedRoute is the route that a price was computed along with its pools prepared for output.
+type preparedRoute struct {
+	domain.SplitRoute
+	pools []sqsdomain.RoutablePool
+}
+
+// GetPools implements domain.Route.
+func (r *preparedRoute) GetPools() []sqsdomain.RoutablePool {
+	return r.pools
+}
+
+// ExplainPrice implements domain.PricingSource.
+// The explanation is recorded by computePrice so that it never drifts from the computation.
+// Returns the explanation alongside the error if the price is computed with the default scaling factor.
+// Returns error if the quote denom is the composite quote since its price is the weighted average
+// of the several computations.
+func (c *chainPricing) ExplainPrice(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (domain.PriceExplanation, error) {
+	options := domain.PricingOptions{
+		MinLiquidity:   c.minOSMOLiquidity,
+		PricePrecision: domain.NoPricePrecision,
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if quoteDenom == "" {
+		var err error
+		quoteDenom, err = c.resolveDefaultQuoteDenom(options)
+		if err != nil {
+			return domain.PriceExplanation{}, err
+		}
+	}
+
+	if len(c.compositeQuote) > 0 && quoteDenom == c.defaultQuoteDenom {
+		return domain.PriceExplanation{}, fmt.Errorf("explaining the price in the composite quote (%s) is not supported", quoteDenom)
+	}
+
+	// Equal base and quote yield the price of one without computation unless forced.
+	if baseDenom == quoteDenom && !options.ForceCompute {
+		return domain.PriceExplanation{
+			BaseDenom:  baseDenom,
+			QuoteDenom: quoteDenom,
+			ChainPrice: osmomath.OneBigDec(),
+			Price:      osmomath.OneBigDec(),
+		}, nil
+	}
+
+	ctx, explanationRecorder := withPriceExplanationRecorder(ctx)
+
+	_, err := c.computePrice(ctx, baseDenom, quoteDenom, options)
+
+	explanation := explanationRecorder.getExplanation()
+	explanation.BaseDenom = baseDenom
+	explanation.QuoteDenom = quoteDenom
+
+	// The low-confidence prices are flagged by the explanation.
+	if isPricingFailure(err) {
+		return domain.PriceExplanation{}, err
+	}
+
+	return explanation, nil
+}
+
+// ComputePriceForRoute implements domain.PricingSource.
+func (c *chainPricing) ComputePriceForRoute(ctx context.Context, route domain.SplitRoute, baseDenom string, quoteDenom string) (osmomath.BigDec, error) {
+	if baseDenom == quoteDenom {
+		return osmomath.OneBigDec(), nil
+	}
+
+	pools := route.GetPools()
+	if len(pools) == 0 {
+		// Increase empty route pools counter
+		pricesEmptyRoutePoolsCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+		return osmomath.BigDec{}, fmt.Errorf("%w when computing pricing for %s (base) -> %s (quote)", domain.ErrEmptyRoutePools, baseDenom, quoteDenom)
+	}
+
+	if tokenOutDenom := pools[len(pools)-1].GetTokenOutDenom(); tokenOutDenom != baseDenom {
+		return osmomath.BigDec{}, fmt.Errorf("route token out denom (%s) does not match base denom (%s)", tokenOutDenom, baseDenom)
+	}
+
+	_, precisionScalingFactor, err := c.getQuoteCoinAndPrecisionScalingFactor(baseDenom, quoteDenom)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	chainPrice, err := c.computeRouteSpotPrice(ctx, route, quoteDenom, 0)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	// Apply scaling facors to descale the amounts to real amounts.
+	return chainPrice.MulMut(precisionScalingFactor), nil
+}
+
+// hasPrecisionLoss returns true if the given value deviates from its full precision counterpart
+// by more than precisionLossTolerance relative to the full precision value.
+func hasPrecisionLoss(value osmomath.BigDec, fullPrecisionValue osmomath.BigDec) bool {
+	if fullPrecisionValue.IsZero() {
+		return !value.IsZero()
+	}
+
+	return value.Sub(fullPrecisionValue).Abs().GT(fullPrecisionValue.Abs().MulMut(precisionLossTolerance))
+}
+
+// getQuoteCoinAndPrecisionScalingFactor returns the quote coin used to compute the pricing quote
+// and the precision scaling factor that descales the chain price of the given denoms to the real price.
+// Returns error if the scaling factor of either of the denoms is unknown.
+func (c *chainPricing) getQuoteCoinAndPrecisionScalingFactor(baseDenom string, quoteDenom string) (sdk.Coin, osmomath.BigDec, error) {
+	tenQuoteCoin, precisionScalingFactor, _, err := c.getQuoteCoinAndPrecisionScalingFactorWithDefault(baseDenom, quoteDenom, osmomath.Dec{}, nil)
+	return tenQuoteCoin, precisionScalingFactor, err
+}
+
+// getScalingFactor returns the scaling factor of the given denom from the precision provider if it has one.
+// Otherwise, falls back to the on-chain scaling factor.
+func (c *chainPricing) getScalingFactor(denom string, precisionProvider domain.PrecisionProvider) (osmomath.Dec, error) {
+	if precisionProvider != nil {
+		if scalingFactor, ok := precisionProvider.GetScalingFactor(denom); ok {
+			// Clone since the provider's scaling factor might be shared.
+			return scalingFactor.Clone(), nil
+		}
+	}
+
+	return c.TUsecase.GetChainScalingFactorByDenomMut(denom)
+}
+
+// getQuoteCoinAndPrecisionScalingFactorWithDefault is equivalent to getQuoteCoinAndPrecisionScalingFactor
+// but uses the given default scaling factor in place of the unknown scaling factors unless it is nil.
+// The scaling factors are looked up in the given precision provider first, if any (see getScalingFactor).
+// Returns true if the default scaling factor is used.
+func (c *chainPricing) getQuoteCoinAndPrecisionScalingFactorWithDefault(baseDenom string, quoteDenom string, defaultScalingFactor osmomath.Dec, precisionProvider domain.PrecisionProvider) (sdk.Coin, osmomath.BigDec, bool, error) {
+	isDefaultScalingFactorUsed := false
+
+	// Get scaling factor for base denom.
+	baseDenomScalingFactor, err := c.getScalingFactor(baseDenom, precisionProvider)
+	if err != nil {
+		if defaultScalingFactor.IsNil() {
+			return sdk.Coin{}, osmomath.BigDec{}, false, fmt.Errorf("base scaling factor for %s: %w", baseDenom, err)
+		}
+		baseDenomScalingFactor = defaultScalingFactor.Clone()
+		isDefaultScalingFactorUsed = true
+	}
+
+	// Get scaling factor for quote denom.
+	quoteDenomScalingFactor, err := c.getScalingFactor(quoteDenom, precisionProvider)
+	if err != nil {
+		if defaultScalingFactor.IsNil() {
+			return sdk.Coin{}, osmomath.BigDec{}, false, fmt.Errorf("quote scaling factor for %s: %w", quoteDenom, err)
+		}
+		quoteDenomScalingFactor = defaultScalingFactor.Clone()
+		isDefaultScalingFactorUsed = true
+	}
+
+	// Create a quote denom coin.
+	// We use multiplier so that stablecoin quotes avoid selecting low liquidity routes.
+	multiplier := int64(1)
+	if _, isStablecoinQuote := c.stablecoinQuoteDenoms[quoteDenom]; isStablecoinQuote || c.stablecoinQuoteDenoms == nil {
+		multiplier = tokenInMultiplier
+	}
+	tenQuoteCoin := sdk.NewCoin(quoteDenom, osmomath.NewInt(multiplier).Mul(quoteDenomScalingFactor.TruncateInt()))
+
+	// Compute precision scaling factor.
+	precisionScalingFactor := osmomath.BigDecFromDec(osmomath.NewDec(multiplier).MulMut(baseDenomScalingFactor.Quo(tenQuoteCoin.Amount.ToLegacyDec())))
+
+	// Dec has fewer decimal places than BigDec so the scaling factor loses precision
+	// for the denoms with extreme scaling factors. Prefer the full precision if so.
+	fullPrecisionScalingFactor := osmomath.NewBigDec(multiplier).MulMut(osmomath.BigDecFromDec(baseDenomScalingFactor)).QuoMut(osmomath.NewBigDecFromBigInt(tenQuoteCoin.Amount.BigInt()))
+	if hasPrecisionLoss(precisionScalingFactor, fullPrecisionScalingFactor) {
+		pricesPrecisionLossCounter.WithLabelValues(baseDenom, quoteDenom).Inc()
+
+		return tenQuoteCoin, fullPrecisionScalingFactor, isDefaultScalingFactorUsed, nil
+	}
+
+	return tenQuoteCoin, precisionScalingFactor, isDefaultScalingFactorUsed, nil
+}
+
+// getQuote returns the quote for the given token in and base denom.
+// If route reuse is enabled and the height is known, reuses the quote computed
+// within the same route update height window. Otherwise, computes and stores it for reuse.
+func (c *chainPricing) getQuote(ctx context.Context, tokenIn sdk.Coin, baseDenom string, options domain.PricingOptions, routingOptions []domain.RouterOption) (domain.Quote, error) {
+	if c.routeCache == nil || options.Height == 0 {
+		return c.getOptimalQuoteAvoidingSuspectPools(ctx, tokenIn, baseDenom, routingOptions)
+	}
+
+	// Quotes are only reusable for the same direction and routing options.
+	routeCacheKey := formatRouteCacheKey(tokenIn.Denom, baseDenom, options)
+	heightWindow := options.Height / c.routeUpdateHeightInterval
+
+	if cachedValue, found := c.routeCache.Get(routeCacheKey); found {
+		if cachedQuote, ok := cachedValue.(reusableQuote); ok && cachedQuote.heightWindow == heightWindow && !c.suspectPools.containsAny(getQuotePoolIDs(cachedQuote.quote), time.Now()) {
+			return cachedQuote.quote, nil
+		}
+	}
+
+	quote, err := c.getOptimalQuoteAvoidingSuspectPools(ctx, tokenIn, baseDenom, routingOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// The quotes computed for a window lower than the latest one are already outdated.
+	if c.advanceRouteHeightWindow(heightWindow) {
+		c.routeCache.Set(routeCacheKey, reusableQuote{heightWindow: heightWindow, quote: quote}, cache.NoExpirationTTL)
+	}
+
+	return quote, nil
+}
+
+// advanceRouteHeightWindow advances the latest route update height window to the given one,
+// purging the quotes cached for the lower windows.
+// Returns false if the given window is lower than the latest one.
+func (c *chainPricing) advanceRouteHeightWindow(heightWindow uint64) bool {
+	for {
+		latestHeightWindow := c.latestRouteHeightWindow.Load()
+		if heightWindow < latestHeightWindow {
+			return false
+		}
+
+		if heightWindow == latestHeightWindow {
+			return true
+		}
+
+		if c.latestRouteHeightWindow.CompareAndSwap(latestHeightWindow, heightWindow) {
+			break
+		}
+	}
+
+	// The cache must not be modified from within Range so the stale keys are collected first.
+	staleKeys := make([]string, 0)
+	c.routeCache.Range(func(key string, value interface{}, _ time.Time) bool {
+		if cachedQuote, ok := value.(reusableQuote); !ok || cachedQuote.heightWindow < heightWindow {
+			staleKeys = append(staleKeys, key)
+		}
+		return true
+	})
+
+	for _, key := range staleKeys {
+		c.routeCache.Delete(key)
+	}
+
+	return true
+}
+
+// getQuotePoolIDs returns the IDs of the pools across all routes of the given quote.
+func getQuotePoolIDs(quote domain.Quote) []uint64 {
+	var poolIDs []uint64
+	for _, route := range quote.GetRoute() {
+		for _, pool := range route.GetPools() {
+			poolIDs = append(poolIDs, pool.GetId())
+		}
+	}
+	return poolIDs
+}
+
+// getPoolPrices returns the pool prices for the given requests.
+// If the TWAP window is positive and the TWAP provider is set, the pool TWAPs over the window are used.
+// The pools without a TWAP fall back to their spot prices fetched in one batch.
+// The TWAPs are queried pool by pool so the remaining pools fail with the context error once it is done.
+// Otherwise, the pool spot prices are returned.
+func (c *chainPricing) getPoolPrices(ctx context.Context, requests []domain.SpotPriceRequest, twapWindow time.Duration) ([]osmomath.BigDec, []error) {
+	if twapWindow <= 0 {
+		return c.getPoolSpotPrices(ctx, requests)
+	}
+
+	c.twapProviderMu.RLock()
+	provider := c.twapProvider
+	c.twapProviderMu.RUnlock()
+
+	if provider == nil {
+		return c.getPoolSpotPrices(ctx, requests)
+	}
+
+	prices := make([]osmomath.BigDec, len(requests))
+	errs := make([]error, len(requests))
+
+	missingRequests := make([]domain.SpotPriceRequest, 0, len(requests))
+	missingIndexes := make([]int, 0, len(requests))
+
+	for i, request := range requests {
+		// Stop querying the TWAPs of the remaining pools once the request is abandoned.
+		select {
+		case <-ctx.Done():
+			for _, j := range missingIndexes {
+				errs[j] = fmt.Errorf("price of pool (%d): %w", requests[j].PoolID, ctx.Err())
+			}
+			for j := i; j < len(requests); j++ {
+				errs[j] = fmt.Errorf("price of pool (%d): %w", requests[j].PoolID, ctx.Err())
+			}
+			return prices, errs
+		default:
+		}
+
+		if twap, ok := provider.GetPoolTWAP(ctx, request.PoolID, request.QuoteDenom, request.BaseDenom, twapWindow); ok && !twap.IsNil() && twap.IsPositive() {
+			prices[i] = twap
+			continue
+		}
+
+		missingRequests = append(missingRequests, request)
+		missingIndexes = append(missingIndexes, i)
+	}
+
+	pricesTWAPPoolPricesCounter.WithLabelValues(poolPriceSourceTWAP).Add(float64(len(requests) - len(missingRequests)))
+
+	if len(missingRequests) == 0 {
+		return prices, errs
+	}
+
+	pricesTWAPPoolPricesCounter.WithLabelValues(poolPriceSourceSpot).Add(float64(len(missingRequests)))
+
+	spotPrices, spotErrs := c.getPoolSpotPrices(ctx, missingRequests)
+	for j, i := range missingIndexes {
+		prices[i], errs[i] = spotPrices[j], spotErrs[j]
+	}
+
+	return prices, errs
+}
+
+// getPoolSpotPrices returns the pool spot prices for the given requests.
+// If the spot price cache is enabled, only the spot prices missing from cache are fetched
+// in one batch and the successfully fetched ones are cached.
+func (c *chainPricing) getPoolSpotPrices(ctx context.Context, requests []domain.SpotPriceRequest) ([]osmomath.BigDec, []error) {
+	if c.spotPriceCache == nil {
+		return c.fetchPoolSpotPrices(ctx, requests)
+	}
+
+	spotPrices := make([]osmomath.BigDec, len(requests))
+	errs := make([]error, len(requests))
+
+	missingRequests := make([]domain.SpotPriceRequest, 0, len(requests))
+	missingIndexes := make([]int, 0, len(requests))
+
+	for i, request := range requests {
+		if cachedValue, found := c.spotPriceCache.Get(formatSpotPriceCacheKey(request)); found {
+			if cachedSpotPrice, ok := cachedValue.(osmomath.BigDec); ok {
+				spotPrices[i] = cachedSpotPrice
+				continue
+			}
+		}
+
+		missingRequests = append(missingRequests, request)
+		missingIndexes = append(missingIndexes, i)
+	}
+
+	if len(missingRequests) == 0 {
+		return spotPrices, errs
+	}
+
+	missingSpotPrices, missingErrs := c.fetchPoolSpotPrices(ctx, missingRequests)
+
+	for j, i := range missingIndexes {
+		spotPrices[i], errs[i] = missingSpotPrices[j], missingErrs[j]
+
+		if missingErrs[j] == nil && !missingSpotPrices[j].IsNil() {
+			c.spotPriceCache.Set(formatSpotPriceCacheKey(missingRequests[j]), missingSpotPrices[j], c.spotPriceCacheExpiry)
+		}
+	}
+
+	return spotPrices, errs
+}
+
+// fetchPoolSpotPrices fetches the pool spot prices for the given requests in one batch.
+// The requests failing with retryable errors are retried in one batch up to the configured
+// max number of retries, doubling the backoff between the retries.
+// Stops retrying if the context is done.
+func (c *chainPricing) fetchPoolSpotPrices(ctx context.Context, requests []domain.SpotPriceRequest) ([]osmomath.BigDec, []error) {
+	spotPrices, errs := c.RUsecase.GetPoolSpotPrices(ctx, requests)
+
+	backoff := c.spotPriceRetryBackoff
+	for retry := 0; retry < c.spotPriceMaxRetries; retry++ {
+		retryIndexes := make([]int, 0)
+		for i, err := range errs {
+			if err != nil && isRetryableSpotPriceError(err) {
+				retryIndexes = append(retryIndexes, i)
+			}
+		}
+
+		if len(retryIndexes) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return spotPrices, errs
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+
+		retryRequests := make([]domain.SpotPriceRequest, 0, len(retryIndexes))
+		for _, i := range retryIndexes {
+			retryRequests = append(retryRequests, requests[i])
+		}
+
+		retrySpotPrices, retryErrs := c.RUsecase.GetPoolSpotPrices(ctx, retryRequests)
+		for j, i := range retryIndexes {
+			spotPrices[i], errs[i] = retrySpotPrices[j], retryErrs[j]
+		}
+	}
+
+	return spotPrices, errs
+}
+
+// isContextError returns true if the given error is due to the context being cancelled or past its deadline.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// isRetryableSpotPriceError returns true if the given pool spot price error might be transient.
+// Returns false for the definitive errors where the pool does not support the spot price
+// in its current state as well as for the context errors.
+func isRetryableSpotPriceError(err error) bool {
+	if isContextError(err) {
+		return false
+	}
+
+	var (
+		invalidPoolTypeError           domain.InvalidPoolTypeError
+		unsupportedCosmWasmPoolError   domain.UnsupportedCosmWasmPoolTypeError
+		poolNotFoundError              domain.PoolNotFoundError
+		noTickModelError               domain.ConcentratedPoolNoTickModelError
+		tickModelNotSetError           domain.ConcentratedTickModelNotSetError
+		takerFeeNotFoundError          domain.TakerFeeNotFoundForDenomPairError
+		failedToCastPoolModelError     domain.FailedToCastPoolModelError
+		noLiquidityError               domain.ConcentratedNoLiquidityError
+		zeroCurrentSqrtPriceError      domain.ConcentratedZeroCurrentSqrtPriceError
+		currentTickNotWithinBucketErr  domain.ConcentratedCurrentTickNotWithinBucketError
+		currentTickBucketMismatchError domain.ConcentratedCurrentTickAndBucketMismatchError
+	)
+
+	return !errors.As(err, &invalidPoolTypeError) &&
+		!errors.As(err, &unsupportedCosmWasmPoolError) &&
+		!errors.As(err, &poolNotFoundError) &&
+		!errors.As(err, &noTickModelError) &&
+		!errors.As(err, &tickModelNotSetError) &&
+		!errors.As(err, &takerFeeNotFoundError) &&
+		!errors.As(err, &failedToCastPoolModelError) &&
+		!errors.As(err, &noLiquidityError) &&
+		!errors.As(err, &zeroCurrentSqrtPriceError) &&
+		!errors.As(err, &currentTickNotWithinBucketErr) &&
+		!errors.As(err, &currentTickBucketMismatchError)
+}
+
+// formatSpotPriceCacheKey formats the spot price cache key for the given request.
+func formatSpotPriceCacheKey(request domain.SpotPriceRequest) string {
+	return fmt.Sprintf("%d|%s|%s", request.PoolID, request.BaseDenom, request.QuoteDenom)
+}
+
+// computeRouteSpotPrice computes the spot price of the route by multiplying the spot prices
+// of all pools in the route, starting from the quote denom.
+// The pool spot prices are fetched concurrently in one batch.
+// The pool TWAPs over the given window are used instead of the spot prices where available (see getPoolPrices).
+// Returns error if any of the pool spot prices fails to compute or is zero.
+func (c *chainPricing) computeRouteSpotPrice(ctx context.Context, route domain.SplitRoute, quoteDenom string, twapWindow time.Duration) (osmomath.BigDec, error) {
+	spotPrices, err := c.getRouteSpotPrices(ctx, route, quoteDenom, twapWindow)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	return multiplySpotPrices(spotPrices), nil
+}
+
+// getRouteSpotPrices returns the pool spot prices along the given route swapping from the quote denom
+// in the order of the route pools.
+// Returns error if any of the pool spot prices fails to compute or is zero.
+func (c *chainPricing) getRouteSpotPrices(ctx context.Context, route domain.SplitRoute, quoteDenom string, twapWindow time.Duration) ([]osmomath.BigDec, error) {
+	pools := route.GetPools()
+
+	spotPriceRequests := make([]domain.SpotPriceRequest, 0, len(pools))
+
+	tempQuoteDenom := quoteDenom
+	for _, pool := range pools {
+		tempBaseDenom := pool.GetTokenOutDenom()
+
+		spotPriceRequests = append(spotPriceRequests, domain.SpotPriceRequest{
+			PoolID:     pool.GetId(),
+			QuoteDenom: tempQuoteDenom,
+			BaseDenom:  tempBaseDenom,
+		})
+
+		tempQuoteDenom = tempBaseDenom
+	}
+
+	return c.getValidatedPoolSpotPrices(ctx, spotPriceRequests, twapWindow)
+}
+
+// computeSpotPriceProduct computes the product of the pool spot prices for the given requests.
+// Returns error if any of the pool spot prices fails to compute or is zero.
+func (c *chainPricing) computeSpotPriceProduct(ctx context.Context, spotPriceRequests []domain.SpotPriceRequest, twapWindow time.Duration) (osmomath.BigDec, error) {
+	spotPrices, err := c.getValidatedPoolSpotPrices(ctx, spotPriceRequests, twapWindow)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	return multiplySpotPrices(spotPrices), nil
+}
+
+// getValidatedPoolSpotPrices returns the pool spot prices for the given requests.
+// Returns error if the context is done or if any of the pool spot prices fails to compute or is zero.
+func (c *chainPricing) getValidatedPoolSpotPrices(ctx context.Context, spotPriceRequests []domain.SpotPriceRequest, twapWindow time.Duration) ([]osmomath.BigDec, error) {
+	// Do not fetch the spot prices for the abandoned requests.
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("pool spot prices: %w", ctx.Err())
+	default:
+	}
+
+	poolSpotPrices, errs := c.getPoolPrices(ctx, spotPriceRequests, twapWindow)
+
+	for i, poolSpotPrice := range poolSpotPrices {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("spot price of pool (%d) for %s (base) -> %s (quote): %w", spotPriceRequests[i].PoolID, spotPriceRequests[i].BaseDenom, spotPriceRequests[i].QuoteDenom, errs[i])
+		}
+		if poolSpotPrice.IsNil() || poolSpotPrice.IsZero() {
+			return nil, fmt.Errorf("invalid spot price (%s) for pool (%d)", poolSpotPrice, spotPriceRequests[i].PoolID)
+		}
+	}
+
+	getPriceExplanationRecorder(ctx).recordRoute(spotPriceRequests, poolSpotPrices)
+
+	return poolSpotPrices, nil
+}
+
+// multiplySpotPrices returns the product of the given spot prices without mutating them.
+func multiplySpotPrices(spotPrices []osmomath.BigDec) osmomath.BigDec {
+	chainPrice := osmomath.OneBigDec()
+	for _, spotPrice := range spotPrices {
+		// Multiply spot price by the previous spot price.
+		chainPrice = chainPrice.MulMut(spotPrice)
+	}
+
+	return chainPrice
+}
+
+// computeVolumeWeightedSpotPrice computes the average of the route spot prices
+// weighted by the amount in of each route.
+// The route spot prices are computed concurrently, bounded by the configured max concurrent route computes.
+// They are aggregated in the route order so that the result is deterministic.
+// Returns error if any of the route spot prices fails to compute or the total amount in is zero.
+func (c *chainPricing) computeVolumeWeightedSpotPrice(ctx context.Context, routes []domain.SplitRoute, quoteDenom string, twapWindow time.Duration) (osmomath.BigDec, error) {
+	routePrices := make([]osmomath.BigDec, len(routes))
+	routeErrs := make([]error, len(routes))
+
+	var wg sync.WaitGroup
+	for i, route := range routes {
+		wg.Add(1)
+		go func(i int, route domain.SplitRoute) {
+			defer wg.Done()
+
+			if c.routeComputeSlots != nil {
+				select {
+				case c.routeComputeSlots <- struct{}{}:
+					defer func() { <-c.routeComputeSlots }()
+				case <-ctx.Done():
+					routeErrs[i] = ctx.Err()
+					return
+				}
+			}
+
+			routePrices[i], routeErrs[i] = c.computeRouteSpotPrice(ctx, route, quoteDenom, twapWindow)
+		}(i, route)
+	}
+	wg.Wait()
+
+	var (
+		weightedPriceSum = osmomath.ZeroBigDec()
+		totalAmountIn    = osmomath.ZeroInt()
+	)
+
+	for i, route := range routes {
+		if routeErrs[i] != nil {
+			return osmomath.BigDec{}, routeErrs[i]
+		}
+
+		routeAmountIn := route.GetAmountIn()
+
+		weightedPriceSum.AddMut(routePrices[i].MulMut(osmomath.NewBigDecFromBigInt(routeAmountIn.BigInt())))
+		totalAmountIn = totalAmountIn.Add(routeAmountIn)
+	}
+
+	if totalAmountIn.IsZero() {
+		return osmomath.BigDec{}, fmt.Errorf("total amount in across routes is zero")
+	}
+
+	return weightedPriceSum.QuoMut(osmomath.NewBigDecFromBigInt(totalAmountIn.BigInt())), nil
+}
+
+// PurgeExpired removes all expired entries from the current pricing cache so that the periodic purge
+// follows the cache swapped by InitializeCache. Returns the number of purged entries.
+// Returns zero if no cache is set. The last known good prices past the retention are dropped as well.
+func (c *chainPricing) PurgeExpired() int {
+	c.pruneLastKnownGoodPrices()
+
+	pricingCache := c.getCache()
+	if pricingCache == nil {
+		return 0
+	}
+
+	purgedCount := pricingCache.PurgeExpired()
+
+	cachePurgedEntriesCounter.Add(float64(purgedCount))
+
+	return purgedCount
+}
+
+// purgeExpiredPeriodically purges the expired cache entries on the given interval
+// until the context is cancelled.
+func (c *chainPricing) purgeExpiredPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.PurgeExpired()
+		}
+	}
+}
+
+// roundPrice rounds the price to the given number of decimal places.
+// If rounding turns a positive price into zero, the number of decimal places
+// is increased until the rounded price is non-zero.
+// Returns the price as is if the precision is negative or the price is nil.
+func roundPrice(price osmomath.BigDec, decimals int) osmomath.BigDec {
+	if decimals < 0 || price.IsNil() || decimals >= maxPricePrecision {
+		return price
+	}
+
+	for ; decimals < maxPricePrecision; decimals++ {
+		scale := osmomath.NewBigDecFromBigInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+
+		roundedPrice := osmomath.NewBigDecFromBigInt(price.Mul(scale).RoundInt().BigInt()).QuoMut(scale)
+		if !roundedPrice.IsZero() || price.IsZero() {
+			return roundedPrice
+		}
+	}
+
+	return price
+}
+
+// ListCachedPairs implements domain.PricingSourceAdmin.
+func (c *chainPricing) ListCachedPairs() []domain.CachedPricePair {
+	now := time.Now()
+
+	cachedPairs := []domain.CachedPricePair{}
+	c.getCache().Range(func(key string, value interface{}, expiry time.Time) bool {
+		baseDenom, quoteDenom, err := parseCacheKey(key)
+		if err != nil {
+			return true
+		}
+
+		var price osmomath.BigDec
+		switch v := value.(type) {
+		case cachedPrice:
+			price = v.price
+		case osmomath.BigDec:
+			price = v
+		default:
+			return true
+		}
+
+		ttlRemaining := time.Duration(0)
+		if !expiry.IsZero() {
+			ttlRemaining = expiry.Sub(now)
+		}
+
+		cachedPairs = append(cachedPairs, domain.CachedPricePair{
+			BaseDenom:    baseDenom,
+			QuoteDenom:   quoteDenom,
+			Price:        price,
+			TTLRemaining: ttlRemaining,
+		})
+
+		return true
+	})
+
+	return cachedPairs
+}
+
+// ExportCacheSnapshot implements domain.PricingSourceAdmin.
+func (c *chainPricing) ExportCacheSnapshot() ([]byte, error) {
+	snapshot := cacheSnapshot{
+		ExportedAt: time.Now(),
+		Entries:    c.ListCachedPairs(),
+	}
+
+	return json.Marshal(snapshot)
+}
+
+// ImportCacheSnapshot implements domain.PricingSourceAdmin.
+func (c *chainPricing) ImportCacheSnapshot(data []byte) error {
+	var snapshot cacheSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to unmarshal pricing cache snapshot: %w", err)
+	}
+
+	elapsedSinceExport := time.Since(snapshot.ExportedAt)
+
+	for _, entry := range snapshot.Entries {
+		if entry.Price.IsNil() {
+			return fmt.Errorf("pricing cache snapshot entry for base denom (%s) and quote denom (%s) has no price", entry.BaseDenom, entry.QuoteDenom)
+		}
+
+		expirationTTL := cache.NoExpirationTTL
+		if entry.TTLRemaining != cache.NoExpirationTTL {
+			expirationTTL = entry.TTLRemaining - elapsedSinceExport
+
+			// Drop the entries that have expired since the export.
+			if expirationTTL <= 0 {
+				continue
+			}
+		}
+
+		cacheKey, err := formatCacheKey(entry.BaseDenom, entry.QuoteDenom)
+		if err != nil {
+			return fmt.Errorf("invalid pricing cache snapshot entry: %w", err)
+		}
+
+		// The compute time is not exported so the imported prices are treated as computed at import.
+		c.setCachedValue(cacheKey, cachedPrice{price: entry.Price, computedAt: time.Now()}, expirationTTL)
+	}
+
+	return nil
+}
+
+// WarmReverseDefaultQuotePrices implements domain.PricingSourceAdmin.
+// It caches the inverse of the cached default quote price of every worker-tracked base denom
+// under the reversed pair so that pricing the default quote in terms of the worker-tracked denoms
+// is served from the cache. Zero prices are skipped since they have no inverse.
+// The reverse prices warmed previously are deleted once their base denoms are no longer warmed,
+// for example, since they are no longer tracked, so that they are never served stale indefinitely.
+func (c *chainPricing) WarmReverseDefaultQuotePrices() error {
+	c.workerTrackedDenomsMu.RLock()
+	trackedDenoms := make([]string, 0, len(c.workerTrackedDenoms))
+	for denom := range c.workerTrackedDenoms {
+		trackedDenoms = append(trackedDenoms, denom)
+	}
+	c.workerTrackedDenomsMu.RUnlock()
+
+	warmedDenoms := make(map[string]struct{}, len(trackedDenoms))
+	for _, baseDenom := range trackedDenoms {
+		if baseDenom == c.defaultQuoteDenom {
+			continue
+		}
+
+		price, ok := c.getCachedPrice(baseDenom, c.defaultQuoteDenom)
+		if !ok || price.price.IsNil() || !price.price.IsPositive() {
+			continue
+		}
+
+		reverseKey, err := formatCacheKey(c.defaultQuoteDenom, baseDenom)
+		if err != nil {
+			return fmt.Errorf("failed to format reverse pricing cache key: %w", err)
+		}
+
+		c.setCachedValue(reverseKey, cachedPrice{
+			price:      osmomath.OneBigDec().Quo(price.price),
+			computedAt: price.computedAt,
+		}, cache.NoExpirationTTL)
+		warmedDenoms[baseDenom] = struct{}{}
+	}
+
+	c.warmedReverseDenomsMu.Lock()
+	previouslyWarmedDenoms := c.warmedReverseDenoms
+	c.warmedReverseDenoms = warmedDenoms
+	c.warmedReverseDenomsMu.Unlock()
+
+	pricingCache := c.getCache()
+	for baseDenom := range previouslyWarmedDenoms {
+		if _, ok := warmedDenoms[baseDenom]; ok {
+			continue
+		}
+
+		// The key was formatted when warming so it does not fail.
+		if reverseKey, err := formatCacheKey(c.defaultQuoteDenom, baseDenom); err == nil {
+			pricingCache.Delete(reverseKey)
+		}
+	}
+
+	return nil
+}
+
+// RefreshDefaultQuotePrices implements domain.PricingSourceAdmin.
+// All of the base denoms are attempted even if some fail to refresh. Returns the first error, if any.
+func (c *chainPricing) RefreshDefaultQuotePrices(ctx context.Context, baseDenoms []string, opts ...domain.PricingOption) error {
+	// Never refresh from the pools that are stale due to the ingest lag.
+	if err := c.validatePoolDataFreshness(ctx); err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, baseDenom := range baseDenoms {
+		// Abort the remaining refreshes once the context is done, for example, on shutdown.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := c.refreshDefaultQuotePrice(ctx, baseDenom, opts); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to refresh default quote price of (%s): %w", baseDenom, err)
+		}
+	}
+
+	return firstErr
+}
+
+// refreshDefaultQuotePrice recomputes the default quote price of the given base denom along its stored route.
+// Falls back to recomputing the price from scratch if the price has no stored route, if the stored route
+// passes through a suspect pool, if the pools of the stored route no longer connect the denoms
+// or if the spot prices along it fail to compute. The given options apply to the recompute from scratch.
+func (c *chainPricing) refreshDefaultQuotePrice(ctx context.Context, baseDenom string, opts []domain.PricingOption) error {
+	if baseDenom == c.defaultQuoteDenom {
+		return nil
+	}
+
+	cacheKey, err := formatCacheKey(baseDenom, c.defaultQuoteDenom)
+	if err != nil {
+		return err
+	}
+
+	if cachedValue, ok := c.getCache().Get(cacheKey); ok {
+		if cachedPrice, ok := cachedValue.(cachedPrice); ok && len(cachedPrice.routePoolIDs) > 0 && !c.suspectPools.containsAny(cachedPrice.routePoolIDs, time.Now()) {
+			if err := c.recomputeAlongRoute(ctx, cacheKey, baseDenom, cachedPrice.routePoolIDs); err == nil {
+				return nil
+			}
+		}
+	}
+
+	_, err = c.GetPrice(ctx, baseDenom, c.defaultQuoteDenom, append([]domain.PricingOption{domain.WithRecomputePrices()}, opts...)...)
+	return err
+}
+
+// recomputeAlongRoute recomputes the default quote price of the given base denom from the spot prices
+// along the given route and caches it indefinitely with the route.
+// Returns error if the route pools no longer connect the denoms or if the price fails to compute.
+func (c *chainPricing) recomputeAlongRoute(ctx context.Context, cacheKey string, baseDenom string, routePoolIDs []uint64) error {
+	spotPriceRequests, err := buildPinnedRouteSpotPriceRequests(c.RUsecase.GetSortedPools(), routePoolIDs, baseDenom, c.defaultQuoteDenom)
+	if err != nil {
+		return err
+	}
+
+	_, precisionScalingFactor, err := c.getQuoteCoinAndPrecisionScalingFactor(baseDenom, c.defaultQuoteDenom)
+	if err != nil {
+		return err
+	}
+
+	chainPrice, err := c.computeSpotPriceProduct(ctx, spotPriceRequests, 0)
+	if err != nil {
+		return err
+	}
+
+	currentPrice := chainPrice.MulMut(precisionScalingFactor)
+	if err := c.validatePriceRange(baseDenom, c.defaultQuoteDenom, currentPrice); err != nil {
+		return err
+	}
+
+	computedPrice := cachedPrice{price: currentPrice, computedAt: time.Now(), routePoolIDs: routePoolIDs}
+	c.setCachedValue(cacheKey, computedPrice, cache.NoExpirationTTL)
+	c.setLastKnownGoodPrice(cacheKey, computedPrice)
+
+	return nil
+}
+
+// totalSharesPool is a pool with fungible shares.
+type totalSharesPool interface {
+	GetTotalShares() osmomath.Int
+}
+
+// GetPoolSharePrice implements domain.PricingSource.
+func (c *chainPricing) GetPoolSharePrice(ctx context.Context, poolShareDenom string, quoteDenom string) (osmomath.BigDec, error) {
+	poolIDStr, ok := strings.CutPrefix(poolShareDenom, poolShareDenomPrefix)
+	if !ok {
+		return osmomath.BigDec{}, fmt.Errorf("denom (%s) is not a pool share denom", poolShareDenom)
+	}
+
+	poolID, err := strconv.ParseUint(poolIDStr, 10, 64)
+	if err != nil {
+		return osmomath.BigDec{}, fmt.Errorf("denom (%s) is not a pool share denom: %w", poolShareDenom, err)
+	}
+
+	var pool sqsdomain.PoolI
+	for _, sortedPool := range c.RUsecase.GetSortedPools() {
+		if sortedPool.GetId() == poolID {
+			pool = sortedPool
+			break
+		}
+	}
+	if pool == nil {
+		return osmomath.BigDec{}, domain.PoolNotFoundError{PoolID: poolID}
+	}
+
+	sharesPool, ok := pool.GetUnderlyingPool().(totalSharesPool)
+	if !ok {
+		return osmomath.BigDec{}, fmt.Errorf("pool (%d) of type (%s) has no fungible shares", poolID, pool.GetType())
+	}
+
+	totalShares := sharesPool.GetTotalShares()
+	if totalShares.IsNil() || !totalShares.IsPositive() {
+		return osmomath.BigDec{}, fmt.Errorf("pool (%d) has no shares", poolID)
+	}
+
+	// Sum the balances valued in the quote denom.
+	poolValue := osmomath.ZeroBigDec()
+	for _, balance := range pool.GetSQSPoolModel().Balances {
+		scalingFactor, err := c.TUsecase.GetChainScalingFactorByDenomMut(balance.Denom)
+		if err != nil {
+			return osmomath.BigDec{}, err
+		}
+
+		price, err := c.GetPrice(ctx, balance.Denom, quoteDenom)
+		if err != nil {
+			return osmomath.BigDec{}, err
+		}
+
+		balanceValue := osmomath.BigDecFromDec(balance.Amount.ToLegacyDec().QuoMut(scalingFactor)).MulMut(price)
+		poolValue = poolValue.AddMut(balanceValue)
+	}
+
+	// Descale the total shares to whole shares.
+	totalWholeShares := osmomath.NewBigDecFromBigIntWithPrec(totalShares.BigInt(), poolShareExponent)
+
+	return poolValue.QuoMut(totalWholeShares), nil
+}
+
+// DefaultQuoteDenom implements domain.PricingSourceAdmin.
+func (c *chainPricing) DefaultQuoteDenom() string {
+	return c.defaultQuoteDenom
+}
+
+// PricingDebugInfo implements domain.PricingSourceAdmin.
+func (c *chainPricing) PricingDebugInfo() domain.PricingDebugInfo {
+	return domain.PricingDebugInfo{
+		Config:            c.config,
+		DefaultQuoteDenom: c.defaultQuoteDenom,
+		CacheSize:         c.getCache().Len(),
+		CacheHits:         c.cacheHits.Load(),
+		CacheMisses:       c.cacheMisses.Load(),
+		InFlightComputes:  c.inFlightComputes.Load(),
+	}
+}
+
+// GetBasketPrice implements domain.PricingSource.
+func (c *chainPricing) GetBasketPrice(ctx context.Context, components []domain.BasketComponent, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	if err := validateBasketWeights(components); err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	basketPrice := osmomath.ZeroBigDec()
+	for _, component := range components {
+		price, err := c.GetPrice(ctx, component.Denom, quoteDenom, opts...)
+		if err != nil {
+			return osmomath.BigDec{}, fmt.Errorf("failed to price basket component (%s): %w", component.Denom, err)
+		}
+
+		// Non-mutative since the price might be shared with the cache.
+		basketPrice = basketPrice.AddMut(price.Mul(osmomath.BigDecFromDec(component.Weight)))
+	}
+
+	return basketPrice, nil
+}
+
+// DetectArbitrage implements domain.PricingSource.
+func (c *chainPricing) DetectArbitrage(ctx context.Context, denomA, denomB, denomC string) (osmomath.Dec, error) {
+	cycle := [][2]string{
+		{denomA, denomB},
+		{denomB, denomC},
+		{denomC, denomA},
+	}
+
+	cycleGain := osmomath.OneBigDec()
+	for _, pair := range cycle {
+		price, err := c.GetPrice(ctx, pair[0], pair[1])
+		if err != nil {
+			return osmomath.Dec{}, fmt.Errorf("failed to price (%s) in (%s): %w", pair[0], pair[1], err)
+		}
+
+		// Only the gain is mutated since the price might be shared with the cache.
+		cycleGain = cycleGain.MulMut(price)
+	}
+
+	return cycleGain.Dec(), nil
+}
+
+// parseCompositeQuote parses the composite quote components from the config.
+// Returns error if any of the components is not a valid chain denom, contains the pricing cache key separator
+// or has a malformed weight, or if the weights are not positive or do not sum to one.
+func parseCompositeQuote(compositeQuote []domain.WeightedDenom, tokenUseCase mvc.TokensUsecase) ([]domain.BasketComponent, error) {
+	components := make([]domain.BasketComponent, 0, len(compositeQuote))
+	for _, weightedDenom := range compositeQuote {
+		if !tokenUseCase.IsValidChainDenom(weightedDenom.Denom) {
+			return nil, fmt.Errorf("composite quote component (%s) is not a valid chain denom", weightedDenom.Denom)
+		}
+
+		if _, err := domain.FormatPricingCacheKey(weightedDenom.Denom, weightedDenom.Denom); err != nil {
+			return nil, err
+		}
+
+		weight, err := osmomath.NewDecFromStr(weightedDenom.Weight)
+		if err != nil {
+			return nil, fmt.Errorf("weight of composite quote component (%s): %w", weightedDenom.Denom, err)
+		}
+
+		components = append(components, domain.BasketComponent{Denom: weightedDenom.Denom, Weight: weight})
+	}
+
+	if err := validateBasketWeights(components); err != nil {
+		return nil, err
+	}
+
+	return components, nil
+}
+
+// formatCompositeQuoteDenom formats the synthetic denom identifying the given composite quote
+// as "composite:<denom>@<weight>,...". It never collides with the chain denoms since they cannot contain "@".
+func formatCompositeQuoteDenom(components []domain.BasketComponent) string {
+	formattedComponents := make([]string, 0, len(components))
+	for _, component := range components {
+		formattedComponents = append(formattedComponents, fmt.Sprintf("%s@%s", component.Denom, component.Weight))
+	}
+
+	return compositeQuoteDenomPrefix + strings.Join(formattedComponents, ",")
+}
+
+// validateBasketWeights returns InvalidBasketWeightsError if the basket is empty, any of the weights
+// is not positive or the weights do not sum to one within basketWeightSumTolerance.
+func validateBasketWeights(components []domain.BasketComponent) error {
+	weightSum := osmomath.ZeroDec()
+	hasNonPositiveWeight := false
+	for _, component := range components {
+		if component.Weight.IsNil() || !component.Weight.IsPositive() {
+			hasNonPositiveWeight = true
+			continue
+		}
+		weightSum = weightSum.Add(component.Weight)
+	}
+
+	if len(components) == 0 || hasNonPositiveWeight || weightSum.Sub(osmomath.OneDec()).Abs().GT(basketWeightSumTolerance) {
+		return domain.InvalidBasketWeightsError{WeightSum: weightSum}
+	}
+
+	return nil
+}
+
+// SetWorkerTrackedDenoms implements domain.PricingSourceAdmin.
+func (c *chainPricing) SetWorkerTrackedDenoms(denoms map[string]struct{}) {
+	c.workerTrackedDenomsMu.Lock()
+	defer c.workerTrackedDenomsMu.Unlock()
+
+	c.workerTrackedDenoms = denoms
+}
+
+// SetBlockHeightProvider implements domain.PricingSourceAdmin.
+func (c *chainPricing) SetBlockHeightProvider(provider domain.BlockHeightProvider) {
+	c.blockHeightProviderMu.Lock()
+	defer c.blockHeightProviderMu.Unlock()
+
+	c.blockHeightProvider = provider
+}
+
+// SetTWAPProvider implements domain.PricingSourceAdmin.
+func (c *chainPricing) SetTWAPProvider(provider domain.TWAPProvider) {
+	c.twapProviderMu.Lock()
+	defer c.twapProviderMu.Unlock()
+
+	c.twapProvider = provider
+}
+
+// MarkPoolSuspect implements domain.PricingSourceAdmin.
+// The cached prices whose stored routes pass through the pool are deleted so that they are recomputed.
+func (c *chainPricing) MarkPoolSuspect(poolID uint64, until time.Time) {
+	c.suspectPools.mark(poolID, until)
+
+	if !time.Now().Before(until) {
+		return
+	}
+
+	pricingCache := c.getCache()
+
+	var invalidatedKeys []string
+	pricingCache.Range(func(key string, value interface{}, expiry time.Time) bool {
+		if cachedPrice, ok := value.(cachedPrice); ok && slices.Contains(cachedPrice.routePoolIDs, poolID) {
+			invalidatedKeys = append(invalidatedKeys, key)
+		}
+		return true
+	})
+
+	// Deleted outside of the range since it holds the cache lock.
+	for _, key := range invalidatedKeys {
+		pricingCache.Delete(key)
+	}
+}
+
+// ClearPoolSuspect implements domain.PricingSourceAdmin.
+func (c *chainPricing) ClearPoolSuspect(poolID uint64) {
+	c.suspectPools.clear(poolID)
+}
+
+// SuspectPools implements domain.PricingSourceAdmin.
+func (c *chainPricing) SuspectPools() map[uint64]time.Time {
+	return c.suspectPools.snapshot(time.Now())
+}
+
+// validatePoolDataFreshness returns StalePoolDataError if the pool data height lags the chain height
+// by more than the configured max staleness. Updates the pool data lag gauge.
+// No-op if the check is disabled or the block height provider is not set.
+// Passes if the chain height cannot be provided, counting the skipped check.
+func (c *chainPricing) validatePoolDataFreshness(ctx context.Context) error {
+	if c.maxPoolDataStalenessBlocks == 0 {
+		return nil
+	}
+
+	c.blockHeightProviderMu.RLock()
+	provider := c.blockHeightProvider
+	c.blockHeightProviderMu.RUnlock()
+
+	if provider == nil {
+		return nil
+	}
+
+	// Fail open since the provider errors are not specific to the priced denoms
+	// and the node being unavailable must not take down the pricing.
+	chainHeight, err := provider.GetChainHeight(ctx)
+	if err != nil {
+		poolDataFreshnessCheckErrorsCounter.Inc()
+		return nil
+	}
+
+	// The pool data might be ahead of the chain height reported by a lagging node.
+	poolDataHeight := provider.GetPoolDataHeight()
+	lag := uint64(0)
+	if chainHeight > poolDataHeight {
+		lag = chainHeight - poolDataHeight
+	}
+
+	poolDataLagGauge.Set(float64(lag))
+
+	if lag > c.maxPoolDataStalenessBlocks {
+		return domain.StalePoolDataError{
+			PoolDataHeight:     poolDataHeight,
+			ChainHeight:        chainHeight,
+			MaxStalenessBlocks: c.maxPoolDataStalenessBlocks,
+		}
+	}
+
+	return nil
+}
+
+// isWorkerTrackedDenom returns true if the given base denom is tracked by the background pricing worker.
+func (c *chainPricing) isWorkerTrackedDenom(baseDenom string) bool {
+	c.workerTrackedDenomsMu.RLock()
+	defer c.workerTrackedDenomsMu.RUnlock()
+
+	_, ok := c.workerTrackedDenoms[baseDenom]
+	return ok
+}
+
+// InitializeCache implements domain.PricingSource.
+// The cache is swapped atomically so that it is safe to call concurrently with serving the prices.
+// The gauge of the cached entries is reset to the entries of the given cache and no longer tracks the previous cache.
+func (c *chainPricing) InitializeCache(cache *cache.Cache) {
+	trackCachedEntries(cache)
+	if previousCache := c.cache.Swap(cache); previousCache != nil && previousCache != cache {
+		previousCache.OnEvict(nil)
+	}
+	cachedEntriesGauge.Set(float64(cache.Len()))
+}
+
+// getCache returns the current pricing cache.
+func (c *chainPricing) getCache() *cache.Cache {
+	return c.cache.Load()
+}
+
+// trackCachedEntries registers the eviction callback of the given pricing cache
+// that decrements the gauge of the cached entries, including on Delete. The gauge is incremented by setCachedValue.
+func trackCachedEntries(pricingCache *cache.Cache) {
+	pricingCache.OnEvict(func(key string, value interface{}) {
+		cachedEntriesGauge.Dec()
+	})
+}
+
+// setCachedValue sets the given value in the pricing cache and increments the gauge of the cached entries.
+func (c *chainPricing) setCachedValue(cacheKey string, value interface{}, expiration time.Duration) {
+	c.getCache().Set(cacheKey, value, expiration)
+	cachedEntriesGauge.Inc()
+}
+
+// formatCacheKey formats the pricing cache key for the given base and quote denoms
+// in the namespace of the chain pricing source.
+func formatCacheKey(baseDenom string, quoteDenom string) (string, error) {
+	return domain.FormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, baseDenom, quoteDenom)
+}
+
+// parseCacheKey parses the base and quote denoms from the pricing cache key formatted by formatCacheKey.
+// Returns error for the keys of the other pricing sources sharing the cache.
+func parseCacheKey(key string) (string, string, error) {
+	return domain.ParsePricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, key)
+}
+
+// formatPinnedRouteKey formats the pinned route key for the given base and quote denoms.
+// The keys are case-insensitive since the config loader lower cases the map keys.
+// Returns error if either of the denoms contains the key separator.
+func formatPinnedRouteKey(baseDenom string, quoteDenom string) (string, error) {
+	key, err := domain.FormatPricingCacheKey(baseDenom, quoteDenom)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(key), nil
+}
+
+// parsePinnedRoutes parses the configured pinned routes into a map keyed by formatPinnedRouteKey.
+// Returns error if any of the keys is malformed or any of the pool ID lists is empty.
+func parsePinnedRoutes(pinnedRoutesConfig map[string][]uint64) (map[string][]uint64, error) {
+	pinnedRoutes := make(map[string][]uint64, len(pinnedRoutesConfig))
+	for key, poolIDs := range pinnedRoutesConfig {
+		baseDenom, quoteDenom, err := domain.ParsePricingCacheKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(poolIDs) == 0 {
+			return nil, fmt.Errorf("pinned route (%s) has no pools", key)
+		}
+
+		pinnedRouteKey, err := formatPinnedRouteKey(baseDenom, quoteDenom)
+		if err != nil {
+			return nil, err
+		}
+
+		pinnedRoutes[pinnedRouteKey] = poolIDs
+	}
+
+	return pinnedRoutes, nil
+}
+
+// validatePinnedRoutes validates that the pinned pools connect the quote denom to the base denom
+// for each of the pinned routes parsed by parsePinnedRoutes.
+// Returns error if any of the pinned routes does not connect.
+func validatePinnedRoutes(pinnedRoutes map[string][]uint64, pools []sqsdomain.PoolI) error {
+	for pinnedRouteKey, poolIDs := range pinnedRoutes {
+		if err := validatePinnedRoute(pinnedRouteKey, poolIDs, pools); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validatePinnedRoute validates that the pinned pools connect the quote denom to the base denom
+// of the pinned route with the given key formatted by formatPinnedRouteKey.
+func validatePinnedRoute(pinnedRouteKey string, poolIDs []uint64, pools []sqsdomain.PoolI) error {
+	baseDenom, quoteDenom, err := domain.ParsePricingCacheKey(pinnedRouteKey)
+	if err != nil {
+		return err
+	}
+
+	if _, err := buildPinnedRouteSpotPriceRequests(pools, poolIDs, baseDenom, quoteDenom); err != nil {
+		return fmt.Errorf("invalid pinned route (%s): %w", pinnedRouteKey, err)
+	}
+
+	return nil
+}
+
+// buildPinnedRouteSpotPriceRequests builds the spot price requests for the pinned pools
+// swapping from the quote denom to the base denom.
+// Each pool must contain the denom swapped in. The denom swapped out is the base denom for the last pool.
+// For the other pools, it is the only other denom of the pool that is also in the next pool.
+// The denoms are compared case-insensitively since the pinned route keys are case-insensitive.
+// Returns error if any of the pools is not found or if the pools do not connect unambiguously.
+func buildPinnedRouteSpotPriceRequests(pools []sqsdomain.PoolI, pinnedPoolIDs []uint64, baseDenom string, quoteDenom string) ([]domain.SpotPriceRequest, error) {
+	poolsByID := make(map[uint64]sqsdomain.PoolI, len(pools))
+	for _, pool := range pools {
+		poolsByID[pool.GetId()] = pool
+	}
+
+	getPoolDenoms := func(poolID uint64) ([]string, error) {
+		pool, ok := poolsByID[poolID]
+		if !ok {
+			return nil, domain.PoolNotFoundError{PoolID: poolID}
+		}
+		return pool.GetPoolDenoms(), nil
+	}
+
+	containsDenom := func(denoms []string, denom string) bool {
+		for _, d := range denoms {
+			if strings.EqualFold(d, denom) {
+				return true
+			}
+		}
+		return false
+	}
+
+	spotPriceRequests := make([]domain.SpotPriceRequest, 0, len(pinnedPoolIDs))
+
+	tokenInDenom := quoteDenom
+	for i, poolID := range pinnedPoolIDs {
+		poolDenoms, err := getPoolDenoms(poolID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !containsDenom(poolDenoms, tokenInDenom) {
+			return nil, fmt.Errorf("pinned pool (%d) does not contain denom (%s)", poolID, tokenInDenom)
+		}
+
+		var tokenOutDenom string
+		if i == len(pinnedPoolIDs)-1 {
+			if !containsDenom(poolDenoms, baseDenom) {
+				return nil, fmt.Errorf("last pinned pool (%d) does not contain base denom (%s)", poolID, baseDenom)
+			}
+			tokenOutDenom = baseDenom
+		} else {
+			nextPoolDenoms, err := getPoolDenoms(pinnedPoolIDs[i+1])
+			if err != nil {
+				return nil, err
+			}
+
+			numCandidates := 0
+			for _, denom := range poolDenoms {
+				if strings.EqualFold(denom, tokenInDenom) {
+					continue
+				}
+				if containsDenom(nextPoolDenoms, denom) {
+					tokenOutDenom = denom
+					numCandidates++
+				}
+			}
+
+			if numCandidates != 1 {
+				return nil, fmt.Errorf("pinned pools (%d) and (%d) do not connect unambiguously, found (%d) shared denoms", poolID, pinnedPoolIDs[i+1], numCandidates)
+			}
+		}
+
+		spotPriceRequests = append(spotPriceRequests, domain.SpotPriceRequest{
+			PoolID:     poolID,
+			QuoteDenom: tokenInDenom,
+			BaseDenom:  tokenOutDenom,
+		})
+
+		tokenInDenom = tokenOutDenom
+	}
+
+	return spotPriceRequests, nil
+}
+
+// parsePriceBound parses the price bound from the given decimal string.
+// Returns nil BigDec if the string is empty.
+// Returns error if the string is malformed or negative.
+func parsePriceBound(priceBoundStr string) (osmomath.BigDec, error) {
+	if priceBoundStr == "" {
+		return osmomath.BigDec{}, nil
+	}
+
+	priceBound, err := osmomath.NewBigDecFromStr(priceBoundStr)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+
+	if priceBound.IsNegative() {
+		return osmomath.BigDec{}, fmt.Errorf("price bound (%s) is negative", priceBoundStr)
+	}
+
+	return priceBound, nil
+}
+
+// parseDenomCategories returns the given denom categories keyed by the lower cased denoms.
+// The denoms with an empty category are omitted so that they fall into the default category.
+func parseDenomCategories(denomCategories map[string]string) map[string]string {
+	parsedDenomCategories := make(map[string]string, len(denomCategories))
+	for denom, category := range denomCategories {
+		if category == "" {
+			continue
+		}
+
+		parsedDenomCategories[strings.ToLower(denom)] = category
+	}
+
+	return parsedDenomCategories
+}
+
+// getDenomCategory returns the configured category of the given denom or the default category if uncategorized.
+func (c *chainPricing) getDenomCategory(denom string) string {
+	if category, ok := c.denomCategories[strings.ToLower(denom)]; ok {
+		return category
+	}
+	return denomCategoryOther
+}
+
+// parsePriceBounds parses the per base denom price bounds keyed by the lower cased base denoms.
+// Returns error if any of the bounds is malformed or negative, or if the min price exceeds the max price.
+func parsePriceBounds(priceBounds map[string]domain.PriceBound) (map[string]priceBound, error) {
+	parsedPriceBounds := make(map[string]priceBound, len(priceBounds))
+	for baseDenom, bound := range priceBounds {
+		minPrice, err := parsePriceBound(bound.Min)
+		if err != nil {
+			return nil, fmt.Errorf("min price of (%s): %w", baseDenom, err)
+		}
+
+		maxPrice, err := parsePriceBound(bound.Max)
+		if err != nil {
+			return nil, fmt.Errorf("max price of (%s): %w", baseDenom, err)
+		}
+
+		if !minPrice.IsNil() && !maxPrice.IsNil() && minPrice.GT(maxPrice) {
+			return nil, fmt.Errorf("min price (%s) of (%s) exceeds max price (%s)", minPrice, baseDenom, maxPrice)
+		}
+
+		parsedPriceBounds[strings.ToLower(baseDenom)] = priceBound{min: minPrice, max: maxPrice}
+	}
+
+	return parsedPriceBounds, nil
+}
+
+// startComputePriceSpan starts the span of the price computation for the given base and quote denoms
+// with the given caller-provided attributes.
+func startComputePriceSpan(ctx context.Context, baseDenom string, quoteDenom string, traceAttributes map[string]string) (context.Context, trace.Span) {
+	attributes := make([]attribute.KeyValue, 0, len(traceAttributes)+2)
+	attributes = append(attributes,
+		attribute.String("pricing.base", baseDenom),
+		attribute.String("pricing.quote", quoteDenom),
+	)
+	for key, value := range traceAttributes {
+		attributes = append(attributes, attribute.String(key, value))
+	}
+
+	return tracer.Start(ctx, "chainPricing.computePrice", trace.WithAttributes(attributes...))
+}
+
+// annotateSpanWithRoutes sets the pool IDs of the given routes on the span from the context.
+func annotateSpanWithRoutes(ctx context.Context, routes []domain.SplitRoute) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	formattedRoutes := make([]string, 0, len(routes))
+	for _, route := range routes {
+		pools := route.GetPools()
+		poolIDs := make([]uint64, 0, len(pools))
+		for _, pool := range pools {
+			poolIDs = append(poolIDs, pool.GetId())
+		}
+		formattedRoutes = append(formattedRoutes, formatRoutePoolIDs(poolIDs))
+	}
+
+	span.SetAttributes(attribute.StringSlice("pricing.routes", formattedRoutes))
+}
+
+// formatRoutePoolIDs formats the pool IDs of a route as a dash-separated string (e.g. "1-2-3").
+func formatRoutePoolIDs(poolIDs []uint64) string {
+	formattedPoolIDs := make([]string, 0, len(poolIDs))
+	for _, poolID := range poolIDs {
+		formattedPoolIDs = append(formattedPoolIDs, strconv.FormatUint(poolID, 10))
+	}
+	return strings.Join(formattedPoolIDs, "-")
 }
@@ -2,13 +2,38 @@ package chainpricing_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/osmosis/v24/x/gamm/pool-models/balancer"
+	poolmanagertypes "github.com/osmosis-labs/osmosis/v24/x/poolmanager/types"
+	"github.com/osmosis-labs/sqs/domain"
+	"github.com/osmosis-labs/sqs/domain/cache"
+	"github.com/osmosis-labs/sqs/domain/mocks"
+	"github.com/osmosis-labs/sqs/domain/mvc"
+	"github.com/osmosis-labs/sqs/log"
+	"github.com/osmosis-labs/sqs/router/usecase"
+	"github.com/osmosis-labs/sqs/router/usecase/route"
 	"github.com/osmosis-labs/sqs/router/usecase/routertesting"
+	"github.com/osmosis-labs/sqs/sqsdomain"
 	"github.com/osmosis-labs/sqs/tokens/usecase/pricing"
+	chainpricing "github.com/osmosis-labs/sqs/tokens/usecase/pricing/chain"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestPricingTestSuite(t *testing.T) {
@@ -45,7 +70,7 @@ func (s *PricingTestSuite) TestGetPrices_Chain() {
 	mainnetUsecase := s.SetupRouterAndPoolsUsecase(mainnetState, routertesting.WithRouterConfig(defaultPricingRouterConfig), routertesting.WithPricingConfig(defaultPricingConfig))
 
 	// Set up on-chain pricing strategy
-	pricingStrategy, err := pricing.NewPricingStrategy(defaultPricingConfig, mainnetUsecase.Tokens, mainnetUsecase.Router)
+	pricingStrategy, _, err := pricing.NewPricingStrategy(context.Background(), defaultPricingConfig, mainnetUsecase.Tokens, mainnetUsecase.Router, &log.NoOpLogger{})
 	s.Require().NoError(err)
 
 	s.Require().NotZero(len(routertesting.MainnetDenoms))
@@ -70,3 +95,3587 @@ func (s *PricingTestSuite) TestGetPrices_Chain() {
 		})
 	}
 }
+
+// Validates that with volume-weighted pricing, the price is the average of the route prices
+// weighted by the amount in of each route. Without it, only the top route is used.
+func (s *PricingTestSuite) TestGetPrice_VolumeWeightedPricing() {
+	const (
+		firstRoutePoolID  = uint64(1)
+		secondRoutePoolID = uint64(2)
+	)
+
+	var (
+		// 10 USDC (10 * 10^6) is used as the token in for pricing.
+		// It is split 60% / 40% between the two routes.
+		firstRouteAmountIn  = osmomath.NewInt(6_000_000)
+		secondRouteAmountIn = osmomath.NewInt(4_000_000)
+
+		poolSpotPrices = map[uint64]osmomath.BigDec{
+			firstRoutePoolID:  osmomath.NewBigDec(2),
+			secondRoutePoolID: osmomath.NewBigDec(3),
+		}
+
+		newSplitRoute = func(poolID uint64, amountIn osmomath.Int) domain.SplitRoute {
+			return &usecase.RouteWithOutAmount{
+				RouteImpl: route.RouteImpl{
+					Pools: []sqsdomain.RoutablePool{
+						mocks.WithTokenOutDenom(mocks.WithPoolID(routertesting.DefaultPool, poolID), ATOM),
+					},
+				},
+				InAmount:  amountIn,
+				OutAmount: amountIn,
+			}
+		}
+	)
+
+	routerUsecase := &mocks.RouterUsecaseMock{
+		GetOptimalQuoteFunc: func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			return &mocks.MockQuote{
+				AmountIn:  tokenIn,
+				AmountOut: tokenIn.Amount,
+				Route: []domain.SplitRoute{
+					newSplitRoute(firstRoutePoolID, firstRouteAmountIn),
+					newSplitRoute(secondRoutePoolID, secondRouteAmountIn),
+				},
+			}, nil
+		},
+		GetPoolSpotPriceFunc: func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+			return poolSpotPrices[poolID], nil
+		},
+	}
+
+	tokensUsecase := &mocks.TokensUsecaseMock{
+		ChainDenoms: map[string]string{
+			defaultPricingConfig.DefaultQuoteHumanDenom: USDC,
+		},
+		ScalingFactors: map[string]osmomath.Dec{
+			USDC: osmomath.NewDec(1_000_000),
+			ATOM: osmomath.NewDec(1_000_000),
+		},
+	}
+
+	pricingSource, err := chainpricing.New(context.Background(), routerUsecase, tokensUsecase, defaultPricingConfig, &log.NoOpLogger{})
+	s.Require().NoError(err)
+
+	// (2 * 6 + 3 * 4) / 10 = 2.4
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithVolumeWeightedPricing())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("2.4").String(), price.String())
+
+	// Only the top route is used without volume-weighted pricing.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(2).String(), price.String())
+}
+
+// Validates that the route spot prices of the split pricing, computed concurrently with and
+// without a bound on the concurrent route computes, are aggregated deterministically.
+// Meant to be run with the race detector (go test -race).
+func (s *PricingTestSuite) TestGetPrice_VolumeWeightedPricing_ConcurrentRoutes() {
+	const (
+		numRoutes     = 5
+		numIterations = 20
+	)
+
+	newSplitRoute := func(poolID uint64, amountIn osmomath.Int) domain.SplitRoute {
+		return &usecase.RouteWithOutAmount{
+			RouteImpl: route.RouteImpl{
+				Pools: []sqsdomain.RoutablePool{
+					mocks.WithTokenOutDenom(mocks.WithPoolID(routertesting.DefaultPool, poolID), ATOM),
+				},
+			},
+			InAmount:  amountIn,
+			OutAmount: amountIn,
+		}
+	}
+
+	// Route i has the spot price of i and the amount in of 2 * 10^6 so that the expected price
+	// is the average of 1, ..., 5.
+	routes := make([]domain.SplitRoute, 0, numRoutes)
+	for poolID := uint64(1); poolID <= numRoutes; poolID++ {
+		routes = append(routes, newSplitRoute(poolID, osmomath.NewInt(2_000_000)))
+	}
+	expectedPrice := osmomath.NewBigDec(3)
+
+	var spotPriceCallCount atomic.Int64
+	routerUsecase := &mocks.RouterUsecaseMock{
+		GetOptimalQuoteFunc: func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			return &mocks.MockQuote{
+				AmountIn:  tokenIn,
+				AmountOut: tokenIn.Amount,
+				Route:     routes,
+			}, nil
+		},
+		GetPoolSpotPriceFunc: func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+			spotPriceCallCount.Add(1)
+			return osmomath.NewBigDec(int64(poolID)), nil
+		},
+	}
+
+	for _, maxConcurrentRouteComputes := range []int{0, 2} {
+		pricingConfig := defaultPricingConfig
+		pricingConfig.MaxConcurrentRouteComputes = maxConcurrentRouteComputes
+		pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+		spotPriceCallCount.Store(0)
+		for i := 0; i < numIterations; i++ {
+			price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithVolumeWeightedPricing())
+			s.Require().NoError(err)
+			s.Require().Equal(expectedPrice.String(), price.String())
+		}
+
+		s.Require().Equal(int64(numRoutes*numIterations), spotPriceCallCount.Load())
+	}
+}
+
+// Validates that the suspect pools are excluded from the pricing routes until they are cleared
+// or their expiry passes and that the suspect set is exposed for introspection.
+func (s *PricingTestSuite) TestMarkPoolSuspect() {
+	const (
+		manipulatedPoolID = uint64(1)
+		fallbackPoolID    = uint64(2)
+	)
+
+	poolSpotPrices := map[uint64]osmomath.BigDec{
+		manipulatedPoolID: osmomath.NewBigDec(5),
+		fallbackPoolID:    osmomath.NewBigDec(2),
+	}
+
+	routerUsecase := &mocks.RouterUsecaseMock{
+		// Routes through the manipulated pool unless it is excluded.
+		GetOptimalQuoteFunc: func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			var routerOptions domain.RouterOptions
+			for _, opt := range opts {
+				opt(&routerOptions)
+			}
+
+			poolID := manipulatedPoolID
+			if slices.Contains(routerOptions.ExcludedPoolIDs, manipulatedPoolID) {
+				poolID = fallbackPoolID
+			}
+
+			return &mocks.MockQuote{
+				AmountIn:  tokenIn,
+				AmountOut: tokenIn.Amount,
+				Route: []domain.SplitRoute{
+					&usecase.RouteWithOutAmount{
+						RouteImpl: route.RouteImpl{
+							Pools: []sqsdomain.RoutablePool{
+								mocks.WithTokenOutDenom(mocks.WithPoolID(routertesting.DefaultPool, poolID), tokenOutDenom),
+							},
+						},
+						InAmount:  tokenIn.Amount,
+						OutAmount: tokenIn.Amount,
+					},
+				},
+			}, nil
+		},
+		GetPoolSpotPriceFunc: func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+			return poolSpotPrices[poolID], nil
+		},
+	}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	getPrice := func() osmomath.BigDec {
+		price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+		s.Require().NoError(err)
+		return price
+	}
+
+	s.Require().Empty(pricingSource.SuspectPools())
+	s.Require().Equal(poolSpotPrices[manipulatedPoolID].String(), getPrice().String())
+
+	// The suspect pool is avoided until cleared.
+	until := time.Now().Add(time.Hour)
+	pricingSource.MarkPoolSuspect(manipulatedPoolID, until)
+	s.Require().Equal(map[uint64]time.Time{manipulatedPoolID: until}, pricingSource.SuspectPools())
+	s.Require().Equal(poolSpotPrices[fallbackPoolID].String(), getPrice().String())
+
+	pricingSource.ClearPoolSuspect(manipulatedPoolID)
+	s.Require().Empty(pricingSource.SuspectPools())
+	s.Require().Equal(poolSpotPrices[manipulatedPoolID].String(), getPrice().String())
+
+	// The expired suspect pool is no longer avoided.
+	pricingSource.MarkPoolSuspect(manipulatedPoolID, time.Now().Add(-time.Second))
+	s.Require().Empty(pricingSource.SuspectPools())
+	s.Require().Equal(poolSpotPrices[manipulatedPoolID].String(), getPrice().String())
+}
+
+// Validates that the suspect pools are only excluded from the routes passing through them
+// so that the other routes keep the router route caches.
+func (s *PricingTestSuite) TestMarkPoolSuspect_ExcludedOnlyIfRoutedThrough() {
+	const suspectPoolID = uint64(2)
+
+	var excludedPoolIDs [][]uint64
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	singleRouteQuoteFunc := routerUsecase.GetOptimalQuoteFunc
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		var routerOptions domain.RouterOptions
+		for _, opt := range opts {
+			opt(&routerOptions)
+		}
+		excludedPoolIDs = append(excludedPoolIDs, routerOptions.ExcludedPoolIDs)
+
+		return singleRouteQuoteFunc(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+	pricingSource.MarkPoolSuspect(suspectPoolID, time.Now().Add(time.Hour))
+
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal([][]uint64{nil}, excludedPoolIDs)
+}
+
+// Validates that marking a pool suspect invalidates the cached prices whose stored routes pass through it.
+func (s *PricingTestSuite) TestMarkPoolSuspect_InvalidatesCachedRoutePrices() {
+	const routePoolID = uint64(1)
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+	pricingSource.SetWorkerTrackedDenoms(map[string]struct{}{ATOM: {}})
+
+	pricingCache := cache.New()
+	pricingSource.InitializeCache(pricingCache)
+
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(1, pricingCache.Len())
+
+	// The unrelated pools leave the cached price intact.
+	pricingSource.MarkPoolSuspect(routePoolID+1, time.Now().Add(time.Hour))
+	s.Require().Equal(1, pricingCache.Len())
+
+	pricingSource.MarkPoolSuspect(routePoolID, time.Now().Add(time.Hour))
+	s.Require().Zero(pricingCache.Len())
+}
+
+// Validates that with median pricing, the price is the liquidity-weighted median of the ranked route prices
+// so that a manipulated low liquidity top route does not move the price, that the routes through
+// the same pools are de-duplicated and that the median prices are not cached.
+func (s *PricingTestSuite) TestGetPrice_MedianPricing() {
+	const (
+		manipulatedPoolID = uint64(1)
+		firstPoolID       = uint64(2)
+		secondPoolID      = uint64(3)
+
+		numRankedRoutes = 3
+	)
+
+	var (
+		poolSpotPrices = map[uint64]osmomath.BigDec{
+			manipulatedPoolID: osmomath.NewBigDec(100),
+			firstPoolID:       osmomath.NewBigDec(2),
+			secondPoolID:      osmomath.NewBigDec(3),
+		}
+
+		// OSMO liquidity of each pool.
+		poolLiquidities = map[uint64]int64{
+			manipulatedPoolID: 10,
+			firstPoolID:       500,
+			secondPoolID:      600,
+		}
+
+		newQuote = func(tokenIn sdk.Coin, poolID uint64) domain.Quote {
+			pool := newRoutePool(poolID, ATOM)
+			pool.Balances = sdk.NewCoins(sdk.NewCoin(UOSMO, osmomath.NewInt(poolLiquidities[poolID]*1_000_000)))
+
+			return newRouteQuote(tokenIn, tokenIn.Amount, pool)
+		}
+	)
+
+	pricingSource := s.newPricingSourceWithRouter(newRoutesRouterUsecaseMock(
+		func(tokenIn sdk.Coin, tokenOutDenom string) domain.Quote {
+			return newQuote(tokenIn, manipulatedPoolID)
+		},
+		func(tokenIn sdk.Coin, tokenOutDenom string, k int) []domain.Quote {
+			s.Require().Equal(numRankedRoutes, k)
+
+			// The duplicate route would move the median to its price if it was not de-duplicated.
+			return []domain.Quote{
+				newQuote(tokenIn, manipulatedPoolID),
+				newQuote(tokenIn, firstPoolID),
+				newQuote(tokenIn, firstPoolID),
+				newQuote(tokenIn, secondPoolID),
+			}
+		},
+		poolSpotPrices,
+	), defaultPricingConfig)
+
+	// Sorted by price, the cumulative liquidity of 500 + 600 is the first to reach half of 1110.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithMedianPricing(numRankedRoutes))
+	s.Require().NoError(err)
+	s.Require().Equal(poolSpotPrices[secondPoolID].String(), price.String())
+
+	// The median price is not cached so the optimal route price is computed.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(poolSpotPrices[manipulatedPoolID].String(), price.String())
+}
+
+// Validates that the tied routes pricing returns the geometric mean of the spot prices of the ranked routes
+// whose amounts out are within the tolerance of the top route amount out and that it is not cached.
+func (s *PricingTestSuite) TestGetPrice_GeoMeanAcrossTiedRoutes() {
+	const (
+		topPoolID      = uint64(1)
+		tiedPoolID     = uint64(2)
+		untiedPoolID   = uint64(3)
+		optimalPoolID  = uint64(4)
+		failingPoolID  = uint64(5)
+		tieTolerance   = "0.01"
+		geoMeanEpsilon = "0.000000000001"
+	)
+
+	var (
+		poolSpotPrices = map[uint64]osmomath.BigDec{
+			topPoolID:     osmomath.NewBigDec(4),
+			tiedPoolID:    osmomath.NewBigDec(9),
+			untiedPoolID:  osmomath.NewBigDec(100),
+			optimalPoolID: osmomath.NewBigDec(7),
+		}
+
+		poolAmountsOut = map[uint64]int64{
+			topPoolID:     1_000_000,
+			failingPoolID: 999_500,
+			tiedPoolID:    999_000,
+			untiedPoolID:  900_000,
+			optimalPoolID: 1_000_000,
+		}
+
+		newQuote = func(tokenIn sdk.Coin, poolID uint64) domain.Quote {
+			return newRouteQuote(tokenIn, osmomath.NewInt(poolAmountsOut[poolID]), newRoutePool(poolID, ATOM))
+		}
+	)
+
+	// The spot price of the failing pool errors since it is missing from the spot prices.
+	pricingSource := s.newPricingSourceWithRouter(newRoutesRouterUsecaseMock(
+		func(tokenIn sdk.Coin, tokenOutDenom string) domain.Quote {
+			return newQuote(tokenIn, optimalPoolID)
+		},
+		func(tokenIn sdk.Coin, tokenOutDenom string, k int) []domain.Quote {
+			s.Require().Equal(defaultPricingConfig.MaxRoutes, k)
+
+			return []domain.Quote{
+				newQuote(tokenIn, topPoolID),
+				newQuote(tokenIn, failingPoolID),
+				newQuote(tokenIn, tiedPoolID),
+				newQuote(tokenIn, untiedPoolID),
+			}
+		},
+		poolSpotPrices,
+	), defaultPricingConfig)
+
+	// sqrt(4 * 9), skipping the tied route whose spot price fails and the untied route.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithGeoMeanAcrossTiedRoutes(osmomath.MustNewDecFromStr(tieTolerance)))
+	s.Require().NoError(err)
+	s.Require().True(price.Sub(osmomath.NewBigDec(6)).Abs().LT(osmomath.MustNewBigDecFromStr(geoMeanEpsilon)), "price: %s", price)
+
+	// Zero tolerance only ties the routes with exactly the top amount out.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithGeoMeanAcrossTiedRoutes(osmomath.ZeroDec()))
+	s.Require().NoError(err)
+	s.Require().Equal(poolSpotPrices[topPoolID].String(), price.String())
+
+	// The tied routes price is not cached so the optimal route price is computed.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(poolSpotPrices[optimalPoolID].String(), price.String())
+
+	// Negative tolerance and tolerance of at least one, which would tie all of the ranked routes,
+	// disable the tied routes pricing.
+	for _, tolerance := range []osmomath.Dec{osmomath.NewDec(-1), osmomath.OneDec(), osmomath.NewDec(2)} {
+		price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithGeoMeanAcrossTiedRoutes(tolerance))
+		s.Require().NoError(err)
+		s.Require().Equal(poolSpotPrices[optimalPoolID].String(), price.String(), "tolerance: %s", tolerance)
+	}
+}
+
+// Validates that with TWAP pricing, the pool TWAPs over the requested window are used instead of the pool spot prices,
+// that the pools without a TWAP fall back to their spot prices, that the mix is tracked and that the TWAP prices are not cached.
+func (s *PricingTestSuite) TestGetPrice_TWAPPricing() {
+	const (
+		twapPoolID = uint64(1)
+		spotPoolID = uint64(2)
+
+		twapWindow = 5 * time.Minute
+	)
+
+	var (
+		poolSpotPrices = map[uint64]osmomath.BigDec{
+			twapPoolID: osmomath.NewBigDec(2),
+			spotPoolID: osmomath.NewBigDec(3),
+		}
+
+		twapProvider = &mocks.TWAPProviderMock{
+			PoolTWAPs: map[uint64]osmomath.BigDec{
+				twapPoolID: osmomath.NewBigDec(5),
+			},
+			Window: twapWindow,
+		}
+	)
+
+	pricingSource := s.newPricingSourceWithRouter(newRoutesRouterUsecaseMock(
+		func(tokenIn sdk.Coin, tokenOutDenom string) domain.Quote {
+			return newRouteQuote(tokenIn, tokenIn.Amount, newRoutePool(twapPoolID, UOSMO), newRoutePool(spotPoolID, tokenOutDenom))
+		},
+		nil,
+		poolSpotPrices,
+	), defaultPricingConfig)
+
+	// Without the provider, the pool spot prices are used.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithTWAPPricing(twapWindow))
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(6).String(), price.String())
+
+	pricingSource.SetTWAPProvider(twapProvider)
+
+	twapCounter := chainpricing.PricesTWAPPoolPricesCounter.WithLabelValues("twap")
+	spotCounter := chainpricing.PricesTWAPPoolPricesCounter.WithLabelValues("spot")
+	twapCountBefore, spotCountBefore := testutil.ToFloat64(twapCounter), testutil.ToFloat64(spotCounter)
+
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithTWAPPricing(twapWindow))
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(15).String(), price.String())
+
+	s.Require().Equal(twapCountBefore+1, testutil.ToFloat64(twapCounter))
+	s.Require().Equal(spotCountBefore+1, testutil.ToFloat64(spotCounter))
+
+	// No TWAP is available over another window so all pools fall back to their spot prices.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithTWAPPricing(time.Minute))
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(6).String(), price.String())
+
+	// The TWAP price is not cached so the spot price is computed.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(6).String(), price.String())
+}
+
+// funcTWAPProvider is a TWAP provider delegating to the function.
+type funcTWAPProvider func(ctx context.Context, poolID uint64, quoteDenom string, baseDenom string, window time.Duration) (osmomath.BigDec, bool)
+
+// GetPoolTWAP implements domain.TWAPProvider.
+func (f funcTWAPProvider) GetPoolTWAP(ctx context.Context, poolID uint64, quoteDenom string, baseDenom string, window time.Duration) (osmomath.BigDec, bool) {
+	return f(ctx, poolID, quoteDenom, baseDenom, window)
+}
+
+// Validates that the cancellation of the request stops the per-route and the per-pool loops of the price computation
+// early with the context error rather than falling back to the alternative method.
+func (s *PricingTestSuite) TestGetPrice_ContextCanceledMidComputation() {
+	s.Run("median pricing routes", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var spotPriceCalls atomic.Int32
+
+		routerUsecase := newRoutesRouterUsecaseMock(nil, func(tokenIn sdk.Coin, tokenOutDenom string, k int) []domain.Quote {
+			quotes := make([]domain.Quote, 0, k)
+			for poolID := uint64(1); poolID <= uint64(k); poolID++ {
+				quotes = append(quotes, newRouteQuote(tokenIn, tokenIn.Amount, newRoutePool(poolID, tokenOutDenom)))
+			}
+			return quotes
+		}, nil)
+		routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+			spotPriceCalls.Add(1)
+			// The request is abandoned while the first route is priced.
+			// The computation is shared so it observes the cancellation once the request is abandoned.
+			cancel()
+			<-ctx.Done()
+			return osmomath.NewBigDec(2), nil
+		}
+
+		pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+		_, err := pricingSource.GetPrice(ctx, ATOM, USDC, domain.WithMedianPricing(3))
+		s.Require().ErrorIs(err, context.Canceled)
+		s.Require().Equal(int32(1), spotPriceCalls.Load())
+	})
+
+	s.Run("twap pricing pools", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var twapCalls, spotPriceCalls atomic.Int32
+
+		routerUsecase := newRoutesRouterUsecaseMock(func(tokenIn sdk.Coin, tokenOutDenom string) domain.Quote {
+			return newRouteQuote(tokenIn, tokenIn.Amount, newRoutePool(1, UOSMO), newRoutePool(2, stATOM), newRoutePool(3, tokenOutDenom))
+		}, nil, nil)
+		routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+			spotPriceCalls.Add(1)
+			return osmomath.NewBigDec(2), nil
+		}
+
+		pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+		pricingSource.SetTWAPProvider(funcTWAPProvider(func(ctx context.Context, poolID uint64, quoteDenom string, baseDenom string, window time.Duration) (osmomath.BigDec, bool) {
+			twapCalls.Add(1)
+			// The request is abandoned while the first pool is priced.
+			// The computation is shared so it observes the cancellation once the request is abandoned.
+			cancel()
+			<-ctx.Done()
+			return osmomath.BigDec{}, false
+		}))
+
+		_, err := pricingSource.GetPrice(ctx, ATOM, USDC, domain.WithTWAPPricing(time.Minute))
+		s.Require().ErrorIs(err, context.Canceled)
+		s.Require().Equal(int32(1), twapCalls.Load())
+		s.Require().Zero(spotPriceCalls.Load())
+	})
+}
+
+// Validates that GetPrice counts the requests by the category of the base denom
+// with the case-insensitive denoms and the uncategorized denoms falling into "other".
+func (s *PricingTestSuite) TestGetPrice_DenomCategories() {
+	pricingConfig := defaultPricingConfig
+	pricingConfig.DenomCategories = map[string]string{
+		strings.ToLower(ATOM): "major",
+	}
+
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(2), pricingConfig)
+
+	majorCounter := chainpricing.PricesRequestsCounter.WithLabelValues("major")
+	otherCounter := chainpricing.PricesRequestsCounter.WithLabelValues("other")
+	majorCountBefore, otherCountBefore := testutil.ToFloat64(majorCounter), testutil.ToFloat64(otherCounter)
+
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+
+	// The cache hits are requests too.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+
+	_, err = pricingSource.GetPrice(context.Background(), UOSMO, USDC)
+	s.Require().NoError(err)
+
+	s.Require().Equal(majorCountBefore+2, testutil.ToFloat64(majorCounter))
+	s.Require().Equal(otherCountBefore+1, testutil.ToFloat64(otherCounter))
+}
+
+// Validates that with a composite quote, the default quote prices are the averages of the prices
+// in the composite quote components weighted by their weights, that they are cached under the synthetic
+// composite quote denom and that the malformed composite quotes panic on startup.
+func (s *PricingTestSuite) TestGetPrice_CompositeQuote() {
+	quoteSpotPrices := map[string]osmomath.BigDec{
+		USDC:  osmomath.NewBigDec(2),
+		UOSMO: osmomath.NewBigDec(4),
+	}
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.BigDec{})
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		return quoteSpotPrices[quoteAsset], nil
+	}
+
+	pricingConfig := defaultPricingConfig
+	pricingConfig.CompositeQuote = []domain.WeightedDenom{
+		{Denom: USDC, Weight: "0.25"},
+		{Denom: UOSMO, Weight: "0.75"},
+	}
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	compositeQuoteDenom := pricingSource.DefaultQuoteDenom()
+	s.Require().True(strings.HasPrefix(compositeQuoteDenom, "composite:"))
+
+	// 0.25 * 2 + 0.75 * 4
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, "")
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("3.5").String(), price.String())
+
+	// The USD price is quoted in the USD-pegged default quote human denom rather than in the composite quote.
+	price, err = pricingSource.GetUSDPrice(context.Background(), ATOM)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(2).String(), price.String())
+
+	// The component prices are cached under their own keys.
+	quoteSpotPrices[USDC] = osmomath.NewBigDec(10)
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(2).String(), price.String())
+
+	// The composite price is cached under the composite quote denom.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, compositeQuoteDenom)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("3.5").String(), price.String())
+
+	// 0.25 * 10 + 0.75 * 4
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, "", domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("5.5").String(), price.String())
+
+	// The chain prices in the components are not comparable.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, "", domain.WithRawChainPrice())
+	s.Require().Error(err)
+
+	// Weights not summing to one fail on startup.
+	pricingConfig.CompositeQuote = []domain.WeightedDenom{
+		{Denom: USDC, Weight: "0.5"},
+		{Denom: UOSMO, Weight: "0.4"},
+	}
+	_, err = s.newPricingSourceWithRouterOrError(context.Background(), routerUsecase, pricingConfig)
+	s.Require().Error(err)
+
+	// Unknown component denom fails on startup.
+	pricingConfig.CompositeQuote = []domain.WeightedDenom{
+		{Denom: "unknown", Weight: "1"},
+	}
+	_, err = s.newPricingSourceWithRouterOrError(context.Background(), routerUsecase, pricingConfig)
+	s.Require().Error(err)
+}
+
+// Validates that GetPriceAndRoute returns the price alongside the exact route it was computed along
+// without a second route search.
+func (s *PricingTestSuite) TestGetPriceAndRoute() {
+	// Each route search returns a route through a different pool with a different spot price
+	// so that a recomputed route would not match the price.
+	var (
+		optimalQuoteCalls = 0
+		poolSpotPrices    = map[uint64]osmomath.BigDec{
+			1: osmomath.NewBigDec(3),
+			2: osmomath.NewBigDec(4),
+		}
+	)
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.BigDec{})
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		optimalQuoteCalls++
+
+		return &mocks.MockQuote{
+			AmountIn:  tokenIn,
+			AmountOut: tokenIn.Amount,
+			Route: []domain.SplitRoute{
+				&usecase.RouteWithOutAmount{
+					RouteImpl: route.RouteImpl{
+						Pools: []sqsdomain.RoutablePool{
+							// The chain pool model is only used by the result preparation.
+							mocks.WithTokenOutDenom(mocks.WithChainPoolModel(mocks.WithPoolID(routertesting.DefaultPool, uint64(optimalQuoteCalls)), &balancer.Pool{}), tokenOutDenom),
+						},
+					},
+					InAmount:  tokenIn.Amount,
+					OutAmount: tokenIn.Amount,
+				},
+			},
+		}, nil
+	}
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		return poolSpotPrices[poolID], nil
+	}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	price, priceRoute, err := pricingSource.GetPriceAndRoute(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(1, optimalQuoteCalls)
+	s.Require().Equal(poolSpotPrices[1].String(), price.String())
+
+	routePools := priceRoute.GetPools()
+	s.Require().Len(routePools, 1)
+	s.Require().Equal(uint64(1), routePools[0].GetId())
+	s.Require().Equal(ATOM, routePools[0].GetTokenOutDenom())
+
+	// Equal denoms are priced at one without a route.
+	price, priceRoute, err = pricingSource.GetPriceAndRoute(context.Background(), ATOM, ATOM)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.OneBigDec().String(), price.String())
+	s.Require().Nil(priceRoute)
+
+	// The abandoned computation errors rather than falls back to the alternative method.
+	ctx, cancel := context.WithCancel(context.Background())
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		cancel()
+		return osmomath.BigDec{}, ctx.Err()
+	}
+
+	_, priceRoute, err = pricingSource.GetPriceAndRoute(ctx, ATOM, USDC)
+	s.Require().ErrorIs(err, context.Canceled)
+	s.Require().Nil(priceRoute)
+}
+
+// Validates that GetPriceAndRoute honours the pinned routes like GetPrice
+// and returns no route since the pinned pools bypass the route selection.
+func (s *PricingTestSuite) TestGetPriceAndRoute_PinnedRoute() {
+	routerUsecase := &mocks.RouterUsecaseMock{
+		SortedPools: []sqsdomain.PoolI{
+			&mocks.MockRoutablePool{ID: 1, Denoms: []string{USDC, UOSMO}},
+			&mocks.MockRoutablePool{ID: 2, Denoms: []string{UOSMO, ATOM}},
+		},
+		GetOptimalQuoteFunc: func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			s.FailNow("route selection must be bypassed for pinned pairs")
+			return nil, nil
+		},
+		GetPoolSpotPriceFunc: func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+			return osmomath.NewBigDec(int64(poolID + 1)), nil
+		},
+	}
+
+	pricingConfig := defaultPricingConfig
+	pricingConfig.PinnedRoutes = map[string][]uint64{
+		domain.MustFormatPricingCacheKey(ATOM, USDC): {1, 2},
+	}
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	price, priceRoute, err := pricingSource.GetPriceAndRoute(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(6).String(), price.String())
+	s.Require().Nil(priceRoute)
+}
+
+// Validates that ExplainPrice returns the per-pool spot prices, the scaling and the flags
+// of the same computation as GetPrice, including the alternative method fallback.
+func (s *PricingTestSuite) TestExplainPrice() {
+	spotPrice := osmomath.NewBigDec(10).QuoMut(osmomath.NewBigDec(3))
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(spotPrice)
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	explanation, err := pricingSource.ExplainPrice(context.Background(), ATOM, USDC, domain.WithPricePrecision(2))
+	s.Require().NoError(err)
+
+	expectedPrice, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithPricePrecision(2))
+	s.Require().NoError(err)
+	s.Require().Equal(expectedPrice.String(), explanation.Price.String())
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("3.33").String(), explanation.Price.String())
+
+	s.Require().Equal(ATOM, explanation.BaseDenom)
+	s.Require().Equal(USDC, explanation.QuoteDenom)
+	s.Require().Equal(USDC, explanation.QuoteCoin.Denom)
+	s.Require().Equal(osmomath.OneBigDec().String(), explanation.PrecisionScalingFactor.String())
+	s.Require().Equal(spotPrice.String(), explanation.ChainPrice.String())
+	s.Require().True(explanation.IsPriceRounded)
+	s.Require().False(explanation.IsAlternativeMethodUsed)
+	s.Require().False(explanation.IsChainPriceTruncated)
+	s.Require().False(explanation.IsDefaultScalingFactorUsed)
+	s.Require().False(explanation.IsPinnedRoute)
+	s.Require().True(explanation.RouteFee.IsNil())
+
+	s.Require().Len(explanation.Routes, 1)
+	s.Require().Equal(spotPrice.String(), explanation.Routes[0].ChainPrice.String())
+	s.Require().Equal([]domain.PriceExplanationPool{
+		{PoolID: 1, QuoteDenom: USDC, BaseDenom: ATOM, SpotPrice: spotPrice},
+	}, explanation.Routes[0].Pools)
+
+	// The failing spot prices fall back to the alternative method without any route spot prices.
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		return osmomath.BigDec{}, errors.New("spot price error")
+	}
+
+	explanation, err = pricingSource.ExplainPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().True(explanation.IsAlternativeMethodUsed)
+	s.Require().False(explanation.IsPriceRounded)
+	s.Require().Empty(explanation.Routes)
+	s.Require().Equal(osmomath.OneBigDec().String(), explanation.Price.String())
+
+	// Equal denoms are priced at one without computation.
+	explanation, err = pricingSource.ExplainPrice(context.Background(), ATOM, ATOM)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.OneBigDec().String(), explanation.Price.String())
+	s.Require().Empty(explanation.Routes)
+}
+
+// Validates that GetPriceByHumanDenom resolves human denoms to chain denoms
+// and errors if either of them is unknown.
+func (s *PricingTestSuite) TestGetPriceByHumanDenom() {
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+
+	price, err := pricingSource.GetPriceByHumanDenom(context.Background(), "atom", "usdc")
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	_, err = pricingSource.GetPriceByHumanDenom(context.Background(), "unknown", "usdc")
+	s.Require().ErrorIs(err, domain.UnknownHumanDenomError{HumanDenom: "unknown"})
+
+	_, err = pricingSource.GetPriceByHumanDenom(context.Background(), "atom", "unknown")
+	s.Require().ErrorIs(err, domain.UnknownHumanDenomError{HumanDenom: "unknown"})
+}
+
+// Validates that prices are rounded to the given precision
+// while tiny positive prices are never rounded to zero.
+func (s *PricingTestSuite) TestGetPrice_PricePrecision() {
+	testcases := map[string]struct {
+		spotPrice osmomath.BigDec
+		precision int
+
+		expectedPrice osmomath.BigDec
+	}{
+		"rounded to 2 decimals": {
+			spotPrice: osmomath.MustNewBigDecFromStr("2.456"),
+			precision: 2,
+
+			expectedPrice: osmomath.MustNewBigDecFromStr("2.46"),
+		},
+		"rounded to 0 decimals": {
+			spotPrice: osmomath.MustNewBigDecFromStr("2.456"),
+			precision: 0,
+
+			expectedPrice: osmomath.NewBigDec(2),
+		},
+		"small value token keeps precision to stay non-zero": {
+			spotPrice: osmomath.MustNewBigDecFromStr("0.000000123"),
+			precision: 2,
+
+			expectedPrice: osmomath.MustNewBigDecFromStr("0.0000001"),
+		},
+		"no precision option keeps full precision": {
+			spotPrice: osmomath.MustNewBigDecFromStr("0.000000123"),
+			precision: domain.NoPricePrecision,
+
+			expectedPrice: osmomath.MustNewBigDecFromStr("0.000000123"),
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		s.Run(name, func() {
+			pricingSource := s.newSingleRoutePricingSource(tc.spotPrice, defaultPricingConfig)
+
+			price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithPricePrecision(tc.precision))
+			s.Require().NoError(err)
+			s.Require().Equal(tc.expectedPrice.String(), price.String())
+		})
+	}
+}
+
+// Validates that the prices are cached with full precision
+// so that the rounded prices are not served to the callers without the precision option.
+func (s *PricingTestSuite) TestGetPrice_PricePrecision_CachesFullPrecision() {
+	spotPrice := osmomath.MustNewBigDecFromStr("2.456")
+	pricingSource := s.newSingleRoutePricingSource(spotPrice, defaultPricingConfig)
+	pricingSource.InitializeCache(cache.New())
+
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithPricePrecision(0))
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(2).String(), price.String())
+
+	// Served from cache with full precision.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(spotPrice.String(), price.String())
+
+	// Served from cache rounded to the requested precision.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithPricePrecision(2))
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("2.46").String(), price.String())
+}
+
+// Validates that a cached zero price is served as is by default
+// and recomputed with the recompute if zero option.
+func (s *PricingTestSuite) TestGetPrice_RecomputeIfZero() {
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+
+	// Seed a zero price in cache.
+	pricingCache := cache.New()
+	pricingCache.Set(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, ATOM, USDC), osmomath.ZeroBigDec(), cache.NoExpirationTTL)
+	pricingSource.InitializeCache(pricingCache)
+
+	// Zero price is served from cache.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().True(price.IsZero())
+
+	// Zero price is recomputed.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputeIfZero())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// Recomputed price overwrites the zero price in cache.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+}
+
+// Validates that the always recompute config defaults to recomputing the prices
+// and that the per-call options override it.
+func (s *PricingTestSuite) TestGetPrice_AlwaysRecompute() {
+	pricingConfig := defaultPricingConfig
+	pricingConfig.AlwaysRecompute = true
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), pricingConfig)
+
+	// Seed a stale price in cache.
+	pricingCache := cache.New()
+	pricingCache.Set(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, ATOM, USDC), osmomath.NewBigDec(3), cache.NoExpirationTTL)
+	pricingSource.InitializeCache(pricingCache)
+
+	// Stale price is served when opting into the cache.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithUseCache())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(3).String(), price.String())
+
+	// Price is recomputed by default.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+}
+
+// Validates that the max routes and max pools per route pricing options
+// override the configured values in the routing options and fall back to them when unset.
+func (s *PricingTestSuite) TestGetPrice_RouteLimitsOverride() {
+	pricingConfig := defaultPricingConfig
+	pricingConfig.MaxRoutes = 3
+	pricingConfig.MaxPoolsPerRoute = 2
+
+	tests := []struct {
+		name    string
+		options []domain.PricingOption
+
+		expectedMaxRoutes        int
+		expectedMaxPoolsPerRoute int
+	}{
+		{
+			name:                     "no overrides",
+			expectedMaxRoutes:        3,
+			expectedMaxPoolsPerRoute: 2,
+		},
+		{
+			name:                     "max routes override",
+			options:                  []domain.PricingOption{domain.WithPricingMaxRoutes(10)},
+			expectedMaxRoutes:        10,
+			expectedMaxPoolsPerRoute: 2,
+		},
+		{
+			name:                     "max pools per route override",
+			options:                  []domain.PricingOption{domain.WithPricingMaxPoolsPerRoute(5)},
+			expectedMaxRoutes:        3,
+			expectedMaxPoolsPerRoute: 5,
+		},
+		{
+			name:                     "both overrides",
+			options:                  []domain.PricingOption{domain.WithPricingMaxRoutes(10), domain.WithPricingMaxPoolsPerRoute(5)},
+			expectedMaxRoutes:        10,
+			expectedMaxPoolsPerRoute: 5,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		s.Run(tc.name, func() {
+			routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+
+			var routerOptions domain.RouterOptions
+			getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+			routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+				for _, opt := range opts {
+					opt(&routerOptions)
+				}
+				return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+			}
+
+			pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+			_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, append(tc.options, domain.WithRecomputePrices())...)
+			s.Require().NoError(err)
+
+			s.Require().Equal(tc.expectedMaxRoutes, routerOptions.MaxRoutes)
+			s.Require().Equal(tc.expectedMaxPoolsPerRoute, routerOptions.MaxPoolsPerRoute)
+		})
+	}
+}
+
+// Validates that the prices computed with the per-call routing overrides are not cached
+// under the shared price key while the prices computed with the zero min liquidity of the pricing worker are.
+func (s *PricingTestSuite) TestGetPrice_RoutingOverridesNotCached() {
+	tests := []struct {
+		name    string
+		options []domain.PricingOption
+
+		expectCached bool
+	}{
+		{
+			name:         "no overrides",
+			expectCached: true,
+		},
+		{
+			name:         "zero min liquidity",
+			options:      []domain.PricingOption{domain.WithMinLiquidity(0)},
+			expectCached: true,
+		},
+		{
+			name:    "max routes override",
+			options: []domain.PricingOption{domain.WithPricingMaxRoutes(10)},
+		},
+		{
+			name:    "max pools per route override",
+			options: []domain.PricingOption{domain.WithPricingMaxPoolsPerRoute(5)},
+		},
+		{
+			name:    "default scaling factor",
+			options: []domain.PricingOption{domain.WithDefaultScalingFactor(osmomath.NewDec(1_000_000))},
+		},
+		{
+			name:    "non-default min liquidity",
+			options: []domain.PricingOption{domain.WithMinLiquidity(500)},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		s.Run(tc.name, func() {
+			pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+			pricingSource.InitializeCache(cache.New())
+
+			_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, tc.options...)
+			s.Require().NoError(err)
+
+			s.Require().Equal(tc.expectCached, len(pricingSource.ListCachedPairs()) > 0)
+		})
+	}
+}
+
+// Validates that the circuit breaker short-circuits the pricing of a base denom
+// after consecutive failures and closes after a successful probe once the cooldown elapses.
+func (s *PricingTestSuite) TestGetPrice_CircuitBreaker() {
+	const cooldown = 50 * time.Millisecond
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	quoteCallCount := 0
+	shouldFail := true
+	routingErr := errors.New("routing failure")
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		quoteCallCount++
+		if shouldFail {
+			return nil, routingErr
+		}
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	pricingConfig := defaultPricingConfig
+	pricingConfig.CircuitBreakerFailureThreshold = 2
+	pricingConfig.CircuitBreakerCooldownMs = int(cooldown.Milliseconds())
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	// Failures up to the threshold reach the router.
+	for i := 0; i < pricingConfig.CircuitBreakerFailureThreshold; i++ {
+		_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+		s.Require().ErrorIs(err, routingErr)
+	}
+	s.Require().Equal(2, quoteCallCount)
+
+	// Circuit is open so the router is not called.
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().ErrorAs(err, &domain.PricingCircuitOpenError{})
+	s.Require().ErrorIs(err, routingErr)
+	s.Require().Equal(2, quoteCallCount)
+
+	// Other base denoms are unaffected.
+	shouldFail = false
+	_, err = pricingSource.GetPrice(context.Background(), USDC, ATOM, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(3, quoteCallCount)
+
+	// Probe after cooldown closes the circuit.
+	time.Sleep(cooldown)
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+	s.Require().Equal(4, quoteCallCount)
+
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(5, quoteCallCount)
+}
+
+// Validates that the raw chain price option returns the price prior to applying the scaling factors
+// while the scaled price is still cached.
+func (s *PricingTestSuite) TestGetPrice_RawChainPrice() {
+	tokensUsecase := &mocks.TokensUsecaseMock{
+		ChainDenoms: map[string]string{
+			"usdc": USDC,
+		},
+		ScalingFactors: map[string]osmomath.Dec{
+			USDC: osmomath.NewDec(1_000_000),
+			ATOM: osmomath.NewDec(1_000_000_000_000),
+		},
+	}
+
+	pricingSource, err := chainpricing.New(context.Background(), newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5)), tokensUsecase, defaultPricingConfig, &log.NoOpLogger{})
+	s.Require().NoError(err)
+
+	// Raw chain price is the spot price.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRawChainPrice())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// Scaled price is cached.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5_000_000).String(), price.String())
+}
+
+// Validates that the prices of the tokens with extreme scaling factors that fall outside
+// of the configured bounds are rejected with PriceOutOfRangeError and never cached.
+func (s *PricingTestSuite) TestGetPrice_OutOfRange() {
+	tests := []struct {
+		name                    string
+		baseDenomScalingFactor  osmomath.Dec
+		quoteDenomScalingFactor osmomath.Dec
+
+		expectedPrice osmomath.BigDec
+		expectErr     bool
+	}{
+		{
+			name:                    "regular scaling factors",
+			baseDenomScalingFactor:  osmomath.NewDec(1_000_000),
+			quoteDenomScalingFactor: osmomath.NewDec(1_000_000),
+			expectedPrice:           osmomath.NewBigDec(5),
+		},
+		{
+			name:                    "very large base scaling factor",
+			baseDenomScalingFactor:  osmomath.MustNewDecFromStr("10000000000000000000000000000000000000000"),
+			quoteDenomScalingFactor: osmomath.NewDec(1_000_000),
+			expectErr:               true,
+		},
+		{
+			name:                    "very large quote scaling factor",
+			baseDenomScalingFactor:  osmomath.OneDec(),
+			quoteDenomScalingFactor: osmomath.MustNewDecFromStr("1000000000000000000000000000000"),
+			expectErr:               true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		s.Run(tc.name, func() {
+			tokensUsecase := &mocks.TokensUsecaseMock{
+				ChainDenoms: map[string]string{
+					"usdc": USDC,
+				},
+				ScalingFactors: map[string]osmomath.Dec{
+					USDC: tc.quoteDenomScalingFactor,
+					ATOM: tc.baseDenomScalingFactor,
+				},
+			}
+
+			pricingConfig := defaultPricingConfig
+			pricingConfig.MinPrice = "0.000000000000000001"
+			pricingConfig.MaxPrice = "1000000000000000000000000000000"
+			pricingSource, err := chainpricing.New(context.Background(), newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5)), tokensUsecase, pricingConfig, &log.NoOpLogger{})
+			s.Require().NoError(err)
+
+			price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+
+			if tc.expectErr {
+				s.Require().ErrorAs(err, &domain.PriceOutOfRangeError{})
+				s.Require().Empty(pricingSource.ListCachedPairs())
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Require().Equal(tc.expectedPrice.String(), price.String())
+			s.Require().Len(pricingSource.ListCachedPairs(), 1)
+		})
+	}
+}
+
+// Validates that the prices against the default quote denom outside of the per base denom bounds
+// are rejected with PriceOutOfRangeError and never cached while the unbounded denoms are unaffected.
+func (s *PricingTestSuite) TestGetPrice_PriceBounds() {
+	pricingConfig := defaultPricingConfig
+	pricingConfig.PriceBounds = map[string]domain.PriceBound{
+		// The config loader lower cases the map keys.
+		strings.ToLower(ATOM): {Min: "6", Max: "100"},
+		UOSMO:                 {Max: "10"},
+	}
+
+	tests := []struct {
+		name      string
+		baseDenom string
+		quote     string
+
+		expectErr bool
+	}{
+		{
+			name:      "below the min price",
+			baseDenom: ATOM,
+			quote:     USDC,
+			expectErr: true,
+		},
+		{
+			name:      "within the bound",
+			baseDenom: UOSMO,
+			quote:     USDC,
+		},
+		{
+			name:      "not against the default quote denom",
+			baseDenom: ATOM,
+			quote:     UOSMO,
+		},
+		{
+			name:      "unbounded denom",
+			baseDenom: USDC,
+			quote:     UOSMO,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		s.Run(tc.name, func() {
+			pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), pricingConfig)
+
+			price, err := pricingSource.GetPrice(context.Background(), tc.baseDenom, tc.quote)
+
+			if tc.expectErr {
+				s.Require().ErrorAs(err, &domain.PriceOutOfRangeError{})
+				s.Require().Empty(pricingSource.ListCachedPairs())
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+			s.Require().Len(pricingSource.ListCachedPairs(), 1)
+		})
+	}
+}
+
+// Validates that the prices diverging from the prices cross-checked via the cached reference prices
+// are counted in the background while the primary prices are still returned.
+// The reference prices are never computed for the cross-check.
+func (s *PricingTestSuite) TestGetPrice_ReferenceQuoteDivergence() {
+	// Prices of ATOM and USDC in OSMO imply the ATOM price of 5 USDC.
+	referencePrices := map[string]osmomath.BigDec{
+		ATOM: osmomath.NewBigDec(10),
+		USDC: osmomath.NewBigDec(2),
+	}
+
+	tests := []struct {
+		name                string
+		primaryPrice        osmomath.BigDec
+		isReferenceUncached bool
+
+		expectDivergence bool
+	}{
+		{
+			name:         "consistent prices",
+			primaryPrice: osmomath.NewBigDec(5),
+		},
+		{
+			name:             "diverging prices",
+			primaryPrice:     osmomath.NewBigDec(4),
+			expectDivergence: true,
+		},
+		{
+			name:                "diverging prices with uncached reference prices",
+			primaryPrice:        osmomath.NewBigDec(4),
+			isReferenceUncached: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		s.Run(tc.name, func() {
+			routerUsecase := newSingleRouteRouterUsecaseMock(tc.primaryPrice)
+			routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+				if quoteAsset == UOSMO {
+					return referencePrices[baseAsset], nil
+				}
+				return tc.primaryPrice, nil
+			}
+
+			pricingConfig := defaultPricingConfig
+			pricingConfig.ReferenceQuoteDenom = UOSMO
+			pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+			if !tc.isReferenceUncached {
+				for _, denom := range []string{ATOM, USDC} {
+					_, err := pricingSource.GetPrice(context.Background(), denom, UOSMO)
+					s.Require().NoError(err)
+				}
+			}
+
+			divergenceCounter := chainpricing.PricesReferenceDivergenceCounter.WithLabelValues(ATOM, USDC)
+			divergenceCountBefore := testutil.ToFloat64(divergenceCounter)
+
+			price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+			s.Require().NoError(err)
+			s.Require().Equal(tc.primaryPrice.String(), price.String())
+
+			if tc.expectDivergence {
+				s.Require().Eventually(func() bool {
+					return testutil.ToFloat64(divergenceCounter) == divergenceCountBefore+1
+				}, time.Second, time.Millisecond)
+				return
+			}
+
+			// Give the background cross-check the time to complete.
+			time.Sleep(50 * time.Millisecond)
+			s.Require().Equal(divergenceCountBefore, testutil.ToFloat64(divergenceCounter))
+		})
+	}
+}
+
+// Validates that with the spot price cache enabled, the pool spot prices
+// are reused across the pricing computations until expiry.
+func (s *PricingTestSuite) TestGetPrice_SpotPriceCache() {
+	const spotPriceCacheExpiry = 50 * time.Millisecond
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+
+	spotPriceCallCount := 0
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		spotPriceCallCount++
+		return osmomath.NewBigDec(5), nil
+	}
+
+	pricingConfig := defaultPricingConfig
+	pricingConfig.SpotPriceCacheExpiryMs = int(spotPriceCacheExpiry.Milliseconds())
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	for i := 0; i < 2; i++ {
+		price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+		s.Require().NoError(err)
+		s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+	}
+	s.Require().Equal(1, spotPriceCallCount)
+
+	// Spot price is refetched after expiry.
+	time.Sleep(spotPriceCacheExpiry)
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(2, spotPriceCallCount)
+}
+
+// Validates that ListCachedPairs returns the computed prices with their denoms in order.
+func (s *PricingTestSuite) TestListCachedPairs() {
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+
+	s.Require().Empty(pricingSource.ListCachedPairs())
+
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+
+	cachedPairs := pricingSource.ListCachedPairs()
+	s.Require().Len(cachedPairs, 1)
+	s.Require().Equal(ATOM, cachedPairs[0].BaseDenom)
+	s.Require().Equal(USDC, cachedPairs[0].QuoteDenom)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), cachedPairs[0].Price.String())
+	s.Require().Positive(cachedPairs[0].TTLRemaining)
+}
+
+// Validates that the periodic purge purges the expired entries of the cache swapped in by InitializeCache.
+func (s *PricingTestSuite) TestPurgeExpiredPeriodically_FollowsInitializedCache() {
+	pricingConfig := defaultPricingConfig
+	pricingConfig.CachePurgeIntervalMs = 10
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), pricingConfig)
+
+	pricingCache := cache.New()
+	pricingCache.Set(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, ATOM, USDC), osmomath.NewBigDec(5), time.Nanosecond)
+
+	purgedCountBefore := testutil.ToFloat64(chainpricing.CachePurgedEntriesCounter)
+	pricingSource.InitializeCache(pricingCache)
+
+	s.Require().Eventually(func() bool {
+		return testutil.ToFloat64(chainpricing.CachePurgedEntriesCounter) > purgedCountBefore
+	}, time.Second, 10*time.Millisecond)
+}
+
+// Validates that the reverse default quote prices are warmed from the default quote prices of the worker-tracked
+// denoms, skipping the zero prices and the untracked denoms, and that the reverse prices are deleted once untracked.
+func (s *PricingTestSuite) TestWarmReverseDefaultQuotePrices() {
+	const untrackedDenom = "uion"
+
+	pricingCache := cache.New()
+	pricingCache.Set(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, ATOM, USDC), osmomath.NewBigDec(5), cache.NoExpirationTTL)
+	pricingCache.Set(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, UOSMO, USDC), osmomath.ZeroBigDec(), cache.NoExpirationTTL)
+	pricingCache.Set(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, untrackedDenom, USDC), osmomath.NewBigDec(2), time.Hour)
+
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+	pricingSource.InitializeCache(pricingCache)
+	pricingSource.SetWorkerTrackedDenoms(map[string]struct{}{ATOM: {}, UOSMO: {}})
+
+	s.Require().NoError(pricingSource.WarmReverseDefaultQuotePrices())
+
+	getCachedPairsByKey := func() map[string]domain.CachedPricePair {
+		cachedPairsByKey := make(map[string]domain.CachedPricePair)
+		for _, cachedPair := range pricingSource.ListCachedPairs() {
+			cachedPairsByKey[domain.MustFormatPricingCacheKey(cachedPair.BaseDenom, cachedPair.QuoteDenom)] = cachedPair
+		}
+		return cachedPairsByKey
+	}
+	cachedPairsByKey := getCachedPairsByKey()
+
+	reversePair, ok := cachedPairsByKey[domain.MustFormatPricingCacheKey(USDC, ATOM)]
+	s.Require().True(ok)
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("0.2").String(), reversePair.Price.String())
+	s.Require().Zero(reversePair.TTLRemaining)
+
+	// The zero price has no inverse.
+	_, ok = cachedPairsByKey[domain.MustFormatPricingCacheKey(USDC, UOSMO)]
+	s.Require().False(ok)
+
+	// The untracked denoms are not maintained by the pricing worker.
+	_, ok = cachedPairsByKey[domain.MustFormatPricingCacheKey(USDC, untrackedDenom)]
+	s.Require().False(ok)
+
+	// The reverse price is deleted once its base denom is no longer tracked.
+	pricingSource.SetWorkerTrackedDenoms(map[string]struct{}{UOSMO: {}})
+	s.Require().NoError(pricingSource.WarmReverseDefaultQuotePrices())
+
+	_, ok = getCachedPairsByKey()[domain.MustFormatPricingCacheKey(USDC, ATOM)]
+	s.Require().False(ok)
+}
+
+// Validates that the prices of another pricing source sharing the cache neither collide with
+// nor are served as the chain prices of the same pair.
+func (s *PricingTestSuite) TestGetPrice_CacheNamespace() {
+	const otherNamespace = "coingecko"
+
+	otherSourceKey := domain.MustFormatPricingCacheKeyWithNamespace(otherNamespace, ATOM, USDC)
+
+	sharedCache := cache.New()
+	sharedCache.Set(otherSourceKey, osmomath.NewBigDec(7), cache.NoExpirationTTL)
+
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+	pricingSource.InitializeCache(sharedCache)
+
+	// The other source's entry is neither listed nor served.
+	s.Require().Empty(pricingSource.ListCachedPairs())
+
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// The chain price does not overwrite the other source's entry.
+	otherSourcePrice, ok := sharedCache.Get(otherSourceKey)
+	s.Require().True(ok)
+	s.Require().Equal(osmomath.NewBigDec(7).String(), otherSourcePrice.(osmomath.BigDec).String())
+
+	_, ok = sharedCache.Get(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, ATOM, USDC))
+	s.Require().True(ok)
+}
+
+// Validates that the cache snapshot round-trips the cached prices with their remaining TTLs,
+// including the entries without expiration, and drops the entries expired by the import time.
+func (s *PricingTestSuite) TestCacheSnapshot_RoundTrip() {
+	const shortTTL = 50 * time.Millisecond
+
+	exportingCache := cache.New()
+	exportingCache.Set(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, ATOM, USDC), osmomath.NewBigDec(5), cache.NoExpirationTTL)
+	exportingCache.Set(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, UOSMO, USDC), osmomath.MustNewBigDecFromStr("0.5"), time.Hour)
+	exportingCache.Set(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, USDC, ATOM), osmomath.MustNewBigDecFromStr("0.2"), shortTTL)
+
+	exportingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+	exportingSource.InitializeCache(exportingCache)
+
+	snapshot, err := exportingSource.ExportCacheSnapshot()
+	s.Require().NoError(err)
+
+	// Let the short TTL entry expire before the import.
+	time.Sleep(shortTTL)
+
+	importingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+	s.Require().NoError(importingSource.ImportCacheSnapshot(snapshot))
+
+	cachedPairs := importingSource.ListCachedPairs()
+	s.Require().Len(cachedPairs, 2)
+
+	cachedPairsByKey := make(map[string]domain.CachedPricePair, len(cachedPairs))
+	for _, cachedPair := range cachedPairs {
+		cachedPairsByKey[domain.MustFormatPricingCacheKey(cachedPair.BaseDenom, cachedPair.QuoteDenom)] = cachedPair
+	}
+
+	noExpirationPair, ok := cachedPairsByKey[domain.MustFormatPricingCacheKey(ATOM, USDC)]
+	s.Require().True(ok)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), noExpirationPair.Price.String())
+	s.Require().Zero(noExpirationPair.TTLRemaining)
+
+	expiringPair, ok := cachedPairsByKey[domain.MustFormatPricingCacheKey(UOSMO, USDC)]
+	s.Require().True(ok)
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("0.5").String(), expiringPair.Price.String())
+	s.Require().Positive(expiringPair.TTLRemaining)
+	s.Require().Less(expiringPair.TTLRemaining, time.Hour)
+
+	// Imported prices are served from cache.
+	price, err := importingSource.GetPrice(context.Background(), UOSMO, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("0.5").String(), price.String())
+
+	// Malformed snapshot.
+	s.Require().Error(importingSource.ImportCacheSnapshot([]byte("not a snapshot")))
+}
+
+// Validates that only the default quote prices of the base denoms tracked by the pricing worker
+// are cached indefinitely while the rest use the configured cache expiry.
+func (s *PricingTestSuite) TestGetPrice_WorkerTrackedDenoms() {
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+
+	getTTLRemaining := func() time.Duration {
+		cachedPairs := pricingSource.ListCachedPairs()
+		s.Require().Len(cachedPairs, 1)
+		return cachedPairs[0].TTLRemaining
+	}
+
+	// Untracked base denom uses the configured cache expiry.
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Positive(getTTLRemaining())
+
+	// Tracked base denom does not expire.
+	pricingSource.SetWorkerTrackedDenoms(map[string]struct{}{ATOM: {}})
+
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Zero(getTTLRemaining())
+}
+
+// Validates that ComputePriceForRoute prices along the given route without selecting the route
+// and rejects the routes that do not end in the base denom.
+func (s *PricingTestSuite) TestComputePriceForRoute() {
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	routerUsecase.GetOptimalQuoteFunc = nil
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	quoteToBaseRoute := &usecase.RouteWithOutAmount{
+		RouteImpl: route.RouteImpl{
+			Pools: []sqsdomain.RoutablePool{
+				mocks.WithTokenOutDenom(mocks.WithPoolID(routertesting.DefaultPool, 1), ATOM),
+			},
+		},
+	}
+
+	price, err := pricingSource.ComputePriceForRoute(context.Background(), quoteToBaseRoute, ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// Route token out denom does not match the base denom.
+	_, err = pricingSource.ComputePriceForRoute(context.Background(), quoteToBaseRoute, USDC, ATOM)
+	s.Require().Error(err)
+
+	// Price is not cached.
+	s.Require().Empty(pricingSource.ListCachedPairs())
+}
+
+// Validates that the transient spot price errors are retried before falling back to the alternative method
+// while the definitive errors fall back immediately.
+func (s *PricingTestSuite) TestGetPrice_SpotPriceRetry() {
+	const (
+		maxRetries = 2
+		// The alternative method yields the price of one since the mock quotes the amount out equal to the amount in.
+		alternativeMethodPrice = 1
+	)
+
+	tests := []struct {
+		name      string
+		numErrors int
+		err       error
+
+		expectedCallCount int
+		expectedPrice     osmomath.BigDec
+	}{
+		{
+			name:              "no errors",
+			numErrors:         0,
+			err:               errors.New("transient error"),
+			expectedCallCount: 1,
+			expectedPrice:     osmomath.NewBigDec(5),
+		},
+		{
+			name:              "transient errors within max retries",
+			numErrors:         maxRetries,
+			err:               errors.New("transient error"),
+			expectedCallCount: maxRetries + 1,
+			expectedPrice:     osmomath.NewBigDec(5),
+		},
+		{
+			name:              "transient errors exceeding max retries",
+			numErrors:         maxRetries + 1,
+			err:               errors.New("transient error"),
+			expectedCallCount: maxRetries + 1,
+			expectedPrice:     osmomath.NewBigDec(alternativeMethodPrice),
+		},
+		{
+			name:              "definitive error",
+			numErrors:         1,
+			err:               domain.PoolNotFoundError{PoolID: 1},
+			expectedCallCount: 1,
+			expectedPrice:     osmomath.NewBigDec(alternativeMethodPrice),
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		s.Run(tc.name, func() {
+			routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+
+			spotPriceCallCount := 0
+			routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+				spotPriceCallCount++
+				if spotPriceCallCount <= tc.numErrors {
+					return osmomath.BigDec{}, tc.err
+				}
+				return osmomath.NewBigDec(5), nil
+			}
+
+			pricingConfig := defaultPricingConfig
+			pricingConfig.SpotPriceMaxRetries = maxRetries
+			pricingConfig.SpotPriceRetryBackoffMs = 1
+			pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+			price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+			s.Require().NoError(err)
+			s.Require().Equal(tc.expectedPrice.String(), price.String())
+			s.Require().Equal(tc.expectedCallCount, spotPriceCallCount)
+		})
+	}
+}
+
+// Validates that the prices of the pinned pairs are computed over the pinned pools
+// without the route selection and that the disconnected pinned routes are rejected on startup
+// or disabled on the first pool load.
+func (s *PricingTestSuite) TestGetPrice_PinnedRoutes() {
+	sortedPools := []sqsdomain.PoolI{
+		&mocks.MockRoutablePool{ID: 1, Denoms: []string{USDC, UOSMO}},
+		&mocks.MockRoutablePool{ID: 2, Denoms: []string{UOSMO, ATOM}},
+	}
+
+	spotPrices := map[uint64]osmomath.BigDec{
+		1: osmomath.NewBigDec(2),
+		2: osmomath.NewBigDec(3),
+	}
+
+	routerUsecase := &mocks.RouterUsecaseMock{
+		SortedPools: sortedPools,
+		GetOptimalQuoteFunc: func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			s.FailNow("route selection must be bypassed for pinned pairs")
+			return nil, nil
+		},
+		GetPoolSpotPriceFunc: func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+			return spotPrices[poolID], nil
+		},
+	}
+
+	pricingConfig := defaultPricingConfig
+	// Lower cased to mimic the config loader.
+	pricingConfig.PinnedRoutes = map[string][]uint64{
+		strings.ToLower(domain.MustFormatPricingCacheKey(ATOM, USDC)): {1, 2},
+	}
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(6).String(), price.String())
+
+	// Disconnected pinned route fails on startup.
+	pricingConfig.PinnedRoutes = map[string][]uint64{
+		domain.MustFormatPricingCacheKey(ATOM, USDC): {2, 1},
+	}
+	_, err = s.newPricingSourceWithRouterOrError(context.Background(), routerUsecase, pricingConfig)
+	s.Require().Error(err)
+
+	// Empty pinned route fails on startup.
+	pricingConfig.PinnedRoutes = map[string][]uint64{
+		domain.MustFormatPricingCacheKey(ATOM, USDC): {},
+	}
+	_, err = s.newPricingSourceWithRouterOrError(context.Background(), routerUsecase, pricingConfig)
+	s.Require().Error(err)
+
+	// Disconnected pinned route does not panic on startup before the pools are loaded.
+	pricingConfig.PinnedRoutes = map[string][]uint64{
+		domain.MustFormatPricingCacheKey(ATOM, USDC): {2, 1},
+	}
+	routerUsecase.SortedPools = nil
+	// Cancelled so that the deferred validation in the background stops before the pools are loaded.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pricingSource = s.newPricingSourceWithRouterAndContext(ctx, routerUsecase, pricingConfig)
+
+	// Deferred validation waits for the pools to load.
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer waitCancel()
+	chainpricing.ValidatePinnedRoutesOnPoolLoad(waitCtx, pricingSource, time.Millisecond)
+
+	// Deferred validation disables the disconnected pinned route on the first pool load
+	// so that the pair is priced along the selected route.
+	routerUsecase.SortedPools = sortedPools
+	s.Require().NotPanics(func() {
+		chainpricing.ValidatePinnedRoutesOnPoolLoad(context.Background(), pricingSource, time.Millisecond)
+	})
+
+	isRouteSelected := false
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		isRouteSelected = true
+		return nil, errors.New("no route")
+	}
+
+	_, _ = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().True(isRouteSelected)
+}
+
+// Validates that mid prices are computed from the pool spot prices only
+// and error rather than fall back to the alternative method if any spot price is unavailable.
+func (s *PricingTestSuite) TestGetPrice_MidPriceOnly() {
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithMidPriceOnly())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	spotPriceErr := domain.PoolNotFoundError{PoolID: 1}
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		return osmomath.BigDec{}, spotPriceErr
+	}
+
+	// Mid price errors on unavailable spot price even though the price is cached.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithMidPriceOnly())
+	s.Require().ErrorIs(err, spotPriceErr)
+
+	// Regular price falls back to the alternative method.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.OneBigDec().String(), price.String())
+}
+
+// Validates that the routes without pools are rejected with ErrEmptyRoutePools
+// rather than yielding the unscaled price of one.
+func (s *PricingTestSuite) TestGetPrice_EmptyRoutePools() {
+	routerUsecase := &mocks.RouterUsecaseMock{
+		GetOptimalQuoteFunc: func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			return &mocks.MockQuote{
+				AmountIn:  tokenIn,
+				AmountOut: tokenIn.Amount,
+				Route: []domain.SplitRoute{
+					&usecase.RouteWithOutAmount{
+						InAmount:  tokenIn.Amount,
+						OutAmount: tokenIn.Amount,
+					},
+				},
+			}, nil
+		},
+	}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().ErrorIs(err, domain.ErrEmptyRoutePools)
+	s.Require().Empty(pricingSource.ListCachedPairs())
+}
+
+// Validates that the pricing computation span carries the base and quote denoms,
+// the caller-provided trace attributes and the selected route.
+func (s *PricingTestSuite) TestGetPrice_TraceAttributes() {
+	spanRecorder := tracetest.NewSpanRecorder()
+	originalTracerProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	defer otel.SetTracerProvider(originalTracerProvider)
+
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithTraceAttributes(map[string]string{
+		"request_id": "abc",
+	}))
+	s.Require().NoError(err)
+
+	spans := spanRecorder.Ended()
+	s.Require().Len(spans, 1)
+
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans[0].Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+
+	s.Require().Equal(ATOM, attributes["pricing.base"].AsString())
+	s.Require().Equal(USDC, attributes["pricing.quote"].AsString())
+	s.Require().Equal("abc", attributes["request_id"].AsString())
+	s.Require().Equal([]string{"1"}, attributes["pricing.routes"].AsStringSlice())
+}
+
+// Validates that with the route liquidity enforcement, the prices whose selected route
+// has a pool below the min OSMO liquidity are rejected.
+func (s *PricingTestSuite) TestGetPrice_EnforceRouteLiquidity() {
+	// 100 OSMO of liquidity.
+	pool := mocks.WithPoolID(routertesting.DefaultPool, 1)
+	pool.Balances = sdk.NewCoins(sdk.NewCoin(UOSMO, osmomath.NewInt(100_000_000)))
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		return &mocks.MockQuote{
+			AmountIn:  tokenIn,
+			AmountOut: tokenIn.Amount,
+			Route: []domain.SplitRoute{
+				&usecase.RouteWithOutAmount{
+					RouteImpl: route.RouteImpl{
+						Pools: []sqsdomain.RoutablePool{
+							mocks.WithTokenOutDenom(pool, tokenOutDenom),
+						},
+					},
+					InAmount:  tokenIn.Amount,
+					OutAmount: tokenIn.Amount,
+				},
+			},
+		}, nil
+	}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	// Route liquidity above min liquidity.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithMinLiquidity(50), domain.WithEnforceRouteLiquidity())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// Route liquidity below min liquidity.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithMinLiquidity(500), domain.WithEnforceRouteLiquidity())
+	s.Require().ErrorAs(err, &domain.RouteLiquidityTooLowError{})
+
+	// No enforcement by default.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithMinLiquidity(500), domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+}
+
+// Validates that GetPoolSharePrice sums the pool balances priced in the quote denom
+// and divides by the total shares, and that it rejects the non-share denoms.
+func (s *PricingTestSuite) TestGetPoolSharePrice() {
+	const poolID = uint64(1)
+
+	// 1000 USDC and 200 ATOM at 5 USDC each over 100 shares yield 20 USDC per share.
+	pool := mocks.WithChainPoolModel(mocks.WithPoolID(routertesting.DefaultPool, poolID), &balancer.Pool{
+		TotalShares: sdk.NewCoin("gamm/pool/1", osmomath.NewInt(100).Mul(osmomath.NewInt(1_000_000_000_000_000_000))),
+	})
+	pool.Balances = sdk.NewCoins(
+		sdk.NewCoin(USDC, osmomath.NewInt(1_000_000_000)),
+		sdk.NewCoin(ATOM, osmomath.NewInt(200_000_000)),
+	)
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	routerUsecase.SortedPools = []sqsdomain.PoolI{pool}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	price, err := pricingSource.GetPoolSharePrice(context.Background(), "gamm/pool/1", USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(20).String(), price.String())
+
+	// Non-share denom.
+	_, err = pricingSource.GetPoolSharePrice(context.Background(), ATOM, USDC)
+	s.Require().Error(err)
+
+	// Unknown pool.
+	_, err = pricingSource.GetPoolSharePrice(context.Background(), "gamm/pool/2", USDC)
+	s.Require().ErrorIs(err, domain.PoolNotFoundError{PoolID: 2})
+}
+
+// Validates that with the force compute option, the scaling factors of a denom priced against itself
+// are applied so that the price round-trips to one unless the scaling factor is malformed.
+func (s *PricingTestSuite) TestGetPrice_ForceCompute() {
+	const fractionalScalingFactorDenom = "ufractional"
+
+	tokensUsecase := &mocks.TokensUsecaseMock{
+		ChainDenoms: map[string]string{
+			"usdc": USDC,
+		},
+		ScalingFactors: map[string]osmomath.Dec{
+			USDC:                         osmomath.NewDec(1_000_000),
+			ATOM:                         osmomath.NewDec(1_000_000_000_000),
+			fractionalScalingFactorDenom: osmomath.MustNewDecFromStr("1.5"),
+		},
+	}
+
+	pricingSource, err := chainpricing.New(context.Background(), &mocks.RouterUsecaseMock{}, tokensUsecase, defaultPricingConfig, &log.NoOpLogger{})
+	s.Require().NoError(err)
+
+	for _, denom := range []string{USDC, ATOM} {
+		price, err := pricingSource.GetPrice(context.Background(), denom, denom, domain.WithForceCompute())
+		s.Require().NoError(err)
+		s.Require().Equal(osmomath.OneBigDec().String(), price.String())
+	}
+
+	// The fractional scaling factor is truncated in the quote coin so the price does not round-trip.
+	price, err := pricingSource.GetPrice(context.Background(), fractionalScalingFactorDenom, fractionalScalingFactorDenom, domain.WithForceCompute())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("1.5").String(), price.String())
+
+	// The equal denom shortcut applies by default.
+	price, err = pricingSource.GetPrice(context.Background(), fractionalScalingFactorDenom, fractionalScalingFactorDenom)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.OneBigDec().String(), price.String())
+
+	// Forced prices are not cached.
+	s.Require().Empty(pricingSource.ListCachedPairs())
+}
+
+// Validates that the pricing errors are wrapped with the failing denom and stage.
+func (s *PricingTestSuite) TestGetPrice_ErrorDenomContext() {
+	const unknownDenom = "uunknown"
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	// Base scaling factor.
+	_, err := pricingSource.GetPrice(context.Background(), unknownDenom, USDC, domain.WithRecomputePrices())
+	s.Require().ErrorContains(err, fmt.Sprintf("base scaling factor for %s: ", unknownDenom))
+
+	// Quote scaling factor.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, unknownDenom, domain.WithRecomputePrices())
+	s.Require().ErrorContains(err, fmt.Sprintf("quote scaling factor for %s: ", unknownDenom))
+
+	// Optimal quote.
+	quoteErr := errors.New("no routes")
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		return nil, quoteErr
+	}
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().ErrorIs(err, quoteErr)
+	s.Require().ErrorContains(err, fmt.Sprintf("optimal quote for %s (base) -> %s (quote): ", ATOM, USDC))
+
+	// Spot price.
+	routerUsecase = newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	spotPriceErr := domain.PoolNotFoundError{PoolID: 1}
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		return osmomath.BigDec{}, spotPriceErr
+	}
+	pricingSource = s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithMidPriceOnly())
+	s.Require().ErrorIs(err, spotPriceErr)
+	s.Require().ErrorContains(err, fmt.Sprintf("spot price of pool (1) for %s (base) -> %s (quote): ", ATOM, USDC))
+}
+
+// Validates that the price recomputations are rate limited per rate limit key
+// while the cache hits and the calls without a key are exempt.
+func (s *PricingTestSuite) TestGetPrice_RateLimit() {
+	const (
+		clientKey      = "client"
+		otherClientKey = "other-client"
+		burst          = 2
+	)
+
+	pricingConfig := defaultPricingConfig
+	// Low enough rate for the bucket not to refill within the test.
+	pricingConfig.RateLimitPerSecond = 0.001
+	pricingConfig.RateLimitBurst = burst
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), pricingConfig)
+
+	for i := 0; i < burst; i++ {
+		_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithRateLimitKey(clientKey))
+		s.Require().NoError(err)
+	}
+
+	// Budget exhausted.
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithRateLimitKey(clientKey))
+	s.Require().ErrorIs(err, domain.ErrRateLimited)
+
+	// Cache hits are exempt.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRateLimitKey(clientKey))
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// Other keys have their own budget.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithRateLimitKey(otherClientKey))
+	s.Require().NoError(err)
+
+	// Calls without a key are not rate limited.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+}
+
+// Validates that the rate limiter prunes the buckets of the idle keys once they have refilled.
+func (s *PricingTestSuite) TestTokenBucketRateLimiter_PrunesIdleBuckets() {
+	// The bucket refills within one millisecond.
+	rateLimiter := chainpricing.NewTokenBucketRateLimiter(1000, 1)
+
+	s.Require().True(rateLimiter.Allow("idle"))
+	s.Require().Equal(1, chainpricing.RateLimiterBucketsLen(rateLimiter))
+
+	time.Sleep(10 * time.Millisecond)
+
+	// The idle bucket is pruned in favor of the active one.
+	s.Require().True(rateLimiter.Allow("active"))
+	s.Require().Equal(1, chainpricing.RateLimiterBucketsLen(rateLimiter))
+
+	// The pruned bucket starts full again.
+	s.Require().True(rateLimiter.Allow("idle"))
+}
+
+// Validates that the concurrent computations of the same pair share a single computation,
+// including when the prices are recomputed.
+func (s *PricingTestSuite) TestGetPrice_DeduplicatesConcurrentComputations() {
+	const numRequests = 10
+
+	var (
+		computeCount atomic.Int32
+		release      = make(chan struct{})
+	)
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		computeCount.Add(1)
+		<-release
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	var (
+		wg     sync.WaitGroup
+		prices = make([]osmomath.BigDec, numRequests)
+		errs   = make([]error, numRequests)
+	)
+	for i := 0; i < numRequests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prices[i], errs[i] = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+		}(i)
+	}
+
+	// Let all the requests join the in-flight computation before completing it.
+	s.Require().Eventually(func() bool { return computeCount.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	s.Require().Equal(int32(1), computeCount.Load())
+	for i := 0; i < numRequests; i++ {
+		s.Require().NoError(errs[i])
+		s.Require().Equal(osmomath.NewBigDec(5).String(), prices[i].String())
+	}
+}
+
+// Validates that the caller abandoning the shared computation does not fail it for the other callers
+// and that the computation is canceled once all of its callers have abandoned it.
+func (s *PricingTestSuite) TestGetPrice_DeduplicatedComputationOutlivesCanceledCaller() {
+	var (
+		computeCount, canceledCount atomic.Int32
+		// release releases a single blocked computation.
+		release = make(chan struct{})
+	)
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		computeCount.Add(1)
+		select {
+		case <-release:
+		case <-ctx.Done():
+			canceledCount.Add(1)
+			return nil, ctx.Err()
+		}
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	defer cancelFirst()
+
+	var (
+		wg                  sync.WaitGroup
+		firstErr, secondErr error
+		secondPrice         osmomath.BigDec
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, firstErr = pricingSource.GetPrice(firstCtx, ATOM, USDC, domain.WithRecomputePrices())
+	}()
+	s.Require().Eventually(func() bool { return computeCount.Load() == 1 }, time.Second, time.Millisecond)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		secondPrice, secondErr = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	}()
+
+	// Let the second request join the in-flight computation before the first abandons it.
+	time.Sleep(50 * time.Millisecond)
+	cancelFirst()
+	time.Sleep(50 * time.Millisecond)
+	release <- struct{}{}
+	wg.Wait()
+
+	s.Require().ErrorIs(firstErr, context.Canceled)
+	s.Require().NoError(secondErr)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), secondPrice.String())
+	s.Require().Equal(int32(1), computeCount.Load())
+	s.Require().Zero(canceledCount.Load())
+
+	// The sole caller abandoning the computation cancels it.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var err error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err = pricingSource.GetPrice(ctx, ATOM, USDC, domain.WithRecomputePrices())
+	}()
+	s.Require().Eventually(func() bool { return computeCount.Load() == 2 }, time.Second, time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	s.Require().ErrorIs(err, context.Canceled)
+	s.Require().Eventually(func() bool { return canceledCount.Load() == 1 }, time.Second, time.Millisecond)
+}
+
+// Validates that WithStaleOnError serves the expired cached price within the stale grace period
+// alongside ErrStaleData when the computation fails, and that the computation error is returned otherwise.
+func (s *PricingTestSuite) TestGetPrice_StaleOnError() {
+	errChainUnavailable := errors.New("chain unavailable")
+
+	newFailingPricingSource := func(staleGracePeriodMs int) (domain.ChainPricingSource, *bool) {
+		shouldFail := false
+
+		routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+		getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+		routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			if shouldFail {
+				return nil, errChainUnavailable
+			}
+			return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+		}
+
+		pricingConfig := defaultPricingConfig
+		pricingConfig.CacheExpiryMs = 1
+		pricingConfig.StaleGracePeriodMs = staleGracePeriodMs
+
+		return s.newPricingSourceWithRouter(routerUsecase, pricingConfig), &shouldFail
+	}
+
+	pricingSource, shouldFail := newFailingPricingSource(60_000)
+
+	// Warm the cache and let the price expire.
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	time.Sleep(10 * time.Millisecond)
+
+	*shouldFail = true
+
+	// Default behavior is unchanged.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().ErrorIs(err, errChainUnavailable)
+	s.Require().NotErrorIs(err, domain.ErrStaleData)
+
+	// The stale price is served alongside the error.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithStaleOnError())
+	s.Require().ErrorIs(err, domain.ErrStaleData)
+	s.Require().ErrorIs(err, errChainUnavailable)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// Recomputations are served stale too.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithStaleOnError())
+	s.Require().ErrorIs(err, domain.ErrStaleData)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// No cached price to serve.
+	_, err = pricingSource.GetPrice(context.Background(), UOSMO, USDC, domain.WithStaleOnError())
+	s.Require().ErrorIs(err, errChainUnavailable)
+	s.Require().NotErrorIs(err, domain.ErrStaleData)
+
+	// The expired prices are not retained without the grace period.
+	pricingSource, shouldFail = newFailingPricingSource(0)
+
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	time.Sleep(10 * time.Millisecond)
+
+	*shouldFail = true
+
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithStaleOnError())
+	s.Require().ErrorIs(err, errChainUnavailable)
+	s.Require().NotErrorIs(err, domain.ErrStaleData)
+}
+
+// Validates that the empty quote denom prices against the configured default quote denom
+// unless overridden per call, that only the configured default quote denom prices are cached indefinitely
+// for the worker-tracked denoms and that invalid overrides are rejected.
+func (s *PricingTestSuite) TestGetPrice_DefaultQuoteDenomOverride() {
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+	pricingSource.SetWorkerTrackedDenoms(map[string]struct{}{ATOM: {}})
+
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, "")
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, "", domain.WithDefaultQuoteDenom(UOSMO))
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	cachedTTLs := map[string]time.Duration{}
+	for _, pair := range pricingSource.ListCachedPairs() {
+		s.Require().Equal(ATOM, pair.BaseDenom)
+		cachedTTLs[pair.QuoteDenom] = pair.TTLRemaining
+	}
+	s.Require().Len(cachedTTLs, 2)
+
+	// The configured default quote denom is cached indefinitely while the override uses the normal TTL.
+	s.Require().Zero(cachedTTLs[USDC])
+	s.Require().Positive(cachedTTLs[UOSMO])
+
+	// Explicit quote denoms take precedence over the override.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithDefaultQuoteDenom("unknown"))
+	s.Require().NoError(err)
+
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, "", domain.WithDefaultQuoteDenom("unknown"))
+	s.Require().ErrorAs(err, &domain.InvalidDefaultQuoteDenomError{})
+}
+
+// Validates that the pairs without a route are cached as unpriceable if configured
+// so that the subsequent requests fail fast with ErrUnpriceable without searching the routes.
+func (s *PricingTestSuite) TestGetPrice_CacheUnpriceable() {
+	newNoRoutePricingSource := func(cacheUnpriceable bool) (domain.ChainPricingSource, *int) {
+		quoteCount := 0
+
+		routerUsecase := &mocks.RouterUsecaseMock{
+			GetOptimalQuoteFunc: func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+				quoteCount++
+				return nil, nil
+			},
+		}
+
+		pricingConfig := defaultPricingConfig
+		pricingConfig.CacheUnpriceable = cacheUnpriceable
+		pricingConfig.UnpriceableTTLMs = 60_000
+
+		return s.newPricingSourceWithRouter(routerUsecase, pricingConfig), &quoteCount
+	}
+
+	pricingSource, quoteCount := newNoRoutePricingSource(true)
+
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().ErrorIs(err, domain.ErrUnpriceable)
+	s.Require().Equal(1, *quoteCount)
+
+	// Served from cache without searching the routes.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().ErrorIs(err, domain.ErrUnpriceable)
+	s.Require().Equal(1, *quoteCount)
+
+	// The unpriceable pairs are not listed as cached prices.
+	s.Require().Empty(pricingSource.ListCachedPairs())
+
+	// Recomputations search the routes again.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().ErrorIs(err, domain.ErrUnpriceable)
+	s.Require().Equal(2, *quoteCount)
+
+	// Disabled by default.
+	pricingSource, quoteCount = newNoRoutePricingSource(false)
+
+	for i := 0; i < 2; i++ {
+		_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+		s.Require().ErrorIs(err, domain.ErrUnpriceable)
+	}
+	s.Require().Equal(2, *quoteCount)
+}
+
+// Validates that the unpriceable pairs are cached for the unpriceable TTL
+// independently from the cache expiry of the computed prices.
+func (s *PricingTestSuite) TestGetPrice_CacheUnpriceableTTL() {
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	priceableQuoteFunc := routerUsecase.GetOptimalQuoteFunc
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		if tokenOutDenom == UOSMO {
+			return nil, nil
+		}
+		return priceableQuoteFunc(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	pricingConfig := defaultPricingConfig
+	pricingConfig.CacheExpiryMs = 60_000
+	pricingConfig.CacheUnpriceable = true
+	pricingConfig.UnpriceableTTLMs = 100
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+	pricingCache := cache.New()
+	pricingSource.InitializeCache(pricingCache)
+
+	start := time.Now()
+
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	_, err = pricingSource.GetPrice(context.Background(), UOSMO, USDC)
+	s.Require().ErrorIs(err, domain.ErrUnpriceable)
+
+	expiries := map[string]time.Time{}
+	pricingCache.Range(func(key string, value interface{}, expiry time.Time) bool {
+		expiries[key] = expiry
+		return true
+	})
+	s.Require().Len(expiries, 2)
+
+	priceExpiry := expiries[domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, ATOM, USDC)]
+	s.Require().WithinRange(priceExpiry, start.Add(time.Minute), time.Now().Add(time.Minute))
+
+	unpriceableExpiry := expiries[domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, UOSMO, USDC)]
+	s.Require().WithinRange(unpriceableExpiry, start.Add(100*time.Millisecond), time.Now().Add(100*time.Millisecond))
+
+	// The unpriceable pair is recomputed once its TTL elapses while the price is still cached.
+	time.Sleep(150 * time.Millisecond)
+
+	routerUsecase.GetOptimalQuoteFunc = priceableQuoteFunc
+
+	_, err = pricingSource.GetPrice(context.Background(), UOSMO, USDC)
+	s.Require().NoError(err)
+
+	_, status := pricingCache.GetWithStatus(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, ATOM, USDC))
+	s.Require().Equal(cache.StatusHit, status)
+}
+
+// Validates that the configured required intermediate denom constrains the pricing routes
+// except when pricing the intermediate denom itself or against it.
+func (s *PricingTestSuite) TestGetPrice_RequiredIntermediateDenom() {
+	var routerOptions domain.RouterOptions
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		routerOptions = domain.RouterOptions{}
+		for _, opt := range opts {
+			opt(&routerOptions)
+		}
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	pricingConfig := defaultPricingConfig
+	pricingConfig.RequiredIntermediateDenom = UOSMO
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(UOSMO, routerOptions.RequiredIntermediateDenom)
+
+	_, err = pricingSource.GetPrice(context.Background(), UOSMO, USDC)
+	s.Require().NoError(err)
+	s.Require().Empty(routerOptions.RequiredIntermediateDenom)
+}
+
+// Validates that PricingDebugInfo reports the effective config and the live cache stats.
+func (s *PricingTestSuite) TestPricingDebugInfo() {
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+
+	// Cache miss followed by a cache hit.
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+
+	debugInfo := pricingSource.PricingDebugInfo()
+	s.Require().Equal(defaultPricingConfig, debugInfo.Config)
+	s.Require().Equal(USDC, debugInfo.DefaultQuoteDenom)
+	s.Require().Equal(1, debugInfo.CacheSize)
+	s.Require().Equal(uint64(1), debugInfo.CacheHits)
+	s.Require().Equal(uint64(1), debugInfo.CacheMisses)
+	s.Require().Zero(debugInfo.InFlightComputes)
+
+	_, err = json.Marshal(debugInfo)
+	s.Require().NoError(err)
+}
+
+// Validates that the cache misses are counted by reason.
+func (s *PricingTestSuite) TestGetPrice_CacheMissReason() {
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+
+	// Seed a zero price and an expired price in cache.
+	// The expired price is retained within the grace period so that it is reported as expired.
+	pricingCache := cache.NewWithGracePeriod(time.Minute)
+	pricingCache.Set(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, ATOM, USDC), osmomath.ZeroBigDec(), cache.NoExpirationTTL)
+	pricingCache.Set(domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, UOSMO, USDC), osmomath.OneBigDec(), time.Nanosecond)
+	pricingSource.InitializeCache(pricingCache)
+
+	// Sleep to simulate expiration
+	time.Sleep(time.Millisecond * 10)
+
+	testCases := []struct {
+		name           string
+		baseDenom      string
+		options        []domain.PricingOption
+		expectedReason string
+	}{
+		{"zero price recomputed", ATOM, []domain.PricingOption{domain.WithRecomputeIfZero()}, "stale"},
+		{"expired price", UOSMO, nil, "expired"},
+		{"first time", "unknown", nil, "first_time"},
+		{"forced recompute", ATOM, []domain.PricingOption{domain.WithRecomputePrices()}, "forced_recompute"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.Run(tc.name, func() {
+			reasonCounter := chainpricing.CacheMissReasonCounter.WithLabelValues(USDC, tc.expectedReason)
+			reasonCountBefore := testutil.ToFloat64(reasonCounter)
+
+			// The error is irrelevant since the miss is counted prior to computing the price.
+			_, _ = pricingSource.GetPrice(context.Background(), tc.baseDenom, USDC, tc.options...)
+
+			s.Require().Equal(reasonCountBefore+1, testutil.ToFloat64(reasonCounter))
+		})
+	}
+}
+
+// Validates that GetBasketPrice returns the weighted sum of the component prices
+// and rejects the invalid weights.
+func (s *PricingTestSuite) TestGetBasketPrice() {
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.ZeroBigDec())
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		if baseAsset == ATOM {
+			return osmomath.NewBigDec(10), nil
+		}
+		return osmomath.NewBigDec(2), nil
+	}
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	atomPrice, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	osmoPrice, err := pricingSource.GetPrice(context.Background(), UOSMO, USDC)
+	s.Require().NoError(err)
+
+	testCases := []struct {
+		name          string
+		components    []domain.BasketComponent
+		expectedPrice osmomath.BigDec
+		expectedErr   bool
+	}{
+		{
+			name: "weighted sum",
+			components: []domain.BasketComponent{
+				{Denom: ATOM, Weight: osmomath.MustNewDecFromStr("0.4")},
+				{Denom: UOSMO, Weight: osmomath.MustNewDecFromStr("0.6")},
+			},
+			expectedPrice: atomPrice.Mul(osmomath.MustNewBigDecFromStr("0.4")).Add(osmoPrice.Mul(osmomath.MustNewBigDecFromStr("0.6"))),
+		},
+		{
+			name: "weights sum to one within tolerance",
+			components: []domain.BasketComponent{
+				{Denom: ATOM, Weight: osmomath.MustNewDecFromStr("0.3333333")},
+				{Denom: UOSMO, Weight: osmomath.MustNewDecFromStr("0.6666666")},
+			},
+			expectedPrice: atomPrice.Mul(osmomath.MustNewBigDecFromStr("0.3333333")).Add(osmoPrice.Mul(osmomath.MustNewBigDecFromStr("0.6666666"))),
+		},
+		{
+			name: "weights do not sum to one",
+			components: []domain.BasketComponent{
+				{Denom: ATOM, Weight: osmomath.MustNewDecFromStr("0.4")},
+				{Denom: UOSMO, Weight: osmomath.MustNewDecFromStr("0.5")},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "negative weight",
+			components: []domain.BasketComponent{
+				{Denom: ATOM, Weight: osmomath.MustNewDecFromStr("1.5")},
+				{Denom: UOSMO, Weight: osmomath.MustNewDecFromStr("-0.5")},
+			},
+			expectedErr: true,
+		},
+		{
+			name:        "empty basket",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.Run(tc.name, func() {
+			price, err := pricingSource.GetBasketPrice(context.Background(), tc.components, USDC)
+			if tc.expectedErr {
+				s.Require().ErrorAs(err, &domain.InvalidBasketWeightsError{})
+				return
+			}
+			s.Require().NoError(err)
+			s.Require().Equal(tc.expectedPrice.String(), price.String())
+		})
+	}
+
+	// The cached component prices are not mutated.
+	cachedAtomPrice, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(atomPrice.String(), cachedAtomPrice.String())
+}
+
+// Validates that DetectArbitrage returns the product of the pairwise prices around the cycle.
+func (s *PricingTestSuite) TestDetectArbitrage() {
+	denomValues := map[string]int64{USDC: 1, ATOM: 10, UOSMO: 2}
+
+	testCases := []struct {
+		name      string
+		spotPrice func(quoteAsset, baseAsset string) osmomath.BigDec
+
+		expectNoArbitrage bool
+	}{
+		{
+			name: "consistent prices have no cycle gain",
+			spotPrice: func(quoteAsset, baseAsset string) osmomath.BigDec {
+				return osmomath.NewBigDec(denomValues[baseAsset]).QuoMut(osmomath.NewBigDec(denomValues[quoteAsset]))
+			},
+			expectNoArbitrage: true,
+		},
+		{
+			name: "inconsistent prices have a cycle gain",
+			spotPrice: func(quoteAsset, baseAsset string) osmomath.BigDec {
+				return osmomath.NewBigDec(2)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.Run(tc.name, func() {
+			routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.ZeroBigDec())
+			routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+				return tc.spotPrice(quoteAsset, baseAsset), nil
+			}
+			pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+			expectedGain := osmomath.OneBigDec()
+			for _, pair := range [][2]string{{ATOM, UOSMO}, {UOSMO, USDC}, {USDC, ATOM}} {
+				price, err := pricingSource.GetPrice(context.Background(), pair[0], pair[1])
+				s.Require().NoError(err)
+				expectedGain = expectedGain.Mul(price)
+			}
+
+			// System under test.
+			cycleGain, err := pricingSource.DetectArbitrage(context.Background(), ATOM, UOSMO, USDC)
+			s.Require().NoError(err)
+
+			s.Require().Equal(expectedGain.Dec().String(), cycleGain.String())
+			s.Require().Equal(tc.expectNoArbitrage, cycleGain.Equal(osmomath.OneDec()))
+		})
+	}
+}
+
+// Validates that the precision loss is detected beyond the relative tolerance.
+func (s *PricingTestSuite) TestHasPrecisionLoss() {
+	testCases := []struct {
+		name                  string
+		value                 osmomath.BigDec
+		fullPrecisionValue    osmomath.BigDec
+		expectedPrecisionLoss bool
+	}{
+		{"equal", osmomath.MustNewBigDecFromStr("0.5"), osmomath.MustNewBigDecFromStr("0.5"), false},
+		{"within tolerance", osmomath.MustNewBigDecFromStr("0.333333333333333333"), osmomath.OneBigDec().QuoMut(osmomath.NewBigDec(3)), false},
+		{"truncated to zero", osmomath.ZeroBigDec(), osmomath.MustNewBigDecFromStr("0.0000000000000000001"), true},
+		{"truncated digits", osmomath.MustNewBigDecFromStr("0.000000000000000012"), osmomath.MustNewBigDecFromStr("0.0000000000000000125"), true},
+		{"both zero", osmomath.ZeroBigDec(), osmomath.ZeroBigDec(), false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.Run(tc.name, func() {
+			s.Require().Equal(tc.expectedPrecisionLoss, chainpricing.HasPrecisionLoss(tc.value, tc.fullPrecisionValue))
+		})
+	}
+}
+
+// Validates that the price is computed with the full precision scaling factor
+// if the scaling factor truncated to Dec loses precision and that the precision loss is counted.
+func (s *PricingTestSuite) TestGetPrice_FullPrecisionScalingFactor() {
+	tokensUsecase := &mocks.TokensUsecaseMock{
+		ChainDenoms: map[string]string{
+			"usdc": USDC,
+			"atom": ATOM,
+		},
+		ScalingFactors: map[string]osmomath.Dec{
+			// The precision scaling factor of 1e-24 truncates to zero in Dec.
+			ATOM: osmomath.OneDec(),
+			USDC: osmomath.MustNewDecFromStr("1000000000000000000000000"),
+		},
+	}
+
+	pricingSource, err := chainpricing.New(context.Background(), newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5)), tokensUsecase, defaultPricingConfig, &log.NoOpLogger{})
+	s.Require().NoError(err)
+
+	precisionLossCounter := chainpricing.PricesPrecisionLossCounter.WithLabelValues(ATOM, USDC)
+	precisionLossCountBefore := testutil.ToFloat64(precisionLossCounter)
+
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("0.000000000000000000000005").String(), price.String())
+
+	s.Require().Equal(precisionLossCountBefore+1, testutil.ToFloat64(precisionLossCounter))
+}
+
+// Validates that only the stablecoin quote denoms are probed with the multiplier
+// and that the price is unaffected by the probed amount.
+func (s *PricingTestSuite) TestGetPrice_StablecoinQuoteDenoms() {
+	testCases := []struct {
+		name                  string
+		stablecoinQuoteDenoms []string
+		quoteDenom            string
+		expectedMultiplier    int64
+	}{
+		{"unset with stablecoin quote", nil, USDC, 10},
+		{"unset with non-stablecoin quote", nil, ATOM, 10},
+		{"empty", []string{}, USDC, 1},
+		{"configured stablecoin quote", []string{ATOM}, ATOM, 10},
+		{"configured without default quote", []string{ATOM}, USDC, 1},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.Run(tc.name, func() {
+			var tokenInAmount osmomath.Int
+
+			routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+			getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+			routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+				tokenInAmount = tokenIn.Amount
+				return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+			}
+
+			pricingConfig := defaultPricingConfig
+			pricingConfig.StablecoinQuoteDenoms = tc.stablecoinQuoteDenoms
+			pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+			price, err := pricingSource.GetPrice(context.Background(), UOSMO, tc.quoteDenom, domain.WithRecomputePrices())
+			s.Require().NoError(err)
+
+			// The chain price is scaled by the same multiplier so the price is the same.
+			unitProbePricingConfig := pricingConfig
+			unitProbePricingConfig.StablecoinQuoteDenoms = []string{}
+			unitProbePrice, err := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), unitProbePricingConfig).GetPrice(context.Background(), UOSMO, tc.quoteDenom, domain.WithRecomputePrices())
+			s.Require().NoError(err)
+			s.Require().Equal(unitProbePrice.String(), price.String())
+
+			// Each of the test denoms has 6 decimals.
+			s.Require().Equal(osmomath.NewInt(tc.expectedMultiplier*1_000_000).String(), tokenInAmount.String())
+		})
+	}
+}
+
+// Validates that GetPriceWithSlippage returns the price before the swap
+// and the effective price of the pricing quote.
+func (s *PricingTestSuite) TestGetPriceWithSlippage() {
+	// The pool has the spot price of one but only half of the quote coin
+	// is swapped out, implying the effective price of two.
+	pool := mocks.WithMockedTokenOut(&mocks.MockRoutablePool{
+		ID:            1,
+		PoolType:      poolmanagertypes.CosmWasm,
+		TokenOutDenom: ATOM,
+		TakerFee:      osmomath.ZeroDec(),
+		SpreadFactor:  osmomath.ZeroDec(),
+	}, sdk.NewCoin(ATOM, osmomath.NewInt(5_000_000)))
+
+	routerUsecase := &mocks.RouterUsecaseMock{
+		GetOptimalQuoteFunc: func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			return &mocks.MockQuote{
+				AmountIn:  tokenIn,
+				AmountOut: osmomath.NewInt(5_000_000),
+				Route: []domain.SplitRoute{
+					&usecase.RouteWithOutAmount{
+						RouteImpl: route.RouteImpl{
+							Pools: []sqsdomain.RoutablePool{pool},
+						},
+						InAmount:  tokenIn.Amount,
+						OutAmount: osmomath.NewInt(5_000_000),
+					},
+				},
+			}, nil
+		},
+		GetPoolSpotPriceFunc: func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+			return osmomath.OneBigDec(), nil
+		},
+	}
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	spotPriceBefore, effectivePrice, err := pricingSource.GetPriceWithSlippage(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.OneBigDec().String(), spotPriceBefore.String())
+	s.Require().Equal(osmomath.NewBigDec(2).String(), effectivePrice.String())
+
+	// The price before the swap is consistent with GetPrice.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(price.String(), spotPriceBefore.String())
+
+	// Equal base and quote have no slippage.
+	spotPriceBefore, effectivePrice, err = pricingSource.GetPriceWithSlippage(context.Background(), USDC, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.OneBigDec().String(), spotPriceBefore.String())
+	s.Require().Equal(osmomath.OneBigDec().String(), effectivePrice.String())
+}
+
+// Validates that the default scaling factor is used in place of the unknown scaling factor
+// and that the resulting low-confidence price is served, flagged by GetPriceWithConfidence but never cached.
+func (s *PricingTestSuite) TestGetPrice_DefaultScalingFactor() {
+	const unknownScalingFactorDenom = "ibc/unknown"
+
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+
+	// Fails without the default scaling factor.
+	_, err := pricingSource.GetPrice(context.Background(), unknownScalingFactorDenom, USDC)
+	s.Require().Error(err)
+
+	// The default scaling factor of 10^6 matches the quote scaling factor
+	// so that the price equals the spot price. It is returned like any other price.
+	price, err := pricingSource.GetPrice(context.Background(), unknownScalingFactorDenom, USDC, domain.WithDefaultScalingFactor(osmomath.NewDec(1_000_000)))
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// The low-confidence price is flagged.
+	result, err := pricingSource.GetPriceWithConfidence(context.Background(), unknownScalingFactorDenom, USDC, domain.WithDefaultScalingFactor(osmomath.NewDec(1_000_000)))
+	s.Require().NoError(err)
+	s.Require().True(result.IsLowConfidence)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), result.Price.String())
+
+	// The non-positive default scaling factor is replaced by one.
+	result, err = pricingSource.GetPriceWithConfidence(context.Background(), unknownScalingFactorDenom, USDC, domain.WithDefaultScalingFactor(osmomath.ZeroDec()))
+	s.Require().NoError(err)
+	s.Require().True(result.IsLowConfidence)
+	s.Require().Equal(osmomath.NewBigDec(5).QuoMut(osmomath.NewBigDec(1_000_000)).String(), result.Price.String())
+
+	// The low-confidence price is not cached.
+	s.Require().Empty(pricingSource.ListCachedPairs())
+
+	// The known scaling factors yield the confident prices.
+	result, err = pricingSource.GetPriceWithConfidence(context.Background(), ATOM, USDC, domain.WithDefaultScalingFactor(osmomath.NewDec(1_000_000)))
+	s.Require().NoError(err)
+	s.Require().False(result.IsLowConfidence)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), result.Price.String())
+}
+
+// Validates that the last successfully computed price is served when requested
+// after the computation fails and the cached price has been purged.
+func (s *PricingTestSuite) TestGetPrice_LastKnownGoodFallback() {
+	errChainUnavailable := errors.New("chain unavailable")
+	shouldFail := false
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		if shouldFail {
+			return nil, errChainUnavailable
+		}
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	// No stale grace period so that the expired prices are not retained in cache.
+	pricingConfig := defaultPricingConfig
+	pricingConfig.CacheExpiryMs = 1
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	// Compute the price and let it expire from cache.
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	time.Sleep(10 * time.Millisecond)
+
+	shouldFail = true
+
+	// Default behavior is unchanged.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().ErrorIs(err, errChainUnavailable)
+	s.Require().NotErrorIs(err, domain.ErrStaleData)
+
+	// The stale cached price is gone.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithStaleOnError())
+	s.Require().NotErrorIs(err, domain.ErrStaleData)
+
+	// The last known good price is served alongside the error.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithLastKnownGoodFallback())
+	s.Require().ErrorIs(err, domain.ErrStaleData)
+	s.Require().ErrorIs(err, errChainUnavailable)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// No last known good price to serve.
+	_, err = pricingSource.GetPrice(context.Background(), UOSMO, USDC, domain.WithLastKnownGoodFallback())
+	s.Require().ErrorIs(err, errChainUnavailable)
+	s.Require().NotErrorIs(err, domain.ErrStaleData)
+}
+
+// Validates that the last known good prices are not served past the retention.
+func (s *PricingTestSuite) TestGetPrice_LastKnownGoodFallback_Retention() {
+	errChainUnavailable := errors.New("chain unavailable")
+	shouldFail := false
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		if shouldFail {
+			return nil, errChainUnavailable
+		}
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	pricingConfig := defaultPricingConfig
+	pricingConfig.CacheExpiryMs = 1
+	pricingConfig.LastKnownGoodRetentionMs = 1
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	time.Sleep(10 * time.Millisecond)
+
+	shouldFail = true
+
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithLastKnownGoodFallback())
+	s.Require().ErrorIs(err, errChainUnavailable)
+	s.Require().NotErrorIs(err, domain.ErrStaleData)
+}
+
+// Validates that the compute budget bounds GetPrice by abandoning the computation once exhausted
+// in favor of the cached price, then the last known good price, flagged by ErrComputeBudgetExhausted.
+func (s *PricingTestSuite) TestGetPrice_ComputeBudget() {
+	const (
+		exhaustedBudget = 20 * time.Millisecond
+		ampleBudget     = 10 * time.Second
+	)
+
+	var (
+		isBlocked atomic.Bool
+		release   = make(chan struct{})
+	)
+	defer close(release)
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		// Ignores the context so that only the abandonment bounds the latency.
+		if isBlocked.Load() {
+			<-release
+		}
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	// Computed within the budget.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithComputeBudget(ampleBudget))
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	isBlocked.Store(true)
+
+	// The cached price is served without computing as without the budget.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithComputeBudget(exhaustedBudget))
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// The recompute exhausts the budget so the cached price is served.
+	start := time.Now()
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithComputeBudget(exhaustedBudget))
+	s.Require().Less(time.Since(start), ampleBudget)
+	s.Require().ErrorIs(err, domain.ErrComputeBudgetExhausted)
+	s.Require().ErrorIs(err, domain.ErrStaleData)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// Without the cached price, the last known good price is served.
+	pricingSource.InitializeCache(cache.New())
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithComputeBudget(exhaustedBudget))
+	s.Require().ErrorIs(err, domain.ErrComputeBudgetExhausted)
+	s.Require().ErrorIs(err, domain.ErrStaleData)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// The prices that are never cached have no fallback.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithVolumeWeightedPricing(), domain.WithComputeBudget(exhaustedBudget))
+	s.Require().ErrorIs(err, domain.ErrComputeBudgetExhausted)
+	s.Require().NotErrorIs(err, domain.ErrStaleData)
+
+	// No price to fall back to.
+	price, err = pricingSource.GetPrice(context.Background(), UOSMO, USDC, domain.WithComputeBudget(exhaustedBudget))
+	s.Require().ErrorIs(err, domain.ErrComputeBudgetExhausted)
+	s.Require().NotErrorIs(err, domain.ErrStaleData)
+	s.Require().True(price.IsNil())
+}
+
+// Validates that the computations abandoned due to the exhausted compute budget
+// are not recorded as failures by the circuit breaker.
+func (s *PricingTestSuite) TestGetPrice_ComputeBudgetExhaustionKeepsCircuitClosed() {
+	const exhaustedBudget = 20 * time.Millisecond
+
+	var (
+		isBlocked      atomic.Bool
+		quoteCallCount atomic.Int32
+	)
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		quoteCallCount.Add(1)
+		if isBlocked.Load() {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	pricingConfig := defaultPricingConfig
+	pricingConfig.CircuitBreakerFailureThreshold = 2
+	pricingConfig.CircuitBreakerCooldownMs = int(time.Minute.Milliseconds())
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	isBlocked.Store(true)
+
+	const numExhaustions = 3
+	for i := 0; i < numExhaustions; i++ {
+		_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithComputeBudget(exhaustedBudget))
+		s.Require().ErrorIs(err, domain.ErrComputeBudgetExhausted)
+		s.Require().False(errors.As(err, &domain.PricingCircuitOpenError{}))
+	}
+	s.Require().Equal(int32(numExhaustions), quoteCallCount.Load())
+
+	isBlocked.Store(false)
+
+	// The circuit is closed so the router is reached.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+	s.Require().Equal(int32(numExhaustions+1), quoteCallCount.Load())
+}
+
+// Validates that the forced alternative method prices by dividing the quote amount in by the amount out
+// without computing the spot prices, and that its prices are not cached.
+func (s *PricingTestSuite) TestGetPrice_ForceAlternativeMethod() {
+	const spotPrice = 5
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(spotPrice))
+
+	numSpotPriceCalls := 0
+	getPoolSpotPrice := routerUsecase.GetPoolSpotPriceFunc
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		numSpotPriceCalls++
+		return getPoolSpotPrice(ctx, poolID, quoteAsset, baseAsset)
+	}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	// The mock quotes the amount out equal to the amount in so the alternative method prices at one.
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithForceAlternativeMethod())
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.OneBigDec().String(), price.String())
+	s.Require().Zero(numSpotPriceCalls)
+	s.Require().Empty(pricingSource.ListCachedPairs())
+
+	// The spot price method is used otherwise.
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(spotPrice).String(), price.String())
+	s.Require().Equal(1, numSpotPriceCalls)
+}
+
+// Validates that GetPriceAsync delivers exactly one result and that the async computations
+// beyond the configured max concurrency wait for a slot or the context cancellation.
+func (s *PricingTestSuite) TestGetPriceAsync() {
+	const spotPrice = 5
+
+	s.Run("delivers the price", func() {
+		pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(spotPrice), defaultPricingConfig)
+
+		resultCh := pricingSource.GetPriceAsync(context.Background(), ATOM, USDC)
+
+		result := <-resultCh
+		s.Require().NoError(result.Err)
+		s.Require().Equal(osmomath.NewBigDec(spotPrice).String(), result.Price.String())
+
+		// The channel is closed after the single result.
+		_, ok := <-resultCh
+		s.Require().False(ok)
+	})
+
+	s.Run("bounded by max concurrent async computes", func() {
+		config := defaultPricingConfig
+		config.MaxConcurrentAsyncComputes = 1
+
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+
+		routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(spotPrice))
+		getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+		routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			started <- struct{}{}
+			<-release
+			return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+		}
+
+		pricingSource := s.newPricingSourceWithRouter(routerUsecase, config)
+
+		// Occupy the only slot.
+		atomResultCh := pricingSource.GetPriceAsync(context.Background(), ATOM, USDC)
+		<-started
+
+		// The second computation waits for the slot until its context is cancelled.
+		ctx, cancel := context.WithCancel(context.Background())
+		osmoResultCh := pricingSource.GetPriceAsync(ctx, UOSMO, USDC)
+		cancel()
+
+		osmoResult := <-osmoResultCh
+		s.Require().ErrorIs(osmoResult.Err, context.Canceled)
+
+		// The waiting computation never started.
+		s.Require().Empty(started)
+
+		close(release)
+
+		atomResult := <-atomResultCh
+		s.Require().NoError(atomResult.Err)
+		s.Require().Equal(osmomath.NewBigDec(spotPrice).String(), atomResult.Price.String())
+	})
+}
+
+// Validates that the prices are not computed from the pool data lagging the chain height
+// by more than the configured max staleness.
+func (s *PricingTestSuite) TestGetPrice_PoolDataStaleness() {
+	const (
+		spotPrice          = 5
+		maxStalenessBlocks = 10
+		chainHeight        = 100
+	)
+
+	testCases := []struct {
+		name           string
+		poolDataHeight uint64
+		expectErr      bool
+	}{
+		{"pool data at chain height", chainHeight, false},
+		{"lag at max staleness", chainHeight - maxStalenessBlocks, false},
+		{"lag beyond max staleness", chainHeight - maxStalenessBlocks - 1, true},
+		{"pool data ahead of chain height", chainHeight + 1, false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.Run(tc.name, func() {
+			config := defaultPricingConfig
+			config.MaxPoolDataStalenessBlocks = maxStalenessBlocks
+
+			pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(spotPrice), config)
+			pricingSource.SetBlockHeightProvider(&mocks.BlockHeightProviderMock{
+				PoolDataHeight: tc.poolDataHeight,
+				ChainHeight:    chainHeight,
+			})
+
+			price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+			if tc.expectErr {
+				s.Require().ErrorAs(err, &domain.StalePoolDataError{})
+				s.Require().Empty(pricingSource.ListCachedPairs())
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Require().Equal(osmomath.NewBigDec(spotPrice).String(), price.String())
+		})
+	}
+
+	s.Run("unchecked if chain height unavailable", func() {
+		config := defaultPricingConfig
+		config.MaxPoolDataStalenessBlocks = maxStalenessBlocks
+
+		pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(spotPrice), config)
+		pricingSource.SetBlockHeightProvider(&mocks.BlockHeightProviderMock{
+			PoolDataHeight: 0,
+			ChainHeight:    chainHeight,
+			ChainHeightErr: domain.ErrChainHeightUnavailable,
+		})
+
+		errorCountBefore := testutil.ToFloat64(chainpricing.PoolDataFreshnessCheckErrorsCounter)
+
+		price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+		s.Require().NoError(err)
+		s.Require().Equal(osmomath.NewBigDec(spotPrice).String(), price.String())
+		s.Require().Equal(errorCountBefore+1, testutil.ToFloat64(chainpricing.PoolDataFreshnessCheckErrorsCounter))
+	})
+
+	s.Run("unchecked without block height provider", func() {
+		config := defaultPricingConfig
+		config.MaxPoolDataStalenessBlocks = maxStalenessBlocks
+
+		pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(spotPrice), config)
+
+		_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+		s.Require().NoError(err)
+	})
+}
+
+// Validates that the fee-inclusive price nets out the spread and taker fees of the route pool
+// from the fee-exclusive price and that it is not cached.
+func (s *PricingTestSuite) TestGetPrice_FeeInclusivePricing() {
+	const spotPrice = 5
+
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(spotPrice), defaultPricingConfig)
+
+	feeInclusivePrice, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithFeeInclusivePricing())
+	s.Require().NoError(err)
+	s.Require().Empty(pricingSource.ListCachedPairs())
+
+	feeExclusivePrice, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(spotPrice).String(), feeExclusivePrice.String())
+
+	// The route has a single pool so its fee is the sum of the pool spread factor and taker fee.
+	routeFee := routertesting.DefaultSpreadFactor.Add(routertesting.DefaultTakerFee)
+	expectedFeeInclusivePrice := feeExclusivePrice.Mul(osmomath.BigDecFromDec(osmomath.OneDec().Sub(routeFee)))
+	s.Require().Equal(expectedFeeInclusivePrice.String(), feeInclusivePrice.String())
+	s.Require().True(feeInclusivePrice.LT(feeExclusivePrice))
+}
+
+// Validates that RefreshDefaultQuotePrices recomputes the warmed default quote prices along the stored route
+// without the route search and falls back to the full recompute once the route pools change.
+func (s *PricingTestSuite) TestRefreshDefaultQuotePrices() {
+	const poolID = uint64(1)
+
+	spotPrice := osmomath.NewBigDec(5)
+	routerUsecase := newSingleRouteRouterUsecaseMock(spotPrice)
+	routerUsecase.SortedPools = []sqsdomain.PoolI{mocks.WithDenoms(mocks.WithPoolID(routertesting.DefaultPool, poolID), []string{USDC, ATOM})}
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		return spotPrice, nil
+	}
+
+	optimalQuoteCalls := 0
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		optimalQuoteCalls++
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+	pricingSource.SetWorkerTrackedDenoms(map[string]struct{}{ATOM: {}})
+
+	// Warm the default quote price.
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices())
+	s.Require().NoError(err)
+	s.Require().Equal(1, optimalQuoteCalls)
+
+	// The refresh recomputes the spot prices along the stored route.
+	spotPrice = osmomath.NewBigDec(6)
+	s.Require().NoError(pricingSource.RefreshDefaultQuotePrices(context.Background(), []string{ATOM}))
+	s.Require().Equal(1, optimalQuoteCalls)
+
+	price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(6).String(), price.String())
+
+	// The stored route no longer connects the denoms so the price is recomputed from scratch.
+	routerUsecase.SortedPools = []sqsdomain.PoolI{mocks.WithDenoms(mocks.WithPoolID(routertesting.DefaultPool, poolID), []string{USDC, UOSMO})}
+	spotPrice = osmomath.NewBigDec(7)
+	s.Require().NoError(pricingSource.RefreshDefaultQuotePrices(context.Background(), []string{ATOM}))
+	s.Require().Equal(2, optimalQuoteCalls)
+
+	price, err = pricingSource.GetPrice(context.Background(), ATOM, USDC)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(7).String(), price.String())
+}
+
+// Validates that RefreshDefaultQuotePrices returns promptly with context.Canceled
+// once the context is canceled mid-refresh without refreshing the remaining base denoms.
+func (s *PricingTestSuite) TestRefreshDefaultQuotePrices_ContextCanceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancels the context on the first route search.
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	optimalQuoteCalls := 0
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		optimalQuoteCalls++
+		cancel()
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, defaultPricingConfig)
+
+	err := pricingSource.RefreshDefaultQuotePrices(ctx, []string{ATOM, UOSMO})
+	s.Require().ErrorIs(err, context.Canceled)
+	s.Require().Equal(1, optimalQuoteCalls)
+}
+
+// Validates that InitializeCache is safe to call concurrently with serving the prices.
+// Meant to be run with the race detector (go test -race).
+func (s *PricingTestSuite) TestInitializeCache_ConcurrentWithGetPrice() {
+	const (
+		numReaders    = 4
+		numIterations = 100
+	)
+
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < numIterations; i++ {
+			pricingSource.InitializeCache(cache.New())
+		}
+	}()
+
+	for i := 0; i < numReaders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numIterations; j++ {
+				// Assert rather than require since FailNow must not be called from the spawned goroutines.
+				price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC)
+				s.Assert().NoError(err)
+				s.Assert().Equal(osmomath.NewBigDec(5).String(), price.String())
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// mapPrecisionProvider is a precision provider with the scaling factors set in the map.
+type mapPrecisionProvider map[string]osmomath.Dec
+
+// GetScalingFactor implements domain.PrecisionProvider.
+func (p mapPrecisionProvider) GetScalingFactor(denom string) (osmomath.Dec, bool) {
+	scalingFactor, ok := p[denom]
+	return scalingFactor, ok
+}
+
+// Validates that the precision provider overrides the on-chain scaling factors of the denoms it has
+// and falls through to the on-chain scaling factors otherwise, and that its prices are not cached.
+func (s *PricingTestSuite) TestGetPrice_PrecisionProvider() {
+	tests := []struct {
+		name              string
+		precisionProvider domain.PrecisionProvider
+
+		expectedPrice osmomath.BigDec
+	}{
+		{
+			name: "provider overrides the base scaling factor",
+			// 5 * 10^8 / 10^6
+			precisionProvider: mapPrecisionProvider{ATOM: osmomath.NewDec(100_000_000)},
+			expectedPrice:     osmomath.NewBigDec(500),
+		},
+		{
+			name:              "provider falls through to the on-chain scaling factors",
+			precisionProvider: mapPrecisionProvider{UOSMO: osmomath.NewDec(100_000_000)},
+			expectedPrice:     osmomath.NewBigDec(5),
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		s.Run(tc.name, func() {
+			pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+
+			price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithPrecisionProvider(tc.precisionProvider))
+			s.Require().NoError(err)
+			s.Require().Equal(tc.expectedPrice.String(), price.String())
+
+			s.Require().Empty(pricingSource.ListCachedPairs())
+		})
+	}
+}
+
+// Validates that DefaultQuoteDenom returns the chain denom of the configured default quote
+// regardless of the per-call overrides.
+func (s *PricingTestSuite) TestDefaultQuoteDenom() {
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+	s.Require().Equal(USDC, pricingSource.DefaultQuoteDenom())
+
+	_, err := pricingSource.GetPrice(context.Background(), UOSMO, "", domain.WithDefaultQuoteDenom(ATOM))
+	s.Require().NoError(err)
+	s.Require().Equal(USDC, pricingSource.DefaultQuoteDenom())
+
+	atomQuotePricingConfig := defaultPricingConfig
+	atomQuotePricingConfig.DefaultQuoteHumanDenom = "atom"
+	pricingSource = s.newSingleRoutePricingSource(osmomath.NewBigDec(5), atomQuotePricingConfig)
+	s.Require().Equal(ATOM, pricingSource.DefaultQuoteDenom())
+}
+
+// Validates that GetUSDPrice prices against the default quote denom,
+// applies the default quote USD rate if configured and errors if the rate is required but unset.
+func (s *PricingTestSuite) TestGetUSDPrice() {
+	// USD-pegged default quote denom without rate.
+	pricingSource := s.newSingleRoutePricingSource(osmomath.NewBigDec(5), defaultPricingConfig)
+
+	price, err := pricingSource.GetUSDPrice(context.Background(), ATOM)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+	// USD-pegged default quote denom with rate.
+	pricingConfigWithRate := defaultPricingConfig
+	pricingConfigWithRate.DefaultQuoteUSDRate = "0.5"
+	pricingSource = s.newSingleRoutePricingSource(osmomath.NewBigDec(5), pricingConfigWithRate)
+
+	price, err = pricingSource.GetUSDPrice(context.Background(), ATOM)
+	s.Require().NoError(err)
+	s.Require().Equal(osmomath.MustNewBigDecFromStr("2.5").String(), price.String())
+
+	// Non USD-pegged default quote denom without rate.
+	nonPeggedPricingConfig := defaultPricingConfig
+	nonPeggedPricingConfig.DefaultQuoteHumanDenom = "atom"
+	pricingSource = s.newSingleRoutePricingSource(osmomath.NewBigDec(5), nonPeggedPricingConfig)
+
+	_, err = pricingSource.GetUSDPrice(context.Background(), USDC)
+	s.Require().Error(err)
+}
+
+// Validates that with route reuse enabled, the routes are recomputed only once per
+// route update height window while the spot prices are recomputed at every height.
+func (s *PricingTestSuite) TestGetPrice_RouteReuse() {
+	const routeUpdateHeightInterval = 10
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	routerUsecase.Config.RouteUpdateHeightInterval = routeUpdateHeightInterval
+
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	quoteCallCount := 0
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		quoteCallCount++
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	spotPriceCallCount := 0
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		spotPriceCallCount++
+		return osmomath.NewBigDec(5), nil
+	}
+
+	pricingConfig := defaultPricingConfig
+	pricingConfig.EnableRouteReuse = true
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	tests := []struct {
+		name   string
+		height uint64
+
+		expectedQuoteCallCount     int
+		expectedSpotPriceCallCount int
+	}{
+		{
+			name:                       "first height in window computes route",
+			height:                     20,
+			expectedQuoteCallCount:     1,
+			expectedSpotPriceCallCount: 1,
+		},
+		{
+			name:                       "same window reuses route",
+			height:                     29,
+			expectedQuoteCallCount:     1,
+			expectedSpotPriceCallCount: 2,
+		},
+		{
+			name:                       "next window recomputes route",
+			height:                     30,
+			expectedQuoteCallCount:     2,
+			expectedSpotPriceCallCount: 3,
+		},
+		{
+			name:                       "unknown height does not reuse route",
+			height:                     0,
+			expectedQuoteCallCount:     3,
+			expectedSpotPriceCallCount: 4,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		s.Run(tc.name, func() {
+			price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithHeight(tc.height))
+			s.Require().NoError(err)
+			s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+			s.Require().Equal(tc.expectedQuoteCallCount, quoteCallCount)
+			s.Require().Equal(tc.expectedSpotPriceCallCount, spotPriceCallCount)
+		})
+	}
+}
+
+// Validates that with route reuse enabled, the quotes cached for the lower route update height windows
+// are purged once a quote is cached for a higher window.
+func (s *PricingTestSuite) TestGetPrice_RouteReuse_PurgesStaleWindows() {
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	routerUsecase.Config.RouteUpdateHeightInterval = 10
+
+	pricingConfig := defaultPricingConfig
+	pricingConfig.EnableRouteReuse = true
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	_, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithHeight(20))
+	s.Require().NoError(err)
+	singlePairLen := chainpricing.RouteCacheLen(pricingSource)
+	s.Require().Positive(singlePairLen)
+
+	_, err = pricingSource.GetPrice(context.Background(), UOSMO, USDC, domain.WithRecomputePrices(), domain.WithHeight(25))
+	s.Require().NoError(err)
+	s.Require().Greater(chainpricing.RouteCacheLen(pricingSource), singlePairLen)
+
+	// The quotes of the pair that is not priced in the next window are purged.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithHeight(30))
+	s.Require().NoError(err)
+	s.Require().Equal(singlePairLen, chainpricing.RouteCacheLen(pricingSource))
+
+	// The quotes computed for a lower window are not cached.
+	_, err = pricingSource.GetPrice(context.Background(), UOSMO, USDC, domain.WithRecomputePrices(), domain.WithHeight(29))
+	s.Require().NoError(err)
+	s.Require().Equal(singlePairLen, chainpricing.RouteCacheLen(pricingSource))
+
+	// The quotes computed with the route-affecting options are cached separately.
+	_, err = pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithHeight(30), domain.WithForceAlternativeMethod())
+	s.Require().NoError(err)
+	s.Require().Greater(chainpricing.RouteCacheLen(pricingSource), singlePairLen)
+}
+
+// Validates that with route reuse enabled, the repeated computations at the same height
+// recompute the price from the cached spot prices without any router call
+// and that the cached spot prices are invalidated once the height changes.
+func (s *PricingTestSuite) TestGetPrice_HeightPricingCache() {
+	const routeUpdateHeightInterval = 10
+
+	routerUsecase := newSingleRouteRouterUsecaseMock(osmomath.NewBigDec(5))
+	routerUsecase.Config.RouteUpdateHeightInterval = routeUpdateHeightInterval
+
+	getOptimalQuote := routerUsecase.GetOptimalQuoteFunc
+	quoteCallCount := 0
+	routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+		quoteCallCount++
+		return getOptimalQuote(ctx, tokenIn, tokenOutDenom, opts...)
+	}
+
+	spotPriceCallCount := 0
+	routerUsecase.GetPoolSpotPriceFunc = func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+		spotPriceCallCount++
+		return osmomath.NewBigDec(5), nil
+	}
+
+	pricingConfig := defaultPricingConfig
+	pricingConfig.EnableRouteReuse = true
+	pricingSource := s.newPricingSourceWithRouter(routerUsecase, pricingConfig)
+
+	tests := []struct {
+		name   string
+		height uint64
+
+		expectedQuoteCallCount     int
+		expectedSpotPriceCallCount int
+	}{
+		{
+			name:                       "first call at height computes spot prices",
+			height:                     20,
+			expectedQuoteCallCount:     1,
+			expectedSpotPriceCallCount: 1,
+		},
+		{
+			name:                       "second call at the same height is fully cached",
+			height:                     20,
+			expectedQuoteCallCount:     1,
+			expectedSpotPriceCallCount: 1,
+		},
+		{
+			name:                       "next height recomputes spot prices along the reused route",
+			height:                     21,
+			expectedQuoteCallCount:     1,
+			expectedSpotPriceCallCount: 2,
+		},
+		{
+			name:                       "lower height is not cached",
+			height:                     20,
+			expectedQuoteCallCount:     1,
+			expectedSpotPriceCallCount: 3,
+		},
+		{
+			name:                       "latest height remains cached",
+			height:                     21,
+			expectedQuoteCallCount:     1,
+			expectedSpotPriceCallCount: 3,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		s.Run(tc.name, func() {
+			price, err := pricingSource.GetPrice(context.Background(), ATOM, USDC, domain.WithRecomputePrices(), domain.WithHeight(tc.height))
+			s.Require().NoError(err)
+			s.Require().Equal(osmomath.NewBigDec(5).String(), price.String())
+
+			s.Require().Equal(tc.expectedQuoteCallCount, quoteCallCount)
+			s.Require().Equal(tc.expectedSpotPriceCallCount, spotPriceCallCount)
+		})
+	}
+}
+
+// newSingleRoutePricingSource returns a chain pricing source with the given config over mocks
+// where every quote has a single one-pool route with the given spot price.
+// USDC and ATOM human and chain denoms are known with the scaling factor of 10^6.
+func (s *PricingTestSuite) newSingleRoutePricingSource(spotPrice osmomath.BigDec, config domain.PricingConfig) domain.ChainPricingSource {
+	return s.newPricingSourceWithRouter(newSingleRouteRouterUsecaseMock(spotPrice), config)
+}
+
+// newSingleRouteRouterUsecaseMock returns a router usecase mock that always quotes
+// a single route over one pool with the given spot price.
+func newSingleRouteRouterUsecaseMock(spotPrice osmomath.BigDec) *mocks.RouterUsecaseMock {
+	const poolID = uint64(1)
+
+	return &mocks.RouterUsecaseMock{
+		GetOptimalQuoteFunc: func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			return newRouteQuote(tokenIn, tokenIn.Amount, newRoutePool(poolID, tokenOutDenom)), nil
+		},
+		GetPoolSpotPriceFunc: func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+			return spotPrice, nil
+		},
+	}
+}
+
+// newRoutesRouterUsecaseMock returns a router usecase mock quoting the optimal and the ranked routes
+// returned by the given functions, with the pool spot prices looked up by the pool IDs.
+// The nil functions are not mocked. The spot prices of the pools missing from the map error.
+func newRoutesRouterUsecaseMock(optimalQuote func(tokenIn sdk.Coin, tokenOutDenom string) domain.Quote, rankedQuotes func(tokenIn sdk.Coin, tokenOutDenom string, k int) []domain.Quote, poolSpotPrices map[uint64]osmomath.BigDec) *mocks.RouterUsecaseMock {
+	routerUsecase := &mocks.RouterUsecaseMock{
+		GetPoolSpotPriceFunc: func(ctx context.Context, poolID uint64, quoteAsset, baseAsset string) (osmomath.BigDec, error) {
+			spotPrice, ok := poolSpotPrices[poolID]
+			if !ok {
+				return osmomath.BigDec{}, domain.PoolNotFoundError{PoolID: poolID}
+			}
+			return spotPrice, nil
+		},
+	}
+
+	if optimalQuote != nil {
+		routerUsecase.GetOptimalQuoteFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, opts ...domain.RouterOption) (domain.Quote, error) {
+			return optimalQuote(tokenIn, tokenOutDenom), nil
+		}
+	}
+
+	if rankedQuotes != nil {
+		routerUsecase.GetRankedQuotesFunc = func(ctx context.Context, tokenIn sdk.Coin, tokenOutDenom string, k int, opts ...domain.RouterOption) ([]domain.Quote, error) {
+			return rankedQuotes(tokenIn, tokenOutDenom, k), nil
+		}
+	}
+
+	return routerUsecase
+}
+
+// newRouteQuote returns a quote of the given amount out along a single route over the given pools.
+func newRouteQuote(tokenIn sdk.Coin, amountOut osmomath.Int, pools ...sqsdomain.RoutablePool) domain.Quote {
+	return &mocks.MockQuote{
+		AmountIn:  tokenIn,
+		AmountOut: amountOut,
+		Route: []domain.SplitRoute{
+			&usecase.RouteWithOutAmount{
+				RouteImpl: route.RouteImpl{
+					Pools: pools,
+				},
+				InAmount:  tokenIn.Amount,
+				OutAmount: amountOut,
+			},
+		},
+	}
+}
+
+// newRoutePool returns a mock routable pool with the given ID swapping out the given denom.
+func newRoutePool(poolID uint64, tokenOutDenom string) *mocks.MockRoutablePool {
+	return mocks.WithTokenOutDenom(mocks.WithPoolID(routertesting.DefaultPool, poolID), tokenOutDenom)
+}
+
+// newPricingSourceWithRouter returns a chain pricing source over the given router usecase
+// and a tokens usecase mock with USDC, ATOM and OSMO metadata.
+func (s *PricingTestSuite) newPricingSourceWithRouter(routerUsecase mvc.RouterUsecase, config domain.PricingConfig) domain.ChainPricingSource {
+	return s.newPricingSourceWithRouterAndContext(context.Background(), routerUsecase, config)
+}
+
+// newPricingSourceWithRouterAndContext is newPricingSourceWithRouter with the given context
+// bounding the background goroutines of the pricing source.
+func (s *PricingTestSuite) newPricingSourceWithRouterAndContext(ctx context.Context, routerUsecase mvc.RouterUsecase, config domain.PricingConfig) domain.ChainPricingSource {
+	pricingSource, err := s.newPricingSourceWithRouterOrError(ctx, routerUsecase, config)
+	s.Require().NoError(err)
+	return pricingSource
+}
+
+// newPricingSourceWithRouterOrError is newPricingSourceWithRouterAndContext returning the error
+// of the pricing source creation rather than failing the test.
+func (s *PricingTestSuite) newPricingSourceWithRouterOrError(ctx context.Context, routerUsecase mvc.RouterUsecase, config domain.PricingConfig) (domain.ChainPricingSource, error) {
+	tokensUsecase := &mocks.TokensUsecaseMock{
+		ChainDenoms: map[string]string{
+			"usdc": USDC,
+			"atom": ATOM,
+			"osmo": UOSMO,
+		},
+		ScalingFactors: map[string]osmomath.Dec{
+			USDC:  osmomath.NewDec(1_000_000),
+			ATOM:  osmomath.NewDec(1_000_000),
+			UOSMO: osmomath.NewDec(1_000_000),
+		},
+	}
+
+	return chainpricing.New(ctx, routerUsecase, tokensUsecase, config, &log.NoOpLogger{})
+}
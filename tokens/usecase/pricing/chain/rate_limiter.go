@@ -0,0 +1,99 @@
+package chainpricing
+
+import (
+	"sync"
+	"time"
+
+	"github.com/osmosis-labs/sqs/domain"
+)
+
+// tokenBucketRateLimiter is a per-key token bucket rate limiter.
+// Each key has a bucket holding up to burst tokens that refills at the given rate per second.
+// Each allowed call consumes a token.
+// The idle buckets that have refilled to the burst are pruned since they are equivalent to the new buckets
+// so that the memory is bounded by the keys active within the refill duration of a bucket.
+type tokenBucketRateLimiter struct {
+	ratePerSecond float64
+	burst         float64
+	// refillDuration is the duration for an empty bucket to refill to the burst.
+	refillDuration time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+}
+
+// tokenBucket is the token bucket of a single key.
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+var _ domain.RateLimiter = &tokenBucketRateLimiter{}
+
+// newTokenBucketRateLimiter returns a new token bucket rate limiter.
+// Returns nil if the rate is not positive, disabling the rate limiting.
+// The burst defaults to one if not positive.
+func newTokenBucketRateLimiter(ratePerSecond float64, burst int) *tokenBucketRateLimiter {
+	if ratePerSecond <= 0 {
+		return nil
+	}
+
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &tokenBucketRateLimiter{
+		ratePerSecond:  ratePerSecond,
+		burst:          float64(burst),
+		refillDuration: time.Duration(float64(burst) / ratePerSecond * float64(time.Second)),
+		buckets:        make(map[string]*tokenBucket),
+		lastPrune:      time.Now(),
+	}
+}
+
+// Allow implements domain.RateLimiter.
+func (r *tokenBucketRateLimiter) Allow(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	r.pruneIdleBuckets(now)
+
+	bucket, ok := r.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: r.burst, lastRefill: now}
+		r.buckets[key] = bucket
+	}
+
+	// Refill the tokens accumulated since the last refill up to the burst.
+	bucket.tokens += now.Sub(bucket.lastRefill).Seconds() * r.ratePerSecond
+	if bucket.tokens > r.burst {
+		bucket.tokens = r.burst
+	}
+	bucket.lastRefill = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+
+	bucket.tokens--
+	return true
+}
+
+// pruneIdleBuckets removes the buckets that have refilled to the burst by the given time.
+// The buckets are swept at most once per refill duration so that the sweeps are amortized across the calls.
+// The caller must hold the lock.
+func (r *tokenBucketRateLimiter) pruneIdleBuckets(now time.Time) {
+	if now.Sub(r.lastPrune) < r.refillDuration {
+		return
+	}
+	r.lastPrune = now
+
+	for key, bucket := range r.buckets {
+		if now.Sub(bucket.lastRefill) >= r.refillDuration {
+			delete(r.buckets, key)
+		}
+	}
+}
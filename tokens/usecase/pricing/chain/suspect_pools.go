@@ -0,0 +1,88 @@
+package chainpricing
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// suspectPools is the time-bounded set of the pools suspected of manipulation
+// that the pricing routes must avoid until their expiry.
+type suspectPools struct {
+	mu sync.RWMutex
+	// expiries maps the suspect pool IDs to the time until which they are suspect.
+	expiries map[uint64]time.Time
+}
+
+// newSuspectPools returns a new empty suspect pool set.
+func newSuspectPools() *suspectPools {
+	return &suspectPools{
+		expiries: make(map[uint64]time.Time),
+	}
+}
+
+// mark marks the given pool as suspect until the given time, overwriting any previous expiry.
+func (s *suspectPools) mark(poolID uint64, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expiries[poolID] = until
+}
+
+// clear removes the given pool from the suspect set. No-op if it is not suspect.
+func (s *suspectPools) clear(poolID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.expiries, poolID)
+}
+
+// snapshot returns a copy of the pools that are suspect at the given time alongside their expiries.
+// The expired entries are pruned.
+func (s *suspectPools) snapshot(now time.Time) map[uint64]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[uint64]time.Time, len(s.expiries))
+	for poolID, until := range s.expiries {
+		if !now.Before(until) {
+			delete(s.expiries, poolID)
+			continue
+		}
+		result[poolID] = until
+	}
+	return result
+}
+
+// activePoolIDs returns the sorted IDs of the pools that are suspect at the given time.
+// The expired entries are pruned.
+func (s *suspectPools) activePoolIDs(now time.Time) []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var poolIDs []uint64
+	for poolID, until := range s.expiries {
+		if !now.Before(until) {
+			delete(s.expiries, poolID)
+			continue
+		}
+		poolIDs = append(poolIDs, poolID)
+	}
+
+	sort.Slice(poolIDs, func(i, j int) bool { return poolIDs[i] < poolIDs[j] })
+
+	return poolIDs
+}
+
+// containsAny returns true if any of the given pools is suspect at the given time.
+func (s *suspectPools) containsAny(poolIDs []uint64, now time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, poolID := range poolIDs {
+		if until, ok := s.expiries[poolID]; ok && now.Before(until) {
+			return true
+		}
+	}
+	return false
+}
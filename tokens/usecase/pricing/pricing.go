@@ -1,21 +1,35 @@
 package pricing
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/osmosis-labs/sqs/domain"
 	"github.com/osmosis-labs/sqs/domain/cache"
 	"github.com/osmosis-labs/sqs/domain/mvc"
+	"github.com/osmosis-labs/sqs/log"
 	chainpricing "github.com/osmosis-labs/sqs/tokens/usecase/pricing/chain"
 )
 
 // NewPricingStrategy is a factory method to create the pricing strategy based on the desired source.
-func NewPricingStrategy(config domain.PricingConfig, tokensUsecase mvc.TokensUsecase, routerUseCase mvc.RouterUsecase) (domain.PricingSource, error) {
-	if config.DefaultSource == domain.ChainPricingSourceType {
-		return chainpricing.New(routerUseCase, tokensUsecase, config), nil
+// The returned pricing strategy is a PricingSourceRouter defaulting to the desired source
+// so that the callers may request a specific source via domain.WithPricingSource(...).
+// The administration of the desired source is returned alongside the pricing strategy.
+// The context bounds the lifetime of any background work started by the pricing strategy.
+func NewPricingStrategy(ctx context.Context, config domain.PricingConfig, tokensUsecase mvc.TokensUsecase, routerUseCase mvc.RouterUsecase, logger log.Logger) (domain.PricingSource, domain.PricingSourceAdmin, error) {
+	if config.DefaultSource != domain.ChainPricingSourceType {
+		return nil, nil, fmt.Errorf("pricing source (%d) is not supported", config.DefaultSource)
 	}
 
-	return nil, fmt.Errorf("pricing source (%d) is not supported", config.DefaultSource)
+	chainPricingSource, err := chainpricing.New(ctx, routerUseCase, tokensUsecase, config, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pricingSourceRouter := NewPricingSourceRouter(config.DefaultSource)
+	pricingSourceRouter.RegisterSource(domain.ChainPricingSourceType, chainPricingSource)
+
+	return pricingSourceRouter, chainPricingSource, nil
 }
 
 // WithPricingCache initializes the pricing strategy with a given cache.
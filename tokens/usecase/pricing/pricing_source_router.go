@@ -0,0 +1,187 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/domain"
+	"github.com/osmosis-labs/sqs/domain/cache"
+)
+
+// PricingSourceRouter is a pricing source that delegates to the pricing source
+// requested via domain.WithPricingSource(...) or to the default pricing source otherwise.
+// The methods without the pricing options always delegate to the default pricing source.
+// The administration of the pricing sources (see domain.PricingSourceAdmin) is not routed
+// and is done on the sources directly.
+// The sources must be registered before use since the registry is not safe for concurrent writes.
+type PricingSourceRouter struct {
+	defaultSource domain.PricingSourceType
+	sources       map[domain.PricingSourceType]domain.PricingSource
+}
+
+var _ domain.PricingSource = &PricingSourceRouter{}
+
+// NewPricingSourceRouter returns a new pricing source router with the given default pricing source.
+func NewPricingSourceRouter(defaultSource domain.PricingSourceType) *PricingSourceRouter {
+	return &PricingSourceRouter{
+		defaultSource: defaultSource,
+		sources:       map[domain.PricingSourceType]domain.PricingSource{},
+	}
+}
+
+// RegisterSource registers the pricing source of the given type, replacing the existing one, if any.
+func (r *PricingSourceRouter) RegisterSource(sourceType domain.PricingSourceType, source domain.PricingSource) {
+	r.sources[sourceType] = source
+}
+
+// getSource returns the pricing source requested in the given options or the default pricing source.
+// Returns error if the pricing source is not registered.
+func (r *PricingSourceRouter) getSource(opts ...domain.PricingOption) (domain.PricingSource, error) {
+	options := domain.PricingOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	sourceType := r.defaultSource
+	if options.PricingSource != nil {
+		sourceType = *options.PricingSource
+	}
+
+	source, ok := r.sources[sourceType]
+	if !ok {
+		return nil, fmt.Errorf("pricing source (%d) is not registered", sourceType)
+	}
+
+	return source, nil
+}
+
+// mustGetDefaultSource returns the default pricing source.
+// Panics if the default pricing source is not registered.
+func (r *PricingSourceRouter) mustGetDefaultSource() domain.PricingSource {
+	source, err := r.getSource()
+	if err != nil {
+		panic(err)
+	}
+	return source
+}
+
+// GetPrice implements domain.PricingSource.
+func (r *PricingSourceRouter) GetPrice(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	source, err := r.getSource(opts...)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+	return source.GetPrice(ctx, baseDenom, quoteDenom, opts...)
+}
+
+// GetPriceWithConfidence implements domain.PricingSource.
+func (r *PricingSourceRouter) GetPriceWithConfidence(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (domain.PriceWithConfidence, error) {
+	source, err := r.getSource(opts...)
+	if err != nil {
+		return domain.PriceWithConfidence{}, err
+	}
+	return source.GetPriceWithConfidence(ctx, baseDenom, quoteDenom, opts...)
+}
+
+// GetPriceByHumanDenom implements domain.PricingSource.
+func (r *PricingSourceRouter) GetPriceByHumanDenom(ctx context.Context, baseHumanDenom string, quoteHumanDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	source, err := r.getSource(opts...)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+	return source.GetPriceByHumanDenom(ctx, baseHumanDenom, quoteHumanDenom, opts...)
+}
+
+// GetUSDPrice implements domain.PricingSource.
+func (r *PricingSourceRouter) GetUSDPrice(ctx context.Context, baseDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	source, err := r.getSource(opts...)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+	return source.GetUSDPrice(ctx, baseDenom, opts...)
+}
+
+// GetPriceWithSlippage implements domain.PricingSource.
+func (r *PricingSourceRouter) GetPriceWithSlippage(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, osmomath.BigDec, error) {
+	source, err := r.getSource(opts...)
+	if err != nil {
+		return osmomath.BigDec{}, osmomath.BigDec{}, err
+	}
+	return source.GetPriceWithSlippage(ctx, baseDenom, quoteDenom, opts...)
+}
+
+// ComputePriceForRoute implements domain.PricingSource.
+func (r *PricingSourceRouter) ComputePriceForRoute(ctx context.Context, route domain.SplitRoute, baseDenom string, quoteDenom string) (osmomath.BigDec, error) {
+	source, err := r.getSource()
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+	return source.ComputePriceForRoute(ctx, route, baseDenom, quoteDenom)
+}
+
+// GetPoolSharePrice implements domain.PricingSource.
+func (r *PricingSourceRouter) GetPoolSharePrice(ctx context.Context, poolShareDenom string, quoteDenom string) (osmomath.BigDec, error) {
+	source, err := r.getSource()
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+	return source.GetPoolSharePrice(ctx, poolShareDenom, quoteDenom)
+}
+
+// GetBasketPrice implements domain.PricingSource.
+func (r *PricingSourceRouter) GetBasketPrice(ctx context.Context, components []domain.BasketComponent, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+	source, err := r.getSource(opts...)
+	if err != nil {
+		return osmomath.BigDec{}, err
+	}
+	return source.GetBasketPrice(ctx, components, quoteDenom, opts...)
+}
+
+// DetectArbitrage implements domain.PricingSource.
+func (r *PricingSourceRouter) DetectArbitrage(ctx context.Context, denomA, denomB, denomC string) (osmomath.Dec, error) {
+	source, err := r.getSource()
+	if err != nil {
+		return osmomath.Dec{}, err
+	}
+	return source.DetectArbitrage(ctx, denomA, denomB, denomC)
+}
+
+// GetPriceAndRoute implements domain.PricingSource.
+func (r *PricingSourceRouter) GetPriceAndRoute(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, domain.SplitRoute, error) {
+	source, err := r.getSource(opts...)
+	if err != nil {
+		return osmomath.BigDec{}, nil, err
+	}
+
+	return source.GetPriceAndRoute(ctx, baseDenom, quoteDenom, opts...)
+}
+
+// ExplainPrice implements domain.PricingSource.
+func (r *PricingSourceRouter) ExplainPrice(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (domain.PriceExplanation, error) {
+	source, err := r.getSource(opts...)
+	if err != nil {
+		return domain.PriceExplanation{}, err
+	}
+
+	return source.ExplainPrice(ctx, baseDenom, quoteDenom, opts...)
+}
+
+// GetPriceAsync implements domain.PricingSource.
+// The router errors are delivered on the returned channel.
+func (r *PricingSourceRouter) GetPriceAsync(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) <-chan domain.PriceResultOrError {
+	source, err := r.getSource(opts...)
+	if err != nil {
+		resultCh := make(chan domain.PriceResultOrError, 1)
+		resultCh <- domain.PriceResultOrError{Err: err}
+		close(resultCh)
+		return resultCh
+	}
+
+	return source.GetPriceAsync(ctx, baseDenom, quoteDenom, opts...)
+}
+
+// InitializeCache implements domain.PricingSource.
+func (r *PricingSourceRouter) InitializeCache(cache *cache.Cache) {
+	r.mustGetDefaultSource().InitializeCache(cache)
+}
@@ -0,0 +1,72 @@
+package pricing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osmosis-labs/osmosis/osmomath"
+	"github.com/osmosis-labs/sqs/domain"
+	"github.com/osmosis-labs/sqs/domain/mocks"
+	"github.com/osmosis-labs/sqs/tokens/usecase/pricing"
+)
+
+// TestPricingSourceRouter_GetPrice tests that the pricing source router delegates to the requested
+// pricing source, defaults to the default pricing source and errors on the unregistered pricing sources.
+func TestPricingSourceRouter_GetPrice(t *testing.T) {
+	newFixedPriceSource := func(price osmomath.BigDec) *mocks.PricingSourceMock {
+		return &mocks.PricingSourceMock{
+			GetPriceFunc: func(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+				return price, nil
+			},
+		}
+	}
+
+	router := pricing.NewPricingSourceRouter(domain.ChainPricingSourceType)
+	router.RegisterSource(domain.ChainPricingSourceType, newFixedPriceSource(osmomath.NewBigDec(1)))
+
+	testCases := []struct {
+		name string
+		opts []domain.PricingOption
+
+		expectedPrice osmomath.BigDec
+		expectErr     bool
+	}{
+		{
+			name:          "default source",
+			expectedPrice: osmomath.NewBigDec(1),
+		},
+		{
+			name:          "requested chain source",
+			opts:          []domain.PricingOption{domain.WithPricingSource(domain.ChainPricingSourceType)},
+			expectedPrice: osmomath.NewBigDec(1),
+		},
+		{
+			name:      "unregistered source",
+			opts:      []domain.PricingOption{domain.WithPricingSource(domain.CoinGeckoPricingSourceType)},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			price, err := router.GetPrice(context.Background(), "uosmo", "uusdc", tc.opts...)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedPrice.String(), price.String())
+		})
+	}
+
+	// Once registered, the requested source is used over the default source.
+	router.RegisterSource(domain.CoinGeckoPricingSourceType, newFixedPriceSource(osmomath.NewBigDec(2)))
+
+	price, err := router.GetPrice(context.Background(), "uosmo", "uusdc", domain.WithPricingSource(domain.CoinGeckoPricingSourceType))
+	require.NoError(t, err)
+	require.Equal(t, osmomath.NewBigDec(2).String(), price.String())
+}
@@ -13,6 +13,15 @@ import (
 )
 
 type pricingWorker struct {
+	// ctx is the parent context of the price updates.
+	// Once it is done, for example, on shutdown, the in-flight update is aborted and no further update starts.
+	ctx context.Context
+	// updateTimeout is the duration after which a price update is aborted.
+	updateTimeout time.Duration
+	// fullRecomputeHeightInterval is the number of heights between the full recomputes of the prices.
+	// The updates in between refresh the prices along their stored routes.
+	fullRecomputeHeightInterval uint64
+
 	updateListeners []domain.PricingUpdateListener
 	quoteDenom      string
 
@@ -23,24 +32,53 @@ type pricingWorker struct {
 
 	priceUpdateBaseDenomMap map[string]struct{}
 
+	// trackedBaseDenoms is the set of all base denoms ever queued for the price update.
+	trackedBaseDenoms map[string]struct{}
+
 	tokensUseCase mvc.TokensUsecase
+	// pricingSource is notified of the tracked base denoms. Might be nil.
+	pricingSource domain.PricingSourceAdmin
 
 	logger log.Logger
 }
 
 const (
-	priceUpdateTimeout = time.Minute * 2
+	defaultPriceUpdateTimeout = time.Minute * 2
+
+	defaultFullRecomputeHeightInterval = 50
 )
 
-func New(tokensUseCase mvc.TokensUsecase, quoteDenom string, logger log.Logger) domain.PricingWorker {
+// New creates a new pricing worker.
+// The price updates are aborted once the given context is done or after the given update timeout.
+// Zero update timeout implies the default of two minutes.
+// If the pricing source is non-nil, it is notified of the base denoms tracked by the worker
+// so that it caches their default quote prices indefinitely. The prices are then refreshed along
+// their stored routes, except for every full recompute height interval when they are recomputed with the route search.
+// Zero full recompute height interval implies the default of 50. Without the pricing source, the prices are always
+// recomputed with the route search.
+func New(ctx context.Context, tokensUseCase mvc.TokensUsecase, pricingSource domain.PricingSourceAdmin, quoteDenom string, updateTimeout time.Duration, fullRecomputeHeightInterval uint64, logger log.Logger) domain.PricingWorker {
+	if updateTimeout <= 0 {
+		updateTimeout = defaultPriceUpdateTimeout
+	}
+
+	if fullRecomputeHeightInterval == 0 {
+		fullRecomputeHeightInterval = defaultFullRecomputeHeightInterval
+	}
+
 	return &pricingWorker{
+		ctx:                         ctx,
+		updateTimeout:               updateTimeout,
+		fullRecomputeHeightInterval: fullRecomputeHeightInterval,
+
 		updateListeners: []domain.PricingUpdateListener{},
 		quoteDenom:      quoteDenom,
 		tokensUseCase:   tokensUseCase,
+		pricingSource:   pricingSource,
 
 		isProcessing: atomic.Bool{},
 
 		priceUpdateBaseDenomMap: make(map[string]struct{}),
+		trackedBaseDenoms:       make(map[string]struct{}),
 
 		logger: logger,
 	}
@@ -49,8 +87,24 @@ func New(tokensUseCase mvc.TokensUsecase, quoteDenom string, logger log.Logger)
 // UpdatePrices implements PricingWorker.
 func (p *pricingWorker) UpdatePricesAsync(height uint64, baseDenoms map[string]struct{}) {
 	// Queue pricing updates
+	hasNewTrackedDenoms := false
 	for baseDenom := range baseDenoms {
 		p.priceUpdateBaseDenomMap[baseDenom] = struct{}{}
+
+		if _, ok := p.trackedBaseDenoms[baseDenom]; !ok {
+			p.trackedBaseDenoms[baseDenom] = struct{}{}
+			hasNewTrackedDenoms = true
+		}
+	}
+
+	// Notify the pricing source before the update so that the updated prices are cached indefinitely.
+	// A copy is passed since the tracked denoms keep growing.
+	if hasNewTrackedDenoms && p.pricingSource != nil {
+		trackedBaseDenoms := make(map[string]struct{}, len(p.trackedBaseDenoms))
+		for baseDenom := range p.trackedBaseDenoms {
+			trackedBaseDenoms[baseDenom] = struct{}{}
+		}
+		p.pricingSource.SetWorkerTrackedDenoms(trackedBaseDenoms)
 	}
 
 	if p.isProcessing.Load() {
@@ -59,6 +113,13 @@ func (p *pricingWorker) UpdatePricesAsync(height uint64, baseDenoms map[string]s
 		return
 	}
 
+	// Never start an update once the worker is stopping.
+	if p.ctx.Err() != nil {
+		p.logger.Info("pricing update skipped due to worker stopping", zap.Uint64("height", height))
+
+		return
+	}
+
 	p.isProcessing.Store(true)
 
 	// Get all tokens from the queue map
@@ -72,7 +133,7 @@ func (p *pricingWorker) UpdatePricesAsync(height uint64, baseDenoms map[string]s
 }
 
 func (p *pricingWorker) updatePrices(height uint64, baseDenoms []string) {
-	ctx, cancel := context.WithTimeout(context.Background(), priceUpdateTimeout)
+	ctx, cancel := context.WithTimeout(p.ctx, p.updateTimeout)
 	start := time.Now()
 	defer func() {
 		// Reset the processing flag
@@ -86,10 +147,37 @@ func (p *pricingWorker) updatePrices(height uint64, baseDenoms []string) {
 
 	p.logger.Info("starting pricing pre-computation", zap.Uint64("height", height), zap.Int("num_base_denoms", len(baseDenoms)))
 
-	// Note that we recompute prices entirely.
 	// Min osmo liquidity must be zero. The reason is that some pools have TVL incorrectly calculated as zero.
 	// For example, BRNCH / STRDST (1288). As a result, they are incorrectly excluded despite having appropriate liquidity.
-	prices, err := p.tokensUseCase.GetPrices(ctx, baseDenoms, []string{p.quoteDenom}, domain.ChainPricingSourceType, domain.WithRecomputePrices(), domain.WithMinLiquidity(0))
+	pricingOpts := []domain.PricingOption{domain.WithMinLiquidity(0), domain.WithHeight(height)}
+
+	var (
+		prices map[string]map[string]any
+		err    error
+	)
+	if p.pricingSource == nil || height%p.fullRecomputeHeightInterval == 0 {
+		// Recompute prices entirely so that the routes are reselected.
+		prices, err = p.tokensUseCase.GetPrices(ctx, baseDenoms, []string{p.quoteDenom}, domain.ChainPricingSourceType, append([]domain.PricingOption{domain.WithRecomputePrices()}, pricingOpts...)...)
+	} else {
+		// Refresh prices along their stored routes without the route search.
+		// The prices that fail to refresh keep being served from cache, if any, until the next full recompute.
+		if err := p.pricingSource.RefreshDefaultQuotePrices(ctx, baseDenoms, pricingOpts...); err != nil {
+			p.logger.Error("failed to refresh prices", zap.Error(err))
+
+			// Increase error counter
+			domain.SQSPricingWorkerComputeErrorCounter.WithLabelValues(strconv.FormatUint(height, 10)).Inc()
+		}
+
+		prices, err = p.tokensUseCase.GetPrices(ctx, baseDenoms, []string{p.quoteDenom}, domain.ChainPricingSourceType, pricingOpts...)
+	}
+
+	// Abort cleanly without warming or propagating the partial prices once the worker is stopping.
+	if p.ctx.Err() != nil {
+		p.logger.Info("pricing pre-computation aborted due to worker stopping", zap.Uint64("height", height))
+
+		return
+	}
+
 	if err != nil {
 		p.logger.Error("failed to pre-compute prices", zap.Error(err))
 
@@ -97,6 +185,13 @@ func (p *pricingWorker) updatePrices(height uint64, baseDenoms []string) {
 		domain.SQSPricingWorkerComputeErrorCounter.WithLabelValues(strconv.FormatUint(height, 10)).Inc()
 	}
 
+	// Warm the reverse default quote prices from the freshly computed forward prices.
+	if p.pricingSource != nil {
+		if err := p.pricingSource.WarmReverseDefaultQuotePrices(); err != nil {
+			p.logger.Error("failed to warm reverse default quote prices", zap.Error(err))
+		}
+	}
+
 	// Update listeners
 	for _, listener := range p.updateListeners {
 		// Ignore errors
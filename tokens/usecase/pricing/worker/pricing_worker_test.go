@@ -1,6 +1,7 @@
 package worker_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -90,7 +91,7 @@ func (s *PricingWorkerTestSuite) TestUpdatePricesAsync() {
 			s.Require().NoError(err)
 
 			// Create a pricing worker
-			pricingWorker := worker.New(mainnetUsecase.Tokens, defaultQuoteDenom, &log.NoOpLogger{})
+			pricingWorker := worker.New(context.Background(), mainnetUsecase.Tokens, nil, defaultQuoteDenom, 0, 0, &log.NoOpLogger{})
 
 			// Create a mock listener
 			mockPricingUpdateListener := mocks.NewPricingListenerMock(time.Second * 5)
@@ -143,6 +144,47 @@ func (s *PricingWorkerTestSuite) TestUpdatePricesAsync() {
 	}
 }
 
+// TestUpdatePricesAsync_RefreshesBetweenFullRecomputes tests that the prices are refreshed along their stored routes
+// via the pricing source except for every full recompute height interval.
+func (s *PricingWorkerTestSuite) TestUpdatePricesAsync_RefreshesBetweenFullRecomputes() {
+	const fullRecomputeHeightInterval = 2
+
+	mainnetUsecase := s.SetupDefaultRouterAndPoolsUsecase()
+
+	defaultQuoteDenom, err := mainnetUsecase.Tokens.GetChainDenom(defaultPricingConfig.DefaultQuoteHumanDenom)
+	s.Require().NoError(err)
+
+	refreshedBaseDenoms := [][]string{}
+	pricingSource := &mocks.PricingSourceMock{
+		RefreshDefaultQuotePricesFunc: func(ctx context.Context, baseDenoms []string, opts ...domain.PricingOption) error {
+			refreshedBaseDenoms = append(refreshedBaseDenoms, baseDenoms)
+			return nil
+		},
+		SetWorkerTrackedDenomsFunc:        func(denoms map[string]struct{}) {},
+		WarmReverseDefaultQuotePricesFunc: func() error { return nil },
+	}
+
+	pricingWorker := worker.New(context.Background(), mainnetUsecase.Tokens, pricingSource, defaultQuoteDenom, 0, fullRecomputeHeightInterval, &log.NoOpLogger{})
+
+	mockPricingUpdateListener := mocks.NewPricingListenerMock(time.Second * 5)
+	pricingWorker.RegisterListener(mockPricingUpdateListener)
+
+	baseDenoms := map[string]struct{}{ATOM: {}}
+
+	// Refreshed between the full recomputes.
+	pricingWorker.UpdatePricesAsync(fullRecomputeHeightInterval+1, baseDenoms)
+	s.Require().False(mockPricingUpdateListener.WaitOrTimeout())
+	s.Require().Equal([][]string{{ATOM}}, refreshedBaseDenoms)
+	s.ValidatePrices(baseDenoms, defaultQuoteDenom, mockPricingUpdateListener.PricesBaseQuteDenomMap)
+
+	// Recomputed entirely at the full recompute height interval.
+	s.Require().Eventually(func() bool { return !pricingWorker.IsProcessing() }, time.Second, time.Millisecond)
+	pricingWorker.UpdatePricesAsync(2*fullRecomputeHeightInterval, baseDenoms)
+	s.Require().False(mockPricingUpdateListener.WaitOrTimeout())
+	s.Require().Equal([][]string{{ATOM}}, refreshedBaseDenoms)
+	s.ValidatePrices(baseDenoms, defaultQuoteDenom, mockPricingUpdateListener.PricesBaseQuteDenomMap)
+}
+
 func (s *PricingWorkerTestSuite) TestGetPrices_Chain_FindUnsupportedTokens() {
 	env := os.Getenv("CI_SQS_PRICING_WORKER_TEST")
 	if env != "true" {
@@ -166,7 +208,7 @@ func (s *PricingWorkerTestSuite) TestGetPrices_Chain_FindUnsupportedTokens() {
 	s.Require().NoError(err)
 
 	// Create a pricing worker
-	pricingWorker := worker.New(mainnetUsecase.Tokens, defaultQuoteDenom, &log.NoOpLogger{})
+	pricingWorker := worker.New(context.Background(), mainnetUsecase.Tokens, nil, defaultQuoteDenom, 0, 0, &log.NoOpLogger{})
 
 	// Create a mock listener
 	mockPricingUpdateListener := mocks.NewPricingListenerMock(time.Minute * 5)
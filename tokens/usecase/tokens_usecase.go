@@ -28,6 +28,11 @@ type tokensUseCase struct {
 	// We persist pricing strategies across endpoint calls as they
 	// may cache responses internally.
 	pricingStrategyMap map[domain.PricingSourceType]domain.PricingSource
+
+	// poolVolumes maps pool IDs to their recent trade volume.
+	// Replaced as a whole via SetPoolVolumes.
+	poolVolumes   map[uint64]osmomath.Int
+	poolVolumesMu sync.RWMutex
 }
 
 // Struct to represent the JSON structure
@@ -117,6 +122,8 @@ func NewTokensUsecase(tokenMetadataByChainDenom map[string]domain.Token) mvc.Tok
 		pricingStrategyMap: map[domain.PricingSourceType]domain.PricingSource{},
 
 		chainDenoms: chainDenoms,
+
+		poolVolumes: map[uint64]osmomath.Int{},
 	}
 }
 
@@ -126,12 +133,30 @@ func (t *tokensUseCase) GetChainDenom(humanDenom string) (string, error) {
 
 	chainDenom, ok := t.humanToChainDenomMap[humanDenomLowerCase]
 	if !ok {
-		return "", fmt.Errorf("chain denom for human denom (%s) is not found", humanDenomLowerCase)
+		return "", domain.UnknownHumanDenomError{HumanDenom: humanDenomLowerCase}
 	}
 
 	return chainDenom, nil
 }
 
+// GetChainDenoms implements mvc.TokensUsecase.
+func (t *tokensUseCase) GetChainDenoms(humanDenoms []string) (map[string]string, []error) {
+	chainDenoms := make(map[string]string, len(humanDenoms))
+	errs := make([]error, len(humanDenoms))
+
+	for i, humanDenom := range humanDenoms {
+		chainDenom, err := t.GetChainDenom(humanDenom)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		chainDenoms[humanDenom] = chainDenom
+	}
+
+	return chainDenoms, errs
+}
+
 // GetMetadataByChainDenom implements mvc.TokensUsecase.
 func (t *tokensUseCase) GetMetadataByChainDenom(denom string) (domain.Token, error) {
 	token, ok := t.tokenMetadataByChainDenom[denom]
@@ -169,17 +194,26 @@ func (t *tokensUseCase) GetChainScalingFactorByDenomMut(denom string) (osmomath.
 }
 
 // GetPrices implements pricing.PricingStrategy.
+// Stops starting the computations for the remaining base denoms and returns the context error
+// promptly once the context is done.
 func (t *tokensUseCase) GetPrices(ctx context.Context, baseDenoms []string, quoteDenoms []string, pricingSourceType domain.PricingSourceType, opts ...domain.PricingOption) (map[string]map[string]any, error) {
 	byBaseDenomResult := make(map[string]map[string]any, len(baseDenoms))
 
 	// Create a channel to communicate the results
-	resultsChan := make(chan priceResults, len(quoteDenoms))
+	// It is buffered for all base denoms so that the goroutines never block after an early return.
+	resultsChan := make(chan priceResults, len(baseDenoms))
 
 	// Use a WaitGroup to wait for all goroutines to finish
 	var wg sync.WaitGroup
 
 	// For every base denom, create a map with quote denom prices.
+	numStarted := 0
 	for _, baseDenom := range baseDenoms {
+		if ctx.Err() != nil {
+			break
+		}
+
+		numStarted++
 		wg.Add(1)
 		go func(baseDenom string) {
 			defer wg.Done()
@@ -200,8 +234,13 @@ func (t *tokensUseCase) GetPrices(ctx context.Context, baseDenoms []string, quot
 	}()
 
 	// Read from the results channel and update the map
-	for range baseDenoms {
-		result := <-resultsChan
+	for i := 0; i < numStarted; i++ {
+		var result priceResults
+		select {
+		case result = <-resultsChan:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 
 		if result.err != nil {
 			return nil, result.err
@@ -209,6 +248,12 @@ func (t *tokensUseCase) GetPrices(ctx context.Context, baseDenoms []string, quot
 		byBaseDenomResult[result.baseDenom] = result.prices
 	}
 
+	// The failed prices are zeroed rather than returned as errors so a cancellation mid-computation
+	// is only detectable here. The context error is returned and the partial results are dropped.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return byBaseDenomResult, nil
 }
 
@@ -371,3 +416,20 @@ func (t *tokensUseCase) IsValidChainDenom(chainDenom string) bool {
 	metaData, ok := t.tokenMetadataByChainDenom[chainDenom]
 	return ok && !metaData.IsUnlisted
 }
+
+// GetPoolVolume implements mvc.TokensUsecase.
+func (t *tokensUseCase) GetPoolVolume(poolID uint64) (osmomath.Int, bool) {
+	t.poolVolumesMu.RLock()
+	defer t.poolVolumesMu.RUnlock()
+
+	volume, ok := t.poolVolumes[poolID]
+	return volume, ok
+}
+
+// SetPoolVolumes implements mvc.TokensUsecase.
+func (t *tokensUseCase) SetPoolVolumes(poolVolumes map[uint64]osmomath.Int) {
+	t.poolVolumesMu.Lock()
+	defer t.poolVolumesMu.Unlock()
+
+	t.poolVolumes = poolVolumes
+}
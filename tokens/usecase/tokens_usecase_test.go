@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/osmosis-labs/osmosis/osmomath"
 	"github.com/osmosis-labs/sqs/domain"
 	"github.com/osmosis-labs/sqs/domain/cache"
+	"github.com/osmosis-labs/sqs/domain/mocks"
 	"github.com/osmosis-labs/sqs/router/usecase/routertesting"
 	tokensusecase "github.com/osmosis-labs/sqs/tokens/usecase"
 )
@@ -46,12 +48,13 @@ var (
 	AAVE_UNLISTED = "ibc/384E5DD50BDE042E1AAF51F312B55F08F95BC985C503880189258B4D9374CBBE"
 
 	defaultPricingRouterConfig = domain.RouterConfig{
-		PreferredPoolIDs:  []uint64{},
-		MaxRoutes:         5,
-		MaxPoolsPerRoute:  3,
-		MaxSplitRoutes:    3,
-		MinOSMOLiquidity:  50,
-		RouteCacheEnabled: true,
+		PreferredPoolIDs:   []uint64{},
+		MaxRoutes:          5,
+		MaxPoolsPerRoute:   3,
+		MaxSplitRoutes:     3,
+		MaxSplitIterations: 10,
+		MinOSMOLiquidity:   50,
+		RouteCacheEnabled:  true,
 	}
 
 	defaultPricingConfig = domain.PricingConfig{
@@ -119,6 +122,34 @@ func (s *TokensUseCaseTestSuite) TestParseAssetList() {
 	s.Require().True(aaveToken.IsUnlisted)
 }
 
+// Validates that GetChainDenoms resolves the valid human denoms case-insensitively
+// and returns the errors for the invalid ones at their indexes.
+func (s *TokensUseCaseTestSuite) TestGetChainDenoms() {
+	tokensUsecase := tokensusecase.NewTokensUsecase(map[string]domain.Token{
+		ATOM: {HumanDenom: "atom", Precision: defaultCosmosExponent},
+		USDC: {HumanDenom: "usdc", Precision: defaultCosmosExponent},
+	})
+
+	humanDenoms := []string{"atom", "unknown", "USDC", ""}
+
+	chainDenoms, errs := tokensUsecase.GetChainDenoms(humanDenoms)
+	s.Require().Equal(map[string]string{
+		"atom": ATOM,
+		"USDC": USDC,
+	}, chainDenoms)
+
+	s.Require().Len(errs, len(humanDenoms))
+	s.Require().NoError(errs[0])
+	s.Require().ErrorIs(errs[1], domain.UnknownHumanDenomError{HumanDenom: "unknown"})
+	s.Require().NoError(errs[2])
+	s.Require().Error(errs[3])
+
+	// No human denoms resolve to no chain denoms.
+	chainDenoms, errs = tokensUsecase.GetChainDenoms(nil)
+	s.Require().Empty(chainDenoms)
+	s.Require().Empty(errs)
+}
+
 func (s *TokensUseCaseTestSuite) TestParseExponents_Testnet() {
 	s.T().Skip("skip the test that does network call and is used for debugging")
 
@@ -209,6 +240,35 @@ func (s *TokensUseCaseTestSuite) TestGetPrices_Chain_Specific() {
 	fmt.Println(price)
 }
 
+// Validates that GetPrices returns promptly with the context error once the context is canceled
+// mid-computation rather than zeroing the prices that fail due to the cancellation.
+func (s *TokensUseCaseTestSuite) TestGetPrices_ContextCanceled() {
+	tokensUsecase := tokensusecase.NewTokensUsecase(map[string]domain.Token{
+		UOSMO: {HumanDenom: "osmo", Precision: defaultCosmosExponent},
+		ATOM:  {HumanDenom: "atom", Precision: defaultCosmosExponent},
+		USDC:  {HumanDenom: "usdc", Precision: defaultCosmosExponent},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancels the context on the first computation and blocks all of them until the cancellation.
+	var cancelOnce sync.Once
+	tokensUsecase.RegisterPricingStrategy(domain.ChainPricingSourceType, &mocks.PricingSourceMock{
+		GetPriceFunc: func(ctx context.Context, baseDenom string, quoteDenom string, opts ...domain.PricingOption) (osmomath.BigDec, error) {
+			cancelOnce.Do(cancel)
+			<-ctx.Done()
+			return osmomath.BigDec{}, ctx.Err()
+		},
+	})
+
+	start := time.Now()
+	prices, err := tokensUsecase.GetPrices(ctx, []string{UOSMO, ATOM}, []string{USDC}, domain.ChainPricingSourceType)
+	s.Require().ErrorIs(err, context.Canceled)
+	s.Require().Nil(prices)
+	s.Require().Less(time.Since(start), time.Second)
+}
+
 // Test to validate the pricing options work as expected.
 // Currently, only tests recompute pricing options. In the future, we also add pricing options for the source,
 // once more sources are supported.
@@ -294,7 +354,7 @@ func (s *TokensUseCaseTestSuite) TestGetPrices_Chain_PricingOptions() {
 
 			// Pre-set cache if configured.
 			if !tt.cachedPrice.IsNil() {
-				baseQuoteCacheKey := domain.FormatPricingCacheKey(defaultBase, defaultQuote)
+				baseQuoteCacheKey := domain.MustFormatPricingCacheKeyWithNamespace(domain.ChainPricingCacheNamespace, defaultBase, defaultQuote)
 				pricingCache.Set(baseQuoteCacheKey, tt.cachedPrice, defaultPricingCacheExpiry)
 			}
 